@@ -5,13 +5,19 @@ import (
 )
 
 // Backend describes an object that maintains state about a Magic: the
-// Gathering inventory
+// Gathering inventory.
+//
+// OpenTransfer only checks that the from user keeps enough of each card; the
+// cards change keeper when CloseTransfer is called. CloseTransfer and
+// CancelTransfer return ErrTransferClosed for a transfer that's already
+// closed. AddUser returns ErrUserExists for a username that's taken, unlike
+// AddUserIfNotExist, which returns the existing User.
 type Backend interface {
 	GetCardsByOracleID(ctx context.Context, oracleID string, limit, offset uint) ([]*CardRow, error)
 	GetCardsByOwner(ctx context.Context, owner string, limit, offset uint) ([]*CardRow, error)
 	GetCardsByKeeper(ctx context.Context, keeper string, limit, offset uint) ([]*CardRow, error)
 	AddCards(ctx context.Context, cardRows []*CardRow) error
-	ModifyCardQuantity(ctx context.Context, owner, keeper, scryfallID string, foil bool, quantity uint) error
+	ModifyCardQuantity(ctx context.Context, owner, keeper string, card *Card, quantity uint) error
 
 	GetRequestsByRequestor(ctx context.Context, requestor string, limit, offset uint) ([]*Request, error)
 	GetRequestByID(ctx context.Context, id int64, limit, offset uint) (*Request, error)
@@ -24,7 +30,34 @@ type Backend interface {
 	GetTransferByID(ctx context.Context, id int64, limit, offset uint) (*Transfer, error)
 	OpenTransfer(ctx context.Context, toUser, fromUser string, request *int64, rows []*TransferredCards) (*Transfer, error)
 	CloseTransfer(ctx context.Context, id int64) error
+	CancelTransfer(ctx context.Context, id int64) error
+
+	GetDecksByOwner(ctx context.Context, owner string, limit, offset uint) ([]*Deck, error)
+	GetDeckByID(ctx context.Context, id int64, limit, offset uint) (*Deck, error)
+	CreateDeck(ctx context.Context, owner, name string, rows []*DeckCards) (*Deck, error)
+	UpdateDeck(ctx context.Context, id int64, name string, rows []*DeckCards) error
 
 	GetUserByUsername(ctx context.Context, username string) (*User, error)
+	AddUser(ctx context.Context, username string) (*User, error)
 	AddUserIfNotExist(ctx context.Context, username string) (*User, error)
+
+	GetUserByIdentity(ctx context.Context, provider, workspace, externalID string) (*User, error)
+	LinkIdentity(ctx context.Context, identity *Identity) error
+}
+
+// ListAll returns every row listed by list for key, such as a Backend's
+// GetCardsByOwner for an owner or GetCardsByOracleID for an oracle ID, by
+// listing MaxListLimit rows at a time
+func ListAll[K, R any](ctx context.Context, list func(ctx context.Context, key K, limit, offset uint) ([]R, error), key K) ([]R, error) {
+	all := make([]R, 0)
+	for offset := uint(0); ; offset += MaxListLimit {
+		rows, err := list(ctx, key, MaxListLimit, offset)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, rows...)
+		if len(rows) < MaxListLimit {
+			return all, nil
+		}
+	}
 }
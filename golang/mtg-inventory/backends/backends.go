@@ -0,0 +1,86 @@
+/*
+Package backends opens any of the Backend implementations by name, so that
+executables can choose one through configuration.
+*/
+package backends
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/backends/memory"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/backends/postgres"
+	sqlbackend "github.com/benrm/mtg-inventory/golang/mtg-inventory/backends/sql"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/backends/sqlite"
+	_ "github.com/go-sql-driver/mysql" // registers the "mysql" driver
+)
+
+const (
+	// MySQL is the name of the Backend in the sql package
+	MySQL = "mysql"
+
+	// Postgres is the name of the Backend in the postgres package
+	Postgres = "postgres"
+
+	// SQLite is the name of the Backend in the sqlite package
+	SQLite = "sqlite"
+
+	// Memory is the name of the Backend in the memory package
+	Memory = "memory"
+)
+
+// Names contains every name that Open accepts
+var Names = []string{MySQL, Postgres, SQLite, Memory}
+
+// DSNEnvVar returns the name of the environment variable conventionally used
+// to hold the data source name for the named Backend, e.g. MYSQL_DSN
+func DSNEnvVar(name string) string {
+	return strings.ToUpper(name) + "_DSN"
+}
+
+// Open opens the named Backend. The data source name is a DSN or URL for MySQL
+// and PostgreSQL, a file path for SQLite, and ignored for Memory.
+func Open(ctx context.Context, name, dsn string) (inventory.Backend, error) {
+	switch name {
+	case MySQL:
+		db, err := sql.Open("mysql", dsn)
+		if err != nil {
+			return nil, fmt.Errorf("error opening MySQL database: %w", err)
+		}
+		return sqlbackend.NewBackend(db), nil
+	case Postgres:
+		db, err := sql.Open(postgres.DriverName, dsn)
+		if err != nil {
+			return nil, fmt.Errorf("error opening PostgreSQL database: %w", err)
+		}
+		return postgres.NewBackend(db), nil
+	case SQLite:
+		backend, err := sqlite.Open(dsn)
+		if err != nil {
+			return nil, fmt.Errorf("error opening SQLite database: %w", err)
+		}
+		return backend, nil
+	case Memory:
+		return memory.NewBackend(), nil
+	default:
+		return nil, fmt.Errorf("unknown backend %q, must be one of %s", name, strings.Join(Names, ", "))
+	}
+}
+
+// Migrator is implemented by Backends with a schema that can be migrated
+type Migrator interface {
+	Migrate(ctx context.Context) error
+}
+
+// Migrate brings the schema of backend up to date. Backends without a schema,
+// such as Memory, are left alone.
+func Migrate(ctx context.Context, backend inventory.Backend) error {
+	migrator, ok := backend.(Migrator)
+	if !ok {
+		return nil
+	}
+	return migrator.Migrate(ctx)
+}
@@ -0,0 +1,1049 @@
+/*
+Package backendtest contains a conformance test suite that every Backend
+implementation should pass.
+*/
+package backendtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+)
+
+// RunConformance runs the conformance suite against the Backends returned by
+// newBackend, which is called once per subtest. Every name the suite creates
+// is unique to its subtest, so a Backend may be shared between calls and need
+// not start empty.
+func RunConformance(t *testing.T, newBackend func() inventory.Backend) {
+	t.Run("Users", func(t *testing.T) {
+		testUsers(t, newBackend(), newPrefix(t))
+	})
+	t.Run("Identities", func(t *testing.T) {
+		testIdentities(t, newBackend(), newPrefix(t))
+	})
+	t.Run("AddCards", func(t *testing.T) {
+		testAddCards(t, newBackend(), newPrefix(t))
+	})
+	t.Run("CardCopies", func(t *testing.T) {
+		testCardCopies(t, newBackend(), newPrefix(t))
+	})
+	t.Run("ModifyCardQuantity", func(t *testing.T) {
+		testModifyCardQuantity(t, newBackend(), newPrefix(t))
+	})
+	t.Run("CardPagination", func(t *testing.T) {
+		testCardPagination(t, newBackend(), newPrefix(t))
+	})
+	t.Run("Requests", func(t *testing.T) {
+		testRequests(t, newBackend(), newPrefix(t))
+	})
+	t.Run("Transfers", func(t *testing.T) {
+		testTransfers(t, newBackend(), newPrefix(t))
+	})
+	t.Run("TransferErrors", func(t *testing.T) {
+		testTransferErrors(t, newBackend(), newPrefix(t))
+	})
+	t.Run("Decks", func(t *testing.T) {
+		testDecks(t, newBackend(), newPrefix(t))
+	})
+	t.Run("DeckErrors", func(t *testing.T) {
+		testDeckErrors(t, newBackend(), newPrefix(t))
+	})
+}
+
+// newPrefix returns a string that is unique to the running subtest
+func newPrefix(t *testing.T) string {
+	name := t.Name()
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	return fmt.Sprintf("%s-%d", name, time.Now().UnixNano())
+}
+
+func addUser(t *testing.T, b inventory.Backend, username string) string {
+	t.Helper()
+	user, err := b.AddUserIfNotExist(context.Background(), username)
+	if err != nil {
+		t.Fatalf("Failed to add user %q: %s", username, err.Error())
+	}
+	if user.Username != username {
+		t.Fatalf("Added user %q, got %q", username, user.Username)
+	}
+	return username
+}
+
+func newCard(prefix string, i int) *inventory.Card {
+	return &inventory.Card{
+		Name:       fmt.Sprintf("%s-card-name-%03d", prefix, i),
+		OracleID:   fmt.Sprintf("%s-oracle-ID-%03d", prefix, i),
+		ScryfallID: fmt.Sprintf("%s-scryfall-ID-%03d", prefix, i),
+		Finish:     inventory.FinishNonfoil,
+		Condition:  inventory.ConditionNearMint,
+	}
+}
+
+func expectRowError(t *testing.T, err, target error, row any) {
+	t.Helper()
+	if !errors.Is(err, target) {
+		t.Fatalf("Expected error %q, got: %v", target, err)
+	}
+	var rowErr *inventory.RowError
+	if !errors.As(err, &rowErr) {
+		t.Fatalf("Expected a RowError, got: %v", err)
+	}
+	if row != nil && rowErr.Row != row {
+		t.Fatalf("Expected RowError for row %+v, got %+v", row, rowErr.Row)
+	}
+}
+
+// findCardRow returns the quantity of the card row matching the arguments, or
+// zero if there is none
+func findCardRow(cardRows []*inventory.CardRow, card *inventory.Card, owner, keeper string) uint {
+	for _, cardRow := range cardRows {
+		if cardRow.Card.ScryfallID == card.ScryfallID && cardRow.Card.Finish == card.Finish && cardRow.Card.Condition == card.Condition &&
+			cardRow.Card.Language == card.Language && cardRow.Owner == owner && cardRow.Keeper == keeper {
+			return cardRow.Quantity
+		}
+	}
+	return 0
+}
+
+func testUsers(t *testing.T, b inventory.Backend, prefix string) {
+	ctx := context.Background()
+	username := addUser(t, b, prefix+"-user1")
+
+	user, err := b.AddUserIfNotExist(ctx, username)
+	if err != nil {
+		t.Fatalf("Failed to add existing user: %s", err.Error())
+	}
+	if user.Username != username {
+		t.Fatalf("Added existing user %q, got %q", username, user.Username)
+	}
+
+	_, err = b.AddUser(ctx, username)
+	if !errors.Is(err, inventory.ErrUserExists) {
+		t.Fatalf("Expected ErrUserExists adding existing user, got: %v", err)
+	}
+	user, err = b.AddUser(ctx, prefix+"-user2")
+	if err != nil {
+		t.Fatalf("Failed to add new user: %s", err.Error())
+	}
+	if user.Username != prefix+"-user2" {
+		t.Fatalf("Added user %q, got %q", prefix+"-user2", user.Username)
+	}
+
+	user, err = b.GetUserByUsername(ctx, username)
+	if err != nil {
+		t.Fatalf("Failed to get user by username: %s", err.Error())
+	}
+	if user.Username != username {
+		t.Fatalf("Got user %q, expected %q", user.Username, username)
+	}
+
+	_, err = b.GetUserByUsername(ctx, prefix+"-nobody")
+	if !errors.Is(err, inventory.ErrUserNoExist) {
+		t.Fatalf("Expected ErrUserNoExist getting nonexistent user, got: %v", err)
+	}
+}
+
+func testIdentities(t *testing.T, b inventory.Backend, prefix string) {
+	ctx := context.Background()
+	user1 := addUser(t, b, prefix+"-user1")
+	user2 := addUser(t, b, prefix+"-user2")
+	workspace := prefix + "-workspace"
+
+	_, err := b.GetUserByIdentity(ctx, "test", workspace, "U1")
+	if !errors.Is(err, inventory.ErrIdentityNoExist) {
+		t.Fatalf("Expected ErrIdentityNoExist getting unlinked identity, got: %v", err)
+	}
+
+	err = b.LinkIdentity(ctx, &inventory.Identity{Provider: "test", Workspace: workspace, ExternalID: "U1", Username: user1})
+	if err != nil {
+		t.Fatalf("Failed to link identity: %s", err.Error())
+	}
+	user, err := b.GetUserByIdentity(ctx, "test", workspace, "U1")
+	if err != nil {
+		t.Fatalf("Failed to get user by identity: %s", err.Error())
+	}
+	if user.Username != user1 {
+		t.Fatalf("Got user %q by identity, expected %q", user.Username, user1)
+	}
+
+	// The same external ID in another workspace or provider is another identity
+	_, err = b.GetUserByIdentity(ctx, "test", workspace+"-other", "U1")
+	if !errors.Is(err, inventory.ErrIdentityNoExist) {
+		t.Fatalf("Expected ErrIdentityNoExist in another workspace, got: %v", err)
+	}
+	_, err = b.GetUserByIdentity(ctx, "other", workspace, "U1")
+	if !errors.Is(err, inventory.ErrIdentityNoExist) {
+		t.Fatalf("Expected ErrIdentityNoExist for another provider, got: %v", err)
+	}
+
+	// Linking again replaces the user
+	err = b.LinkIdentity(ctx, &inventory.Identity{Provider: "test", Workspace: workspace, ExternalID: "U1", Username: user2})
+	if err != nil {
+		t.Fatalf("Failed to relink identity: %s", err.Error())
+	}
+	user, err = b.GetUserByIdentity(ctx, "test", workspace, "U1")
+	if err != nil {
+		t.Fatalf("Failed to get user by relinked identity: %s", err.Error())
+	}
+	if user.Username != user2 {
+		t.Fatalf("Got user %q by relinked identity, expected %q", user.Username, user2)
+	}
+
+	err = b.LinkIdentity(ctx, &inventory.Identity{Provider: "test", Workspace: workspace, ExternalID: "U2", Username: prefix + "-nobody"})
+	if !errors.Is(err, inventory.ErrUserNoExist) {
+		t.Fatalf("Expected ErrUserNoExist linking to nonexistent user, got: %v", err)
+	}
+}
+
+func testAddCards(t *testing.T, b inventory.Backend, prefix string) {
+	ctx := context.Background()
+	user1 := addUser(t, b, prefix+"-user1")
+	user2 := addUser(t, b, prefix+"-user2")
+
+	card1 := newCard(prefix, 1)
+	card2 := newCard(prefix, 2)
+	foilCard2 := *card2
+	foilCard2.Finish = inventory.FinishFoil
+
+	err := b.AddCards(ctx, []*inventory.CardRow{
+		{Quantity: 2, Card: card1, Owner: user1, Keeper: user1},
+		{Quantity: 1, Card: card2, Owner: user1, Keeper: user1},
+		{Quantity: 3, Card: &foilCard2, Owner: user1, Keeper: user2},
+	})
+	if err != nil {
+		t.Fatalf("Failed to add cards: %s", err.Error())
+	}
+
+	err = b.AddCards(ctx, []*inventory.CardRow{
+		{Quantity: 5, Card: card1, Owner: user1, Keeper: user1},
+	})
+	if err != nil {
+		t.Fatalf("Failed to add existing cards: %s", err.Error())
+	}
+
+	cardRows, err := b.GetCardsByOwner(ctx, user1, inventory.MaxListLimit, 0)
+	if err != nil {
+		t.Fatalf("Failed to get cards by owner: %s", err.Error())
+	}
+	if len(cardRows) != 3 {
+		t.Fatalf("Expected 3 card rows by owner, got %d", len(cardRows))
+	}
+	if quantity := findCardRow(cardRows, card1, user1, user1); quantity != 7 {
+		t.Fatalf("Expected 7 copies of %s after upsert, got %d", card1.Name, quantity)
+	}
+	if cardRows[0].Card.Name != card1.Name || cardRows[0].Card.OracleID != card1.OracleID {
+		t.Fatalf("Expected first card row to be %+v, got %+v", card1, cardRows[0].Card)
+	}
+	for i := 1; i < len(cardRows); i++ {
+		if cardRows[i-1].Card.Name > cardRows[i].Card.Name {
+			t.Fatalf("Card rows not ordered by name: %q before %q", cardRows[i-1].Card.Name, cardRows[i].Card.Name)
+		}
+	}
+
+	cardRows, err = b.GetCardsByKeeper(ctx, user2, inventory.MaxListLimit, 0)
+	if err != nil {
+		t.Fatalf("Failed to get cards by keeper: %s", err.Error())
+	}
+	if len(cardRows) != 1 || findCardRow(cardRows, &foilCard2, user1, user2) != 3 {
+		t.Fatalf("Unexpected cards by keeper: %+v", cardRows)
+	}
+
+	cardRows, err = b.GetCardsByOracleID(ctx, card2.OracleID, inventory.MaxListLimit, 0)
+	if err != nil {
+		t.Fatalf("Failed to get cards by oracle ID: %s", err.Error())
+	}
+	if len(cardRows) != 2 {
+		t.Fatalf("Expected 2 card rows by oracle ID, got %d", len(cardRows))
+	}
+	if findCardRow(cardRows, card2, user1, user1) != 1 || findCardRow(cardRows, &foilCard2, user1, user2) != 3 {
+		t.Fatalf("Unexpected cards by oracle ID: %+v", cardRows)
+	}
+
+	cardRows, err = b.GetCardsByOwner(ctx, prefix+"-nobody", inventory.MaxListLimit, 0)
+	if err != nil {
+		t.Fatalf("Failed to get cards by nonexistent owner: %s", err.Error())
+	}
+	if len(cardRows) != 0 {
+		t.Fatalf("Expected no cards for nonexistent owner, got %d", len(cardRows))
+	}
+
+	zeroRow := &inventory.CardRow{Quantity: 0, Card: newCard(prefix, 3), Owner: user1, Keeper: user1}
+	err = b.AddCards(ctx, []*inventory.CardRow{
+		{Quantity: 1, Card: newCard(prefix, 4), Owner: user1, Keeper: user1},
+		zeroRow,
+	})
+	expectRowError(t, err, inventory.ErrZeroCards, zeroRow)
+
+	noCardRow := &inventory.CardRow{Quantity: 1, Owner: user1, Keeper: user1}
+	err = b.AddCards(ctx, []*inventory.CardRow{noCardRow})
+	expectRowError(t, err, inventory.ErrNoCard, noCardRow)
+
+	tooManyRows := make([]*inventory.CardRow, 0, inventory.RowUploadLimit+1)
+	for i := 0; i <= inventory.RowUploadLimit; i++ {
+		tooManyRows = append(tooManyRows, &inventory.CardRow{Quantity: 1, Card: newCard(prefix, 100+i), Owner: user1, Keeper: user1})
+	}
+	err = b.AddCards(ctx, tooManyRows)
+	if !errors.Is(err, inventory.ErrTooManyRows) {
+		t.Fatalf("Expected ErrTooManyRows adding %d rows, got: %v", len(tooManyRows), err)
+	}
+
+	err = b.AddCards(ctx, []*inventory.CardRow{
+		{Quantity: 1, Card: newCard(prefix, 5), Owner: prefix + "-nobody", Keeper: user1},
+	})
+	if !errors.Is(err, inventory.ErrUserNoExist) {
+		t.Fatalf("Expected ErrUserNoExist adding cards for nonexistent owner, got: %v", err)
+	}
+
+	cardRows, err = b.GetCardsByOwner(ctx, user1, inventory.MaxListLimit, 0)
+	if err != nil {
+		t.Fatalf("Failed to get cards by owner: %s", err.Error())
+	}
+	if len(cardRows) != 3 {
+		t.Fatalf("Expected failed uploads to add no cards, got %d card rows", len(cardRows))
+	}
+}
+
+func testCardCopies(t *testing.T, b inventory.Backend, prefix string) {
+	ctx := context.Background()
+	user1 := addUser(t, b, prefix+"-user1")
+	user2 := addUser(t, b, prefix+"-user2")
+
+	card := newCard(prefix, 1)
+	etched := *card
+	etched.Finish = inventory.FinishEtched
+	played := *card
+	played.Condition = inventory.ConditionHeavilyPlayed
+	japanese := *card
+	japanese.Language = "ja"
+	err := b.AddCards(ctx, []*inventory.CardRow{
+		{Quantity: 1, Card: card, Owner: user1, Keeper: user1},
+		{Quantity: 2, Card: &etched, Owner: user1, Keeper: user1},
+		{Quantity: 3, Card: &played, Owner: user1, Keeper: user1},
+		{Quantity: 4, Card: &japanese, Owner: user1, Keeper: user1},
+	})
+	if err != nil {
+		t.Fatalf("Failed to add cards: %s", err.Error())
+	}
+
+	cardRows, err := b.GetCardsByOracleID(ctx, card.OracleID, inventory.MaxListLimit, 0)
+	if err != nil {
+		t.Fatalf("Failed to get cards by oracle ID: %s", err.Error())
+	}
+	if len(cardRows) != 4 {
+		t.Fatalf("Expected a row for each copy, got %d rows", len(cardRows))
+	}
+	for copyCard, quantity := range map[*inventory.Card]uint{card: 1, &etched: 2, &played: 3, &japanese: 4} {
+		if found := findCardRow(cardRows, copyCard, user1, user1); found != quantity {
+			t.Fatalf("Expected %d of %+v, got %d", quantity, copyCard, found)
+		}
+	}
+
+	err = b.ModifyCardQuantity(ctx, user1, user1, &played, 5)
+	if err != nil {
+		t.Fatalf("Failed to modify card quantity: %s", err.Error())
+	}
+	transfer, err := b.OpenTransfer(ctx, user2, user1, nil, []*inventory.TransferredCards{
+		{Quantity: 4, Card: &japanese, Owner: user1},
+	})
+	if err != nil {
+		t.Fatalf("Failed to open transfer: %s", err.Error())
+	}
+	err = b.CloseTransfer(ctx, transfer.ID)
+	if err != nil {
+		t.Fatalf("Failed to close transfer: %s", err.Error())
+	}
+	transfer, err = b.GetTransferByID(ctx, transfer.ID, inventory.MaxListLimit, 0)
+	if err != nil {
+		t.Fatalf("Failed to get transfer by ID: %s", err.Error())
+	}
+	if len(transfer.Cards) != 1 || transfer.Cards[0].Card.Language != "ja" {
+		t.Fatalf("Expected transferred cards to keep their language, got %+v", transfer.Cards)
+	}
+
+	cardRows, err = b.GetCardsByOracleID(ctx, card.OracleID, inventory.MaxListLimit, 0)
+	if err != nil {
+		t.Fatalf("Failed to get cards by oracle ID: %s", err.Error())
+	}
+	if findCardRow(cardRows, card, user1, user1) != 1 || findCardRow(cardRows, &played, user1, user1) != 5 ||
+		findCardRow(cardRows, &japanese, user1, user1) != 0 || findCardRow(cardRows, &japanese, user1, user2) != 4 {
+		t.Fatalf("Unexpected cards after modifying and transferring copies: %+v", cardRows)
+	}
+
+	badFinish := &inventory.CardRow{Quantity: 1, Card: newCard(prefix, 2), Owner: user1, Keeper: user1}
+	badFinish.Card.Finish = "shiny"
+	err = b.AddCards(ctx, []*inventory.CardRow{badFinish})
+	expectRowError(t, err, inventory.ErrInvalidFinish, badFinish)
+
+	badCondition := &inventory.CardRow{Quantity: 1, Card: newCard(prefix, 2), Owner: user1, Keeper: user1}
+	badCondition.Card.Condition = ""
+	err = b.AddCards(ctx, []*inventory.CardRow{badCondition})
+	expectRowError(t, err, inventory.ErrInvalidCondition, badCondition)
+}
+
+func testModifyCardQuantity(t *testing.T, b inventory.Backend, prefix string) {
+	ctx := context.Background()
+	user1 := addUser(t, b, prefix+"-user1")
+
+	card1 := newCard(prefix, 1)
+	card2 := newCard(prefix, 2)
+	err := b.AddCards(ctx, []*inventory.CardRow{
+		{Quantity: 1, Card: card1, Owner: user1, Keeper: user1},
+		{Quantity: 1, Card: card2, Owner: user1, Keeper: user1},
+	})
+	if err != nil {
+		t.Fatalf("Failed to add cards: %s", err.Error())
+	}
+
+	err = b.ModifyCardQuantity(ctx, user1, user1, card1, 7)
+	if err != nil {
+		t.Fatalf("Failed to modify card quantity: %s", err.Error())
+	}
+
+	err = b.ModifyCardQuantity(ctx, user1, user1, card2, 0)
+	if err != nil {
+		t.Fatalf("Failed to modify card quantity to zero: %s", err.Error())
+	}
+
+	cardRows, err := b.GetCardsByOwner(ctx, user1, inventory.MaxListLimit, 0)
+	if err != nil {
+		t.Fatalf("Failed to get cards by owner: %s", err.Error())
+	}
+	if len(cardRows) != 1 {
+		t.Fatalf("Expected a quantity of zero to delete the row, got %d rows", len(cardRows))
+	}
+	if quantity := findCardRow(cardRows, card1, user1, user1); quantity != 7 {
+		t.Fatalf("Expected 7 copies of %s, got %d", card1.Name, quantity)
+	}
+}
+
+func testCardPagination(t *testing.T, b inventory.Backend, prefix string) {
+	ctx := context.Background()
+	user1 := addUser(t, b, prefix+"-user1")
+
+	total := inventory.MaxListLimit + 5
+	for i := 0; i < total; i += inventory.RowUploadLimit {
+		rows := make([]*inventory.CardRow, 0, inventory.RowUploadLimit)
+		for j := i; j < total && j < i+inventory.RowUploadLimit; j++ {
+			rows = append(rows, &inventory.CardRow{Quantity: 1, Card: newCard(prefix, j), Owner: user1, Keeper: user1})
+		}
+		err := b.AddCards(ctx, rows)
+		if err != nil {
+			t.Fatalf("Failed to add cards: %s", err.Error())
+		}
+	}
+
+	cardRows, err := b.GetCardsByOwner(ctx, user1, 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to get cards by owner: %s", err.Error())
+	}
+	if len(cardRows) != inventory.DefaultListLimit {
+		t.Fatalf("Expected a limit of zero to return %d rows, got %d", inventory.DefaultListLimit, len(cardRows))
+	}
+
+	cardRows, err = b.GetCardsByKeeper(ctx, user1, inventory.MaxListLimit+50, 0)
+	if err != nil {
+		t.Fatalf("Failed to get cards by keeper: %s", err.Error())
+	}
+	if len(cardRows) != inventory.MaxListLimit {
+		t.Fatalf("Expected a large limit to return %d rows, got %d", inventory.MaxListLimit, len(cardRows))
+	}
+
+	cardRows, err = b.GetCardsByOwner(ctx, user1, 3, 4)
+	if err != nil {
+		t.Fatalf("Failed to get cards by owner: %s", err.Error())
+	}
+	if len(cardRows) != 3 {
+		t.Fatalf("Expected 3 rows, got %d", len(cardRows))
+	}
+	for i, cardRow := range cardRows {
+		if expected := newCard(prefix, 4+i).Name; cardRow.Card.Name != expected {
+			t.Fatalf("Expected row %d to be %q, got %q", i, expected, cardRow.Card.Name)
+		}
+	}
+
+	cardRows, err = b.GetCardsByOwner(ctx, user1, inventory.MaxListLimit, uint(total-2))
+	if err != nil {
+		t.Fatalf("Failed to get cards by owner: %s", err.Error())
+	}
+	if len(cardRows) != 2 {
+		t.Fatalf("Expected 2 rows at the end, got %d", len(cardRows))
+	}
+
+	cardRows, err = b.GetCardsByOwner(ctx, user1, inventory.MaxListLimit, uint(total))
+	if err != nil {
+		t.Fatalf("Failed to get cards by owner: %s", err.Error())
+	}
+	if len(cardRows) != 0 {
+		t.Fatalf("Expected no rows past the end, got %d", len(cardRows))
+	}
+}
+
+func testRequests(t *testing.T, b inventory.Backend, prefix string) {
+	ctx := context.Background()
+	user1 := addUser(t, b, prefix+"-user1")
+
+	card1 := newCard(prefix, 1)
+	card2 := newCard(prefix, 2)
+	request1, err := b.OpenRequest(ctx, user1, []*inventory.RequestedCards{
+		{Quantity: 3, Name: card2.Name, OracleID: card2.OracleID},
+		{Quantity: 4, Name: card1.Name, OracleID: card1.OracleID},
+	})
+	if err != nil {
+		t.Fatalf("Failed to open request: %s", err.Error())
+	}
+	if request1.Requestor != user1 || request1.Closed != nil {
+		t.Fatalf("Unexpected opened request: %+v", request1)
+	}
+
+	request2, err := b.OpenRequest(ctx, user1, []*inventory.RequestedCards{
+		{Quantity: 1, Name: card1.Name, OracleID: card1.OracleID},
+	})
+	if err != nil {
+		t.Fatalf("Failed to open request: %s", err.Error())
+	}
+	if request1.ID == request2.ID {
+		t.Fatalf("Requests share an ID: %d", request1.ID)
+	}
+
+	request, err := b.GetRequestByID(ctx, request1.ID, inventory.MaxListLimit, 0)
+	if err != nil {
+		t.Fatalf("Failed to get request by ID: %s", err.Error())
+	}
+	if request.ID != request1.ID || request.Requestor != user1 || request.Closed != nil {
+		t.Fatalf("Unexpected request by ID: %+v", request)
+	}
+	if request.Quantity != 7 || len(request.Cards) != 2 {
+		t.Fatalf("Expected 7 cards over 2 rows, got %d cards over %d rows", request.Quantity, len(request.Cards))
+	}
+	if request.Cards[0].Name != card1.Name || request.Cards[0].OracleID != card1.OracleID || request.Cards[0].Quantity != 4 {
+		t.Fatalf("Unexpected first requested cards: %+v", request.Cards[0])
+	}
+
+	request, err = b.GetRequestByID(ctx, request1.ID, 1, 1)
+	if err != nil {
+		t.Fatalf("Failed to get request by ID: %s", err.Error())
+	}
+	if len(request.Cards) != 1 || request.Cards[0].Name != card2.Name {
+		t.Fatalf("Unexpected paginated requested cards: %+v", request.Cards)
+	}
+
+	requests, err := b.GetRequestsByRequestor(ctx, user1, inventory.MaxListLimit, 0)
+	if err != nil {
+		t.Fatalf("Failed to get requests by requestor: %s", err.Error())
+	}
+	if len(requests) != 2 {
+		t.Fatalf("Expected 2 requests, got %d", len(requests))
+	}
+	if requests[0].ID != request1.ID || requests[0].Quantity != 7 || requests[1].ID != request2.ID || requests[1].Quantity != 1 {
+		t.Fatalf("Unexpected requests by requestor: %+v, %+v", requests[0], requests[1])
+	}
+
+	requests, err = b.GetRequestsByRequestor(ctx, user1, 1, 1)
+	if err != nil {
+		t.Fatalf("Failed to get requests by requestor: %s", err.Error())
+	}
+	if len(requests) != 1 || requests[0].ID != request2.ID {
+		t.Fatalf("Unexpected paginated requests by requestor: %+v", requests)
+	}
+
+	err = b.CloseRequest(ctx, request1.ID)
+	if err != nil {
+		t.Fatalf("Failed to close request: %s", err.Error())
+	}
+	request, err = b.GetRequestByID(ctx, request1.ID, inventory.MaxListLimit, 0)
+	if err != nil {
+		t.Fatalf("Failed to get request by ID: %s", err.Error())
+	}
+	if request.Closed == nil {
+		t.Fatalf("Closed request has no closed time")
+	}
+
+	_, err = b.GetRequestByID(ctx, -1, inventory.MaxListLimit, 0)
+	if !errors.Is(err, inventory.ErrRequestNoExist) {
+		t.Fatalf("Expected ErrRequestNoExist getting nonexistent request, got: %v", err)
+	}
+
+	err = b.CloseRequest(ctx, -1)
+	if !errors.Is(err, inventory.ErrRequestNoExist) {
+		t.Fatalf("Expected ErrRequestNoExist closing nonexistent request, got: %v", err)
+	}
+
+	zeroRow := &inventory.RequestedCards{Quantity: 0, Name: card1.Name, OracleID: card1.OracleID}
+	_, err = b.OpenRequest(ctx, user1, []*inventory.RequestedCards{zeroRow})
+	expectRowError(t, err, inventory.ErrZeroCards, zeroRow)
+
+	tooManyRows := make([]*inventory.RequestedCards, 0, inventory.RowUploadLimit+1)
+	for i := 0; i <= inventory.RowUploadLimit; i++ {
+		card := newCard(prefix, 100+i)
+		tooManyRows = append(tooManyRows, &inventory.RequestedCards{Quantity: 1, Name: card.Name, OracleID: card.OracleID})
+	}
+	_, err = b.OpenRequest(ctx, user1, tooManyRows)
+	if !errors.Is(err, inventory.ErrTooManyRows) {
+		t.Fatalf("Expected ErrTooManyRows requesting %d rows, got: %v", len(tooManyRows), err)
+	}
+
+	_, err = b.OpenRequest(ctx, prefix+"-nobody", []*inventory.RequestedCards{
+		{Quantity: 1, Name: card1.Name, OracleID: card1.OracleID},
+	})
+	if !errors.Is(err, inventory.ErrUserNoExist) {
+		t.Fatalf("Expected ErrUserNoExist opening request for nonexistent user, got: %v", err)
+	}
+}
+
+func testTransfers(t *testing.T, b inventory.Backend, prefix string) {
+	ctx := context.Background()
+	user1 := addUser(t, b, prefix+"-user1")
+	user2 := addUser(t, b, prefix+"-user2")
+
+	card1 := newCard(prefix, 1)
+	card2 := newCard(prefix, 2)
+	err := b.AddCards(ctx, []*inventory.CardRow{
+		{Quantity: 4, Card: card1, Owner: user1, Keeper: user1},
+		{Quantity: 1, Card: card2, Owner: user1, Keeper: user1},
+	})
+	if err != nil {
+		t.Fatalf("Failed to add cards: %s", err.Error())
+	}
+
+	request, err := b.OpenRequest(ctx, user2, []*inventory.RequestedCards{
+		{Quantity: 3, Name: card1.Name, OracleID: card1.OracleID},
+	})
+	if err != nil {
+		t.Fatalf("Failed to open request: %s", err.Error())
+	}
+
+	transfer1, err := b.OpenTransfer(ctx, user2, user1, &request.ID, []*inventory.TransferredCards{
+		{Quantity: 3, Card: card1, Owner: user1},
+	})
+	if err != nil {
+		t.Fatalf("Failed to open transfer: %s", err.Error())
+	}
+	if transfer1.ToUser != user2 || transfer1.FromUser != user1 || transfer1.Closed != nil {
+		t.Fatalf("Unexpected opened transfer: %+v", transfer1)
+	}
+	if transfer1.RequestID == nil || *transfer1.RequestID != request.ID {
+		t.Fatalf("Opened transfer has request ID %v, expected %d", transfer1.RequestID, request.ID)
+	}
+
+	cardRows, err := b.GetCardsByOwner(ctx, user1, inventory.MaxListLimit, 0)
+	if err != nil {
+		t.Fatalf("Failed to get cards by owner: %s", err.Error())
+	}
+	if quantity := findCardRow(cardRows, card1, user1, user1); quantity != 4 {
+		t.Fatalf("Expected opening a transfer to leave 4 copies with the keeper, got %d", quantity)
+	}
+
+	transfer2, err := b.OpenTransfer(ctx, user2, user1, nil, []*inventory.TransferredCards{
+		{Quantity: 1, Card: card2, Owner: user1},
+	})
+	if err != nil {
+		t.Fatalf("Failed to open transfer: %s", err.Error())
+	}
+	if transfer2.RequestID != nil {
+		t.Fatalf("Opened transfer has request ID %d, expected none", *transfer2.RequestID)
+	}
+
+	transfer, err := b.GetTransferByID(ctx, transfer1.ID, inventory.MaxListLimit, 0)
+	if err != nil {
+		t.Fatalf("Failed to get transfer by ID: %s", err.Error())
+	}
+	if transfer.ToUser != user2 || transfer.FromUser != user1 || transfer.Closed != nil {
+		t.Fatalf("Unexpected transfer by ID: %+v", transfer)
+	}
+	if len(transfer.Cards) != 1 {
+		t.Fatalf("Expected 1 row of transferred cards, got %d", len(transfer.Cards))
+	}
+	if row := transfer.Cards[0]; row.Quantity != 3 || row.Owner != user1 || row.Card.Name != card1.Name || row.Card.ScryfallID != card1.ScryfallID {
+		t.Fatalf("Unexpected transferred cards: %+v", row)
+	}
+
+	transfers, err := b.GetTransfersByToUser(ctx, user2, inventory.MaxListLimit, 0)
+	if err != nil {
+		t.Fatalf("Failed to get transfers by to user: %s", err.Error())
+	}
+	if len(transfers) != 2 || transfers[0].ID != transfer1.ID || transfers[0].Quantity != 3 || transfers[1].ID != transfer2.ID || transfers[1].Quantity != 1 {
+		t.Fatalf("Unexpected transfers by to user: %+v", transfers)
+	}
+
+	transfers, err = b.GetTransfersByFromUser(ctx, user1, 1, 1)
+	if err != nil {
+		t.Fatalf("Failed to get transfers by from user: %s", err.Error())
+	}
+	if len(transfers) != 1 || transfers[0].ID != transfer2.ID || transfers[0].FromUser != user1 || transfers[0].ToUser != user2 {
+		t.Fatalf("Unexpected paginated transfers by from user: %+v", transfers)
+	}
+
+	transfers, err = b.GetTransfersByRequestID(ctx, request.ID, inventory.MaxListLimit, 0)
+	if err != nil {
+		t.Fatalf("Failed to get transfers by request ID: %s", err.Error())
+	}
+	if len(transfers) != 1 || transfers[0].ID != transfer1.ID {
+		t.Fatalf("Unexpected transfers by request ID: %+v", transfers)
+	}
+
+	err = b.CloseTransfer(ctx, transfer1.ID)
+	if err != nil {
+		t.Fatalf("Failed to close transfer: %s", err.Error())
+	}
+
+	transfer, err = b.GetTransferByID(ctx, transfer1.ID, inventory.MaxListLimit, 0)
+	if err != nil {
+		t.Fatalf("Failed to get transfer by ID: %s", err.Error())
+	}
+	if transfer.Closed == nil {
+		t.Fatalf("Closed transfer has no closed time")
+	}
+
+	cardRows, err = b.GetCardsByOwner(ctx, user1, inventory.MaxListLimit, 0)
+	if err != nil {
+		t.Fatalf("Failed to get cards by owner: %s", err.Error())
+	}
+	if quantity := findCardRow(cardRows, card1, user1, user1); quantity != 1 {
+		t.Fatalf("Expected 1 copy left with the owner after closing, got %d", quantity)
+	}
+	if quantity := findCardRow(cardRows, card1, user1, user2); quantity != 3 {
+		t.Fatalf("Expected 3 copies with the new keeper after closing, got %d", quantity)
+	}
+
+	err = b.CloseTransfer(ctx, transfer1.ID)
+	if !errors.Is(err, inventory.ErrTransferClosed) {
+		t.Fatalf("Expected ErrTransferClosed closing a closed transfer, got: %v", err)
+	}
+
+	err = b.CancelTransfer(ctx, transfer1.ID)
+	if !errors.Is(err, inventory.ErrTransferClosed) {
+		t.Fatalf("Expected ErrTransferClosed canceling a closed transfer, got: %v", err)
+	}
+
+	err = b.CancelTransfer(ctx, transfer2.ID)
+	if err != nil {
+		t.Fatalf("Failed to cancel transfer: %s", err.Error())
+	}
+
+	_, err = b.GetTransferByID(ctx, transfer2.ID, inventory.MaxListLimit, 0)
+	if !errors.Is(err, inventory.ErrTransferNoExist) {
+		t.Fatalf("Expected ErrTransferNoExist getting canceled transfer, got: %v", err)
+	}
+
+	cardRows, err = b.GetCardsByKeeper(ctx, user1, inventory.MaxListLimit, 0)
+	if err != nil {
+		t.Fatalf("Failed to get cards by keeper: %s", err.Error())
+	}
+	if quantity := findCardRow(cardRows, card2, user1, user1); quantity != 1 {
+		t.Fatalf("Expected canceling a transfer to leave the cards with the keeper, got %d", quantity)
+	}
+
+	transfer3, err := b.OpenTransfer(ctx, user1, user2, nil, []*inventory.TransferredCards{
+		{Quantity: 3, Card: card1, Owner: user1},
+	})
+	if err != nil {
+		t.Fatalf("Failed to open return transfer: %s", err.Error())
+	}
+
+	err = b.CloseTransfer(ctx, transfer3.ID)
+	if err != nil {
+		t.Fatalf("Failed to close return transfer: %s", err.Error())
+	}
+
+	cardRows, err = b.GetCardsByOwner(ctx, user1, inventory.MaxListLimit, 0)
+	if err != nil {
+		t.Fatalf("Failed to get cards by owner: %s", err.Error())
+	}
+	if len(cardRows) != 2 || findCardRow(cardRows, card1, user1, user1) != 4 {
+		t.Fatalf("Expected all cards back with the owner after return, got %+v", cardRows)
+	}
+}
+
+func testTransferErrors(t *testing.T, b inventory.Backend, prefix string) {
+	ctx := context.Background()
+	user1 := addUser(t, b, prefix+"-user1")
+	user2 := addUser(t, b, prefix+"-user2")
+
+	card1 := newCard(prefix, 1)
+	err := b.AddCards(ctx, []*inventory.CardRow{
+		{Quantity: 2, Card: card1, Owner: user1, Keeper: user1},
+	})
+	if err != nil {
+		t.Fatalf("Failed to add cards: %s", err.Error())
+	}
+
+	tooFewRow := &inventory.TransferredCards{Quantity: 3, Card: card1, Owner: user1}
+	_, err = b.OpenTransfer(ctx, user2, user1, nil, []*inventory.TransferredCards{tooFewRow})
+	expectRowError(t, err, inventory.ErrTooFewCards, nil)
+
+	missingRow := &inventory.TransferredCards{Quantity: 1, Card: newCard(prefix, 2), Owner: user1}
+	_, err = b.OpenTransfer(ctx, user2, user1, nil, []*inventory.TransferredCards{missingRow})
+	expectRowError(t, err, inventory.ErrTooFewCards, nil)
+
+	_, err = b.OpenTransfer(ctx, user1, user2, nil, []*inventory.TransferredCards{
+		{Quantity: 1, Card: card1, Owner: user1},
+	})
+	expectRowError(t, err, inventory.ErrTooFewCards, nil)
+
+	zeroRow := &inventory.TransferredCards{Quantity: 0, Card: card1, Owner: user1}
+	_, err = b.OpenTransfer(ctx, user2, user1, nil, []*inventory.TransferredCards{zeroRow})
+	expectRowError(t, err, inventory.ErrZeroCards, zeroRow)
+
+	noCardRow := &inventory.TransferredCards{Quantity: 1, Owner: user1}
+	_, err = b.OpenTransfer(ctx, user2, user1, nil, []*inventory.TransferredCards{noCardRow})
+	expectRowError(t, err, inventory.ErrNoCard, noCardRow)
+
+	tooManyRows := make([]*inventory.TransferredCards, 0, inventory.RowUploadLimit+1)
+	for i := 0; i <= inventory.RowUploadLimit; i++ {
+		tooManyRows = append(tooManyRows, &inventory.TransferredCards{Quantity: 1, Card: newCard(prefix, 100+i), Owner: user1})
+	}
+	_, err = b.OpenTransfer(ctx, user2, user1, nil, tooManyRows)
+	if !errors.Is(err, inventory.ErrTooManyRows) {
+		t.Fatalf("Expected ErrTooManyRows transferring %d rows, got: %v", len(tooManyRows), err)
+	}
+
+	_, err = b.OpenTransfer(ctx, prefix+"-nobody", user1, nil, []*inventory.TransferredCards{
+		{Quantity: 1, Card: card1, Owner: user1},
+	})
+	if !errors.Is(err, inventory.ErrUserNoExist) {
+		t.Fatalf("Expected ErrUserNoExist transferring to nonexistent user, got: %v", err)
+	}
+
+	badRequestID := int64(-1)
+	_, err = b.OpenTransfer(ctx, user2, user1, &badRequestID, []*inventory.TransferredCards{
+		{Quantity: 1, Card: card1, Owner: user1},
+	})
+	if !errors.Is(err, inventory.ErrRequestNoExist) {
+		t.Fatalf("Expected ErrRequestNoExist transferring for nonexistent request, got: %v", err)
+	}
+
+	transfer, err := b.OpenTransfer(ctx, user2, user1, nil, []*inventory.TransferredCards{
+		{Quantity: 2, Card: card1, Owner: user1},
+	})
+	if err != nil {
+		t.Fatalf("Failed to open transfer: %s", err.Error())
+	}
+
+	err = b.ModifyCardQuantity(ctx, user1, user1, card1, 1)
+	if err != nil {
+		t.Fatalf("Failed to modify card quantity: %s", err.Error())
+	}
+
+	err = b.CloseTransfer(ctx, transfer.ID)
+	expectRowError(t, err, inventory.ErrTooFewCards, nil)
+
+	transfer, err = b.GetTransferByID(ctx, transfer.ID, inventory.MaxListLimit, 0)
+	if err != nil {
+		t.Fatalf("Failed to get transfer by ID: %s", err.Error())
+	}
+	if transfer.Closed != nil {
+		t.Fatalf("Transfer that failed to close has a closed time")
+	}
+
+	cardRows, err := b.GetCardsByOwner(ctx, user1, inventory.MaxListLimit, 0)
+	if err != nil {
+		t.Fatalf("Failed to get cards by owner: %s", err.Error())
+	}
+	if len(cardRows) != 1 || findCardRow(cardRows, card1, user1, user1) != 1 {
+		t.Fatalf("Expected a transfer that failed to close to move no cards, got %+v", cardRows)
+	}
+
+	err = b.CloseTransfer(ctx, -1)
+	if !errors.Is(err, inventory.ErrTransferNoExist) {
+		t.Fatalf("Expected ErrTransferNoExist closing nonexistent transfer, got: %v", err)
+	}
+
+	err = b.CancelTransfer(ctx, -1)
+	if !errors.Is(err, inventory.ErrTransferNoExist) {
+		t.Fatalf("Expected ErrTransferNoExist canceling nonexistent transfer, got: %v", err)
+	}
+
+	_, err = b.GetTransferByID(ctx, -1, inventory.MaxListLimit, 0)
+	if !errors.Is(err, inventory.ErrTransferNoExist) {
+		t.Fatalf("Expected ErrTransferNoExist getting nonexistent transfer, got: %v", err)
+	}
+}
+
+// expectDeckCards fails unless deck has the cards in rows, in order
+func expectDeckCards(t *testing.T, deck *inventory.Deck, rows []*inventory.DeckCards) {
+	t.Helper()
+	if len(deck.Cards) != len(rows) {
+		t.Fatalf("Expected %d rows in deck, got %d", len(rows), len(deck.Cards))
+	}
+	for i, row := range rows {
+		got := deck.Cards[i]
+		if got.Zone != row.Zone || got.Quantity != row.Quantity || *got.Card != *row.Card {
+			t.Fatalf("Expected row %d of deck to be %d %+v in %s, got %d %+v in %s", i, row.Quantity, row.Card, row.Zone, got.Quantity, got.Card, got.Zone)
+		}
+	}
+}
+
+func testDecks(t *testing.T, b inventory.Backend, prefix string) {
+	ctx := context.Background()
+	user1 := addUser(t, b, prefix+"-user1")
+	user2 := addUser(t, b, prefix+"-user2")
+
+	card1 := newCard(prefix, 1)
+	card2 := newCard(prefix, 2)
+	card3 := newCard(prefix, 3)
+	err := b.AddCards(ctx, []*inventory.CardRow{
+		{Quantity: 4, Card: card1, Owner: user1, Keeper: user1},
+		{Quantity: 2, Card: card2, Owner: user1, Keeper: user2},
+		{Quantity: 1, Card: card3, Owner: user1, Keeper: user1},
+	})
+	if err != nil {
+		t.Fatalf("Failed to add cards: %s", err.Error())
+	}
+
+	deck, err := b.CreateDeck(ctx, user1, "Deck B", []*inventory.DeckCards{
+		{Zone: inventory.ZoneSideboard, Quantity: 1, Card: card1},
+		{Zone: inventory.ZoneMainboard, Quantity: 3, Card: card1},
+		{Zone: inventory.ZoneMainboard, Quantity: 2, Card: card2},
+		{Zone: inventory.ZoneCommander, Quantity: 1, Card: card3},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create deck: %s", err.Error())
+	}
+	if deck.Name != "Deck B" || deck.Owner != user1 || deck.Quantity != 7 {
+		t.Fatalf("Unexpected deck created: %+v", deck)
+	}
+
+	deck, err = b.GetDeckByID(ctx, deck.ID, inventory.MaxListLimit, 0)
+	if err != nil {
+		t.Fatalf("Failed to get deck by ID: %s", err.Error())
+	}
+	if deck.Name != "Deck B" || deck.Owner != user1 || deck.Quantity != 7 {
+		t.Fatalf("Unexpected deck: %+v", deck)
+	}
+	expectDeckCards(t, deck, []*inventory.DeckCards{
+		{Zone: inventory.ZoneCommander, Quantity: 1, Card: card3},
+		{Zone: inventory.ZoneMainboard, Quantity: 3, Card: card1},
+		{Zone: inventory.ZoneMainboard, Quantity: 2, Card: card2},
+		{Zone: inventory.ZoneSideboard, Quantity: 1, Card: card1},
+	})
+
+	page, err := b.GetDeckByID(ctx, deck.ID, 2, 2)
+	if err != nil {
+		t.Fatalf("Failed to get page of deck: %s", err.Error())
+	}
+	if page.Quantity != 7 {
+		t.Fatalf("Expected the quantity of a page of a deck to be that of the deck, got %d", page.Quantity)
+	}
+	expectDeckCards(t, page, []*inventory.DeckCards{
+		{Zone: inventory.ZoneMainboard, Quantity: 2, Card: card2},
+		{Zone: inventory.ZoneSideboard, Quantity: 1, Card: card1},
+	})
+
+	emptyDeck, err := b.CreateDeck(ctx, user1, "Deck A", nil)
+	if err != nil {
+		t.Fatalf("Failed to create empty deck: %s", err.Error())
+	}
+
+	decks, err := b.GetDecksByOwner(ctx, user1, inventory.MaxListLimit, 0)
+	if err != nil {
+		t.Fatalf("Failed to get decks by owner: %s", err.Error())
+	}
+	if len(decks) != 2 || decks[0].ID != emptyDeck.ID || decks[0].Quantity != 0 || decks[1].ID != deck.ID || decks[1].Quantity != 7 {
+		t.Fatalf("Expected decks A and B, got %+v", decks)
+	}
+	decks, err = b.GetDecksByOwner(ctx, user2, inventory.MaxListLimit, 0)
+	if err != nil {
+		t.Fatalf("Failed to get decks by owner: %s", err.Error())
+	}
+	if len(decks) != 0 {
+		t.Fatalf("Expected no decks for %q, got %+v", user2, decks)
+	}
+
+	err = b.UpdateDeck(ctx, deck.ID, "Deck C", []*inventory.DeckCards{
+		{Zone: inventory.ZoneMainboard, Quantity: 4, Card: card1},
+	})
+	if err != nil {
+		t.Fatalf("Failed to update deck: %s", err.Error())
+	}
+	deck, err = b.GetDeckByID(ctx, deck.ID, inventory.MaxListLimit, 0)
+	if err != nil {
+		t.Fatalf("Failed to get deck by ID: %s", err.Error())
+	}
+	if deck.Name != "Deck C" || deck.Quantity != 4 {
+		t.Fatalf("Unexpected updated deck: %+v", deck)
+	}
+	expectDeckCards(t, deck, []*inventory.DeckCards{
+		{Zone: inventory.ZoneMainboard, Quantity: 4, Card: card1},
+	})
+}
+
+func testDeckErrors(t *testing.T, b inventory.Backend, prefix string) {
+	ctx := context.Background()
+	user1 := addUser(t, b, prefix+"-user1")
+	user2 := addUser(t, b, prefix+"-user2")
+
+	card1 := newCard(prefix, 1)
+	err := b.AddCards(ctx, []*inventory.CardRow{
+		{Quantity: 3, Card: card1, Owner: user1, Keeper: user2},
+		{Quantity: 2, Card: card1, Owner: user2, Keeper: user2},
+	})
+	if err != nil {
+		t.Fatalf("Failed to add cards: %s", err.Error())
+	}
+
+	_, err = b.CreateDeck(ctx, user1, "Deck", []*inventory.DeckCards{
+		{Zone: inventory.ZoneMainboard, Quantity: 2, Card: card1},
+		{Zone: inventory.ZoneSideboard, Quantity: 2, Card: card1},
+	})
+	expectRowError(t, err, inventory.ErrTooFewCards, nil)
+
+	_, err = b.CreateDeck(ctx, user1, "Deck", []*inventory.DeckCards{
+		{Zone: inventory.ZoneMainboard, Quantity: 1, Card: newCard(prefix, 2)},
+	})
+	expectRowError(t, err, inventory.ErrTooFewCards, nil)
+
+	zeroRow := &inventory.DeckCards{Zone: inventory.ZoneMainboard, Quantity: 0, Card: card1}
+	_, err = b.CreateDeck(ctx, user1, "Deck", []*inventory.DeckCards{zeroRow})
+	expectRowError(t, err, inventory.ErrZeroCards, zeroRow)
+
+	zoneRow := &inventory.DeckCards{Zone: "graveyard", Quantity: 1, Card: card1}
+	_, err = b.CreateDeck(ctx, user1, "Deck", []*inventory.DeckCards{zoneRow})
+	expectRowError(t, err, inventory.ErrInvalidZone, zoneRow)
+
+	_, err = b.CreateDeck(ctx, user1, " ", nil)
+	if !errors.Is(err, inventory.ErrNoDeckName) {
+		t.Fatalf("Expected ErrNoDeckName creating a deck without a name, got: %v", err)
+	}
+
+	_, err = b.CreateDeck(ctx, prefix+"-nobody", "Deck", nil)
+	if !errors.Is(err, inventory.ErrUserNoExist) {
+		t.Fatalf("Expected ErrUserNoExist creating a deck for a nonexistent user, got: %v", err)
+	}
+
+	deck, err := b.CreateDeck(ctx, user1, "Deck", []*inventory.DeckCards{
+		{Zone: inventory.ZoneMainboard, Quantity: 3, Card: card1},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create deck: %s", err.Error())
+	}
+
+	err = b.UpdateDeck(ctx, deck.ID, "Other deck", []*inventory.DeckCards{
+		{Zone: inventory.ZoneMainboard, Quantity: 4, Card: card1},
+	})
+	expectRowError(t, err, inventory.ErrTooFewCards, nil)
+
+	deck, err = b.GetDeckByID(ctx, deck.ID, inventory.MaxListLimit, 0)
+	if err != nil {
+		t.Fatalf("Failed to get deck by ID: %s", err.Error())
+	}
+	if deck.Name != "Deck" || deck.Quantity != 3 {
+		t.Fatalf("Expected a deck that failed to update to be unchanged, got %+v", deck)
+	}
+
+	err = b.UpdateDeck(ctx, -1, "Deck", nil)
+	if !errors.Is(err, inventory.ErrDeckNoExist) {
+		t.Fatalf("Expected ErrDeckNoExist updating nonexistent deck, got: %v", err)
+	}
+
+	_, err = b.GetDeckByID(ctx, -1, inventory.MaxListLimit, 0)
+	if !errors.Is(err, inventory.ErrDeckNoExist) {
+		t.Fatalf("Expected ErrDeckNoExist getting nonexistent deck, got: %v", err)
+	}
+}
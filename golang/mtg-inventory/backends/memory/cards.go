@@ -0,0 +1,167 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+)
+
+func (b *Backend) listCards(match func(cardKey, *cardEntry) bool, limit, offset uint) []*inventory.CardRow {
+	cardRows := make([]*inventory.CardRow, 0)
+	for key, entry := range b.cards {
+		if !match(key, entry) {
+			continue
+		}
+		cardRows = append(cardRows, &inventory.CardRow{
+			Quantity: entry.Quantity,
+			Card: &inventory.Card{
+				Name:       entry.Name,
+				OracleID:   entry.OracleID,
+				ScryfallID: key.ScryfallID,
+				Finish:     key.Finish,
+				Condition:  key.Condition,
+				Language:   key.Language,
+			},
+			Owner:  key.Owner,
+			Keeper: key.Keeper,
+		})
+	}
+
+	sort.Slice(cardRows, func(i, j int) bool {
+		a, b := cardRows[i], cardRows[j]
+		if a.Card.Name != b.Card.Name {
+			return a.Card.Name < b.Card.Name
+		}
+		if a.Card.ScryfallID != b.Card.ScryfallID {
+			return a.Card.ScryfallID < b.Card.ScryfallID
+		}
+		if a.Card.Finish != b.Card.Finish {
+			return slices.Index(inventory.Finishes, a.Card.Finish) < slices.Index(inventory.Finishes, b.Card.Finish)
+		}
+		if a.Card.Condition != b.Card.Condition {
+			return slices.Index(inventory.Conditions, a.Card.Condition) < slices.Index(inventory.Conditions, b.Card.Condition)
+		}
+		if a.Card.Language != b.Card.Language {
+			return a.Card.Language < b.Card.Language
+		}
+		if a.Owner != b.Owner {
+			return a.Owner < b.Owner
+		}
+		return a.Keeper < b.Keeper
+	})
+
+	return paginate(cardRows, limit, offset)
+}
+
+// GetCardsByOracleID gets cards based on their Oracle ID
+func (b *Backend) GetCardsByOracleID(_ context.Context, oracleID string, limit, offset uint) ([]*inventory.CardRow, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.listCards(func(_ cardKey, entry *cardEntry) bool {
+		return entry.OracleID == oracleID
+	}, limit, offset), nil
+}
+
+// GetCardsByOwner gets cards based on their owner
+func (b *Backend) GetCardsByOwner(_ context.Context, owner string, limit, offset uint) ([]*inventory.CardRow, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.listCards(func(key cardKey, _ *cardEntry) bool {
+		return key.Owner == owner
+	}, limit, offset), nil
+}
+
+// GetCardsByKeeper gets cards based on their keeper
+func (b *Backend) GetCardsByKeeper(_ context.Context, keeper string, limit, offset uint) ([]*inventory.CardRow, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.listCards(func(key cardKey, _ *cardEntry) bool {
+		return key.Keeper == keeper
+	}, limit, offset), nil
+}
+
+func (b *Backend) addCards(key cardKey, name, oracleID string, quantity uint) {
+	if entry, exists := b.cards[key]; exists {
+		entry.Quantity += quantity
+		return
+	}
+	b.cards[key] = &cardEntry{
+		Quantity: quantity,
+		Name:     name,
+		OracleID: oracleID,
+	}
+}
+
+func (b *Backend) removeCards(key cardKey, quantity uint) {
+	entry := b.cards[key]
+	if entry.Quantity <= quantity {
+		delete(b.cards, key)
+		return
+	}
+	entry.Quantity -= quantity
+}
+
+// AddCards adds cards given a slice of them
+func (b *Backend) AddCards(_ context.Context, rows []*inventory.CardRow) error {
+	if len(rows) > inventory.RowUploadLimit {
+		return inventory.ErrTooManyRows
+	}
+	for _, row := range rows {
+		if row.Quantity == 0 {
+			return &inventory.RowError{
+				Err: inventory.ErrZeroCards,
+				Row: row,
+			}
+		}
+		err := row.Card.Check()
+		if err != nil {
+			return &inventory.RowError{
+				Err: err,
+				Row: row,
+			}
+		}
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for _, row := range rows {
+		if !b.userExists(row.Owner) || !b.userExists(row.Keeper) {
+			return fmt.Errorf("error adding cards: %w", &inventory.RowError{
+				Err: inventory.ErrUserNoExist,
+				Row: row,
+			})
+		}
+	}
+
+	for _, row := range rows {
+		b.addCards(newCardKey(row.Card, row.Owner, row.Keeper), row.Card.Name, row.Card.OracleID, row.Quantity)
+	}
+
+	return nil
+}
+
+// ModifyCardQuantity modifies the quantity of a card row that exists
+func (b *Backend) ModifyCardQuantity(_ context.Context, owner, keeper string, card *inventory.Card, quantity uint) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	key := newCardKey(card, owner, keeper)
+	entry, exists := b.cards[key]
+	if !exists {
+		return nil
+	}
+	if quantity == 0 {
+		delete(b.cards, key)
+		return nil
+	}
+	entry.Quantity = quantity
+
+	return nil
+}
@@ -0,0 +1,258 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+)
+
+type deckCardKey struct {
+	Zone       inventory.Zone
+	ScryfallID string
+	Finish     inventory.Finish
+	Condition  inventory.Condition
+	Language   string
+}
+
+func sumDeckCards(rows []*inventory.DeckCards) uint {
+	var quantity uint
+	for _, row := range rows {
+		quantity += row.Quantity
+	}
+	return quantity
+}
+
+// copyDeck copies a Deck without its cards
+func copyDeck(deck *inventory.Deck) *inventory.Deck {
+	deckCopy := *deck
+	deckCopy.Cards = nil
+	return &deckCopy
+}
+
+// copyDeckCards copies the cards of a deck in the range given by limit and
+// offset
+func copyDeckCards(rows []*inventory.DeckCards, limit, offset uint) []*inventory.DeckCards {
+	rowsCopy := make([]*inventory.DeckCards, 0)
+	for _, row := range paginate(rows, limit, offset) {
+		card := *row.Card
+		rowsCopy = append(rowsCopy, &inventory.DeckCards{
+			Zone:     row.Zone,
+			Quantity: row.Quantity,
+			Card:     &card,
+		})
+	}
+	return rowsCopy
+}
+
+// checkDeck returns an error if a deck can't have name or rows
+func checkDeck(name string, rows []*inventory.DeckCards) error {
+	if strings.TrimSpace(name) == "" {
+		return inventory.ErrNoDeckName
+	}
+	if len(rows) > inventory.RowUploadLimit {
+		return inventory.ErrTooManyRows
+	}
+	for _, row := range rows {
+		if row.Quantity == 0 {
+			return &inventory.RowError{
+				Err: inventory.ErrZeroCards,
+				Row: row,
+			}
+		}
+		err := row.Check()
+		if err != nil {
+			return &inventory.RowError{
+				Err: err,
+				Row: row,
+			}
+		}
+	}
+	return nil
+}
+
+// mergeDeckCards copies rows, merging those of the same copy in the same
+// zone, and sorts them by zone and name
+func mergeDeckCards(rows []*inventory.DeckCards) []*inventory.DeckCards {
+	byKey := make(map[deckCardKey]*inventory.DeckCards)
+	cards := make([]*inventory.DeckCards, 0, len(rows))
+	for _, row := range rows {
+		key := deckCardKey{
+			Zone:       row.Zone,
+			ScryfallID: row.Card.ScryfallID,
+			Finish:     row.Card.Finish,
+			Condition:  row.Card.Condition,
+			Language:   row.Card.Language,
+		}
+		if existing, exists := byKey[key]; exists {
+			existing.Quantity += row.Quantity
+			continue
+		}
+		card := *row.Card
+		rowCopy := &inventory.DeckCards{
+			Zone:     row.Zone,
+			Quantity: row.Quantity,
+			Card:     &card,
+		}
+		byKey[key] = rowCopy
+		cards = append(cards, rowCopy)
+	}
+	sort.SliceStable(cards, func(i, j int) bool {
+		if cards[i].Zone != cards[j].Zone {
+			return slices.Index(inventory.Zones, cards[i].Zone) < slices.Index(inventory.Zones, cards[j].Zone)
+		}
+		if cards[i].Card.Name != cards[j].Card.Name {
+			return cards[i].Card.Name < cards[j].Card.Name
+		}
+		return cards[i].Card.ScryfallID < cards[j].Card.ScryfallID
+	})
+	return cards
+}
+
+// ownedQuantity returns the number of copies of a card that owner owns,
+// whoever is keeping them
+func (b *Backend) ownedQuantity(card *inventory.Card, owner string) uint {
+	var quantity uint
+	for key, entry := range b.cards {
+		key.Keeper = ""
+		if key == newCardKey(card, owner, "") {
+			quantity += entry.Quantity
+		}
+	}
+	return quantity
+}
+
+// checkDeckQuantities returns a RowError if owner doesn't own enough copies
+// of a card for every zone it's in
+func (b *Backend) checkDeckQuantities(owner string, rows []*inventory.DeckCards) error {
+	needed := make(map[cardKey]uint)
+	for _, row := range rows {
+		key := newCardKey(row.Card, owner, "")
+		needed[key] += row.Quantity
+		if b.ownedQuantity(row.Card, owner) < needed[key] {
+			return &inventory.RowError{
+				Err: inventory.ErrTooFewCards,
+				Row: row,
+			}
+		}
+	}
+	return nil
+}
+
+// GetDecksByOwner returns Decks based on their owner, without their cards
+func (b *Backend) GetDecksByOwner(_ context.Context, owner string, limit, offset uint) ([]*inventory.Deck, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	decks := make([]*inventory.Deck, 0)
+	for _, deck := range b.decks {
+		if deck.Owner == owner {
+			decks = append(decks, deck)
+		}
+	}
+	sort.Slice(decks, func(i, j int) bool {
+		if decks[i].Name != decks[j].Name {
+			return decks[i].Name < decks[j].Name
+		}
+		return decks[i].ID < decks[j].ID
+	})
+
+	decks = paginate(decks, limit, offset)
+	for i, deck := range decks {
+		decks[i] = copyDeck(deck)
+	}
+	return decks, nil
+}
+
+// GetDeckByID returns a Deck based on its ID
+func (b *Backend) GetDeckByID(_ context.Context, id int64, limit, offset uint) (*inventory.Deck, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	deck, exists := b.decks[id]
+	if !exists {
+		return nil, fmt.Errorf("error getting deck \"%d\": %w", id, inventory.ErrDeckNoExist)
+	}
+
+	deckCopy := copyDeck(deck)
+	deckCopy.Cards = copyDeckCards(deck.Cards, limit, offset)
+
+	return deckCopy, nil
+}
+
+// CreateDeck creates a deck of cards that owner owns
+func (b *Backend) CreateDeck(_ context.Context, owner, name string, rows []*inventory.DeckCards) (_ *inventory.Deck, err error) {
+	err = checkDeck(name, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("error creating deck: %w", err)
+		}
+	}()
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if !b.userExists(owner) {
+		return nil, inventory.ErrUserNoExist
+	}
+	cards := mergeDeckCards(rows)
+	err = b.checkDeckQuantities(owner, cards)
+	if err != nil {
+		return nil, err
+	}
+
+	deck := &inventory.Deck{
+		ID:       b.nextDeckID,
+		Name:     name,
+		Owner:    owner,
+		Quantity: sumDeckCards(cards),
+		Cards:    cards,
+	}
+	b.decks[deck.ID] = deck
+	b.nextDeckID++
+
+	deckCopy := copyDeck(deck)
+	deckCopy.Cards = copyDeckCards(deck.Cards, inventory.MaxListLimit, 0)
+
+	return deckCopy, nil
+}
+
+// UpdateDeck renames a deck and replaces its cards
+func (b *Backend) UpdateDeck(_ context.Context, id int64, name string, rows []*inventory.DeckCards) (err error) {
+	err = checkDeck(name, rows)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("error updating deck \"%d\": %w", id, err)
+		}
+	}()
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	deck, exists := b.decks[id]
+	if !exists {
+		return inventory.ErrDeckNoExist
+	}
+	cards := mergeDeckCards(rows)
+	err = b.checkDeckQuantities(deck.Owner, cards)
+	if err != nil {
+		return err
+	}
+
+	deck.Name = name
+	deck.Quantity = sumDeckCards(cards)
+	deck.Cards = cards
+
+	return nil
+}
@@ -0,0 +1,108 @@
+/*
+Package memory contains a Backend implementation that keeps all of its state
+in memory, which is useful for tests and demos.
+*/
+package memory
+
+import (
+	"sync"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+)
+
+type cardKey struct {
+	ScryfallID string
+	Finish     inventory.Finish
+	Condition  inventory.Condition
+	Language   string
+	Owner      string
+	Keeper     string
+}
+
+func newCardKey(card *inventory.Card, owner, keeper string) cardKey {
+	return cardKey{
+		ScryfallID: card.ScryfallID,
+		Finish:     card.Finish,
+		Condition:  card.Condition,
+		Language:   card.Language,
+		Owner:      owner,
+		Keeper:     keeper,
+	}
+}
+
+type cardEntry struct {
+	Quantity uint
+	Name     string
+	OracleID string
+}
+
+type identityKey struct {
+	Provider   string
+	Workspace  string
+	ExternalID string
+}
+
+type transferKey struct {
+	ScryfallID string
+	Finish     inventory.Finish
+	Condition  inventory.Condition
+	Language   string
+	Owner      string
+}
+
+// Backend contains everything needed to run an in-memory backend, it is safe
+// for concurrent use
+type Backend struct {
+	mutex sync.Mutex
+
+	users          map[string]struct{}
+	identities     map[identityKey]string
+	cards          map[cardKey]*cardEntry
+	requests       map[int64]*inventory.Request
+	transfers      map[int64]*inventory.Transfer
+	decks          map[int64]*inventory.Deck
+	nextRequestID  int64
+	nextTransferID int64
+	nextDeckID     int64
+}
+
+// NewBackend returns an instantiated Backend
+func NewBackend() *Backend {
+	return &Backend{
+		users:          make(map[string]struct{}),
+		identities:     make(map[identityKey]string),
+		cards:          make(map[cardKey]*cardEntry),
+		requests:       make(map[int64]*inventory.Request),
+		transfers:      make(map[int64]*inventory.Transfer),
+		decks:          make(map[int64]*inventory.Deck),
+		nextRequestID:  1,
+		nextTransferID: 1,
+		nextDeckID:     1,
+	}
+}
+
+func clampLimit(limit uint) uint {
+	if limit == 0 {
+		return inventory.DefaultListLimit
+	} else if limit > inventory.MaxListLimit {
+		return inventory.MaxListLimit
+	}
+	return limit
+}
+
+func paginate[T any](items []T, limit, offset uint) []T {
+	limit = clampLimit(limit)
+	if offset >= uint(len(items)) {
+		return make([]T, 0)
+	}
+	items = items[offset:]
+	if limit < uint(len(items)) {
+		items = items[:limit]
+	}
+	return items
+}
+
+func (b *Backend) userExists(username string) bool {
+	_, exists := b.users[username]
+	return exists
+}
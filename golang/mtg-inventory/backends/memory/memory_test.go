@@ -0,0 +1,72 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/backends/backendtest"
+)
+
+var _ inventory.Backend = (*Backend)(nil)
+
+func TestMemory(t *testing.T) {
+	backendtest.RunConformance(t, func() inventory.Backend {
+		return NewBackend()
+	})
+}
+
+func TestMemoryConcurrent(t *testing.T) {
+	ctx := context.Background()
+	b := NewBackend()
+
+	_, err := b.AddUserIfNotExist(ctx, "user1")
+	if err != nil {
+		t.Fatalf("Failed to add user: %s", err.Error())
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := b.AddCards(ctx, []*inventory.CardRow{
+				{
+					Quantity: 1,
+					Card: &inventory.Card{
+						Name:       fmt.Sprintf("fake-card-name-%d", i%5),
+						OracleID:   fmt.Sprintf("fake-oracle-ID-%d", i%5),
+						ScryfallID: fmt.Sprintf("fake-scryfall-ID-%d", i%5),
+						Finish:     inventory.FinishNonfoil,
+						Condition:  inventory.ConditionNearMint,
+					},
+					Owner:  "user1",
+					Keeper: "user1",
+				},
+			})
+			if err != nil {
+				t.Errorf("Failed to add cards: %s", err.Error())
+			}
+			_, err = b.GetCardsByOwner(ctx, "user1", 0, 0)
+			if err != nil {
+				t.Errorf("Failed to get cards by owner: %s", err.Error())
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	cardRows, err := b.GetCardsByOwner(ctx, "user1", 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to get cards by owner: %s", err.Error())
+	}
+	if len(cardRows) != 5 {
+		t.Fatalf("Unexpected number of card rows: %d", len(cardRows))
+	}
+	for _, cardRow := range cardRows {
+		if cardRow.Quantity != 10 {
+			t.Fatalf("Unexpected quantity for %s: %d", cardRow.Card.Name, cardRow.Quantity)
+		}
+	}
+}
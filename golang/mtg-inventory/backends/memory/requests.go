@@ -0,0 +1,152 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+)
+
+func sumRequestedCards(rows []*inventory.RequestedCards) uint {
+	var quantity uint
+	for _, row := range rows {
+		quantity += row.Quantity
+	}
+	return quantity
+}
+
+// copyRequest copies a Request without its requested cards
+func copyRequest(request *inventory.Request) *inventory.Request {
+	requestCopy := *request
+	if request.Closed != nil {
+		closed := *request.Closed
+		requestCopy.Closed = &closed
+	}
+	requestCopy.Cards = nil
+	return &requestCopy
+}
+
+// copyRequestedCards copies the requested cards in the range given by limit
+// and offset
+func copyRequestedCards(rows []*inventory.RequestedCards, limit, offset uint) []*inventory.RequestedCards {
+	rowsCopy := make([]*inventory.RequestedCards, 0)
+	for _, row := range paginate(rows, limit, offset) {
+		rowCopy := *row
+		rowsCopy = append(rowsCopy, &rowCopy)
+	}
+	return rowsCopy
+}
+
+// GetRequestsByRequestor gets a number of requests made by the requestor up to
+// the provided limit
+func (b *Backend) GetRequestsByRequestor(_ context.Context, requestor string, limit, offset uint) ([]*inventory.Request, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	requests := make([]*inventory.Request, 0)
+	for _, request := range b.requests {
+		if request.Requestor == requestor {
+			requests = append(requests, request)
+		}
+	}
+	sort.Slice(requests, func(i, j int) bool {
+		if !requests[i].Opened.Equal(requests[j].Opened) {
+			return requests[i].Opened.Before(requests[j].Opened)
+		}
+		return requests[i].ID < requests[j].ID
+	})
+
+	requests = paginate(requests, limit, offset)
+	for i, request := range requests {
+		requests[i] = copyRequest(request)
+	}
+
+	return requests, nil
+}
+
+// GetRequestByID returns a request and associated requested cards given an ID
+func (b *Backend) GetRequestByID(_ context.Context, id int64, limit, offset uint) (*inventory.Request, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	request, exists := b.requests[id]
+	if !exists {
+		return nil, fmt.Errorf("error getting request \"%d\": %w", id, inventory.ErrRequestNoExist)
+	}
+
+	requestCopy := copyRequest(request)
+	requestCopy.Cards = copyRequestedCards(request.Cards, limit, offset)
+	requestCopy.Quantity = sumRequestedCards(requestCopy.Cards)
+
+	return requestCopy, nil
+}
+
+// OpenRequest creates a Request from the provided rows of RequestedCards
+func (b *Backend) OpenRequest(_ context.Context, requestor string, rows []*inventory.RequestedCards) (*inventory.Request, error) {
+	if len(rows) > inventory.RowUploadLimit {
+		return nil, inventory.ErrTooManyRows
+	}
+	for _, row := range rows {
+		if row.Quantity == 0 {
+			return nil, &inventory.RowError{
+				Err: inventory.ErrZeroCards,
+				Row: row,
+			}
+		}
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if !b.userExists(requestor) {
+		return nil, fmt.Errorf("error requesting cards: %w", inventory.ErrUserNoExist)
+	}
+
+	byOracleID := make(map[string]*inventory.RequestedCards)
+	cards := make([]*inventory.RequestedCards, 0, len(rows))
+	for _, row := range rows {
+		if existing, exists := byOracleID[row.OracleID]; exists {
+			existing.Quantity += row.Quantity
+			continue
+		}
+		rowCopy := *row
+		byOracleID[row.OracleID] = &rowCopy
+		cards = append(cards, &rowCopy)
+	}
+	sort.SliceStable(cards, func(i, j int) bool {
+		return cards[i].Name < cards[j].Name
+	})
+
+	request := &inventory.Request{
+		ID:        b.nextRequestID,
+		Requestor: requestor,
+		Opened:    time.Now(),
+		Quantity:  sumRequestedCards(cards),
+		Cards:     cards,
+	}
+	b.requests[request.ID] = request
+	b.nextRequestID++
+
+	requestCopy := copyRequest(request)
+	requestCopy.Cards = copyRequestedCards(request.Cards, inventory.MaxListLimit, 0)
+
+	return requestCopy, nil
+}
+
+// CloseRequest sets the closed time on a Request
+func (b *Backend) CloseRequest(_ context.Context, id int64) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	request, exists := b.requests[id]
+	if !exists {
+		return fmt.Errorf("error closing request \"%d\": %w", id, inventory.ErrRequestNoExist)
+	}
+
+	now := time.Now()
+	request.Closed = &now
+
+	return nil
+}
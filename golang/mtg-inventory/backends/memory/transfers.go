@@ -0,0 +1,287 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+)
+
+func sumTransferredCards(rows []*inventory.TransferredCards) uint {
+	var quantity uint
+	for _, row := range rows {
+		quantity += row.Quantity
+	}
+	return quantity
+}
+
+// copyTransfer copies a Transfer without its transferred cards
+func copyTransfer(transfer *inventory.Transfer) *inventory.Transfer {
+	transferCopy := *transfer
+	if transfer.RequestID != nil {
+		requestID := *transfer.RequestID
+		transferCopy.RequestID = &requestID
+	}
+	if transfer.Closed != nil {
+		closed := *transfer.Closed
+		transferCopy.Closed = &closed
+	}
+	transferCopy.Cards = nil
+	return &transferCopy
+}
+
+// copyTransferredCards copies the transferred cards in the range given by
+// limit and offset
+func copyTransferredCards(rows []*inventory.TransferredCards, limit, offset uint) []*inventory.TransferredCards {
+	rowsCopy := make([]*inventory.TransferredCards, 0)
+	for _, row := range paginate(rows, limit, offset) {
+		card := *row.Card
+		rowsCopy = append(rowsCopy, &inventory.TransferredCards{
+			Quantity: row.Quantity,
+			Card:     &card,
+			Owner:    row.Owner,
+		})
+	}
+	return rowsCopy
+}
+
+func (b *Backend) listTransfers(match func(*inventory.Transfer) bool, limit, offset uint) []*inventory.Transfer {
+	transfers := make([]*inventory.Transfer, 0)
+	for _, transfer := range b.transfers {
+		if match(transfer) {
+			transfers = append(transfers, transfer)
+		}
+	}
+	sort.Slice(transfers, func(i, j int) bool {
+		if !transfers[i].Opened.Equal(transfers[j].Opened) {
+			return transfers[i].Opened.Before(transfers[j].Opened)
+		}
+		return transfers[i].ID < transfers[j].ID
+	})
+
+	transfers = paginate(transfers, limit, offset)
+	for i, transfer := range transfers {
+		transfers[i] = copyTransfer(transfer)
+	}
+	return transfers
+}
+
+// GetTransfersByToUser returns Transfers based on their ToUser
+func (b *Backend) GetTransfersByToUser(_ context.Context, toUser string, limit, offset uint) ([]*inventory.Transfer, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.listTransfers(func(transfer *inventory.Transfer) bool {
+		return transfer.ToUser == toUser
+	}, limit, offset), nil
+}
+
+// GetTransfersByFromUser returns Transfers based on their FromUser
+func (b *Backend) GetTransfersByFromUser(_ context.Context, fromUser string, limit, offset uint) ([]*inventory.Transfer, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.listTransfers(func(transfer *inventory.Transfer) bool {
+		return transfer.FromUser == fromUser
+	}, limit, offset), nil
+}
+
+// GetTransfersByRequestID returns Transfers based on their RequestID
+func (b *Backend) GetTransfersByRequestID(_ context.Context, requestID int64, limit, offset uint) ([]*inventory.Transfer, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.listTransfers(func(transfer *inventory.Transfer) bool {
+		return transfer.RequestID != nil && *transfer.RequestID == requestID
+	}, limit, offset), nil
+}
+
+// GetTransferByID returns a Transfer based on its ID
+func (b *Backend) GetTransferByID(_ context.Context, id int64, limit, offset uint) (*inventory.Transfer, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	transfer, exists := b.transfers[id]
+	if !exists {
+		return nil, fmt.Errorf("error getting transfer \"%d\": %w", id, inventory.ErrTransferNoExist)
+	}
+
+	transferCopy := copyTransfer(transfer)
+	transferCopy.Cards = copyTransferredCards(transfer.Cards, limit, offset)
+
+	return transferCopy, nil
+}
+
+// checkTransferQuantities returns a RowError if fromUser is not keeping
+// enough cards to cover every row
+func (b *Backend) checkTransferQuantities(fromUser string, rows []*inventory.TransferredCards) error {
+	for _, row := range rows {
+		entry, exists := b.cards[newCardKey(row.Card, row.Owner, fromUser)]
+		if !exists || entry.Quantity < row.Quantity {
+			return &inventory.RowError{
+				Err: inventory.ErrTooFewCards,
+				Row: row,
+			}
+		}
+	}
+	return nil
+}
+
+// OpenTransfer creates a transfer
+func (b *Backend) OpenTransfer(_ context.Context, toUser, fromUser string, requestID *int64, rows []*inventory.TransferredCards) (_ *inventory.Transfer, err error) {
+	if len(rows) > inventory.RowUploadLimit {
+		return nil, inventory.ErrTooManyRows
+	}
+	for _, row := range rows {
+		if row.Quantity == 0 {
+			return nil, &inventory.RowError{
+				Err: inventory.ErrZeroCards,
+				Row: row,
+			}
+		}
+		err := row.Card.Check()
+		if err != nil {
+			return nil, &inventory.RowError{
+				Err: err,
+				Row: row,
+			}
+		}
+	}
+
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("error opening transfer: %w", err)
+		}
+	}()
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if !b.userExists(toUser) || !b.userExists(fromUser) {
+		return nil, inventory.ErrUserNoExist
+	}
+	if requestID != nil {
+		if _, exists := b.requests[*requestID]; !exists {
+			return nil, inventory.ErrRequestNoExist
+		}
+	}
+
+	byKey := make(map[transferKey]*inventory.TransferredCards)
+	cards := make([]*inventory.TransferredCards, 0, len(rows))
+	for _, row := range rows {
+		key := transferKey{
+			ScryfallID: row.Card.ScryfallID,
+			Finish:     row.Card.Finish,
+			Condition:  row.Card.Condition,
+			Language:   row.Card.Language,
+			Owner:      row.Owner,
+		}
+		if existing, exists := byKey[key]; exists {
+			existing.Quantity += row.Quantity
+			continue
+		}
+		card := *row.Card
+		rowCopy := &inventory.TransferredCards{
+			Quantity: row.Quantity,
+			Card:     &card,
+			Owner:    row.Owner,
+		}
+		byKey[key] = rowCopy
+		cards = append(cards, rowCopy)
+	}
+	sort.SliceStable(cards, func(i, j int) bool {
+		if cards[i].Card.Name != cards[j].Card.Name {
+			return cards[i].Card.Name < cards[j].Card.Name
+		}
+		return cards[i].Owner < cards[j].Owner
+	})
+
+	err = b.checkTransferQuantities(fromUser, cards)
+	if err != nil {
+		return nil, err
+	}
+
+	transfer := &inventory.Transfer{
+		ID:       b.nextTransferID,
+		ToUser:   toUser,
+		FromUser: fromUser,
+		Opened:   time.Now(),
+		Quantity: sumTransferredCards(cards),
+		Cards:    cards,
+	}
+	if requestID != nil {
+		id := *requestID
+		transfer.RequestID = &id
+	}
+	b.transfers[transfer.ID] = transfer
+	b.nextTransferID++
+
+	transferCopy := copyTransfer(transfer)
+	transferCopy.Cards = copyTransferredCards(transfer.Cards, inventory.MaxListLimit, 0)
+
+	return transferCopy, nil
+}
+
+// CancelTransfer cancels a transfer, deleting it
+func (b *Backend) CancelTransfer(_ context.Context, id int64) (err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("error canceling transfer \"%d\": %w", id, err)
+		}
+	}()
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	transfer, exists := b.transfers[id]
+	if !exists {
+		return inventory.ErrTransferNoExist
+	}
+	if transfer.Closed != nil {
+		return inventory.ErrTransferClosed
+	}
+
+	delete(b.transfers, id)
+
+	return nil
+}
+
+// CloseTransfer sets the closed time on a Transfer and reassigns the keeper of
+// the cards
+func (b *Backend) CloseTransfer(_ context.Context, id int64) (err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("error closing transfer \"%d\": %w", id, err)
+		}
+	}()
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	transfer, exists := b.transfers[id]
+	if !exists {
+		return inventory.ErrTransferNoExist
+	}
+	if transfer.Closed != nil {
+		return inventory.ErrTransferClosed
+	}
+
+	err = b.checkTransferQuantities(transfer.FromUser, transfer.Cards)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range transfer.Cards {
+		fromKey := newCardKey(row.Card, row.Owner, transfer.FromUser)
+		entry := b.cards[fromKey]
+		b.addCards(newCardKey(row.Card, row.Owner, transfer.ToUser), entry.Name, entry.OracleID, row.Quantity)
+		b.removeCards(fromKey, row.Quantity)
+	}
+
+	now := time.Now()
+	transfer.Closed = &now
+
+	return nil
+}
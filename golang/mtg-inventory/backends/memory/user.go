@@ -0,0 +1,81 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+)
+
+// GetUserByUsername gets a User by its username
+func (b *Backend) GetUserByUsername(_ context.Context, username string) (*inventory.User, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if !b.userExists(username) {
+		return nil, fmt.Errorf("error getting user by username %s: %w", username, inventory.ErrUserNoExist)
+	}
+	return &inventory.User{
+		Username: username,
+	}, nil
+}
+
+// AddUser adds a User given its username, failing if it exists
+func (b *Backend) AddUser(_ context.Context, username string) (*inventory.User, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.userExists(username) {
+		return nil, fmt.Errorf("error adding user %s: %w", username, inventory.ErrUserExists)
+	}
+	b.users[username] = struct{}{}
+	return &inventory.User{
+		Username: username,
+	}, nil
+}
+
+// AddUserIfNotExist adds a User given its username
+func (b *Backend) AddUserIfNotExist(_ context.Context, username string) (*inventory.User, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.users[username] = struct{}{}
+	return &inventory.User{
+		Username: username,
+	}, nil
+}
+
+// GetUserByIdentity gets the User linked to an external identity
+func (b *Backend) GetUserByIdentity(_ context.Context, provider, workspace, externalID string) (*inventory.User, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	username, exists := b.identities[identityKey{
+		Provider:   provider,
+		Workspace:  workspace,
+		ExternalID: externalID,
+	}]
+	if !exists {
+		return nil, fmt.Errorf("error getting user by identity %s/%s/%s: %w", provider, workspace, externalID, inventory.ErrIdentityNoExist)
+	}
+	return &inventory.User{
+		Username: username,
+	}, nil
+}
+
+// LinkIdentity links an external identity to an existing User, replacing any
+// User it was linked to before
+func (b *Backend) LinkIdentity(_ context.Context, identity *inventory.Identity) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if !b.userExists(identity.Username) {
+		return fmt.Errorf("error linking identity to %s: %w", identity.Username, inventory.ErrUserNoExist)
+	}
+	b.identities[identityKey{
+		Provider:   identity.Provider,
+		Workspace:  identity.Workspace,
+		ExternalID: identity.ExternalID,
+	}] = identity.Username
+	return nil
+}
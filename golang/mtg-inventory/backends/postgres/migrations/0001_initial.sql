@@ -0,0 +1,59 @@
+CREATE TABLE IF NOT EXISTS users (
+	id SERIAL PRIMARY KEY,
+	username VARCHAR(256) NOT NULL,
+	UNIQUE (username)
+);
+
+CREATE TABLE IF NOT EXISTS cards (
+	quantity INTEGER NOT NULL,
+	name VARCHAR(256) NOT NULL,
+	oracle_id VARCHAR(256) NOT NULL,
+	scryfall_id VARCHAR(256) NOT NULL,
+	foil BOOLEAN,
+	owner INTEGER NOT NULL,
+	keeper INTEGER NOT NULL,
+	UNIQUE (scryfall_id, foil, owner, keeper),
+	FOREIGN KEY (owner) REFERENCES users(id),
+	FOREIGN KEY (keeper) REFERENCES users(id)
+);
+
+CREATE TABLE IF NOT EXISTS requests (
+	id SERIAL PRIMARY KEY,
+	requestor INTEGER NOT NULL,
+	opened TIMESTAMPTZ NOT NULL,
+	closed TIMESTAMPTZ,
+	FOREIGN KEY (requestor) REFERENCES users(id)
+);
+
+CREATE TABLE IF NOT EXISTS requested_cards (
+	request_id INTEGER NOT NULL,
+	name VARCHAR(256) NOT NULL,
+	oracle_id VARCHAR(256) NOT NULL,
+	quantity INTEGER NOT NULL,
+	UNIQUE (request_id, oracle_id),
+	FOREIGN KEY (request_id) REFERENCES requests(id)
+);
+
+CREATE TABLE IF NOT EXISTS transfers (
+	id SERIAL PRIMARY KEY,
+	request_id INTEGER,
+	to_user INTEGER NOT NULL,
+	from_user INTEGER NOT NULL,
+	opened TIMESTAMPTZ NOT NULL,
+	closed TIMESTAMPTZ,
+	FOREIGN KEY (to_user) REFERENCES users(id),
+	FOREIGN KEY (from_user) REFERENCES users(id),
+	FOREIGN KEY (request_id) REFERENCES requests(id) ON DELETE SET NULL
+);
+
+CREATE TABLE IF NOT EXISTS transferred_cards (
+	transfer_id INTEGER NOT NULL,
+	quantity INTEGER NOT NULL,
+	name VARCHAR(256) NOT NULL,
+	scryfall_id VARCHAR(256) NOT NULL,
+	foil BOOLEAN,
+	owner INTEGER NOT NULL,
+	UNIQUE (transfer_id, scryfall_id, foil, owner),
+	FOREIGN KEY (transfer_id) REFERENCES transfers(id) ON DELETE CASCADE,
+	FOREIGN KEY (owner) REFERENCES users(id)
+);
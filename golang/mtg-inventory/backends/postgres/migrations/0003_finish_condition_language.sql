@@ -0,0 +1,24 @@
+-- Copies of a card are told apart by finish, condition and language, and foil
+-- becomes a finish
+ALTER TABLE cards
+	ADD COLUMN finish VARCHAR(16) NOT NULL DEFAULT 'nonfoil',
+	ADD COLUMN card_condition VARCHAR(8) NOT NULL DEFAULT 'NM',
+	ADD COLUMN language VARCHAR(16) NOT NULL DEFAULT '';
+
+UPDATE cards SET finish = 'foil' WHERE foil;
+
+-- Dropping foil drops the unique constraint it is part of
+ALTER TABLE cards
+	DROP COLUMN foil,
+	ADD UNIQUE (scryfall_id, finish, card_condition, language, owner, keeper);
+
+ALTER TABLE transferred_cards
+	ADD COLUMN finish VARCHAR(16) NOT NULL DEFAULT 'nonfoil',
+	ADD COLUMN card_condition VARCHAR(8) NOT NULL DEFAULT 'NM',
+	ADD COLUMN language VARCHAR(16) NOT NULL DEFAULT '';
+
+UPDATE transferred_cards SET finish = 'foil' WHERE foil;
+
+ALTER TABLE transferred_cards
+	DROP COLUMN foil,
+	ADD UNIQUE (transfer_id, scryfall_id, finish, card_condition, language, owner);
@@ -0,0 +1,80 @@
+/*
+Package postgres contains a Backend implementation based on a PostgreSQL
+database.
+*/
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"strconv"
+
+	sqlbackend "github.com/benrm/mtg-inventory/golang/mtg-inventory/backends/sql"
+	_ "github.com/jackc/pgx/v5/stdlib" // registers DriverName
+)
+
+// DriverName is the name of the database/sql driver this package registers
+const DriverName = "pgx"
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// Dialect is the Dialect of PostgreSQL
+var Dialect = &sqlbackend.Dialect{
+	Placeholder: func(n int) string {
+		return "$" + strconv.Itoa(n)
+	},
+	Upsert:     sqlbackend.OnConflict,
+	Ignore:     sqlbackend.OnConflictDoNothing,
+	Returning:  true,
+	CastParams: true,
+	ForUpdate:  "FOR UPDATE",
+}
+
+// Backend contains everything needed to run a PostgreSQL backend, which is
+// the SQL backend speaking Dialect with its own migrations
+type Backend struct {
+	*sqlbackend.Backend
+}
+
+// NewBackend returns an instantiated Backend
+func NewBackend(db *sql.DB) *Backend {
+	return &Backend{
+		Backend: sqlbackend.NewDialectBackend(db, Dialect),
+	}
+}
+
+// Migrate applies any migrations that have not been applied yet
+func (b *Backend) Migrate(ctx context.Context) error {
+	sub, err := fs.Sub(migrations, "migrations")
+	if err != nil {
+		return fmt.Errorf("error migrating database: %w", err)
+	}
+	m := &sqlbackend.Migrator{
+		DB:          b.DB,
+		Migrations:  sub,
+		Placeholder: b.Dialect.Placeholder,
+		Lock:        advisoryLock,
+	}
+	return m.Migrate(ctx)
+}
+
+// migrationLockKey is the key of the advisory lock that keeps migrations from
+// running concurrently
+const migrationLockKey = 0x6d74675f6d696772
+
+// advisoryLock locks migrations with a session level advisory lock, waiting
+// as long as it takes
+func advisoryLock(ctx context.Context, conn *sql.Conn) (func(context.Context) error, error) {
+	_, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", int64(migrationLockKey))
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context) error {
+		_, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", int64(migrationLockKey))
+		return err
+	}, nil
+}
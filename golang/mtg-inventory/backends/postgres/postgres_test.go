@@ -0,0 +1,33 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/backends/backendtest"
+)
+
+func TestPostgres(t *testing.T) {
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN is not set, skipping PostgreSQL tests")
+	}
+	db, err := sql.Open(DriverName, dsn)
+	if err != nil {
+		t.Fatalf("Failed to open db connection: %s", err.Error())
+	}
+	defer db.Close()
+
+	b := NewBackend(db)
+	err = b.Migrate(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to migrate: %s", err.Error())
+	}
+
+	backendtest.RunConformance(t, func() inventory.Backend {
+		return b
+	})
+}
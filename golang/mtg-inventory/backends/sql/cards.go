@@ -21,7 +21,7 @@ func (b *Backend) GetCardsByOracleID(ctx context.Context, oracleID string, limit
 		limit = inventory.MaxListLimit
 	}
 
-	queryStmt, err := b.DB.PrepareContext(ctx, `SELECT cards.quantity, cards.name, cards.scryfall_id, cards.foil, owners.username, keepers.username
+	queryStmt, err := b.prepare(ctx, b.DB, `SELECT cards.quantity, cards.name, cards.scryfall_id, cards.finish, cards.card_condition, cards.language, owners.username, keepers.username
 FROM cards
 LEFT JOIN users owners ON cards.owner = owners.id
 LEFT JOIN users keepers ON cards.keeper = keepers.id
@@ -38,13 +38,16 @@ LIMIT ? OFFSET ?
 	if err != nil {
 		return nil, fmt.Errorf("failed to select for cards: %w", err)
 	}
+	defer queryRows.Close()
 
 	cardRows := make([]*inventory.CardRow, 0)
 	for queryRows.Next() {
 		var quantity uint
 		var cardName, scryfallID, ownerUsername, keeperUsername string
-		var foil bool
-		err = queryRows.Scan(&quantity, &cardName, &scryfallID, &foil, &ownerUsername, &keeperUsername)
+		var finish inventory.Finish
+		var condition inventory.Condition
+		var language string
+		err = queryRows.Scan(&quantity, &cardName, &scryfallID, &finish, &condition, &language, &ownerUsername, &keeperUsername)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan select on cards: %w", err)
 		}
@@ -54,7 +57,9 @@ LIMIT ? OFFSET ?
 				Name:       cardName,
 				OracleID:   oracleID,
 				ScryfallID: scryfallID,
-				Foil:       foil,
+				Finish:     finish,
+				Condition:  condition,
+				Language:   language,
 			},
 			Owner:  ownerUsername,
 			Keeper: keeperUsername,
@@ -83,7 +88,7 @@ func (b *Backend) GetCardsByOwner(ctx context.Context, ownerUsername string, lim
 		limit = inventory.MaxListLimit
 	}
 
-	queryStmt, err := b.DB.PrepareContext(ctx, `SELECT cards.quantity, cards.name, cards.oracle_id, cards.scryfall_id, cards.foil, keepers.username
+	queryStmt, err := b.prepare(ctx, b.DB, `SELECT cards.quantity, cards.name, cards.oracle_id, cards.scryfall_id, cards.finish, cards.card_condition, cards.language, keepers.username
 	FROM cards
 	LEFT JOIN users owners ON cards.owner = owners.id
 	LEFT JOIN users keepers ON cards.keeper = keepers.id
@@ -100,13 +105,16 @@ func (b *Backend) GetCardsByOwner(ctx context.Context, ownerUsername string, lim
 	if err != nil {
 		return nil, fmt.Errorf("failed to select for cards: %w", err)
 	}
+	defer queryRows.Close()
 
 	cardRows := make([]*inventory.CardRow, 0)
 	for queryRows.Next() {
 		var quantity uint
 		var cardName, oracleID, scryfallID, keeperUsername string
-		var foil bool
-		err = queryRows.Scan(&quantity, &cardName, &oracleID, &scryfallID, &foil, &keeperUsername)
+		var finish inventory.Finish
+		var condition inventory.Condition
+		var language string
+		err = queryRows.Scan(&quantity, &cardName, &oracleID, &scryfallID, &finish, &condition, &language, &keeperUsername)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan select on cards: %w", err)
 		}
@@ -116,7 +124,9 @@ func (b *Backend) GetCardsByOwner(ctx context.Context, ownerUsername string, lim
 				Name:       cardName,
 				OracleID:   oracleID,
 				ScryfallID: scryfallID,
-				Foil:       foil,
+				Finish:     finish,
+				Condition:  condition,
+				Language:   language,
 			},
 			Owner:  ownerUsername,
 			Keeper: keeperUsername,
@@ -145,7 +155,7 @@ func (b *Backend) GetCardsByKeeper(ctx context.Context, keeperUsername string, l
 		limit = inventory.MaxListLimit
 	}
 
-	queryStmt, err := b.DB.PrepareContext(ctx, `SELECT cards.quantity, cards.name, cards.oracle_id, cards.scryfall_id, cards.foil, owners.username
+	queryStmt, err := b.prepare(ctx, b.DB, `SELECT cards.quantity, cards.name, cards.oracle_id, cards.scryfall_id, cards.finish, cards.card_condition, cards.language, owners.username
 	FROM cards
 	LEFT JOIN users owners ON cards.owner = owners.id
 	LEFT JOIN users keepers ON cards.keeper = keepers.id
@@ -162,13 +172,16 @@ func (b *Backend) GetCardsByKeeper(ctx context.Context, keeperUsername string, l
 	if err != nil {
 		return nil, fmt.Errorf("failed to select for cards: %w", err)
 	}
+	defer queryRows.Close()
 
 	cardRows := make([]*inventory.CardRow, 0)
 	for queryRows.Next() {
 		var quantity uint
 		var cardName, oracleID, scryfallID, ownerUsername string
-		var foil bool
-		err = queryRows.Scan(&quantity, &cardName, &oracleID, &scryfallID, &foil, &ownerUsername)
+		var finish inventory.Finish
+		var condition inventory.Condition
+		var language string
+		err = queryRows.Scan(&quantity, &cardName, &oracleID, &scryfallID, &finish, &condition, &language, &ownerUsername)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan select on cards: %w", err)
 		}
@@ -178,7 +191,9 @@ func (b *Backend) GetCardsByKeeper(ctx context.Context, keeperUsername string, l
 				Name:       cardName,
 				OracleID:   oracleID,
 				ScryfallID: scryfallID,
-				Foil:       foil,
+				Finish:     finish,
+				Condition:  condition,
+				Language:   language,
 			},
 			Owner:  ownerUsername,
 			Keeper: keeperUsername,
@@ -205,6 +220,13 @@ func (b *Backend) AddCards(ctx context.Context, rows []*inventory.CardRow) (err
 				Row: row,
 			}
 		}
+		err := row.Card.Check()
+		if err != nil {
+			return &inventory.RowError{
+				Err: err,
+				Row: row,
+			}
+		}
 	}
 
 	tx, err := b.DB.BeginTx(ctx, nil)
@@ -222,11 +244,11 @@ func (b *Backend) AddCards(ctx context.Context, rows []*inventory.CardRow) (err
 		}
 	}()
 
-	upsertStmt, err := tx.PrepareContext(ctx, `INSERT INTO cards (quantity, name, oracle_id, scryfall_id, foil, owner, keeper)
-SELECT ?, ?, ?, ?, ?, owners.id, keepers.id
+	upsertStmt, err := b.prepare(ctx, tx, `INSERT INTO cards (quantity, name, oracle_id, scryfall_id, finish, card_condition, language, owner, keeper)
+SELECT `+b.Dialect.cast("INTEGER")+`, ?, ?, ?, ?, ?, ?, owners.id, keepers.id
 FROM users owners, users keepers
 WHERE owners.username = ? AND keepers.username = ?
-ON DUPLICATE KEY UPDATE quantity = quantity + ?
+`+b.Dialect.Upsert("cards", "scryfall_id", "finish", "card_condition", "language", "owner", "keeper")+`
 `)
 	if err != nil {
 		return fmt.Errorf("failed to prepare insert on cards: %w", err)
@@ -234,19 +256,30 @@ ON DUPLICATE KEY UPDATE quantity = quantity + ?
 	defer upsertStmt.Close()
 
 	for _, cardRow := range rows {
-		_, err := upsertStmt.ExecContext(ctx,
+		result, err := upsertStmt.ExecContext(ctx,
 			cardRow.Quantity,
 			cardRow.Card.Name,
 			cardRow.Card.OracleID,
 			cardRow.Card.ScryfallID,
-			cardRow.Card.Foil,
+			cardRow.Card.Finish,
+			cardRow.Card.Condition,
+			cardRow.Card.Language,
 			cardRow.Owner,
 			cardRow.Keeper,
-			cardRow.Quantity,
 		)
 		if err != nil {
 			return fmt.Errorf("failed to execute insert on cards: %w", err)
 		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected by insert on cards: %w", err)
+		}
+		if rowsAffected == 0 {
+			return &inventory.RowError{
+				Err: inventory.ErrUserNoExist,
+				Row: cardRow,
+			}
+		}
 	}
 
 	err = tx.Commit()
@@ -258,26 +291,25 @@ ON DUPLICATE KEY UPDATE quantity = quantity + ?
 }
 
 // ModifyCardQuantity modifies the quantity of a card row that exists
-func (b *Backend) ModifyCardQuantity(ctx context.Context, owner, keeper, scryfallID string, foil bool, quantity uint) (err error) {
+func (b *Backend) ModifyCardQuantity(ctx context.Context, owner, keeper string, card *inventory.Card, quantity uint) (err error) {
 	defer func() {
 		if err != nil {
-			err = fmt.Errorf("error modifying quantity of %q for %q: %w", scryfallID, owner, err)
+			err = fmt.Errorf("error modifying quantity of %q for %q: %w", card.ScryfallID, owner, err)
 		}
 	}()
 
 	if quantity == 0 {
-		deleteStmt, err := b.DB.PrepareContext(ctx, `DELETE cards
-FROM cards
-LEFT JOIN users owners ON owners.id = cards.owner
-LEFT JOIN users keepers ON keepers.id = cards.keeper
-WHERE scryfall_id = ? AND foil = ? AND owners.username = ? AND keepers.username = ?
+		deleteStmt, err := b.prepare(ctx, b.DB, `DELETE FROM cards
+WHERE scryfall_id = ? AND finish = ? AND card_condition = ? AND language = ?
+AND owner = (SELECT id FROM users WHERE username = ?)
+AND keeper = (SELECT id FROM users WHERE username = ?)
 `)
 		if err != nil {
 			return fmt.Errorf("failed to prepare delete: %w", err)
 		}
 		defer deleteStmt.Close()
 
-		_, err = deleteStmt.ExecContext(ctx, scryfallID, foil, owner, keeper)
+		_, err = deleteStmt.ExecContext(ctx, card.ScryfallID, card.Finish, card.Condition, card.Language, owner, keeper)
 		if err != nil {
 			return fmt.Errorf("failed to delete: %w", err)
 		}
@@ -285,17 +317,17 @@ WHERE scryfall_id = ? AND foil = ? AND owners.username = ? AND keepers.username
 		return nil
 	}
 
-	upsertStmt, err := b.DB.PrepareContext(ctx, `UPDATE cards
-LEFT JOIN users owners ON owners.id = cards.owner
-LEFT JOIN users keepers ON keepers.id = cards.keeper
-SET cards.quantity = ?
-WHERE scryfall_id = ? AND foil = ? AND owners.username = ? AND keepers.username = ?`)
+	upsertStmt, err := b.prepare(ctx, b.DB, `UPDATE cards
+SET quantity = ?
+WHERE scryfall_id = ? AND finish = ? AND card_condition = ? AND language = ?
+AND owner = (SELECT id FROM users WHERE username = ?)
+AND keeper = (SELECT id FROM users WHERE username = ?)`)
 	if err != nil {
 		return fmt.Errorf("failed to prepare update: %w", err)
 	}
 	defer upsertStmt.Close()
 
-	_, err = upsertStmt.ExecContext(ctx, quantity, scryfallID, foil, owner, keeper)
+	_, err = upsertStmt.ExecContext(ctx, quantity, card.ScryfallID, card.Finish, card.Condition, card.Language, owner, keeper)
 	if err != nil {
 		return fmt.Errorf("failed to update: %w", err)
 	}
@@ -0,0 +1,348 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+)
+
+// checkDeck returns an error if a deck can't have name or rows
+func checkDeck(name string, rows []*inventory.DeckCards) error {
+	if strings.TrimSpace(name) == "" {
+		return inventory.ErrNoDeckName
+	}
+	if len(rows) > inventory.RowUploadLimit {
+		return inventory.ErrTooManyRows
+	}
+	for _, row := range rows {
+		if row.Quantity == 0 {
+			return &inventory.RowError{
+				Err: inventory.ErrZeroCards,
+				Row: row,
+			}
+		}
+		err := row.Check()
+		if err != nil {
+			return &inventory.RowError{
+				Err: err,
+				Row: row,
+			}
+		}
+	}
+	return nil
+}
+
+// GetDecksByOwner returns Decks based on their owner, without their cards
+func (b *Backend) GetDecksByOwner(ctx context.Context, owner string, limit, offset uint) (_ []*inventory.Deck, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("error getting decks of %q: %w", owner, err)
+		}
+	}()
+
+	if limit == 0 {
+		limit = inventory.DefaultListLimit
+	} else if limit > inventory.MaxListLimit {
+		limit = inventory.MaxListLimit
+	}
+
+	selectStmt, err := b.prepare(ctx, b.DB, `SELECT decks.id, decks.name, COALESCE(SUM(dc.quantity), 0)
+FROM decks
+LEFT JOIN users owners ON decks.owner = owners.id
+LEFT JOIN deck_cards dc ON dc.deck_id = decks.id
+WHERE owners.username = ?
+GROUP BY decks.id, owners.username
+ORDER BY decks.name, decks.id
+LIMIT ?
+OFFSET ?
+`)
+	if err != nil {
+		return nil, fmt.Errorf("error preparing select query: %w", err)
+	}
+	defer selectStmt.Close()
+
+	rows, err := selectStmt.QueryContext(ctx, owner, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("error executing select query: %w", err)
+	}
+	defer rows.Close()
+
+	decks := make([]*inventory.Deck, 0)
+	for rows.Next() {
+		deck := &inventory.Deck{
+			Owner: owner,
+		}
+		err = rows.Scan(&deck.ID, &deck.Name, &deck.Quantity)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning row of select: %w", err)
+		}
+		decks = append(decks, deck)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("error getting next row of select: %w", err)
+	}
+
+	return decks, nil
+}
+
+// GetDeckByID returns a Deck based on its ID
+func (b *Backend) GetDeckByID(ctx context.Context, id int64, limit, offset uint) (_ *inventory.Deck, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("error getting deck \"%d\": %w", id, err)
+		}
+	}()
+
+	if limit == 0 {
+		limit = inventory.DefaultListLimit
+	} else if limit > inventory.MaxListLimit {
+		limit = inventory.MaxListLimit
+	}
+
+	selectDeckStmt, err := b.prepare(ctx, b.DB, `SELECT owners.username, decks.name, (SELECT COALESCE(SUM(dc.quantity), 0) FROM deck_cards dc WHERE dc.deck_id = decks.id)
+FROM decks
+LEFT JOIN users owners ON owners.id = decks.owner
+WHERE decks.id = ?
+`)
+	if err != nil {
+		return nil, fmt.Errorf("error preparing select for deck: %w", err)
+	}
+	defer selectDeckStmt.Close()
+
+	deck := &inventory.Deck{
+		ID:    id,
+		Cards: make([]*inventory.DeckCards, 0),
+	}
+	err = selectDeckStmt.QueryRowContext(ctx, id).Scan(&deck.Owner, &deck.Name, &deck.Quantity)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, inventory.ErrDeckNoExist
+		}
+		return nil, fmt.Errorf("error scanning row for deck: %w", err)
+	}
+
+	selectCardsStmt, err := b.prepare(ctx, b.DB, `SELECT zone, quantity, name, oracle_id, scryfall_id, finish, card_condition, language
+FROM deck_cards
+WHERE deck_id = ?
+ORDER BY zone, name, scryfall_id
+LIMIT ?
+OFFSET ?
+`)
+	if err != nil {
+		return nil, fmt.Errorf("error preparing select for cards: %w", err)
+	}
+	defer selectCardsStmt.Close()
+
+	rows, err := selectCardsStmt.QueryContext(ctx, id, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("error executing select for cards: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		deckCardsRow := &inventory.DeckCards{
+			Card: &inventory.Card{},
+		}
+		card := deckCardsRow.Card
+		err = rows.Scan(&deckCardsRow.Zone, &deckCardsRow.Quantity, &card.Name, &card.OracleID, &card.ScryfallID, &card.Finish, &card.Condition, &card.Language)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning row for cards: %w", err)
+		}
+		deck.Cards = append(deck.Cards, deckCardsRow)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("error getting next row of cards: %w", err)
+	}
+
+	return deck, nil
+}
+
+// insertDeckCards inserts rows into a deck after checking that owner owns
+// enough copies of each card for every zone it's in, returning their total
+// quantity
+func (b *Backend) insertDeckCards(ctx context.Context, tx *sql.Tx, deckID int64, owner string, rows []*inventory.DeckCards) (uint, error) {
+	selectQuantityStmt, err := b.prepare(ctx, tx, `SELECT COALESCE(SUM(cards.quantity), 0)
+FROM cards
+LEFT JOIN users owners ON owners.id = cards.owner
+WHERE cards.scryfall_id = ? AND cards.finish = ? AND cards.card_condition = ? AND cards.language = ? AND owners.username = ?
+`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare select for cards: %w", err)
+	}
+	defer selectQuantityStmt.Close()
+
+	upsertDeckCardStmt, err := b.prepare(ctx, tx, `INSERT INTO deck_cards (deck_id, zone, quantity, name, oracle_id, scryfall_id, finish, card_condition, language)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+`+b.Dialect.Upsert("deck_cards", "deck_id", "zone", "scryfall_id", "finish", "card_condition", "language")+`
+`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare upsert for deck_cards: %w", err)
+	}
+	defer upsertDeckCardStmt.Close()
+
+	var total uint
+	needed := make(map[inventory.Card]uint)
+	for _, row := range rows {
+		card := *row.Card
+		card.Name, card.OracleID = "", ""
+		needed[card] += row.Quantity
+
+		var quantity uint
+		err = selectQuantityStmt.QueryRowContext(ctx, card.ScryfallID, card.Finish, card.Condition, card.Language, owner).Scan(&quantity)
+		if err != nil {
+			return 0, fmt.Errorf("failed to scan select row: %w", err)
+		}
+		if quantity < needed[card] {
+			return 0, &inventory.RowError{
+				Err: inventory.ErrTooFewCards,
+				Row: row,
+			}
+		}
+
+		_, err = upsertDeckCardStmt.ExecContext(ctx, deckID, row.Zone, row.Quantity, row.Card.Name, row.Card.OracleID, row.Card.ScryfallID, row.Card.Finish, row.Card.Condition, row.Card.Language)
+		if err != nil {
+			return 0, fmt.Errorf("failed to upsert deck_cards: %w", err)
+		}
+		total += row.Quantity
+	}
+	return total, nil
+}
+
+// CreateDeck creates a deck after checking that its owner owns enough of
+// each card
+func (b *Backend) CreateDeck(ctx context.Context, owner, name string, rows []*inventory.DeckCards) (_ *inventory.Deck, err error) {
+	err = checkDeck(name, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := b.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating deck: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			rollbackErr := tx.Rollback()
+			if rollbackErr != nil {
+				err = fmt.Errorf("error creating deck: %w, unable to rollback: %s", err, rollbackErr)
+			} else {
+				err = fmt.Errorf("error creating deck: %w", err)
+			}
+		}
+	}()
+
+	id, inserted, err := b.insertID(ctx, tx, `INSERT INTO decks (name, owner)
+SELECT ?, users.id
+FROM users
+WHERE users.username = ?
+`, name, owner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert deck: %w", err)
+	}
+	if !inserted {
+		return nil, inventory.ErrUserNoExist
+	}
+
+	quantity, err := b.insertDeckCards(ctx, tx, id, owner, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("failed to commit inserts on multiple tables: %w", err)
+	}
+
+	return &inventory.Deck{
+		ID:       id,
+		Name:     name,
+		Owner:    owner,
+		Quantity: quantity,
+		Cards:    rows,
+	}, nil
+}
+
+// UpdateDeck renames a deck and replaces its cards after checking that its
+// owner owns enough of each card
+func (b *Backend) UpdateDeck(ctx context.Context, id int64, name string, rows []*inventory.DeckCards) (err error) {
+	err = checkDeck(name, rows)
+	if err != nil {
+		return err
+	}
+
+	tx, err := b.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error updating deck \"%d\": %w", id, err)
+	}
+	defer func() {
+		if err != nil {
+			rollbackErr := tx.Rollback()
+			if rollbackErr != nil {
+				err = fmt.Errorf("error updating deck \"%d\": %w, unable to rollback: %s", id, err, rollbackErr)
+			} else {
+				err = fmt.Errorf("error updating deck \"%d\": %w", id, err)
+			}
+		}
+	}()
+
+	selectDeckStmt, err := b.prepare(ctx, tx, `SELECT owners.username
+FROM decks
+INNER JOIN users owners ON owners.id = decks.owner
+WHERE decks.id = ?
+`+b.Dialect.ForUpdate)
+	if err != nil {
+		return fmt.Errorf("error preparing select for deck: %w", err)
+	}
+	defer selectDeckStmt.Close()
+
+	var owner string
+	err = selectDeckStmt.QueryRowContext(ctx, id).Scan(&owner)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return inventory.ErrDeckNoExist
+		}
+		return fmt.Errorf("error querying deck: %w", err)
+	}
+
+	updateStmt, err := b.prepare(ctx, tx, `UPDATE decks
+SET name = ?
+WHERE id = ?
+`)
+	if err != nil {
+		return fmt.Errorf("error preparing update of deck: %w", err)
+	}
+	defer updateStmt.Close()
+
+	_, err = updateStmt.ExecContext(ctx, name, id)
+	if err != nil {
+		return fmt.Errorf("error updating deck: %w", err)
+	}
+
+	deleteStmt, err := b.prepare(ctx, tx, `DELETE FROM deck_cards WHERE deck_id = ?`)
+	if err != nil {
+		return fmt.Errorf("error preparing delete of deck_cards: %w", err)
+	}
+	defer deleteStmt.Close()
+
+	_, err = deleteStmt.ExecContext(ctx, id)
+	if err != nil {
+		return fmt.Errorf("error deleting deck_cards: %w", err)
+	}
+
+	_, err = b.insertDeckCards(ctx, tx, id, owner, rows)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("error committing: %w", err)
+	}
+
+	return nil
+}
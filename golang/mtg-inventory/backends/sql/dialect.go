@@ -0,0 +1,136 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+)
+
+// Dialect describes how a database's SQL differs from the MySQL that the
+// queries in this package are written in, so that the same Backend can run
+// against other databases
+type Dialect struct {
+	// Placeholder returns the nth bind parameter, starting at 1. Queries are
+	// rebound with it when set and keep ? otherwise.
+	Placeholder func(n int) string
+
+	// Upsert returns the clause that makes an insert into table add its
+	// quantity to that of an existing row with the same key columns
+	Upsert func(table string, key ...string) string
+
+	// Ignore returns the clause that makes an insert into table skip a row
+	// with the same key columns as an existing one
+	Ignore func(table string, key ...string) string
+
+	// Returning is whether an insert returns the ID of its row with
+	// RETURNING id, rather than through LastInsertId
+	Returning bool
+
+	// CastParams is whether a bind parameter selected into an insert must be
+	// cast to the type of its column, because the database can't infer it
+	CastParams bool
+
+	// ForUpdate is the clause that locks the rows a SELECT returns until the
+	// end of its transaction, or empty if the database locks whole
+	// transactions instead
+	ForUpdate string
+}
+
+// MySQL is the Dialect of MySQL
+var MySQL = &Dialect{
+	Upsert: func(string, ...string) string {
+		return "ON DUPLICATE KEY UPDATE quantity = quantity + VALUES(quantity)"
+	},
+	Ignore: func(_ string, key ...string) string {
+		// Setting a column to itself changes no rows
+		return "ON DUPLICATE KEY UPDATE " + key[0] + " = " + key[0]
+	},
+	ForUpdate: "FOR UPDATE",
+}
+
+// OnConflict returns an upsert clause for databases that support ON CONFLICT,
+// such as SQLite and PostgreSQL
+func OnConflict(table string, key ...string) string {
+	return "ON CONFLICT (" + strings.Join(key, ", ") + ") DO UPDATE SET quantity = " + table + ".quantity + excluded.quantity"
+}
+
+// OnConflictDoNothing returns an ignore clause for databases that support ON
+// CONFLICT, such as SQLite and PostgreSQL
+func OnConflictDoNothing(_ string, key ...string) string {
+	return "ON CONFLICT (" + strings.Join(key, ", ") + ") DO NOTHING"
+}
+
+// Rebind replaces the ? bind parameters of query with the dialect's own
+func (d *Dialect) Rebind(query string) string {
+	if d.Placeholder == nil {
+		return query
+	}
+	var rebound strings.Builder
+	var n int
+	var quoted bool
+	for _, r := range query {
+		switch {
+		case r == '\'':
+			quoted = !quoted
+		case r == '?' && !quoted:
+			n++
+			rebound.WriteString(d.Placeholder(n))
+			continue
+		}
+		rebound.WriteRune(r)
+	}
+	return rebound.String()
+}
+
+// cast returns a bind parameter for a value of sqlType selected into an insert
+func (d *Dialect) cast(sqlType string) string {
+	if d.CastParams {
+		return "CAST(? AS " + sqlType + ")"
+	}
+	return "?"
+}
+
+// preparer is implemented by both *sql.DB and *sql.Tx
+type preparer interface {
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+// prepare prepares query, written with ? bind parameters, in the Backend's
+// dialect
+func (b *Backend) prepare(ctx context.Context, p preparer, query string) (*sql.Stmt, error) {
+	return p.PrepareContext(ctx, b.Dialect.Rebind(query))
+}
+
+// insertID executes query, an INSERT ... SELECT into a table with an id
+// column, and returns the ID of the row it inserted, or false if the SELECT
+// matched no rows
+func (b *Backend) insertID(ctx context.Context, tx *sql.Tx, query string, args ...any) (int64, bool, error) {
+	if b.Dialect.Returning {
+		var id int64
+		err := tx.QueryRowContext(ctx, b.Dialect.Rebind(query+"RETURNING id\n"), args...).Scan(&id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, false, nil
+		} else if err != nil {
+			return 0, false, err
+		}
+		return id, true, nil
+	}
+
+	result, err := tx.ExecContext(ctx, b.Dialect.Rebind(query), args...)
+	if err != nil {
+		return 0, false, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, false, err
+	}
+	if rowsAffected == 0 {
+		return 0, false, nil
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, false, err
+	}
+	return id, true, nil
+}
@@ -0,0 +1,40 @@
+package sql
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestRebind(t *testing.T) {
+	numbered := &Dialect{
+		Placeholder: func(n int) string {
+			return "$" + strconv.Itoa(n)
+		},
+	}
+	for _, test := range []struct {
+		dialect  *Dialect
+		query    string
+		expected string
+	}{
+		{
+			dialect:  MySQL,
+			query:    "SELECT id FROM users WHERE username = ? LIMIT ?",
+			expected: "SELECT id FROM users WHERE username = ? LIMIT ?",
+		},
+		{
+			dialect:  numbered,
+			query:    "SELECT id FROM users WHERE username = ? LIMIT ?",
+			expected: "SELECT id FROM users WHERE username = $1 LIMIT $2",
+		},
+		{
+			dialect:  numbered,
+			query:    "SELECT 'what?' FROM users WHERE username = ?",
+			expected: "SELECT 'what?' FROM users WHERE username = $1",
+		},
+	} {
+		actual := test.dialect.Rebind(test.query)
+		if actual != test.expected {
+			t.Errorf("Expected %q to be rebound to %q, got %q", test.query, test.expected, actual)
+		}
+	}
+}
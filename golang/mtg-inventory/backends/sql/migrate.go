@@ -0,0 +1,287 @@
+package sql
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// ErrBadMigrationName is returned when a migration's file name does not start
+// with a version number followed by an underscore, e.g. 0001_initial.sql
+var ErrBadMigrationName = errors.New("bad migration name")
+
+// Migration is a single up migration
+type Migration struct {
+	Version int64
+	Name    string
+	SQL     string
+}
+
+// Migrator brings a database up to date by applying, in order of version,
+// each migration that is not yet recorded in the schema_migrations table.
+// Migrations are never rolled back, so fixes go in a new migration.
+type Migrator struct {
+	DB *sql.DB
+
+	// Migrations contains one file per migration, named with a version
+	// followed by an underscore and a description, e.g. 0001_initial.sql
+	Migrations fs.FS
+
+	// Placeholder returns the nth bind parameter, starting at 1. It defaults
+	// to ? when unset.
+	Placeholder func(n int) string
+
+	// Lock takes a lock held by conn that keeps other migrators from
+	// migrating the same database, and returns the function that releases
+	// it. Migrations aren't locked when it's unset.
+	Lock func(ctx context.Context, conn *sql.Conn) (unlock func(ctx context.Context) error, err error)
+}
+
+// migrationLock is the name of the lock that keeps migrations from running
+// concurrently
+const migrationLock = "mtg_inventory.schema_migrations"
+
+// ErrNotLocked is returned when a lock can't be taken
+var ErrNotLocked = errors.New("not locked")
+
+// getLock locks migrations with MySQL's GET_LOCK, waiting as long as it takes
+func getLock(ctx context.Context, conn *sql.Conn) (func(context.Context) error, error) {
+	var locked sql.NullInt64
+	err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, -1)", migrationLock).Scan(&locked)
+	if err != nil {
+		return nil, err
+	}
+	if locked.Int64 != 1 {
+		return nil, fmt.Errorf("%w: GET_LOCK(%q) returned %v", ErrNotLocked, migrationLock, locked)
+	}
+	return func(ctx context.Context) error {
+		_, err := conn.ExecContext(ctx, "DO RELEASE_LOCK(?)", migrationLock)
+		return err
+	}, nil
+}
+
+// Migrate applies any migrations that have not been applied yet
+func (b *Backend) Migrate(ctx context.Context) error {
+	sub, err := fs.Sub(migrations, "migrations")
+	if err != nil {
+		return fmt.Errorf("error migrating database: %w", err)
+	}
+	m := &Migrator{
+		DB:         b.DB,
+		Migrations: sub,
+		Lock:       getLock,
+	}
+	return m.Migrate(ctx)
+}
+
+// Migrate applies every migration that has not been applied yet, holding the
+// lock when there is one so that servers started together don't apply the
+// same migration twice. Each migration runs in its own transaction, but note
+// that MySQL commits DDL statements implicitly, so a failed migration may be
+// partially applied there.
+func (m *Migrator) Migrate(ctx context.Context) (err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("error migrating database: %w", err)
+		}
+	}()
+
+	all, err := m.List()
+	if err != nil {
+		return err
+	}
+
+	if m.Lock != nil {
+		conn, err := m.DB.Conn(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get connection for lock: %w", err)
+		}
+		defer conn.Close()
+
+		unlock, err := m.Lock(ctx, conn)
+		if err != nil {
+			return fmt.Errorf("failed to lock migrations: %w", err)
+		}
+		defer func() {
+			// Release the lock even if ctx is done, since it's held by a
+			// connection that goes back to the pool
+			unlockErr := unlock(context.WithoutCancel(ctx))
+			if unlockErr != nil && err == nil {
+				err = fmt.Errorf("failed to unlock migrations: %w", unlockErr)
+			}
+		}()
+	}
+
+	_, err = m.DB.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+	version BIGINT NOT NULL PRIMARY KEY,
+	applied TIMESTAMP NOT NULL
+)`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	applied, err := m.Applied(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, migration := range all {
+		if applied[migration.Version] {
+			continue
+		}
+		err = m.apply(ctx, migration)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// List returns the migrations in order of version
+func (m *Migrator) List() ([]*Migration, error) {
+	entries, err := fs.ReadDir(m.Migrations, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	all := make([]*Migration, 0, len(entries))
+	seen := make(map[int64]string)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+		versionStr, _, found := strings.Cut(entry.Name(), "_")
+		if !found {
+			return nil, fmt.Errorf("%w: %q", ErrBadMigrationName, entry.Name())
+		}
+		version, err := strconv.ParseInt(versionStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", ErrBadMigrationName, entry.Name())
+		}
+		if other, ok := seen[version]; ok {
+			return nil, fmt.Errorf("%w: %q and %q have the same version", ErrBadMigrationName, other, entry.Name())
+		}
+		seen[version] = entry.Name()
+
+		contents, err := fs.ReadFile(m.Migrations, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %q: %w", entry.Name(), err)
+		}
+		all = append(all, &Migration{
+			Version: version,
+			Name:    entry.Name(),
+			SQL:     string(contents),
+		})
+	}
+
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].Version < all[j].Version
+	})
+
+	return all, nil
+}
+
+// Applied returns the set of versions recorded in schema_migrations
+func (m *Migrator) Applied(ctx context.Context) (_ map[int64]bool, err error) {
+	rows, err := m.DB.QueryContext(ctx, "SELECT version FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to select from schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]bool)
+	for rows.Next() {
+		var version int64
+		err = rows.Scan(&version)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan schema_migrations: %w", err)
+		}
+		applied[version] = true
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get next row of schema_migrations: %w", err)
+	}
+
+	return applied, nil
+}
+
+func (m *Migrator) apply(ctx context.Context, migration *Migration) (err error) {
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error applying %q: %w", migration.Name, err)
+	}
+	defer func() {
+		if err != nil {
+			rollbackErr := tx.Rollback()
+			if rollbackErr != nil {
+				err = fmt.Errorf("error applying %q: %w, unable to rollback: %s", migration.Name, err, rollbackErr)
+			} else {
+				err = fmt.Errorf("error applying %q: %w", migration.Name, err)
+			}
+		}
+	}()
+
+	for _, statement := range splitStatements(migration.SQL) {
+		_, err = tx.ExecContext(ctx, statement)
+		if err != nil {
+			return fmt.Errorf("failed to execute %q: %w", statement, err)
+		}
+	}
+
+	placeholder := m.Placeholder
+	if placeholder == nil {
+		placeholder = func(int) string { return "?" }
+	}
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO schema_migrations (version, applied) VALUES ("+placeholder(1)+", "+placeholder(2)+")",
+		migration.Version, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to record migration: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("failed to commit: %w", err)
+	}
+
+	return nil
+}
+
+// splitStatements splits a migration into statements on lines that end with a
+// semicolon, since not every driver accepts several statements in one Exec.
+// Lines that only hold a -- comment are dropped.
+func splitStatements(migration string) []string {
+	statements := make([]string, 0)
+	var current strings.Builder
+	scanner := bufio.NewScanner(strings.NewReader(migration))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSpace(current.String()))
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+	return statements
+}
@@ -0,0 +1,153 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestMigrator(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "migrate.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %s", err.Error())
+	}
+	defer db.Close()
+
+	migrations := fstest.MapFS{
+		"0002_add_color.sql": &fstest.MapFile{Data: []byte(`-- colors are optional
+ALTER TABLE widgets ADD COLUMN color VARCHAR(16);
+`)},
+		"0001_initial.sql": &fstest.MapFile{Data: []byte(`CREATE TABLE widgets (
+	id INTEGER NOT NULL PRIMARY KEY
+);
+
+CREATE TABLE gadgets (
+	id INTEGER NOT NULL PRIMARY KEY
+);
+`)},
+		"README": &fstest.MapFile{Data: []byte("not a migration")},
+	}
+	m := &Migrator{
+		DB:         db,
+		Migrations: migrations,
+	}
+
+	err = m.Migrate(ctx)
+	if err != nil {
+		t.Fatalf("Failed to migrate: %s", err.Error())
+	}
+	_, err = db.ExecContext(ctx, "INSERT INTO widgets (id, color) VALUES (1, 'red')")
+	if err != nil {
+		t.Fatalf("Migrations were not applied: %s", err.Error())
+	}
+
+	// Applying again is a no-op, so the ALTER does not fail
+	err = m.Migrate(ctx)
+	if err != nil {
+		t.Fatalf("Failed to migrate a second time: %s", err.Error())
+	}
+
+	migrations["0003_drop_gadgets.sql"] = &fstest.MapFile{Data: []byte("DROP TABLE gadgets;\n")}
+	err = m.Migrate(ctx)
+	if err != nil {
+		t.Fatalf("Failed to apply a new migration: %s", err.Error())
+	}
+
+	applied, err := m.Applied(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get applied migrations: %s", err.Error())
+	}
+	if !reflect.DeepEqual(applied, map[int64]bool{1: true, 2: true, 3: true}) {
+		t.Fatalf("Unexpected applied migrations: %v", applied)
+	}
+
+	migrations["0004_broken.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE sprockets (id INTEGER);\nNOT SQL;\n")}
+	err = m.Migrate(ctx)
+	if err == nil {
+		t.Fatal("Expected broken migration to fail")
+	}
+	applied, err = m.Applied(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get applied migrations: %s", err.Error())
+	}
+	if applied[4] {
+		t.Fatal("Broken migration was recorded as applied")
+	}
+	_, err = db.ExecContext(ctx, "SELECT * FROM sprockets")
+	if err == nil {
+		t.Fatal("Broken migration was not rolled back")
+	}
+}
+
+func TestMigratorBadName(t *testing.T) {
+	for _, name := range []string{"initial.sql", "one_initial.sql"} {
+		m := &Migrator{
+			Migrations: fstest.MapFS{
+				name: &fstest.MapFile{Data: []byte("SELECT 1;")},
+			},
+		}
+		_, err := m.List()
+		if !errors.Is(err, ErrBadMigrationName) {
+			t.Errorf("Expected ErrBadMigrationName for %q, got: %v", name, err)
+		}
+	}
+
+	m := &Migrator{
+		Migrations: fstest.MapFS{
+			"1_a.sql":    &fstest.MapFile{Data: []byte("SELECT 1;")},
+			"0001_b.sql": &fstest.MapFile{Data: []byte("SELECT 1;")},
+		},
+	}
+	_, err := m.List()
+	if !errors.Is(err, ErrBadMigrationName) {
+		t.Errorf("Expected ErrBadMigrationName for duplicate versions, got: %v", err)
+	}
+}
+
+func TestMigratorLock(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "migrate.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %s", err.Error())
+	}
+	defer db.Close()
+
+	var locked, unlocked bool
+	m := &Migrator{
+		DB: db,
+		Migrations: fstest.MapFS{
+			"0001_initial.sql": &fstest.MapFile{Data: []byte("CREATE TABLE widgets (id INTEGER);\n")},
+		},
+		Lock: func(ctx context.Context, conn *sql.Conn) (func(context.Context) error, error) {
+			locked = true
+			return func(context.Context) error {
+				unlocked = true
+				return nil
+			}, nil
+		},
+	}
+	err = m.Migrate(ctx)
+	if err != nil {
+		t.Fatalf("Failed to migrate: %s", err.Error())
+	}
+	if !locked || !unlocked {
+		t.Fatalf("Expected migrations to be locked and unlocked, got locked %t and unlocked %t", locked, unlocked)
+	}
+
+	m.Lock = func(ctx context.Context, conn *sql.Conn) (func(context.Context) error, error) {
+		return nil, ErrNotLocked
+	}
+	err = m.Migrate(ctx)
+	if !errors.Is(err, ErrNotLocked) {
+		t.Fatalf("Expected ErrNotLocked when the lock can't be taken, got: %v", err)
+	}
+}
@@ -1,7 +1,3 @@
-CREATE DATABASE IF NOT EXISTS mtg_inventory;
-
-USE mtg_inventory;
-
 CREATE TABLE IF NOT EXISTS users (
 	id INT NOT NULL PRIMARY KEY AUTO_INCREMENT,
 	username VARCHAR(256) NOT NULL,
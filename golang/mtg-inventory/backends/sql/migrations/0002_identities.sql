@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS identities (
+	provider VARCHAR(64) NOT NULL,
+	workspace VARCHAR(256) NOT NULL,
+	external_id VARCHAR(256) NOT NULL,
+	user_id INT NOT NULL,
+	PRIMARY KEY (provider, workspace, external_id),
+	FOREIGN KEY (user_id) REFERENCES users(id)
+);
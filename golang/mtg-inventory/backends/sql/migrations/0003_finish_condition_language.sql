@@ -0,0 +1,25 @@
+-- Copies of a card are told apart by finish, condition and language, and foil
+-- becomes a finish
+ALTER TABLE cards
+	ADD COLUMN finish VARCHAR(16) NOT NULL DEFAULT 'nonfoil',
+	ADD COLUMN card_condition VARCHAR(8) NOT NULL DEFAULT 'NM',
+	ADD COLUMN language VARCHAR(16) NOT NULL DEFAULT '';
+
+UPDATE cards SET finish = 'foil' WHERE foil;
+
+ALTER TABLE cards
+	ADD CONSTRAINT cards_copy UNIQUE (scryfall_id, finish, card_condition, language, owner, keeper),
+	DROP INDEX scryfall_id,
+	DROP COLUMN foil;
+
+ALTER TABLE transferred_cards
+	ADD COLUMN finish VARCHAR(16) NOT NULL DEFAULT 'nonfoil',
+	ADD COLUMN card_condition VARCHAR(8) NOT NULL DEFAULT 'NM',
+	ADD COLUMN language VARCHAR(16) NOT NULL DEFAULT '';
+
+UPDATE transferred_cards SET finish = 'foil' WHERE foil;
+
+ALTER TABLE transferred_cards
+	ADD CONSTRAINT transferred_cards_copy UNIQUE (transfer_id, scryfall_id, finish, card_condition, language, owner),
+	DROP INDEX transfer_id,
+	DROP COLUMN foil;
@@ -0,0 +1,21 @@
+-- Decks group copies of cards that their owner owns into zones
+CREATE TABLE IF NOT EXISTS decks (
+	id INT NOT NULL PRIMARY KEY AUTO_INCREMENT,
+	name VARCHAR(256) NOT NULL,
+	owner INT NOT NULL,
+	FOREIGN KEY (owner) REFERENCES users(id)
+);
+
+CREATE TABLE IF NOT EXISTS deck_cards (
+	deck_id INT NOT NULL,
+	zone VARCHAR(16) NOT NULL,
+	quantity INT NOT NULL,
+	name VARCHAR(256) NOT NULL,
+	oracle_id VARCHAR(256) NOT NULL,
+	scryfall_id VARCHAR(256) NOT NULL,
+	finish VARCHAR(16) NOT NULL,
+	card_condition VARCHAR(8) NOT NULL,
+	language VARCHAR(16) NOT NULL DEFAULT '',
+	UNIQUE (deck_id, zone, scryfall_id, finish, card_condition, language),
+	FOREIGN KEY (deck_id) REFERENCES decks(id) ON DELETE CASCADE
+);
@@ -25,13 +25,13 @@ func (b *Backend) GetRequestsByRequestor(ctx context.Context, requestorUsername
 		limit = inventory.MaxListLimit
 	}
 
-	selectStmt, err := b.DB.PrepareContext(ctx, `SELECT requests.id, requests.opened, requests.closed, SUM(rc.quantity)
+	selectStmt, err := b.prepare(ctx, b.DB, `SELECT requests.id, requests.opened, requests.closed, SUM(rc.quantity)
 FROM requests
 LEFT JOIN requested_cards rc ON requests.id = rc.request_id
 LEFT JOIN users ON requests.requestor = users.id
 WHERE users.username = ?
 GROUP BY requests.id
-ORDER BY requests.opened
+ORDER BY requests.opened, requests.id
 LIMIT ?
 OFFSET ?
 `)
@@ -44,6 +44,7 @@ OFFSET ?
 	if err != nil {
 		return nil, fmt.Errorf("error executing select query: %w", err)
 	}
+	defer rows.Close()
 
 	requests := make([]*inventory.Request, 0)
 	for rows.Next() {
@@ -88,7 +89,7 @@ func (b *Backend) GetRequestByID(ctx context.Context, id int64, limit, offset ui
 		limit = inventory.MaxListLimit
 	}
 
-	selectRequestStmt, err := b.DB.PrepareContext(ctx, `SELECT users.username, requests.opened, requests.closed
+	selectRequestStmt, err := b.prepare(ctx, b.DB, `SELECT users.username, requests.opened, requests.closed
 FROM requests
 LEFT JOIN users ON requests.requestor = users.id
 WHERE requests.id = ?
@@ -120,7 +121,7 @@ WHERE requests.id = ?
 		request.Closed = &closed.Time
 	}
 
-	selectCardsStmt, err := b.DB.PrepareContext(ctx, `SELECT quantity, name, oracle_id
+	selectCardsStmt, err := b.prepare(ctx, b.DB, `SELECT quantity, name, oracle_id
 FROM requested_cards
 WHERE request_id = ?
 ORDER BY name
@@ -136,10 +137,11 @@ OFFSET ?
 	if err != nil {
 		return nil, fmt.Errorf("error executing select for cards: %w", err)
 	}
+	defer rows.Close()
 	for rows.Next() {
 		var quantity uint
 		var name, oracleID string
-		err = rows.Scan(&id, &name, &oracleID)
+		err = rows.Scan(&quantity, &name, &oracleID)
 		if err != nil {
 			return nil, fmt.Errorf("error scanning row for cards: %w", err)
 		}
@@ -189,31 +191,23 @@ func (b *Backend) OpenRequest(ctx context.Context, requestorUsername string, row
 		}
 	}()
 
-	insertRequestStmt, err := tx.PrepareContext(ctx, `INSERT INTO requests (requestor, opened)
-SELECT users.id, ?
-FROM users
-WHERE users.username = ?
-`)
-	if err != nil {
-		return nil, fmt.Errorf("error preparing request: %w", err)
-	}
-	defer insertRequestStmt.Close()
-
 	now := time.Now()
 
-	result, err := insertRequestStmt.ExecContext(ctx, now, requestorUsername)
+	requestID, inserted, err := b.insertID(ctx, tx, `INSERT INTO requests (requestor, opened)
+SELECT users.id, `+b.Dialect.cast("TIMESTAMP WITH TIME ZONE")+`
+FROM users
+WHERE users.username = ?
+`, now, requestorUsername)
 	if err != nil {
 		return nil, fmt.Errorf("error inserting request: %w", err)
 	}
-
-	requestID, err := result.LastInsertId()
-	if err != nil {
-		return nil, fmt.Errorf("error getting new request ID: %w", err)
+	if !inserted {
+		return nil, inventory.ErrUserNoExist
 	}
 
-	upsertRequestedCardsStmt, err := tx.PrepareContext(ctx, `INSERT INTO requested_cards (request_id, name, oracle_id, quantity)
+	upsertRequestedCardsStmt, err := b.prepare(ctx, tx, `INSERT INTO requested_cards (request_id, name, oracle_id, quantity)
 VALUES (?, ?, ?, ?)
-ON DUPLICATE KEY UPDATE quantity = quantity + ?
+`+b.Dialect.Upsert("requested_cards", "request_id", "oracle_id")+`
 `)
 	if err != nil {
 		return nil, fmt.Errorf("error preparing request cards: %w", err)
@@ -221,7 +215,7 @@ ON DUPLICATE KEY UPDATE quantity = quantity + ?
 	defer upsertRequestedCardsStmt.Close()
 
 	for _, cards := range rows {
-		_, err := upsertRequestedCardsStmt.ExecContext(ctx, requestID, cards.Name, cards.OracleID, cards.Quantity, cards.Quantity)
+		_, err := upsertRequestedCardsStmt.ExecContext(ctx, requestID, cards.Name, cards.OracleID, cards.Quantity)
 		if err != nil {
 			return nil, fmt.Errorf("error inserting requested cards: %w", err)
 		}
@@ -250,8 +244,8 @@ func (b *Backend) CloseRequest(ctx context.Context, id int64) (err error) {
 		}
 	}()
 
-	closeStmt, err := b.DB.PrepareContext(ctx, `UPDATE requests
-SET closed = NOW()
+	closeStmt, err := b.prepare(ctx, b.DB, `UPDATE requests
+SET closed = ?
 WHERE id = ?
 `)
 	if err != nil {
@@ -259,7 +253,7 @@ WHERE id = ?
 	}
 	defer closeStmt.Close()
 
-	result, err := closeStmt.ExecContext(ctx, id)
+	result, err := closeStmt.ExecContext(ctx, time.Now(), id)
 	if err != nil {
 		return fmt.Errorf("error updating request: %w", err)
 	}
@@ -1,5 +1,6 @@
 /*
-Package sql contains a Backend implementation based on a SQL database.
+Package sql contains a Backend implementation based on a SQL database. It's
+written for MySQL, and runs on other databases through a Dialect.
 */
 package sql
 
@@ -9,12 +10,20 @@ import (
 
 // Backend contains everything needed to run a SQL backend
 type Backend struct {
-	DB *sql.DB
+	DB      *sql.DB
+	Dialect *Dialect
 }
 
-// NewBackend returns an instantiated Backend
+// NewBackend returns an instantiated Backend for a MySQL database
 func NewBackend(db *sql.DB) *Backend {
+	return NewDialectBackend(db, MySQL)
+}
+
+// NewDialectBackend returns an instantiated Backend for a database that speaks
+// dialect
+func NewDialectBackend(db *sql.DB, dialect *Dialect) *Backend {
 	return &Backend{
-		DB: db,
+		DB:      db,
+		Dialect: dialect,
 	}
 }
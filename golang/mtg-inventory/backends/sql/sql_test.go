@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/backends/backendtest"
 	_ "github.com/go-sql-driver/mysql"
 )
 
@@ -31,7 +32,20 @@ func TestSQL(t *testing.T) {
 		t.Fatalf("Failed to open db connection: %s", err.Error())
 	}
 
+	err = NewBackend(db).Migrate(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to migrate: %s", err.Error())
+	}
+
 	if deleteAll {
+		_, err = db.Exec("DELETE FROM deck_cards")
+		if err != nil {
+			t.Fatalf("Failed to delete from deck_cards: %s", err.Error())
+		}
+		_, err = db.Exec("DELETE FROM decks")
+		if err != nil {
+			t.Fatalf("Failed to delete from decks: %s", err.Error())
+		}
 		_, err = db.Exec("DELETE FROM transferred_cards")
 		if err != nil {
 			t.Fatalf("Failed to delete from transferred_cards: %s", err.Error())
@@ -52,6 +66,10 @@ func TestSQL(t *testing.T) {
 		if err != nil {
 			t.Fatalf("Failed to delete from cards: %s", err.Error())
 		}
+		_, err = db.Exec("DELETE FROM identities")
+		if err != nil {
+			t.Fatalf("Failed to delete from identities: %s", err.Error())
+		}
 		_, err = db.Exec("DELETE FROM users")
 		if err != nil {
 			t.Fatalf("Failed to delete from users: %s", err.Error())
@@ -74,7 +92,8 @@ func TestSQL(t *testing.T) {
 		Name:       "fake-card-name-1",
 		OracleID:   "fake-oracle-ID-1",
 		ScryfallID: "fake-scryfall-ID-1",
-		Foil:       false,
+		Finish:     inventory.FinishNonfoil,
+		Condition:  inventory.ConditionNearMint,
 	}
 
 	fakeCardRow1 := &inventory.CardRow{
@@ -88,7 +107,8 @@ func TestSQL(t *testing.T) {
 		Name:       "fake-card-name-2",
 		OracleID:   "fake-oracle-ID-2",
 		ScryfallID: "fake-scryfall-ID-2",
-		Foil:       false,
+		Finish:     inventory.FinishNonfoil,
+		Condition:  inventory.ConditionNearMint,
 	}
 
 	fakeCardRow2 := &inventory.CardRow{
@@ -113,12 +133,12 @@ func TestSQL(t *testing.T) {
 		t.Fatalf("Failed to update cards: %s", err.Error())
 	}
 
-	err = b.ModifyCardQuantity(context.Background(), user1.Username, user1.Username, fakeCard1.ScryfallID, false, 7)
+	err = b.ModifyCardQuantity(context.Background(), user1.Username, user1.Username, fakeCard1, 7)
 	if err != nil {
 		t.Fatalf("Failed to update card quantity: %s", err.Error())
 	}
 
-	err = b.ModifyCardQuantity(context.Background(), user1.Username, user1.Username, fakeCard2.ScryfallID, false, 0)
+	err = b.ModifyCardQuantity(context.Background(), user1.Username, user1.Username, fakeCard2, 0)
 	if err != nil {
 		t.Fatalf("Failed to update card quantity: %s", err.Error())
 	}
@@ -206,4 +226,8 @@ func TestSQL(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to close transfer: %s", err.Error())
 	}
+
+	backendtest.RunConformance(t, func() inventory.Backend {
+		return NewBackend(db)
+	})
 }
@@ -24,14 +24,14 @@ func (b *Backend) GetTransfersByToUser(ctx context.Context, toUser string, limit
 		limit = inventory.MaxListLimit
 	}
 
-	selectStmt, err := b.DB.PrepareContext(ctx, `SELECT transfers.id, transfers.request_id, from_users.username, transfers.opened, transfers.closed, SUM(tc.quantity)
+	selectStmt, err := b.prepare(ctx, b.DB, `SELECT transfers.id, transfers.request_id, from_users.username, transfers.opened, transfers.closed, SUM(tc.quantity)
 FROM transfers
 LEFT JOIN users from_users ON transfers.from_user = from_users.id
 LEFT JOIN users to_users ON transfers.to_user = to_users.id
 LEFT JOIN transferred_cards tc ON tc.transfer_id = transfers.id
 WHERE to_users.username = ?
-GROUP BY transfers.id
-ORDER BY transfers.opened
+GROUP BY transfers.id, from_users.username, to_users.username
+ORDER BY transfers.opened, transfers.id
 LIMIT ?
 OFFSET ?
 `)
@@ -44,6 +44,7 @@ OFFSET ?
 	if err != nil {
 		return nil, fmt.Errorf("error executing select query: %w", err)
 	}
+	defer rows.Close()
 
 	transfers := make([]*inventory.Transfer, 0)
 	for rows.Next() {
@@ -94,14 +95,14 @@ func (b *Backend) GetTransfersByFromUser(ctx context.Context, fromUser string, l
 		limit = inventory.MaxListLimit
 	}
 
-	selectStmt, err := b.DB.PrepareContext(ctx, `SELECT transfers.id, transfers.request_id, to_users.username, transfers.opened, transfers.closed, SUM(tc.quantity)
+	selectStmt, err := b.prepare(ctx, b.DB, `SELECT transfers.id, transfers.request_id, to_users.username, transfers.opened, transfers.closed, SUM(tc.quantity)
 FROM transfers
 LEFT JOIN users from_users ON transfers.from_user = from_users.id
 LEFT JOIN users to_users ON transfers.to_user = to_users.id
 LEFT JOIN transferred_cards tc ON tc.transfer_id = transfers.id
 WHERE from_users.username = ?
-GROUP BY transfers.id
-ORDER BY transfers.opened
+GROUP BY transfers.id, from_users.username, to_users.username
+ORDER BY transfers.opened, transfers.id
 LIMIT ?
 OFFSET ?
 `)
@@ -114,6 +115,7 @@ OFFSET ?
 	if err != nil {
 		return nil, fmt.Errorf("error executing select query: %w", err)
 	}
+	defer rows.Close()
 
 	transfers := make([]*inventory.Transfer, 0)
 	for rows.Next() {
@@ -164,14 +166,14 @@ func (b *Backend) GetTransfersByRequestID(ctx context.Context, requestID int64,
 		limit = inventory.MaxListLimit
 	}
 
-	selectStmt, err := b.DB.PrepareContext(ctx, `SELECT transfers.id, to_users.username, from_users.username, transfers.opened, transfers.closed, SUM(tc.quantity)
+	selectStmt, err := b.prepare(ctx, b.DB, `SELECT transfers.id, to_users.username, from_users.username, transfers.opened, transfers.closed, SUM(tc.quantity)
 FROM transfers
 LEFT JOIN users from_users ON transfers.from_user = from_users.id
 LEFT JOIN users to_users ON transfers.to_user = to_users.id
 LEFT JOIN transferred_cards tc ON tc.transfer_id = transfers.id
 WHERE transfers.request_id = ?
-GROUP BY transfers.id
-ORDER BY transfers.opened
+GROUP BY transfers.id, from_users.username, to_users.username
+ORDER BY transfers.opened, transfers.id
 LIMIT ?
 OFFSET ?
 `)
@@ -184,6 +186,7 @@ OFFSET ?
 	if err != nil {
 		return nil, fmt.Errorf("error executing select query: %w", err)
 	}
+	defer rows.Close()
 
 	transfers := make([]*inventory.Transfer, 0)
 	for rows.Next() {
@@ -231,7 +234,7 @@ func (b *Backend) GetTransferByID(ctx context.Context, id int64, limit, offset u
 		limit = inventory.MaxListLimit
 	}
 
-	selectTransferStmt, err := b.DB.PrepareContext(ctx, `SELECT transfers.request_id, to_users.username, from_users.username, opened, closed
+	selectTransferStmt, err := b.prepare(ctx, b.DB, `SELECT transfers.request_id, to_users.username, from_users.username, opened, closed
 FROM transfers
 LEFT JOIN users to_users ON to_users.id = transfers.to_user
 LEFT JOIN users from_users ON from_users.id = transfers.from_user
@@ -269,7 +272,7 @@ WHERE transfers.id = ?
 		transfer.Closed = &closed.Time
 	}
 
-	selectCardsStmt, err := b.DB.PrepareContext(ctx, `SELECT tc.quantity, tc.name, tc.scryfall_id, tc.foil, owners.username
+	selectCardsStmt, err := b.prepare(ctx, b.DB, `SELECT tc.quantity, tc.name, tc.scryfall_id, tc.finish, tc.card_condition, tc.language, owners.username
 FROM transferred_cards AS tc
 LEFT JOIN users owners ON owners.id = tc.owner
 WHERE tc.transfer_id = ?
@@ -286,11 +289,14 @@ OFFSET ?
 	if err != nil {
 		return nil, fmt.Errorf("error executing select for cards: %w", err)
 	}
+	defer rows.Close()
 	for rows.Next() {
 		var quantity uint
 		var name, scryfallID, owner string
-		var foil bool
-		err = rows.Scan(&quantity, &name, &scryfallID, &foil, &owner)
+		var finish inventory.Finish
+		var condition inventory.Condition
+		var language string
+		err = rows.Scan(&quantity, &name, &scryfallID, &finish, &condition, &language, &owner)
 		if err != nil {
 			return nil, fmt.Errorf("error scanning row for cards: %w", err)
 		}
@@ -299,7 +305,9 @@ OFFSET ?
 			Card: &inventory.Card{
 				Name:       name,
 				ScryfallID: scryfallID,
-				Foil:       foil,
+				Finish:     finish,
+				Condition:  condition,
+				Language:   language,
 			},
 			Owner: owner,
 		}
@@ -313,7 +321,8 @@ OFFSET ?
 	return transfer, nil
 }
 
-// OpenTransfer creates a transfer
+// OpenTransfer creates a transfer after checking that the from user is keeping
+// enough of each card, the cards are not moved until the transfer is closed
 func (b *Backend) OpenTransfer(ctx context.Context, toUser, fromUser string, requestIDIn *int64, transferRows []*inventory.TransferredCards) (_ *inventory.Transfer, err error) {
 	if len(transferRows) > inventory.RowUploadLimit {
 		return nil, inventory.ErrTooManyRows
@@ -325,6 +334,13 @@ func (b *Backend) OpenTransfer(ctx context.Context, toUser, fromUser string, req
 				Row: row,
 			}
 		}
+		err := row.Card.Check()
+		if err != nil {
+			return nil, &inventory.RowError{
+				Err: err,
+				Row: row,
+			}
+		}
 	}
 
 	tx, err := b.DB.BeginTx(ctx, nil)
@@ -348,24 +364,33 @@ func (b *Backend) OpenTransfer(ctx context.Context, toUser, fromUser string, req
 	if requestIDIn != nil {
 		requestID.Int64 = *requestIDIn
 		requestID.Valid = true
+
+		selectRequestStmt, err := b.prepare(ctx, tx, "SELECT COUNT(*) FROM requests WHERE id = ?")
+		if err != nil {
+			return nil, fmt.Errorf("failed to prepare select for request: %w", err)
+		}
+		defer selectRequestStmt.Close()
+
+		var count int
+		err = selectRequestStmt.QueryRowContext(ctx, requestID).Scan(&count)
+		if err != nil {
+			return nil, fmt.Errorf("failed to select request: %w", err)
+		}
+		if count == 0 {
+			return nil, inventory.ErrRequestNoExist
+		}
 	}
-	insertTransferStmt, err := tx.PrepareContext(ctx, `INSERT INTO transfers (to_user, from_user, request_id, opened)
-SELECT to_users.id, from_users.id, ?, ?
+
+	id, inserted, err := b.insertID(ctx, tx, `INSERT INTO transfers (to_user, from_user, request_id, opened)
+SELECT to_users.id, from_users.id, `+b.Dialect.cast("INTEGER")+`, `+b.Dialect.cast("TIMESTAMP WITH TIME ZONE")+`
 FROM users to_users, users from_users
 WHERE to_users.username = ? AND from_users.username = ?
-`)
-	if err != nil {
-		return nil, fmt.Errorf("failed to prepare insert transfer: %w", err)
-	}
-	defer insertTransferStmt.Close()
-
-	result, err := insertTransferStmt.ExecContext(ctx, requestID, now, toUser, fromUser)
+`, requestID, now, toUser, fromUser)
 	if err != nil {
 		return nil, fmt.Errorf("failed to insert transfer: %w", err)
 	}
-	id, err := result.LastInsertId()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get last insert: %w", err)
+	if !inserted {
+		return nil, inventory.ErrUserNoExist
 	}
 	transfer := &inventory.Transfer{
 		ID:        id,
@@ -376,44 +401,22 @@ WHERE to_users.username = ? AND from_users.username = ?
 		Cards:     transferRows,
 	}
 
-	selectQuantityStmt, err := tx.PrepareContext(ctx, `SELECT quantity
+	selectQuantityStmt, err := b.prepare(ctx, tx, `SELECT quantity
 FROM cards
 LEFT JOIN users owners ON owners.id = cards.owner
 LEFT JOIN users keepers ON keepers.id = cards.keeper
-WHERE cards.scryfall_id = ? AND cards.foil = ? AND owners.username = ? AND keepers.username = ?
+WHERE cards.scryfall_id = ? AND cards.finish = ? AND cards.card_condition = ? AND cards.language = ? AND owners.username = ? AND keepers.username = ?
 `)
 	if err != nil {
 		return nil, fmt.Errorf("failed to prepare select for cards: %w", err)
 	}
 	defer selectQuantityStmt.Close()
 
-	updateCardStmt, err := tx.PrepareContext(ctx, `UPDATE cards
-LEFT JOIN users owners ON owners.id = cards.owner
-LEFT JOIN users keepers ON keepers.id = cards.keeper
-SET quantity = quantity - ?
-WHERE cards.scryfall_id = ? AND cards.foil = ? AND owners.username = ? AND keepers.username = ?
-`)
-	if err != nil {
-		return nil, fmt.Errorf("failed to prepare update for cards: %w", err)
-	}
-	defer updateCardStmt.Close()
-
-	upsertCardStmt, err := tx.PrepareContext(ctx, `INSERT INTO cards (quantity, name, oracle_id, scryfall_id, foil, owner, keeper)
-SELECT ?, ?, ?, ?, ?, owners.id, keepers.id
-FROM users owners, users keepers
-WHERE owners.username = ? AND keepers.username = ?
-ON DUPLICATE KEY UPDATE quantity = quantity + ?
-`)
-	if err != nil {
-		return nil, fmt.Errorf("failed to prepare upsert for cards: %w", err)
-	}
-	defer upsertCardStmt.Close()
-
-	upsertTransferCardStmt, err := tx.PrepareContext(ctx, `INSERT INTO transferred_cards (transfer_id, quantity, name, scryfall_id, foil, owner)
-SELECT ?, ?, ?, ?, ?, users.id
+	upsertTransferCardStmt, err := b.prepare(ctx, tx, `INSERT INTO transferred_cards (transfer_id, quantity, name, scryfall_id, finish, card_condition, language, owner)
+SELECT `+b.Dialect.cast("INTEGER")+`, `+b.Dialect.cast("INTEGER")+`, ?, ?, ?, ?, ?, users.id
 FROM users
 WHERE users.username = ?
-ON DUPLICATE KEY UPDATE quantity = quantity + ?
+`+b.Dialect.Upsert("transferred_cards", "transfer_id", "scryfall_id", "finish", "card_condition", "language", "owner")+`
 `)
 	if err != nil {
 		return nil, fmt.Errorf("failed to prepare upsert for transferred_cards: %w", err)
@@ -421,47 +424,43 @@ ON DUPLICATE KEY UPDATE quantity = quantity + ?
 	defer upsertTransferCardStmt.Close()
 
 	for _, transferRow := range transferRows {
-		row := selectQuantityStmt.QueryRow(
+		row := selectQuantityStmt.QueryRowContext(ctx,
 			transferRow.Card.ScryfallID,
-			transferRow.Card.Foil,
+			transferRow.Card.Finish,
+			transferRow.Card.Condition,
+			transferRow.Card.Language,
 			transferRow.Owner,
 			fromUser,
 		)
 		var quantity uint
 		err = row.Scan(&quantity)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan select row: %s", err)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("failed to scan select row: %w", err)
 		}
 		if quantity < transferRow.Quantity {
-			return nil, fmt.Errorf("too few cards to transfer %d copies of %s",
-				transferRow.Quantity, transferRow.Card.ScryfallID)
-		}
-
-		_, err = updateCardStmt.ExecContext(ctx, transferRow.Quantity, transferRow.Card.ScryfallID, transferRow.Card.Foil, transferRow.Owner, fromUser)
-		if err != nil {
-			return nil, fmt.Errorf("failed to update cards: %w", err)
-		}
-
-		_, err = upsertCardStmt.ExecContext(ctx, transferRow.Quantity, transferRow.Card.Name, transferRow.Card.OracleID, transferRow.Card.ScryfallID, transferRow.Card.Foil, transferRow.Owner, toUser, transferRow.Quantity)
-		if err != nil {
-			return nil, fmt.Errorf("failed to upsert cards: %w", err)
+			return nil, &inventory.RowError{
+				Err: inventory.ErrTooFewCards,
+				Row: transferRow,
+			}
 		}
 
-		_, err = upsertTransferCardStmt.ExecContext(ctx, transfer.ID, transferRow.Quantity, transferRow.Card.Name, transferRow.Card.ScryfallID, transferRow.Card.Foil, transferRow.Owner, transferRow.Quantity)
+		_, err = upsertTransferCardStmt.ExecContext(ctx, transfer.ID, transferRow.Quantity, transferRow.Card.Name, transferRow.Card.ScryfallID, transferRow.Card.Finish, transferRow.Card.Condition, transferRow.Card.Language, transferRow.Owner)
 		if err != nil {
 			return nil, fmt.Errorf("failed to upsert transferred_cards: %w", err)
 		}
+		transfer.Quantity += transferRow.Quantity
 	}
 
 	err = tx.Commit()
 	if err != nil {
-		return nil, fmt.Errorf("failed to commit inserts and updates on multiple tables: %w", err)
+		return nil, fmt.Errorf("failed to commit inserts on multiple tables: %w", err)
 	}
 
 	return transfer, nil
 }
 
-// CancelTransfer cancels a transfer, deleting it from the database
+// CancelTransfer cancels a transfer that has not been closed, deleting it from
+// the database
 func (b *Backend) CancelTransfer(ctx context.Context, id int64) (err error) {
 	defer func() {
 		if err != nil {
@@ -469,7 +468,7 @@ func (b *Backend) CancelTransfer(ctx context.Context, id int64) (err error) {
 		}
 	}()
 
-	deleteStmt, err := b.DB.PrepareContext(ctx, `DELETE FROM transfers WHERE id = ?`)
+	deleteStmt, err := b.prepare(ctx, b.DB, `DELETE FROM transfers WHERE id = ? AND closed IS NULL`)
 	if err != nil {
 		return fmt.Errorf("error preparing transfer delete: %w", err)
 	}
@@ -486,6 +485,20 @@ func (b *Backend) CancelTransfer(ctx context.Context, id int64) (err error) {
 	}
 
 	if rowsAffected <= 0 {
+		selectStmt, err := b.prepare(ctx, b.DB, `SELECT COUNT(*) FROM transfers WHERE id = ?`)
+		if err != nil {
+			return fmt.Errorf("error preparing select for transfer: %w", err)
+		}
+		defer selectStmt.Close()
+
+		var count int
+		err = selectStmt.QueryRowContext(ctx, id).Scan(&count)
+		if err != nil {
+			return fmt.Errorf("error selecting transfer: %w", err)
+		}
+		if count > 0 {
+			return inventory.ErrTransferClosed
+		}
 		return inventory.ErrTransferNoExist
 	}
 
@@ -493,7 +506,9 @@ func (b *Backend) CancelTransfer(ctx context.Context, id int64) (err error) {
 }
 
 // CloseTransfer sets the closed time on a Transfer and reassigns the keeper of
-// the cards
+// the cards. Cards only move here, not when the transfer is opened, since
+// moving them at both would take them from the from user twice. The transfer
+// row is locked so two closes can't both move the cards.
 func (b *Backend) CloseTransfer(ctx context.Context, id int64) (err error) {
 	tx, err := b.DB.BeginTx(ctx, nil)
 	if err != nil {
@@ -510,30 +525,35 @@ func (b *Backend) CloseTransfer(ctx context.Context, id int64) (err error) {
 		}
 	}()
 
-	selectToUserStmt, err := tx.PrepareContext(ctx, `SELECT to_users.id, to_users.username
+	selectTransferStmt, err := b.prepare(ctx, tx, `SELECT to_user, closed
 FROM transfers
-LEFT JOIN users to_users ON transfers.to_user = users.id
-WHERE id = ?`)
+WHERE id = ?
+`+b.Dialect.ForUpdate)
 	if err != nil {
-		return fmt.Errorf("error preparing select for to user: %w", err)
+		return fmt.Errorf("error preparing select for transfer: %w", err)
 	}
-	defer selectToUserStmt.Close()
+	defer selectTransferStmt.Close()
 
 	var toUserID int64
-	var toUser string
-	queryRow := selectToUserStmt.QueryRowContext(ctx, id)
-	err = queryRow.Scan(&toUserID, &toUser)
+	var closed sql.NullTime
+	queryRow := selectTransferStmt.QueryRowContext(ctx, id)
+	err = queryRow.Scan(&toUserID, &closed)
 	if err != nil {
-		return fmt.Errorf("error querying to user: %w", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return inventory.ErrTransferNoExist
+		}
+		return fmt.Errorf("error querying transfer: %w", err)
+	}
+	if closed.Valid {
+		return inventory.ErrTransferClosed
 	}
 
-	selectCards, err := tx.PrepareContext(ctx, `SELECT cards.name, cards.oracle_id, cards.scryfall_id, cards.foil, cards.quantity, tc.quantity, owners.username, owners.id, from_users.username, from_users.id
-FROM transfers
-LEFT JOIN transferred_cards tc ON transfers.id = tc.transfer_id
-LEFT JOIN cards ON tc.scryfall_id = cards.scryfall_id AND tc.foil = cards.FOIL AND tc.owner = cards.owner AND transfers.from_user = cards.keeper
-LEFT JOIN users owners ON owners.id = cards.owner
-LEFT JOIN users from_users ON transfers.from_user = users.id
-WHERE transfer_id = ?
+	selectCards, err := b.prepare(ctx, tx, `SELECT tc.name, cards.oracle_id, tc.scryfall_id, tc.finish, tc.card_condition, tc.language, cards.quantity, tc.quantity, owners.username, tc.owner, transfers.from_user
+FROM transferred_cards tc
+INNER JOIN transfers ON transfers.id = tc.transfer_id
+LEFT JOIN cards ON tc.scryfall_id = cards.scryfall_id AND tc.finish = cards.finish AND tc.card_condition = cards.card_condition AND tc.language = cards.language AND tc.owner = cards.owner AND transfers.from_user = cards.keeper
+LEFT JOIN users owners ON owners.id = tc.owner
+WHERE tc.transfer_id = ?
 `)
 	if err != nil {
 		return fmt.Errorf("error preparing select: %w", err)
@@ -544,35 +564,44 @@ WHERE transfer_id = ?
 	if err != nil {
 		return fmt.Errorf("error selecting: %w", err)
 	}
+	defer rows.Close()
 
 	type transferredCards struct {
 		name             string
 		oracleID         string
 		scryfallID       string
-		foil             bool
+		finish           inventory.Finish
+		condition        inventory.Condition
+		language         string
 		actualQuantity   uint
 		transferQuantity uint
 		owner            string
 		ownerID          int64
-		fromUser         string
 		fromUserID       int64
 	}
 
 	transferRows := make([]*transferredCards, 0)
 	for rows.Next() {
 		var tc transferredCards
-		err = rows.Scan(&tc.name, &tc.oracleID, &tc.scryfallID, &tc.foil, &tc.actualQuantity, &tc.transferQuantity, &tc.owner, &tc.ownerID, &tc.fromUser, &tc.fromUserID)
+		var oracleID sql.NullString
+		var actualQuantity sql.NullInt64
+		err = rows.Scan(&tc.name, &oracleID, &tc.scryfallID, &tc.finish, &tc.condition, &tc.language, &actualQuantity, &tc.transferQuantity, &tc.owner, &tc.ownerID, &tc.fromUserID)
 		if err != nil {
 			return fmt.Errorf("error scanning on select on transferred_cards: %w", err)
 		}
+		tc.oracleID = oracleID.String
+		tc.actualQuantity = uint(actualQuantity.Int64)
 		if tc.actualQuantity < tc.transferQuantity {
 			return &inventory.RowError{
 				Err: inventory.ErrTooFewCards,
 				Row: &inventory.TransferredCards{
 					Quantity: tc.transferQuantity,
 					Card: &inventory.Card{
+						Name:       tc.name,
 						ScryfallID: tc.scryfallID,
-						Foil:       tc.foil,
+						Finish:     tc.finish,
+						Condition:  tc.condition,
+						Language:   tc.language,
 					},
 					Owner: tc.owner,
 				},
@@ -585,24 +614,24 @@ WHERE transfer_id = ?
 		return fmt.Errorf("error scanning on select on transferred_cards: %w", err)
 	}
 
-	removeStmt, err := tx.PrepareContext(ctx, `UPDATE cards
+	removeStmt, err := b.prepare(ctx, tx, `UPDATE cards
 SET quantity = quantity - ?
-WHERE scryfall_id = ? AND foil = ? AND owner = ? AND keeper = ?`)
+WHERE scryfall_id = ? AND finish = ? AND card_condition = ? AND language = ? AND owner = ? AND keeper = ?`)
 	if err != nil {
 		return fmt.Errorf("error preparing update statement on cards: %w", err)
 	}
 	defer removeStmt.Close()
 
-	deleteStmt, err := tx.PrepareContext(ctx, `DELETE FROM cards
-WHERE scryfall_id = ? AND foil = ? AND owner = ? AND keeper = ?`)
+	deleteStmt, err := b.prepare(ctx, tx, `DELETE FROM cards
+WHERE scryfall_id = ? AND finish = ? AND card_condition = ? AND language = ? AND owner = ? AND keeper = ?`)
 	if err != nil {
 		return fmt.Errorf("error preparing delete statement on cards: %w", err)
 	}
 	defer deleteStmt.Close()
 
-	upsertStmt, err := tx.PrepareContext(ctx, `INSERT INTO cards (quantity, name, oracle_id, scryfall_id, foil, owner, keeper)
-VALUES (?, ?, ?, ?, ?, ?, ?)
-ON DUPLICATE KEY UPDATE quantity = quantity + ?`)
+	upsertStmt, err := b.prepare(ctx, tx, `INSERT INTO cards (quantity, name, oracle_id, scryfall_id, finish, card_condition, language, owner, keeper)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+`+b.Dialect.Upsert("cards", "scryfall_id", "finish", "card_condition", "language", "owner", "keeper"))
 	if err != nil {
 		return fmt.Errorf("error preparing upsert statement on cards: %w", err)
 	}
@@ -610,24 +639,24 @@ ON DUPLICATE KEY UPDATE quantity = quantity + ?`)
 
 	for _, row := range transferRows {
 		if row.actualQuantity == row.transferQuantity {
-			_, err = deleteStmt.ExecContext(ctx, row.scryfallID, row.foil, row.ownerID, row.fromUserID)
+			_, err = deleteStmt.ExecContext(ctx, row.scryfallID, row.finish, row.condition, row.language, row.ownerID, row.fromUserID)
 			if err != nil {
 				return fmt.Errorf("error deleting from cards: %w", err)
 			}
 		} else {
-			_, err = removeStmt.ExecContext(ctx, row.transferQuantity, row.scryfallID, row.foil, row.ownerID, row.fromUserID)
+			_, err = removeStmt.ExecContext(ctx, row.transferQuantity, row.scryfallID, row.finish, row.condition, row.language, row.ownerID, row.fromUserID)
 			if err != nil {
 				return fmt.Errorf("error removing quantity from cards: %w", err)
 			}
 		}
-		_, err = upsertStmt.ExecContext(ctx, row.transferQuantity, row.name, row.oracleID, row.scryfallID, row.foil, row.ownerID, toUserID, row.transferQuantity)
+		_, err = upsertStmt.ExecContext(ctx, row.transferQuantity, row.name, row.oracleID, row.scryfallID, row.finish, row.condition, row.language, row.ownerID, toUserID)
 		if err != nil {
 			return fmt.Errorf("error upserting into cards: %w", err)
 		}
 	}
 
-	closeStmt, err := tx.PrepareContext(ctx, `UPDATE transfers
-SET closed = NOW()
+	closeStmt, err := b.prepare(ctx, tx, `UPDATE transfers
+SET closed = ?
 WHERE id = ?
 `)
 	if err != nil {
@@ -635,20 +664,11 @@ WHERE id = ?
 	}
 	defer closeStmt.Close()
 
-	result, err := closeStmt.ExecContext(ctx, id)
+	_, err = closeStmt.ExecContext(ctx, time.Now(), id)
 	if err != nil {
 		return fmt.Errorf("error updating transfer: %w", err)
 	}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("error getting transfer: %w", err)
-	}
-
-	if rowsAffected <= 0 {
-		return inventory.ErrTransferNoExist
-	}
-
 	err = tx.Commit()
 	if err != nil {
 		return fmt.Errorf("error committing: %w", err)
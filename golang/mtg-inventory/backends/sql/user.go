@@ -2,6 +2,8 @@ package sql
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 
 	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
@@ -15,14 +17,14 @@ func (b *Backend) GetUserByUsername(ctx context.Context, username string) (_ *in
 		}
 	}()
 
-	queryStmt, err := b.DB.PrepareContext(ctx, "SELECT COUNT(*) FROM users WHERE username = ?")
+	queryStmt, err := b.prepare(ctx, b.DB, "SELECT COUNT(*) FROM users WHERE username = ?")
 	if err != nil {
 		return nil, fmt.Errorf("failed to prepare select on users: %w", err)
 	}
 	defer queryStmt.Close()
 
 	var count int
-	row := queryStmt.QueryRow(username)
+	row := queryStmt.QueryRowContext(ctx, username)
 	err = row.Scan(&count)
 	if err != nil {
 		return nil, fmt.Errorf("failed to scan row from select on users: %w", err)
@@ -35,8 +37,38 @@ func (b *Backend) GetUserByUsername(ctx context.Context, username string) (_ *in
 	}, nil
 }
 
+// AddUser adds a User given its username, failing if it exists
+func (b *Backend) AddUser(ctx context.Context, username string) (_ *inventory.User, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("error adding user %s: %w", username, err)
+		}
+	}()
+
+	insertStmt, err := b.prepare(ctx, b.DB, "INSERT INTO users (username) VALUES (?) "+b.Dialect.Ignore("users", "username"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare insert on users: %w", err)
+	}
+	defer insertStmt.Close()
+
+	result, err := insertStmt.ExecContext(ctx, username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert on users: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rows affected by insert on users: %w", err)
+	}
+	if rowsAffected == 0 {
+		return nil, inventory.ErrUserExists
+	}
+	return &inventory.User{
+		Username: username,
+	}, nil
+}
+
 // AddUserIfNotExist adds a User given its username
-func (b *Backend) AddUserIfNotExist(ctx context.Context, username string) (*inventory.User, error) {
+func (b *Backend) AddUserIfNotExist(ctx context.Context, username string) (_ *inventory.User, err error) {
 	tx, err := b.DB.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, fmt.Errorf("error adding user: %w", err)
@@ -52,10 +84,11 @@ func (b *Backend) AddUserIfNotExist(ctx context.Context, username string) (*inve
 		}
 	}()
 
-	selectStmt, err := tx.PrepareContext(ctx, "SELECT COUNT(*) FROM users WHERE username = ?")
+	selectStmt, err := b.prepare(ctx, tx, "SELECT COUNT(*) FROM users WHERE username = ?")
 	if err != nil {
 		return nil, fmt.Errorf("failed to prepare select on users: %w", err)
 	}
+	defer selectStmt.Close()
 
 	row := selectStmt.QueryRowContext(ctx, username)
 	var count int
@@ -64,12 +97,16 @@ func (b *Backend) AddUserIfNotExist(ctx context.Context, username string) (*inve
 		return nil, fmt.Errorf("failed to select on users: %w", err)
 	}
 	if count > 0 {
+		err = tx.Commit()
+		if err != nil {
+			return nil, fmt.Errorf("failed to commit select on users: %w", err)
+		}
 		return &inventory.User{
 			Username: username,
 		}, nil
 	}
 
-	insertStmt, err := tx.PrepareContext(ctx, "INSERT INTO users (username) VALUES (?)")
+	insertStmt, err := b.prepare(ctx, tx, "INSERT INTO users (username) VALUES (?)")
 	if err != nil {
 		return nil, fmt.Errorf("failed to prepare insert on users: %w", err)
 	}
@@ -89,3 +126,94 @@ func (b *Backend) AddUserIfNotExist(ctx context.Context, username string) (*inve
 		Username: username,
 	}, nil
 }
+
+// GetUserByIdentity gets the User linked to an external identity
+func (b *Backend) GetUserByIdentity(ctx context.Context, provider, workspace, externalID string) (_ *inventory.User, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("error getting user by identity %s/%s/%s: %w", provider, workspace, externalID, err)
+		}
+	}()
+
+	queryStmt, err := b.prepare(ctx, b.DB, `SELECT users.username
+FROM identities
+INNER JOIN users ON users.id = identities.user_id
+WHERE identities.provider = ? AND identities.workspace = ? AND identities.external_id = ?`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare select on identities: %w", err)
+	}
+	defer queryStmt.Close()
+
+	var username string
+	err = queryStmt.QueryRowContext(ctx, provider, workspace, externalID).Scan(&username)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, inventory.ErrIdentityNoExist
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to scan row from select on identities: %w", err)
+	}
+	return &inventory.User{
+		Username: username,
+	}, nil
+}
+
+// LinkIdentity links an external identity to an existing User, replacing any
+// User it was linked to before
+func (b *Backend) LinkIdentity(ctx context.Context, identity *inventory.Identity) (err error) {
+	tx, err := b.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error linking identity: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			rollbackErr := tx.Rollback()
+			if rollbackErr != nil {
+				err = fmt.Errorf("error linking identity: %w, unable to rollback: %s", err, rollbackErr)
+			} else {
+				err = fmt.Errorf("error linking identity: %w", err)
+			}
+		}
+	}()
+
+	selectStmt, err := b.prepare(ctx, tx, "SELECT id FROM users WHERE username = ?")
+	if err != nil {
+		return fmt.Errorf("failed to prepare select on users: %w", err)
+	}
+	defer selectStmt.Close()
+
+	var userID int64
+	err = selectStmt.QueryRowContext(ctx, identity.Username).Scan(&userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return inventory.ErrUserNoExist
+	} else if err != nil {
+		return fmt.Errorf("failed to select on users: %w", err)
+	}
+
+	deleteStmt, err := b.prepare(ctx, tx, "DELETE FROM identities WHERE provider = ? AND workspace = ? AND external_id = ?")
+	if err != nil {
+		return fmt.Errorf("failed to prepare delete on identities: %w", err)
+	}
+	defer deleteStmt.Close()
+
+	_, err = deleteStmt.ExecContext(ctx, identity.Provider, identity.Workspace, identity.ExternalID)
+	if err != nil {
+		return fmt.Errorf("failed to delete on identities: %w", err)
+	}
+
+	insertStmt, err := b.prepare(ctx, tx, "INSERT INTO identities (provider, workspace, external_id, user_id) VALUES (?, ?, ?, ?)")
+	if err != nil {
+		return fmt.Errorf("failed to prepare insert on identities: %w", err)
+	}
+	defer insertStmt.Close()
+
+	_, err = insertStmt.ExecContext(ctx, identity.Provider, identity.Workspace, identity.ExternalID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to insert on identities: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("failed to commit insert on identities: %w", err)
+	}
+
+	return nil
+}
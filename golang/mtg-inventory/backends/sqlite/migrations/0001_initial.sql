@@ -0,0 +1,59 @@
+CREATE TABLE IF NOT EXISTS users (
+	id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+	username VARCHAR(256) NOT NULL,
+	UNIQUE (username)
+);
+
+CREATE TABLE IF NOT EXISTS cards (
+	quantity INTEGER NOT NULL,
+	name VARCHAR(256) NOT NULL,
+	oracle_id VARCHAR(256) NOT NULL,
+	scryfall_id VARCHAR(256) NOT NULL,
+	foil BOOLEAN,
+	owner INTEGER NOT NULL,
+	keeper INTEGER NOT NULL,
+	UNIQUE (scryfall_id, foil, owner, keeper),
+	FOREIGN KEY (owner) REFERENCES users(id),
+	FOREIGN KEY (keeper) REFERENCES users(id)
+);
+
+CREATE TABLE IF NOT EXISTS requests (
+	id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+	requestor INTEGER NOT NULL,
+	opened DATETIME NOT NULL,
+	closed DATETIME,
+	FOREIGN KEY (requestor) REFERENCES users(id)
+);
+
+CREATE TABLE IF NOT EXISTS requested_cards (
+	request_id INTEGER NOT NULL,
+	name VARCHAR(256) NOT NULL,
+	oracle_id VARCHAR(256) NOT NULL,
+	quantity INTEGER NOT NULL,
+	UNIQUE (request_id, oracle_id),
+	FOREIGN KEY (request_id) REFERENCES requests(id)
+);
+
+CREATE TABLE IF NOT EXISTS transfers (
+	id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+	request_id INTEGER,
+	to_user INTEGER NOT NULL,
+	from_user INTEGER NOT NULL,
+	opened DATETIME NOT NULL,
+	closed DATETIME,
+	FOREIGN KEY (to_user) REFERENCES users(id),
+	FOREIGN KEY (from_user) REFERENCES users(id),
+	FOREIGN KEY (request_id) REFERENCES requests(id) ON DELETE SET NULL
+);
+
+CREATE TABLE IF NOT EXISTS transferred_cards (
+	transfer_id INTEGER NOT NULL,
+	quantity INTEGER NOT NULL,
+	name VARCHAR(256) NOT NULL,
+	scryfall_id VARCHAR(256) NOT NULL,
+	foil BOOLEAN,
+	owner INTEGER NOT NULL,
+	UNIQUE (transfer_id, scryfall_id, foil, owner),
+	FOREIGN KEY (transfer_id) REFERENCES transfers(id) ON DELETE CASCADE,
+	FOREIGN KEY (owner) REFERENCES users(id)
+);
@@ -0,0 +1,47 @@
+-- Copies of a card are told apart by finish, condition and language, and foil
+-- becomes a finish. SQLite can't drop a unique constraint, so the tables are
+-- rebuilt.
+CREATE TABLE cards_new (
+	quantity INTEGER NOT NULL,
+	name VARCHAR(256) NOT NULL,
+	oracle_id VARCHAR(256) NOT NULL,
+	scryfall_id VARCHAR(256) NOT NULL,
+	finish VARCHAR(16) NOT NULL DEFAULT 'nonfoil',
+	card_condition VARCHAR(8) NOT NULL DEFAULT 'NM',
+	language VARCHAR(16) NOT NULL DEFAULT '',
+	owner INTEGER NOT NULL,
+	keeper INTEGER NOT NULL,
+	UNIQUE (scryfall_id, finish, card_condition, language, owner, keeper),
+	FOREIGN KEY (owner) REFERENCES users(id),
+	FOREIGN KEY (keeper) REFERENCES users(id)
+);
+
+INSERT INTO cards_new (quantity, name, oracle_id, scryfall_id, finish, owner, keeper)
+SELECT quantity, name, oracle_id, scryfall_id, CASE WHEN foil THEN 'foil' ELSE 'nonfoil' END, owner, keeper
+FROM cards;
+
+DROP TABLE cards;
+
+ALTER TABLE cards_new RENAME TO cards;
+
+CREATE TABLE transferred_cards_new (
+	transfer_id INTEGER NOT NULL,
+	quantity INTEGER NOT NULL,
+	name VARCHAR(256) NOT NULL,
+	scryfall_id VARCHAR(256) NOT NULL,
+	finish VARCHAR(16) NOT NULL DEFAULT 'nonfoil',
+	card_condition VARCHAR(8) NOT NULL DEFAULT 'NM',
+	language VARCHAR(16) NOT NULL DEFAULT '',
+	owner INTEGER NOT NULL,
+	UNIQUE (transfer_id, scryfall_id, finish, card_condition, language, owner),
+	FOREIGN KEY (transfer_id) REFERENCES transfers(id) ON DELETE CASCADE,
+	FOREIGN KEY (owner) REFERENCES users(id)
+);
+
+INSERT INTO transferred_cards_new (transfer_id, quantity, name, scryfall_id, finish, owner)
+SELECT transfer_id, quantity, name, scryfall_id, CASE WHEN foil THEN 'foil' ELSE 'nonfoil' END, owner
+FROM transferred_cards;
+
+DROP TABLE transferred_cards;
+
+ALTER TABLE transferred_cards_new RENAME TO transferred_cards;
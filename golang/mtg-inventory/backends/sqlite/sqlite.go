@@ -0,0 +1,75 @@
+/*
+Package sqlite contains a Backend implementation based on a SQLite database,
+which suits single-host deployments. It uses a pure Go driver so it builds
+without cgo.
+*/
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"net/url"
+
+	sqlbackend "github.com/benrm/mtg-inventory/golang/mtg-inventory/backends/sql"
+	_ "modernc.org/sqlite" // registers DriverName
+)
+
+// DriverName is the name of the database/sql driver this package registers
+const DriverName = "sqlite"
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// Dialect is the Dialect of SQLite
+var Dialect = &sqlbackend.Dialect{
+	Upsert: sqlbackend.OnConflict,
+	Ignore: sqlbackend.OnConflictDoNothing,
+}
+
+// Backend contains everything needed to run a SQLite backend, which is the
+// SQL backend speaking Dialect with its own migrations
+type Backend struct {
+	*sqlbackend.Backend
+}
+
+// NewBackend returns an instantiated Backend
+func NewBackend(db *sql.DB) *Backend {
+	return &Backend{
+		Backend: sqlbackend.NewDialectBackend(db, Dialect),
+	}
+}
+
+// Open opens the SQLite database in the file at path, enforcing foreign keys.
+// Like the other backends, it's left at whatever schema it has until
+// Migrate is called.
+func Open(path string) (*Backend, error) {
+	query := url.Values{}
+	query.Add("_pragma", "foreign_keys(1)")
+	query.Add("_pragma", "busy_timeout(5000)")
+	query.Add("_pragma", "journal_mode(WAL)")
+	db, err := sql.Open(DriverName, "file:"+path+"?"+query.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("error opening %q: %w", path, err)
+	}
+	// SQLite only allows a single writer, so serialize access rather than
+	// fail with SQLITE_BUSY when transactions overlap
+	db.SetMaxOpenConns(1)
+
+	return NewBackend(db), nil
+}
+
+// Migrate applies any migrations that have not been applied yet
+func (b *Backend) Migrate(ctx context.Context) error {
+	sub, err := fs.Sub(migrations, "migrations")
+	if err != nil {
+		return fmt.Errorf("error migrating database: %w", err)
+	}
+	m := &sqlbackend.Migrator{
+		DB:         b.DB,
+		Migrations: sub,
+	}
+	return m.Migrate(ctx)
+}
@@ -0,0 +1,87 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"io/fs"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/backends/backendtest"
+	sqlbackend "github.com/benrm/mtg-inventory/golang/mtg-inventory/backends/sql"
+)
+
+func TestSQLite(t *testing.T) {
+	b, err := Open(filepath.Join(t.TempDir(), "inventory.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %s", err.Error())
+	}
+	defer b.DB.Close()
+
+	err = b.Migrate(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to migrate: %s", err.Error())
+	}
+
+	backendtest.RunConformance(t, func() inventory.Backend {
+		return b
+	})
+}
+
+func TestMigrateFoilToFinish(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open(DriverName, filepath.Join(t.TempDir(), "inventory.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %s", err.Error())
+	}
+	defer db.Close()
+
+	// Migrate to the schema from before finishes, with a foil and a nonfoil copy
+	sub, err := fs.Sub(migrations, "migrations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := fstest.MapFS{}
+	for _, name := range []string{"0001_initial.sql", "0002_identities.sql"} {
+		data, err := fs.ReadFile(sub, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		before[name] = &fstest.MapFile{Data: data}
+	}
+	err = (&sqlbackend.Migrator{DB: db, Migrations: before}).Migrate(ctx)
+	if err != nil {
+		t.Fatalf("Failed to migrate to the old schema: %s", err.Error())
+	}
+	_, err = db.ExecContext(ctx, `INSERT INTO users (username) VALUES ('user1')`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.ExecContext(ctx, `INSERT INTO cards (quantity, name, oracle_id, scryfall_id, foil, owner, keeper)
+VALUES (1, 'Lightning Bolt', 'bolt-oracle', 'bolt-m10', FALSE, 1, 1), (2, 'Lightning Bolt', 'bolt-oracle', 'bolt-m10', TRUE, 1, 1)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := NewBackend(db)
+	err = b.Migrate(ctx)
+	if err != nil {
+		t.Fatalf("Failed to migrate: %s", err.Error())
+	}
+	cardRows, err := b.GetCardsByOwner(ctx, "user1", 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to get cards by owner: %s", err.Error())
+	}
+	finishes := make(map[inventory.Finish]uint)
+	for _, cardRow := range cardRows {
+		if cardRow.Card.Condition != inventory.ConditionNearMint || cardRow.Card.Language != "" {
+			t.Errorf("Expected migrated copies to be near mint without a language, got %+v", cardRow.Card)
+		}
+		finishes[cardRow.Card.Finish] = cardRow.Quantity
+	}
+	if finishes[inventory.FinishNonfoil] != 1 || finishes[inventory.FinishFoil] != 2 {
+		t.Errorf("Expected 1 nonfoil and 2 foil copies, got %v", finishes)
+	}
+}
@@ -0,0 +1,193 @@
+/*
+Package validate contains a Backend that checks the cards in submitted rows
+against Scryfall before passing them on to another Backend, which keeps typos
+out of the inventory.
+*/
+package validate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/scryfall"
+)
+
+// Backend wraps a Backend and rejects rows whose cards don't match Scryfall
+// with a RowError. The Scryfall ID of a card is taken as the truth: its name
+// must be that of the printing or one of its faces, its oracle ID that of the
+// printing, and it must have been printed in its finish. Missing names and
+// oracle IDs are filled in, and if Correct is set, mismatched ones are
+// corrected rather than rejected. Rows are corrected in place, but only once
+// every row is valid.
+type Backend struct {
+	inventory.Backend
+
+	Scryfall inventory.Scryfall
+	Correct  bool
+}
+
+// NewBackend returns a Backend that validates the rows submitted to backend
+// with sf
+func NewBackend(backend inventory.Backend, sf inventory.Scryfall) *Backend {
+	return &Backend{
+		Backend:  backend,
+		Scryfall: sf,
+	}
+}
+
+// correct sets *field to want if it's empty or b.Correct is set, and
+// otherwise returns mismatch if it isn't want
+func (b *Backend) correct(field *string, want string, mismatch error) error {
+	if *field == want {
+		return nil
+	}
+	if *field == "" || b.Correct {
+		*field = want
+		return nil
+	}
+	return mismatch
+}
+
+// validateCard checks a card against its printing, returning a corrected
+// copy of it, or a row error for row if it doesn't match
+func (b *Backend) validateCard(card *inventory.Card, row any) (*inventory.Card, error) {
+	if card == nil {
+		return nil, &inventory.RowError{
+			Err: inventory.ErrUnknownCard,
+			Row: row,
+		}
+	}
+	printing, err := b.Scryfall.GetCardByID(card.ScryfallID)
+	if errors.Is(err, scryfall.ErrNotInCache) {
+		return nil, &inventory.RowError{
+			Err: fmt.Errorf("no card has Scryfall ID %q: %w", card.ScryfallID, inventory.ErrUnknownCard),
+			Row: row,
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("error getting card with Scryfall ID %q: %w", card.ScryfallID, err)
+	}
+
+	corrected := *card
+	err = b.correct(&corrected.OracleID, printing.CardOracleID(), inventory.ErrOracleIDMismatch)
+	if err == nil && !printing.HasName(corrected.Name) {
+		err = b.correct(&corrected.Name, printing.Name, inventory.ErrNameMismatch)
+	}
+	if err != nil {
+		return nil, &inventory.RowError{
+			Err: fmt.Errorf("card %q is %q with oracle ID %q: %w", card.ScryfallID, printing.Name, printing.CardOracleID(), err),
+			Row: row,
+		}
+	}
+
+	if !printing.HasFinish(card.Finish) {
+		return nil, &inventory.RowError{
+			Err: fmt.Errorf("%s %q in %s: %w", card.Finish, printing.Name, printing.Set, inventory.ErrFinishUnavailable),
+			Row: row,
+		}
+	}
+	return &corrected, nil
+}
+
+// validateCards validates the card of every row, which card returns the
+// field of, and only once they're all valid replaces them with their
+// corrected copies
+func validateCards[T any](b *Backend, rows []*T, card func(row *T) **inventory.Card) error {
+	if len(rows) > inventory.RowUploadLimit {
+		return inventory.ErrTooManyRows
+	}
+	corrected := make([]*inventory.Card, len(rows))
+	for i, row := range rows {
+		var err error
+		corrected[i], err = b.validateCard(*card(row), row)
+		if err != nil {
+			return err
+		}
+	}
+	for i, row := range rows {
+		*card(row) = corrected[i]
+	}
+	return nil
+}
+
+// AddCards validates the cards in rows and adds them
+func (b *Backend) AddCards(ctx context.Context, rows []*inventory.CardRow) error {
+	err := validateCards(b, rows, func(row *inventory.CardRow) **inventory.Card {
+		return &row.Card
+	})
+	if err != nil {
+		return err
+	}
+	return b.Backend.AddCards(ctx, rows)
+}
+
+// OpenRequest validates the oracle IDs in rows and opens a request for them
+func (b *Backend) OpenRequest(ctx context.Context, requestor string, rows []*inventory.RequestedCards) (*inventory.Request, error) {
+	if len(rows) > inventory.RowUploadLimit {
+		return nil, inventory.ErrTooManyRows
+	}
+	names := make([]string, len(rows))
+	for i, row := range rows {
+		card, err := b.Scryfall.GetCardByOracleID(row.OracleID)
+		if errors.Is(err, scryfall.ErrNotInCache) {
+			return nil, &inventory.RowError{
+				Err: fmt.Errorf("no card has oracle ID %q: %w", row.OracleID, inventory.ErrUnknownCard),
+				Row: row,
+			}
+		} else if err != nil {
+			return nil, fmt.Errorf("error getting card with oracle ID %q: %w", row.OracleID, err)
+		}
+
+		names[i] = row.Name
+		if card.HasName(names[i]) {
+			continue
+		}
+		err = b.correct(&names[i], card.Name, inventory.ErrNameMismatch)
+		if err != nil {
+			return nil, &inventory.RowError{
+				Err: fmt.Errorf("oracle ID %q is %q: %w", row.OracleID, card.Name, err),
+				Row: row,
+			}
+		}
+	}
+	for i, row := range rows {
+		row.Name = names[i]
+	}
+	return b.Backend.OpenRequest(ctx, requestor, rows)
+}
+
+// OpenTransfer validates the cards in rows and opens a transfer of them
+func (b *Backend) OpenTransfer(ctx context.Context, toUser, fromUser string, requestID *int64, rows []*inventory.TransferredCards) (*inventory.Transfer, error) {
+	err := validateCards(b, rows, func(row *inventory.TransferredCards) **inventory.Card {
+		return &row.Card
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b.Backend.OpenTransfer(ctx, toUser, fromUser, requestID, rows)
+}
+
+// deckCard returns the field of the card in a row of a deck
+func deckCard(row *inventory.DeckCards) **inventory.Card {
+	return &row.Card
+}
+
+// CreateDeck validates the cards in rows and creates a deck of them
+func (b *Backend) CreateDeck(ctx context.Context, owner, name string, rows []*inventory.DeckCards) (*inventory.Deck, error) {
+	err := validateCards(b, rows, deckCard)
+	if err != nil {
+		return nil, err
+	}
+	return b.Backend.CreateDeck(ctx, owner, name, rows)
+}
+
+// UpdateDeck validates the cards in rows and replaces a deck's cards with
+// them
+func (b *Backend) UpdateDeck(ctx context.Context, id int64, name string, rows []*inventory.DeckCards) error {
+	err := validateCards(b, rows, deckCard)
+	if err != nil {
+		return err
+	}
+	return b.Backend.UpdateDeck(ctx, id, name, rows)
+}
@@ -0,0 +1,205 @@
+package validate
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/backends/memory"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/chat/chattest"
+)
+
+var _ inventory.Backend = (*Backend)(nil)
+
+func newTestBackend(t *testing.T) *Backend {
+	t.Helper()
+
+	sf := chattest.NewScryfall(t,
+		`{"object": "card", "id": "bolt-4ed", "lang": "en", "oracle_id": "bolt-oracle", "name": "Lightning Bolt", "collector_number": "208", "released_at": "1995-04-01", "set": "4ed", "finishes": ["nonfoil"]}`,
+		chattest.BoltSLD,
+		chattest.FireIce,
+	)
+	b := NewBackend(memory.NewBackend(), sf)
+	_, err := b.AddUserIfNotExist(context.Background(), "user1")
+	if err != nil {
+		t.Fatalf("Failed to add user: %s", err.Error())
+	}
+	_, err = b.AddUserIfNotExist(context.Background(), "user2")
+	if err != nil {
+		t.Fatalf("Failed to add user: %s", err.Error())
+	}
+	return b
+}
+
+func expectRowError(t *testing.T, err, target error, row any) {
+	t.Helper()
+	if !errors.Is(err, target) {
+		t.Fatalf("Expected error %q, got: %v", target, err)
+	}
+	var rowErr *inventory.RowError
+	if !errors.As(err, &rowErr) {
+		t.Fatalf("Expected a RowError, got: %v", err)
+	}
+	if rowErr.Row != row {
+		t.Fatalf("Expected RowError for row %+v, got %+v", row, rowErr.Row)
+	}
+}
+
+func TestAddCards(t *testing.T) {
+	ctx := context.Background()
+	b := newTestBackend(t)
+
+	newRow := func(card *inventory.Card) *inventory.CardRow {
+		return &inventory.CardRow{
+			Quantity: 1,
+			Card:     card,
+			Owner:    "user1",
+			Keeper:   "user1",
+		}
+	}
+	for _, test := range []struct {
+		name   string
+		row    *inventory.CardRow
+		target error
+	}{
+		{"UnknownCard", newRow(&inventory.Card{Name: "Lightning Bolt", OracleID: "bolt-oracle", ScryfallID: "bolt-typo", Finish: inventory.FinishNonfoil, Condition: inventory.ConditionNearMint}), inventory.ErrUnknownCard},
+		{"NoCard", newRow(nil), inventory.ErrUnknownCard},
+		{"OracleIDMismatch", newRow(&inventory.Card{Name: "Lightning Bolt", OracleID: "ragavan-oracle", ScryfallID: "bolt-m10", Finish: inventory.FinishNonfoil, Condition: inventory.ConditionNearMint}), inventory.ErrOracleIDMismatch},
+		{"NameMismatch", newRow(&inventory.Card{Name: "Lightning Blot", OracleID: "bolt-oracle", ScryfallID: "bolt-m10", Finish: inventory.FinishNonfoil, Condition: inventory.ConditionNearMint}), inventory.ErrNameMismatch},
+		{"NotFoil", newRow(&inventory.Card{Name: "Lightning Bolt", OracleID: "bolt-oracle", ScryfallID: "bolt-4ed", Finish: inventory.FinishFoil, Condition: inventory.ConditionNearMint}), inventory.ErrFinishUnavailable},
+		{"OnlyFoil", newRow(&inventory.Card{Name: "Lightning Bolt", OracleID: "bolt-oracle", ScryfallID: "bolt-sld", Finish: inventory.FinishNonfoil, Condition: inventory.ConditionNearMint}), inventory.ErrFinishUnavailable},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			valid := newRow(&inventory.Card{Name: "Lightning Bolt", OracleID: "bolt-oracle", ScryfallID: "bolt-m10", Finish: inventory.FinishNonfoil, Condition: inventory.ConditionNearMint})
+			err := b.AddCards(ctx, []*inventory.CardRow{valid, test.row})
+			expectRowError(t, err, test.target, test.row)
+		})
+	}
+	cardRows, err := b.GetCardsByOwner(ctx, "user1", 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to get cards by owner: %s", err.Error())
+	} else if len(cardRows) != 0 {
+		t.Fatalf("Expected rejected rows not to be added, got %d rows", len(cardRows))
+	}
+
+	rows := []*inventory.CardRow{
+		newRow(&inventory.Card{ScryfallID: "bolt-sld", Finish: inventory.FinishFoil, Condition: inventory.ConditionNearMint}),
+		newRow(&inventory.Card{ScryfallID: "ragavan-mh2", Finish: inventory.FinishFoil, Condition: inventory.ConditionNearMint}),
+	}
+	invalid := newRow(&inventory.Card{ScryfallID: "bolt-typo", Finish: inventory.FinishNonfoil, Condition: inventory.ConditionNearMint})
+	err = b.AddCards(ctx, append(rows[:2:2], invalid))
+	expectRowError(t, err, inventory.ErrUnknownCard, invalid)
+	if rows[0].Card.Name != "" || rows[0].Card.OracleID != "" {
+		t.Errorf("Expected rows not to be corrected when another row is rejected, got %+v", rows[0].Card)
+	}
+
+	err = b.AddCards(ctx, rows)
+	if err != nil {
+		t.Fatalf("Failed to add cards with only Scryfall IDs: %s", err.Error())
+	}
+	if rows[0].Card.Name != "Lightning Bolt" || rows[0].Card.OracleID != "bolt-oracle" {
+		t.Errorf("Expected name and oracle ID to be filled in, got %+v", rows[0].Card)
+	}
+
+	face := newRow(&inventory.Card{Name: "Fire", ScryfallID: "fire-ice-dom", Finish: inventory.FinishNonfoil, Condition: inventory.ConditionNearMint})
+	err = b.AddCards(ctx, []*inventory.CardRow{face})
+	if err != nil {
+		t.Fatalf("Failed to add card named after a face: %s", err.Error())
+	}
+	if face.Card.Name != "Fire" || face.Card.OracleID != "fire-ice-oracle" {
+		t.Errorf("Expected the face name to be kept and the oracle ID filled in, got %+v", face.Card)
+	}
+
+	b.Correct = true
+	row := newRow(&inventory.Card{Name: "Lightning Blot", OracleID: "ragavan-oracle", ScryfallID: "bolt-m10", Finish: inventory.FinishNonfoil, Condition: inventory.ConditionNearMint})
+	err = b.AddCards(ctx, []*inventory.CardRow{row})
+	if err != nil {
+		t.Fatalf("Failed to add card to correct: %s", err.Error())
+	}
+	if row.Card.Name != "Lightning Bolt" || row.Card.OracleID != "bolt-oracle" {
+		t.Errorf("Expected name and oracle ID to be corrected, got %+v", row.Card)
+	}
+	cardRows, err = b.GetCardsByOracleID(ctx, "bolt-oracle", 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to get cards by oracle ID: %s", err.Error())
+	} else if len(cardRows) != 2 {
+		t.Errorf("Expected 2 rows of Lightning Bolt, got %d", len(cardRows))
+	}
+}
+
+func TestOpenRequest(t *testing.T) {
+	ctx := context.Background()
+	b := newTestBackend(t)
+
+	unknown := &inventory.RequestedCards{Quantity: 1, Name: "Lightning Bolt", OracleID: "bolt-typo"}
+	_, err := b.OpenRequest(ctx, "user1", []*inventory.RequestedCards{unknown})
+	expectRowError(t, err, inventory.ErrUnknownCard, unknown)
+
+	mismatch := &inventory.RequestedCards{Quantity: 1, Name: "Ragavan", OracleID: "bolt-oracle"}
+	_, err = b.OpenRequest(ctx, "user1", []*inventory.RequestedCards{mismatch})
+	expectRowError(t, err, inventory.ErrNameMismatch, mismatch)
+
+	row := &inventory.RequestedCards{Quantity: 1, OracleID: "ragavan-oracle"}
+	request, err := b.OpenRequest(ctx, "user1", []*inventory.RequestedCards{row})
+	if err != nil {
+		t.Fatalf("Failed to open request: %s", err.Error())
+	}
+	if request.Cards[0].Name != "Ragavan, Nimble Pilferer" {
+		t.Errorf("Expected name to be filled in, got %q", request.Cards[0].Name)
+	}
+}
+
+func TestOpenTransfer(t *testing.T) {
+	ctx := context.Background()
+	b := newTestBackend(t)
+
+	bolt := &inventory.Card{Name: "Lightning Bolt", OracleID: "bolt-oracle", ScryfallID: "bolt-m10", Finish: inventory.FinishNonfoil, Condition: inventory.ConditionNearMint}
+	err := b.AddCards(ctx, []*inventory.CardRow{{Quantity: 2, Card: bolt, Owner: "user1", Keeper: "user1"}})
+	if err != nil {
+		t.Fatalf("Failed to add cards: %s", err.Error())
+	}
+
+	mismatch := &inventory.TransferredCards{
+		Quantity: 1,
+		Card:     &inventory.Card{Name: "Lightning Bolt", OracleID: "bolt-oracle", ScryfallID: "bolt-4ed", Finish: inventory.FinishFoil, Condition: inventory.ConditionNearMint},
+		Owner:    "user1",
+	}
+	_, err = b.OpenTransfer(ctx, "user2", "user1", nil, []*inventory.TransferredCards{mismatch})
+	expectRowError(t, err, inventory.ErrFinishUnavailable, mismatch)
+
+	transfer, err := b.OpenTransfer(ctx, "user2", "user1", nil, []*inventory.TransferredCards{{Quantity: 1, Card: bolt, Owner: "user1"}})
+	if err != nil {
+		t.Fatalf("Failed to open transfer: %s", err.Error())
+	}
+	if transfer.Quantity != 1 {
+		t.Errorf("Expected a transfer of 1 card, got %d", transfer.Quantity)
+	}
+}
+
+func TestCreateDeck(t *testing.T) {
+	ctx := context.Background()
+	b := newTestBackend(t)
+
+	bolt := &inventory.Card{Name: "Lightning Bolt", OracleID: "bolt-oracle", ScryfallID: "bolt-m10", Finish: inventory.FinishNonfoil, Condition: inventory.ConditionNearMint}
+	err := b.AddCards(ctx, []*inventory.CardRow{{Quantity: 4, Card: bolt, Owner: "user1", Keeper: "user1"}})
+	if err != nil {
+		t.Fatalf("Failed to add cards: %s", err.Error())
+	}
+
+	mismatch := &inventory.DeckCards{
+		Zone:     inventory.ZoneMainboard,
+		Quantity: 1,
+		Card:     &inventory.Card{Name: "Lightning Bolt", OracleID: "bolt-oracle", ScryfallID: "bolt-4ed", Finish: inventory.FinishFoil, Condition: inventory.ConditionNearMint},
+	}
+	_, err = b.CreateDeck(ctx, "user1", "Burn", []*inventory.DeckCards{mismatch})
+	expectRowError(t, err, inventory.ErrFinishUnavailable, mismatch)
+
+	deck, err := b.CreateDeck(ctx, "user1", "Burn", []*inventory.DeckCards{{Zone: inventory.ZoneMainboard, Quantity: 4, Card: bolt}})
+	if err != nil {
+		t.Fatalf("Failed to create deck: %s", err.Error())
+	}
+
+	err = b.UpdateDeck(ctx, deck.ID, "Burn", []*inventory.DeckCards{mismatch})
+	expectRowError(t, err, inventory.ErrFinishUnavailable, mismatch)
+}
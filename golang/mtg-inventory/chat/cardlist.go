@@ -0,0 +1,125 @@
+/*
+Package chat contains the logic shared by the chat integrations, such as the
+Slack and Discord servers, and by the command-line client.
+*/
+package chat
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/scryfall"
+)
+
+// ErrEmptyCardList is returned when a card list has no entries
+var ErrEmptyCardList = errors.New("no cards listed")
+
+// CardListEntry is one entry of a card list, e.g. "4 Lightning Bolt"
+type CardListEntry struct {
+	Quantity uint
+	Name     string
+}
+
+// ParseCardList parses cards separated by commas or new lines, each optionally
+// preceded by a quantity like "4" or "4x". A comma only starts a new entry when
+// a quantity follows it, so "1 Ragavan, Nimble Pilferer" is a single card.
+func ParseCardList(text string) ([]*CardListEntry, error) {
+	entries := make([]*CardListEntry, 0)
+	for _, line := range strings.Split(text, "\n") {
+		var current *CardListEntry
+		for _, raw := range strings.Split(line, ",") {
+			segment := strings.TrimSpace(raw)
+			if segment == "" {
+				continue
+			}
+			quantity, name, hasQuantity := cutQuantity(segment)
+			if current != nil && !hasQuantity {
+				current.Name += "," + strings.TrimRight(raw, " \t\r")
+				continue
+			}
+			if !hasQuantity {
+				quantity, name = 1, segment
+			}
+			if name == "" {
+				return nil, fmt.Errorf("missing card name after %q", segment)
+			}
+			current = &CardListEntry{
+				Quantity: quantity,
+				Name:     name,
+			}
+			entries = append(entries, current)
+		}
+	}
+
+	if len(entries) == 0 {
+		return nil, ErrEmptyCardList
+	}
+	return entries, nil
+}
+
+// cutQuantity splits a leading, non-zero quantity from the rest of an entry
+func cutQuantity(segment string) (uint, string, bool) {
+	first, rest, _ := strings.Cut(segment, " ")
+	first = strings.TrimSuffix(strings.ToLower(first), "x")
+	quantity, err := strconv.ParseUint(first, 10, 0)
+	if err != nil || quantity == 0 {
+		return 0, "", false
+	}
+	return uint(quantity), strings.TrimSpace(rest), true
+}
+
+// ResolveCardList looks up every entry by name, merging entries for the same
+// card. Entries that can't be resolved are described in problems, one sentence
+// each, for showing to the user who wrote the list.
+func ResolveCardList(sf inventory.Scryfall, entries []*CardListEntry) (_ []*inventory.RequestedCards, problems []string) {
+	rows := make([]*inventory.RequestedCards, 0, len(entries))
+	byOracleID := make(map[string]*inventory.RequestedCards)
+	problems = make([]string, 0)
+	for _, entry := range entries {
+		card, problem := FindCard(sf, entry.Name)
+		if card == nil {
+			problems = append(problems, problem)
+			continue
+		}
+
+		oracleID := card.CardOracleID()
+		if row, exists := byOracleID[oracleID]; exists {
+			row.Quantity += entry.Quantity
+			continue
+		}
+		row := &inventory.RequestedCards{
+			Quantity: entry.Quantity,
+			Name:     card.Name,
+			OracleID: oracleID,
+		}
+		byOracleID[oracleID] = row
+		rows = append(rows, row)
+	}
+	return rows, problems
+}
+
+// FindCard looks up a card by a name typed by a user, which can differ from the
+// card's name in case and punctuation or be the name of one of its faces. If
+// it can't be found, problem describes why in one sentence, with suggestions,
+// for showing to the user.
+func FindCard(sf inventory.Scryfall, name string) (_ *inventory.ScryfallCard, problem string) {
+	card, err := scryfall.FindCardByName(sf, name)
+	var suggestions string
+	var nameErr *scryfall.NameError
+	if errors.As(err, &nameErr) && len(nameErr.Suggestions) > 0 {
+		suggestions = " Did you mean " + scryfall.QuoteNames(nameErr.Suggestions, "or") + "?"
+	}
+	if errors.Is(err, scryfall.ErrNotInCache) {
+		return nil, fmt.Sprintf("I couldn't find a card named %q.", name) + suggestions
+	} else if errors.Is(err, scryfall.ErrMultipleCacheHits) {
+		return nil, fmt.Sprintf("More than one card is named %q.", name) + suggestions
+	} else if err != nil {
+		log.Printf("Error looking up card named %q: %s", name, err.Error())
+		return nil, fmt.Sprintf("Something went wrong looking up %q.", name)
+	}
+	return card, ""
+}
@@ -0,0 +1,93 @@
+package chat
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/scryfall"
+)
+
+func TestParseCardList(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected []*CardListEntry
+	}{
+		{
+			text: "4 Lightning Bolt, 1 Ragavan",
+			expected: []*CardListEntry{
+				{Quantity: 4, Name: "Lightning Bolt"},
+				{Quantity: 1, Name: "Ragavan"},
+			},
+		},
+		{
+			text: "2x Lightning Bolt, 1 Ragavan, Nimble Pilferer",
+			expected: []*CardListEntry{
+				{Quantity: 2, Name: "Lightning Bolt"},
+				{Quantity: 1, Name: "Ragavan, Nimble Pilferer"},
+			},
+		},
+		{
+			text: "Counterspell\n3X Brainstorm,",
+			expected: []*CardListEntry{
+				{Quantity: 1, Name: "Counterspell"},
+				{Quantity: 3, Name: "Brainstorm"},
+			},
+		},
+		{
+			text: "1 Borrowing 100,000 Arrows",
+			expected: []*CardListEntry{
+				{Quantity: 1, Name: "Borrowing 100,000 Arrows"},
+			},
+		},
+	}
+	for _, test := range tests {
+		entries, err := ParseCardList(test.text)
+		if err != nil {
+			t.Errorf("Failed to parse %q: %s", test.text, err.Error())
+			continue
+		}
+		if !reflect.DeepEqual(entries, test.expected) {
+			t.Errorf("Unexpected entries for %q: %+v", test.text, entries)
+		}
+	}
+
+	_, err := ParseCardList(" , \n")
+	if !errors.Is(err, ErrEmptyCardList) {
+		t.Errorf("Expected ErrEmptyCardList, got: %v", err)
+	}
+
+	_, err = ParseCardList("4 Lightning Bolt, 4")
+	if err == nil {
+		t.Error("Expected an error for a quantity without a name")
+	}
+}
+
+func TestResolveCardList(t *testing.T) {
+	sf, err := scryfall.NewJSONCache(strings.NewReader(`[
+		{"id": "bolt", "lang": "en", "oracle_id": "bolt-oracle", "name": "Lightning Bolt", "collector_number": "1", "released_at": "2020-01-01", "set": "a"},
+		{"id": "fire-ice", "lang": "en", "name": "Fire // Ice", "collector_number": "2", "released_at": "2020-01-01", "set": "a", "card_faces": [{"name": "Fire", "oracle_id": "fire-ice-oracle"}, {"name": "Ice", "oracle_id": "fire-ice-oracle"}]}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rows, problems := ResolveCardList(sf, []*CardListEntry{
+		{Quantity: 2, Name: "lightning bolt"},
+		{Quantity: 1, Name: "Fire"},
+		{Quantity: 1, Name: "Ice"},
+		{Quantity: 1, Name: "Lightning Blot"},
+	})
+	expected := []*inventory.RequestedCards{
+		{Quantity: 2, Name: "Lightning Bolt", OracleID: "bolt-oracle"},
+		{Quantity: 2, Name: "Fire // Ice", OracleID: "fire-ice-oracle"},
+	}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, rows)
+	}
+	if len(problems) != 1 || problems[0] != `I couldn't find a card named "Lightning Blot". Did you mean "Lightning Bolt"?` {
+		t.Errorf("Unexpected problems: %q", problems)
+	}
+}
@@ -0,0 +1,71 @@
+/*
+Package chattest contains the cards and users that the tests of the chat
+integrations, and of the packages they're built on, run against.
+*/
+package chattest
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/backends/memory"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/scryfall"
+)
+
+const (
+	// BoltM10 is the M10 printing of Lightning Bolt, which is in every
+	// Scryfall returned by NewScryfall
+	BoltM10 = `{"object": "card", "id": "bolt-m10", "lang": "en", "mtgo_id": 33000, "mtgo_foil_id": 33001, "oracle_id": "bolt-oracle", "name": "Lightning Bolt", "collector_number": "146", "released_at": "2009-07-17", "set": "m10", "finishes": ["nonfoil", "foil"], "games": ["paper", "mtgo"]}`
+
+	// RagavanMH2 is the MH2 printing of Ragavan, Nimble Pilferer, which is
+	// in every Scryfall returned by NewScryfall
+	RagavanMH2 = `{"object": "card", "id": "ragavan-mh2", "lang": "en", "mtgo_id": 91000, "oracle_id": "ragavan-oracle", "name": "Ragavan, Nimble Pilferer", "collector_number": "138", "released_at": "2021-06-18", "set": "mh2", "finishes": ["nonfoil", "foil"], "games": ["paper", "arena", "mtgo"]}`
+
+	// BoltSLD is a Secret Lair printing of Lightning Bolt only in foil and
+	// etched
+	BoltSLD = `{"object": "card", "id": "bolt-sld", "lang": "en", "oracle_id": "bolt-oracle", "name": "Lightning Bolt", "collector_number": "999", "released_at": "2022-01-01", "set": "sld", "finishes": ["foil", "etched"], "games": ["paper"]}`
+
+	// FireIce is a split card, whose faces are named Fire and Ice
+	FireIce = `{"object": "card", "id": "fire-ice-dom", "lang": "en", "oracle_id": "fire-ice-oracle", "name": "Fire // Ice", "layout": "split", "collector_number": "128", "released_at": "2018-04-27", "set": "dom", "finishes": ["nonfoil"], "games": ["paper", "arena"], "card_faces": [{"name": "Fire"}, {"name": "Ice"}]}`
+)
+
+// NewScryfall returns a JSONCache of BoltM10, RagavanMH2 and any other cards,
+// given as Scryfall card objects
+func NewScryfall(t testing.TB, cards ...string) *scryfall.JSONCache {
+	t.Helper()
+
+	bulkData := "[" + strings.Join(append([]string{BoltM10, RagavanMH2}, cards...), ",\n") + "]"
+	cache, err := scryfall.NewJSONCache(strings.NewReader(bulkData))
+	if err != nil {
+		t.Fatalf("Failed to load Scryfall data: %s", err.Error())
+	}
+	return cache
+}
+
+// NewBackend returns a memory Backend with the users alice and bob, whose
+// identities with provider in workspace have the external IDs aliceID and
+// bobID
+func NewBackend(t testing.TB, provider, workspace, aliceID, bobID string) *memory.Backend {
+	t.Helper()
+
+	backend := memory.NewBackend()
+	ctx := context.Background()
+	for externalID, username := range map[string]string{aliceID: "alice", bobID: "bob"} {
+		_, err := backend.AddUser(ctx, username)
+		if err != nil {
+			t.Fatalf("Failed to add user %q: %s", username, err.Error())
+		}
+		err = backend.LinkIdentity(ctx, &inventory.Identity{
+			Provider:   provider,
+			Workspace:  workspace,
+			ExternalID: externalID,
+			Username:   username,
+		})
+		if err != nil {
+			t.Fatalf("Failed to link %q: %s", username, err.Error())
+		}
+	}
+	return backend
+}
@@ -0,0 +1,38 @@
+package chat
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+)
+
+// Table renders rows as aligned columns under a header, for showing in a
+// monospace code block
+func Table(header []string, rows [][]string) string {
+	var table strings.Builder
+	writer := tabwriter.NewWriter(&table, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(writer, strings.Join(row, "\t"))
+	}
+	writer.Flush()
+	return table.String()
+}
+
+// DescribeCopy describes what sets a copy of a card apart from a near mint,
+// nonfoil one, e.g. "foil, LP, ja", or returns "" if nothing does
+func DescribeCopy(card *inventory.Card) string {
+	parts := make([]string, 0, 3)
+	if card.Finish != inventory.FinishNonfoil {
+		parts = append(parts, string(card.Finish))
+	}
+	if card.Condition != inventory.ConditionNearMint {
+		parts = append(parts, string(card.Condition))
+	}
+	if card.Language != "" {
+		parts = append(parts, card.Language)
+	}
+	return strings.Join(parts, ", ")
+}
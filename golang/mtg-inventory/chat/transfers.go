@@ -0,0 +1,68 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+)
+
+// KeptCards returns every card row with the oracle ID that username keeps
+func KeptCards(ctx context.Context, backend inventory.Backend, username, oracleID string) ([]*inventory.CardRow, error) {
+	rows, err := inventory.ListAll(ctx, backend.GetCardsByOracleID, oracleID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting cards with oracle ID %q: %w", oracleID, err)
+	}
+	kept := make([]*inventory.CardRow, 0)
+	for _, row := range rows {
+		if row.Keeper == username {
+			kept = append(kept, row)
+		}
+	}
+	return kept, nil
+}
+
+// AllocateTransfer picks rows that username keeps to cover each requested
+// card, preferring cards username owns. Cards username doesn't keep enough of
+// are described in problems, one sentence each.
+func AllocateTransfer(ctx context.Context, backend inventory.Backend, username string, requested []*inventory.RequestedCards) (_ []*inventory.TransferredCards, problems []string, err error) {
+	rows := make([]*inventory.TransferredCards, 0)
+	problems = make([]string, 0)
+	for _, want := range requested {
+		kept, err := KeptCards(ctx, backend, username, want.OracleID)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// Send your own cards before lending out anyone else's
+		ordered := make([]*inventory.CardRow, 0, len(kept))
+		for _, row := range kept {
+			if row.Owner == username {
+				ordered = append(ordered, row)
+			}
+		}
+		for _, row := range kept {
+			if row.Owner != username {
+				ordered = append(ordered, row)
+			}
+		}
+
+		remaining := want.Quantity
+		for _, row := range ordered {
+			if remaining == 0 {
+				break
+			}
+			quantity := min(remaining, row.Quantity)
+			rows = append(rows, &inventory.TransferredCards{
+				Quantity: quantity,
+				Card:     row.Card,
+				Owner:    row.Owner,
+			})
+			remaining -= quantity
+		}
+		if remaining > 0 {
+			problems = append(problems, fmt.Sprintf("You're only keeping %d of the %d %s.", want.Quantity-remaining, want.Quantity, want.Name))
+		}
+	}
+	return rows, problems, nil
+}
@@ -0,0 +1,54 @@
+package chat
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/backends/memory"
+)
+
+func TestAllocateTransfer(t *testing.T) {
+	ctx := context.Background()
+	backend := memory.NewBackend()
+	for _, username := range []string{"alice", "bob"} {
+		_, err := backend.AddUserIfNotExist(ctx, username)
+		if err != nil {
+			t.Fatalf("Failed to add user %q: %s", username, err.Error())
+		}
+	}
+
+	bolt := &inventory.Card{Name: "Lightning Bolt", OracleID: "bolt-oracle", ScryfallID: "bolt-m10", Finish: inventory.FinishNonfoil, Condition: inventory.ConditionNearMint}
+	err := backend.AddCards(ctx, []*inventory.CardRow{
+		{Quantity: 2, Card: bolt, Owner: "alice", Keeper: "bob"},
+		{Quantity: 1, Card: bolt, Owner: "bob", Keeper: "bob"},
+		{Quantity: 5, Card: bolt, Owner: "alice", Keeper: "alice"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to add cards: %s", err.Error())
+	}
+
+	rows, problems, err := AllocateTransfer(ctx, backend, "bob", []*inventory.RequestedCards{
+		{Quantity: 2, Name: "Lightning Bolt", OracleID: "bolt-oracle"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to allocate transfer: %s", err.Error())
+	}
+	if len(problems) != 0 {
+		t.Fatalf("Unexpected problems: %v", problems)
+	}
+	if len(rows) != 2 || rows[0].Owner != "bob" || rows[0].Quantity != 1 || rows[1].Owner != "alice" || rows[1].Quantity != 1 {
+		t.Fatalf("Expected bob's own card first, then one of alice's, got: %+v", rows)
+	}
+
+	_, problems, err = AllocateTransfer(ctx, backend, "bob", []*inventory.RequestedCards{
+		{Quantity: 4, Name: "Lightning Bolt", OracleID: "bolt-oracle"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to allocate transfer: %s", err.Error())
+	}
+	if len(problems) != 1 || !strings.Contains(problems[0], "only keeping 3 of the 4 Lightning Bolt") {
+		t.Fatalf("Unexpected problems: %v", problems)
+	}
+}
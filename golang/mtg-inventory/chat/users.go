@@ -0,0 +1,55 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+)
+
+// maxUsernameAttempts bounds the suffixes tried when a new chat user's handle
+// is already taken by another user
+const maxUsernameAttempts = 100
+
+// Username returns the username linked to an external identity. On the
+// identity's first interaction a User is created, named after their handle or
+// the handle with a numeric suffix if it is taken, and linked to them.
+func Username(ctx context.Context, backend inventory.Backend, provider, workspace, externalID, handle string) (string, error) {
+	user, err := backend.GetUserByIdentity(ctx, provider, workspace, externalID)
+	if err == nil {
+		return user.Username, nil
+	} else if !errors.Is(err, inventory.ErrIdentityNoExist) {
+		return "", fmt.Errorf("error getting user for %s/%s/%s: %w", provider, workspace, externalID, err)
+	}
+
+	if handle == "" {
+		handle = externalID
+	}
+	for i := 1; i <= maxUsernameAttempts; i++ {
+		username := handle
+		if i > 1 {
+			username = fmt.Sprintf("%s-%d", handle, i)
+		}
+		// Adding fails rather than returning a user that exists, so
+		// that identities interacting at once never share a user
+		_, err = backend.AddUser(ctx, username)
+		if errors.Is(err, inventory.ErrUserExists) {
+			continue
+		} else if err != nil {
+			return "", fmt.Errorf("error adding user %q: %w", username, err)
+		}
+		err = backend.LinkIdentity(ctx, &inventory.Identity{
+			Provider:   provider,
+			Workspace:  workspace,
+			ExternalID: externalID,
+			Username:   username,
+		})
+		if err != nil {
+			return "", fmt.Errorf("error linking %s/%s/%s to %q: %w", provider, workspace, externalID, username, err)
+		}
+		return username, nil
+	}
+
+	return "", fmt.Errorf("error finding a free username for %s/%s/%s based on %q", provider, workspace, externalID, handle)
+}
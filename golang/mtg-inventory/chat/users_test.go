@@ -0,0 +1,44 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/backends/memory"
+)
+
+func TestUsernameConcurrent(t *testing.T) {
+	ctx := context.Background()
+	backend := memory.NewBackend()
+
+	const identities = 20
+	usernames := make([]string, identities)
+	errs := make([]error, identities)
+	var wg sync.WaitGroup
+	for i := 0; i < identities; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			usernames[i], errs[i] = Username(ctx, backend, "test", "W1", fmt.Sprintf("U%d", i), "alice")
+		}(i)
+	}
+	wg.Wait()
+
+	seen := make(map[string]bool, identities)
+	for i, username := range usernames {
+		if errs[i] != nil {
+			t.Fatalf("Failed to get username for U%d: %s", i, errs[i].Error())
+		}
+		if seen[username] {
+			t.Fatalf("Expected every identity to get its own user, %q is shared: %v", username, usernames)
+		}
+		seen[username] = true
+
+		user, err := backend.GetUserByIdentity(ctx, "test", "W1", fmt.Sprintf("U%d", i))
+		if err != nil || user.Username != username {
+			t.Fatalf("Expected U%d to be linked to %q, got %+v, %v", i, username, user, err)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+/*
+Executable api runs an instance of the http.Server.
+
+It listens on localhost unless -listen says otherwise. Set API_TOKEN to
+require every request to carry it as a bearer token, which should be done
+whenever the API is reachable from anywhere but a trusted network.
+*/
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	nethttp "net/http"
+	"os"
+	"strings"
+	"time"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/backends"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/backends/validate"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/http"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/scryfall"
+)
+
+var (
+	listenAddr  = flag.String("listen", "127.0.0.1:8080", "The address to listen on for HTTP requests")
+	backendName = flag.String("backend", backends.MySQL, "The backend to use, one of "+strings.Join(backends.Names, ", ")+", configured by the environment variable <BACKEND>_DSN")
+	migrate     = flag.Bool("migrate", false, "Migrate the backend to the latest schema before serving")
+
+	scryfallLayers = flag.String("scryfall", "", "A comma separated list of the layers to look up cards in for imports and exports, in order, from "+strings.Join(scryfall.LayerNames, ", ")+", by default those of -validate, or empty to not serve imports and exports")
+	validateLayers = flag.String("validate", "", "A comma separated list of the layers to validate submitted cards against, in order, from "+strings.Join(scryfall.LayerNames, ", ")+", or empty to not validate them")
+	correctCards   = flag.Bool("correct_cards", false, "Correct the names and oracle IDs of submitted cards that don't match their Scryfall IDs rather than rejecting them")
+	bulkDataFile   = flag.String("bulk_data", "./all-cards.json", "The bulk data file containing all Scryfall data, loaded by the bulk layer or indexed by the index layer")
+	scryfallCache  = flag.String("scryfall_cache", "./scryfall-cache.jsonl", "The file that the file layer persists cards to")
+	scryfallIndex  = flag.String("scryfall_index", "./scryfall-index.db", "The index of the bulk data file that the index layer opens, built when missing or older than the bulk data file")
+)
+
+func main() {
+	flag.Parse()
+
+	backend, err := backends.Open(context.Background(), *backendName, os.Getenv(backends.DSNEnvVar(*backendName)))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening backend: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	if *migrate {
+		err = backends.Migrate(context.Background(), backend)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error migrating backend: %s\n", err.Error())
+			os.Exit(1)
+		}
+	}
+
+	var validateSF inventory.Scryfall
+	if *validateLayers != "" {
+		validateSF = openScryfall(*validateLayers)
+		validating := validate.NewBackend(backend, validateSF)
+		validating.Correct = *correctCards
+		backend = validating
+	}
+
+	handler := http.NewServer(backend)
+	handler.Token = os.Getenv("API_TOKEN")
+	if handler.Token == "" && !isLoopback(*listenAddr) {
+		fmt.Fprintf(os.Stderr, "Warning: serving on %s without API_TOKEN, so anyone who can reach it can change the inventory\n", *listenAddr)
+	}
+	switch *scryfallLayers {
+	case "", *validateLayers:
+		handler.Scryfall = validateSF
+	default:
+		handler.Scryfall = openScryfall(*scryfallLayers)
+	}
+
+	server := &nethttp.Server{
+		Addr:              *listenAddr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	err = server.ListenAndServe()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error with server: %s\n", err.Error())
+		os.Exit(1)
+	}
+}
+
+// openScryfall opens a comma separated list of layers or exits
+func openScryfall(layers string) inventory.Scryfall {
+	sf, err := scryfall.OpenLayers(context.Background(), strings.Split(layers, ","), &scryfall.LayerConfig{
+		BulkDataFile: *bulkDataFile,
+		IndexFile:    *scryfallIndex,
+		CacheFile:    *scryfallCache,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening Scryfall: %s\n", err.Error())
+		os.Exit(1)
+	}
+	return sf
+}
+
+// isLoopback returns whether addr only listens on a loopback interface
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,88 @@
+/*
+Executable discord runs an instance of the discord.Server.
+*/
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/backends"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/discord"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/scryfall"
+)
+
+var (
+	bulkDataFile    = flag.String("bulk_data", "./all-cards.json", "The bulk data file containing all Scryfall data, loaded by the bulk layer or indexed by the index layer")
+	scryfallLayers  = flag.String("scryfall", scryfall.LayerBulk, "A comma separated list of the layers to look up cards in, in order, from "+strings.Join(scryfall.LayerNames, ", "))
+	scryfallCache   = flag.String("scryfall_cache", "./scryfall-cache.jsonl", "The file that the file layer persists cards to")
+	scryfallIndex   = flag.String("scryfall_index", "./scryfall-index.db", "The index of the bulk data file that the index layer opens, built when missing or older than the bulk data file")
+	bulkDataType    = flag.String("bulk_data_type", scryfall.BulkAllCards, "The type of bulk data the bulk layer downloads, one of "+strings.Join(scryfall.BulkTypes, ", "))
+	bulkDataRefresh = flag.Duration("bulk_data_refresh", 0, "How often the bulk layer downloads newer bulk data, e.g. 24h, or 0 to only load the bulk data file at startup")
+	backendName     = flag.String("backend", backends.MySQL, "The backend to use, one of "+strings.Join(backends.Names, ", ")+", configured by the environment variable <BACKEND>_DSN")
+	migrate         = flag.Bool("migrate", false, "Migrate the backend to the latest schema before serving")
+	guildID         = flag.String("guild", "", "The ID of the guild to register commands in, or empty to register them globally")
+	discordAdmins   = flag.String("discord_admins", "", "A comma separated list of the Discord user IDs allowed to run admin commands")
+)
+
+func main() {
+	flag.Parse()
+
+	botToken := os.Getenv("DISCORD_BOT_TOKEN")
+	if botToken == "" {
+		fmt.Fprintf(os.Stderr, "DISCORD_BOT_TOKEN must be set.\n")
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	backend, err := backends.Open(ctx, *backendName, os.Getenv(backends.DSNEnvVar(*backendName)))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening backend: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	if *migrate {
+		err = backends.Migrate(ctx, backend)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error migrating backend: %s\n", err.Error())
+			os.Exit(1)
+		}
+	}
+
+	sf, err := scryfall.OpenLayers(ctx, strings.Split(*scryfallLayers, ","), &scryfall.LayerConfig{
+		BulkDataFile: *bulkDataFile,
+		BulkDataType: *bulkDataType,
+		Refresh:      *bulkDataRefresh,
+		IndexFile:    *scryfallIndex,
+		CacheFile:    *scryfallCache,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening Scryfall: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	server, err := discord.NewServer(backend, sf, botToken)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating server: %s\n", err.Error())
+		os.Exit(1)
+	}
+	server.GuildID = *guildID
+	for _, admin := range strings.Split(*discordAdmins, ",") {
+		if admin = strings.TrimSpace(admin); admin != "" {
+			server.Admins[admin] = true
+		}
+	}
+
+	err = server.Serve(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error with server: %s\n", err.Error())
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/chat"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/importer"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/scryfall"
+)
+
+func cardsAdd(ctx context.Context, c *cli, args []string) error {
+	flags := newFlagSet("cards add")
+	owner := flags.String("owner", "", "The user who owns the cards")
+	keeper := flags.String("keeper", "", "The user who keeps the cards, by default the owner")
+	quantity := flags.Uint("quantity", 1, "The number of cards")
+	finish := flags.String("finish", string(inventory.FinishNonfoil), "The finish of the cards: nonfoil, foil or etched")
+	condition := flags.String("condition", string(inventory.ConditionNearMint), "The condition of the cards: NM, LP, MP, HP or DMG")
+	copyLanguage := flags.String("language", "", "The language of the cards, if Scryfall doesn't have their printing")
+	set := flags.String("set", "", "The set code of the printing, e.g. m10")
+	number := flags.String("number", "", "The collector number of the printing within the set")
+	language := flags.String("lang", "en", "The language of the printing, used with -set")
+	err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	name := strings.Join(flags.Args(), " ")
+	if *owner == "" || name == "" {
+		return usageError("cards add -owner <user> [-keeper <user>] [-quantity <n>] [-finish <finish>] [-condition <condition>] [-language <language>] [-set <set> [-number <collector number>] [-lang <language>]] <card name>")
+	}
+	if *keeper == "" {
+		*keeper = *owner
+	}
+	cardFinish, err := inventory.ParseFinish(*finish)
+	if err != nil {
+		return usageError("%s", err)
+	}
+	cardCondition, err := inventory.ParseCondition(*condition)
+	if err != nil {
+		return usageError("%s", err)
+	}
+
+	sf, err := c.OpenScryfall()
+	if err != nil {
+		return err
+	}
+	card, err := scryfall.FindCardByName(sf, name)
+	if err != nil {
+		return fmt.Errorf("error looking up card %q: %w", name, err)
+	}
+	if *set != "" {
+		name = card.Name
+		card, err = sf.GetCard(name, *set, *language, *number)
+		if err != nil {
+			return fmt.Errorf("error looking up printing of %q: %w", name, err)
+		}
+	}
+
+	row := &inventory.CardRow{
+		Quantity: *quantity,
+		Card: &inventory.Card{
+			Name:       card.Name,
+			OracleID:   card.CardOracleID(),
+			ScryfallID: card.ID,
+			Finish:     cardFinish,
+			Condition:  cardCondition,
+			Language:   *copyLanguage,
+		},
+		Owner:  *owner,
+		Keeper: *keeper,
+	}
+	err = c.Backend.AddCards(ctx, []*inventory.CardRow{row})
+	if err != nil {
+		return fmt.Errorf("error adding cards: %w", err)
+	}
+	return c.printCardRows([]*inventory.CardRow{row})
+}
+
+func cardsImport(ctx context.Context, c *cli, args []string) error {
+	flags := newFlagSet("cards import")
+	owner := flags.String("owner", "", "The user who owns the cards")
+	keeper := flags.String("keeper", "", "The user who keeps the cards, by default the owner")
+	format := flags.String("format", importer.FormatDecklist, "The format of the file: "+importer.FormatDecklist+", "+importer.FormatCSV+" to detect the CSV format from its header, or one of "+strings.Join(importer.FormatNames, ", "))
+	err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if *owner == "" || flags.NArg() > 1 {
+		return usageError("cards import -owner <user> [-keeper <user>] [-format <format>] [<file>]")
+	}
+	if *keeper == "" {
+		*keeper = *owner
+	}
+
+	decklist := c.Stdin
+	if flags.NArg() == 1 {
+		file, err := os.Open(flags.Arg(0))
+		if err != nil {
+			return fmt.Errorf("error opening %s: %w", *format, err)
+		}
+		defer file.Close()
+		decklist = file
+	}
+	lines, err := importer.Read(decklist, *format)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", *format, err)
+	}
+
+	sf, err := c.OpenScryfall()
+	if err != nil {
+		return err
+	}
+	rows, importErr := importer.NewImporter(c.Backend, sf).Import(ctx, lines, *owner, *keeper)
+	if len(rows) > 0 {
+		err = c.printCardRows(rows)
+		if err != nil {
+			return err
+		}
+	}
+	if importErr != nil {
+		return fmt.Errorf("error importing %s: %w", *format, importErr)
+	}
+	return nil
+}
+
+func cardsExport(ctx context.Context, c *cli, args []string) error {
+	flags := newFlagSet("cards export")
+	owner := flags.String("owner", "", "Export the cards this user owns")
+	keeper := flags.String("keeper", "", "Export the cards this user keeps")
+	formatName := flags.String("format", importer.Moxfield.Name, "The format to export, one of "+strings.Join(importer.ExportFormatNames, ", "))
+	err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if (*owner == "") == (*keeper == "") || flags.NArg() > 0 {
+		return usageError("cards export (-owner <user> | -keeper <user>) [-format <format>]")
+	}
+	format, err := importer.ParseExportFormat(*formatName)
+	if err != nil {
+		return usageError("%s", err)
+	}
+
+	var rows []*inventory.CardRow
+	if *owner != "" {
+		rows, err = inventory.ListAll(ctx, c.Backend.GetCardsByOwner, *owner)
+	} else {
+		rows, err = inventory.ListAll(ctx, c.Backend.GetCardsByKeeper, *keeper)
+	}
+	if err != nil {
+		return fmt.Errorf("error getting cards: %w", err)
+	}
+
+	sf, err := c.OpenScryfall()
+	if err != nil {
+		return err
+	}
+	err = importer.Write(c.Stdout, format, sf, rows)
+	if err != nil {
+		return fmt.Errorf("error exporting cards: %w", err)
+	}
+	return nil
+}
+
+func cardsLs(ctx context.Context, c *cli, args []string) error {
+	flags := newFlagSet("cards ls")
+	owner := flags.String("owner", "", "List the cards this user owns")
+	keeper := flags.String("keeper", "", "List the cards this user keeps")
+	name := flags.String("name", "", "List the cards with this name")
+	limit := flags.Uint("limit", inventory.DefaultListLimit, "The maximum number of rows to list")
+	offset := flags.Uint("offset", 0, "The number of rows to skip")
+	err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+
+	var rows []*inventory.CardRow
+	switch {
+	case *owner != "" && *keeper == "" && *name == "":
+		rows, err = c.Backend.GetCardsByOwner(ctx, *owner, *limit, *offset)
+	case *keeper != "" && *owner == "" && *name == "":
+		rows, err = c.Backend.GetCardsByKeeper(ctx, *keeper, *limit, *offset)
+	case *name != "" && *owner == "" && *keeper == "":
+		var card *inventory.ScryfallCard
+		card, err = c.cardByName(*name)
+		if err != nil {
+			return err
+		}
+		rows, err = c.Backend.GetCardsByOracleID(ctx, card.CardOracleID(), *limit, *offset)
+	default:
+		return usageError("cards ls (-owner <user> | -keeper <user> | -name <card name>) [-limit <n>] [-offset <n>]")
+	}
+	if err != nil {
+		return fmt.Errorf("error listing cards: %w", err)
+	}
+	return c.printCardRows(rows)
+}
+
+// cardByName looks up a card by a name that may not be exact
+func (c *cli) cardByName(name string) (*inventory.ScryfallCard, error) {
+	sf, err := c.OpenScryfall()
+	if err != nil {
+		return nil, err
+	}
+	card, err := scryfall.FindCardByName(sf, name)
+	if err != nil {
+		return nil, fmt.Errorf("error looking up card %q: %w", name, err)
+	}
+	return card, nil
+}
+
+func (c *cli) printCardRows(cardRows []*inventory.CardRow) error {
+	rows := make([][]string, 0, len(cardRows))
+	for _, row := range cardRows {
+		rows = append(rows, []string{
+			fmt.Sprint(row.Quantity),
+			row.Card.Name,
+			chat.DescribeCopy(row.Card),
+			row.Owner,
+			row.Keeper,
+			row.Card.ScryfallID,
+		})
+	}
+	return c.print(cardRows, []string{"Qty", "Card", "Copy", "Owner", "Keeper", "Scryfall ID"}, rows)
+}
@@ -0,0 +1,298 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/chat"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/decks"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/importer"
+)
+
+// readDecklist reads a decklist from the named file, or standard input if
+// there is none
+func (c *cli) readDecklist(files []string) ([]*importer.Line, error) {
+	var decklist io.Reader = c.Stdin
+	if len(files) == 1 {
+		file, err := os.Open(files[0])
+		if err != nil {
+			return nil, fmt.Errorf("error opening decklist: %w", err)
+		}
+		defer file.Close()
+		decklist = file
+	}
+	lines, err := importer.Parse(decklist)
+	if err != nil {
+		return nil, fmt.Errorf("error reading decklist: %w", err)
+	}
+	return lines, nil
+}
+
+// resolveDecklist reads a decklist and resolves it to copies of cards that
+// owner owns
+func (c *cli) resolveDecklist(ctx context.Context, owner string, files []string) ([]*inventory.DeckCards, error) {
+	lines, err := c.readDecklist(files)
+	if err != nil {
+		return nil, err
+	}
+
+	sf, err := c.OpenScryfall()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := importer.NewImporter(c.Backend, sf).ResolveDeck(ctx, lines, owner)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving decklist: %w", err)
+	}
+	return rows, nil
+}
+
+func decksCreate(ctx context.Context, c *cli, args []string) error {
+	flags := newFlagSet("decks create")
+	owner := flags.String("owner", "", "The user who owns the deck and its cards")
+	name := flags.String("name", "", "The name of the deck")
+	err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if *owner == "" || *name == "" || flags.NArg() > 1 {
+		return usageError("decks create -owner <user> -name <name> [<file>]")
+	}
+
+	rows, err := c.resolveDecklist(ctx, *owner, flags.Args())
+	if err != nil {
+		return err
+	}
+	deck, err := c.Backend.CreateDeck(ctx, *owner, *name, rows)
+	if err != nil {
+		return fmt.Errorf("error creating deck: %w", err)
+	}
+	deck, err = decks.GetDeck(ctx, c.Backend, deck.ID)
+	if err != nil {
+		return fmt.Errorf("error getting deck: %w", err)
+	}
+	return c.printDeck(deck)
+}
+
+func decksLs(ctx context.Context, c *cli, args []string) error {
+	flags := newFlagSet("decks ls")
+	owner := flags.String("owner", "", "List the decks this user owns")
+	limit := flags.Uint("limit", inventory.DefaultListLimit, "The maximum number of decks to list")
+	offset := flags.Uint("offset", 0, "The number of decks to skip")
+	err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if *owner == "" || flags.NArg() > 0 {
+		return usageError("decks ls -owner <user> [-limit <n>] [-offset <n>]")
+	}
+
+	decks, err := c.Backend.GetDecksByOwner(ctx, *owner, *limit, *offset)
+	if err != nil {
+		return fmt.Errorf("error listing decks: %w", err)
+	}
+
+	rows := make([][]string, 0, len(decks))
+	for _, deck := range decks {
+		rows = append(rows, []string{
+			strconv.FormatInt(deck.ID, 10),
+			deck.Name,
+			deck.Owner,
+			fmt.Sprint(deck.Quantity),
+		})
+	}
+	return c.print(decks, []string{"ID", "Name", "Owner", "Qty"}, rows)
+}
+
+func decksGet(ctx context.Context, c *cli, args []string) error {
+	id, err := parseID(args, "decks get <id>")
+	if err != nil {
+		return err
+	}
+
+	deck, err := decks.GetDeck(ctx, c.Backend, id)
+	if err != nil {
+		return fmt.Errorf("error getting deck: %w", err)
+	}
+	return c.printDeck(deck)
+}
+
+func decksUpdate(ctx context.Context, c *cli, args []string) error {
+	flags := newFlagSet("decks update")
+	name := flags.String("name", "", "The new name of the deck, by default its current name")
+	err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	usage := "decks update [-name <name>] <id> [<file>]"
+	if flags.NArg() < 1 || flags.NArg() > 2 {
+		return usageError(usage)
+	}
+	id, err := parseID(flags.Args()[:1], usage)
+	if err != nil {
+		return err
+	}
+
+	deck, err := c.Backend.GetDeckByID(ctx, id, 1, 0)
+	if err != nil {
+		return fmt.Errorf("error getting deck: %w", err)
+	}
+	if *name == "" {
+		*name = deck.Name
+	}
+	rows, err := c.resolveDecklist(ctx, deck.Owner, flags.Args()[1:])
+	if err != nil {
+		return err
+	}
+	err = c.Backend.UpdateDeck(ctx, id, *name, rows)
+	if err != nil {
+		return fmt.Errorf("error updating deck: %w", err)
+	}
+
+	deck, err = decks.GetDeck(ctx, c.Backend, id)
+	if err != nil {
+		return fmt.Errorf("error getting deck: %w", err)
+	}
+	return c.printDeck(deck)
+}
+
+func decksLend(ctx context.Context, c *cli, args []string) error {
+	flags := newFlagSet("decks lend")
+	to := flags.String("to", "", "The user borrowing the deck")
+	err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	usage := "decks lend -to <user> <id>"
+	if *to == "" {
+		return usageError(usage)
+	}
+	id, err := parseID(flags.Args(), usage)
+	if err != nil {
+		return err
+	}
+
+	deck, err := decks.GetDeck(ctx, c.Backend, id)
+	if err != nil {
+		return fmt.Errorf("error getting deck: %w", err)
+	}
+	transfer, err := decks.LendDeck(ctx, c.Backend, deck, *to)
+	if errors.Is(err, inventory.ErrTooFewCards) {
+		return fmt.Errorf("%s isn't keeping every card in the deck, see decks away %d: %w", deck.Owner, deck.ID, err)
+	} else if err != nil {
+		return err
+	}
+	return c.printTransfer(transfer)
+}
+
+func decksAway(ctx context.Context, c *cli, args []string) error {
+	id, err := parseID(args, "decks away <id>")
+	if err != nil {
+		return err
+	}
+
+	deck, err := decks.GetDeck(ctx, c.Backend, id)
+	if err != nil {
+		return fmt.Errorf("error getting deck: %w", err)
+	}
+	away, err := decks.CardsAway(ctx, c.Backend, deck)
+	if err != nil {
+		return fmt.Errorf("error finding cards away: %w", err)
+	}
+
+	rows := make([][]string, 0, len(away.Away)+len(away.Missing))
+	for _, row := range away.Away {
+		rows = append(rows, []string{fmt.Sprint(row.Quantity), row.Card.Name, chat.DescribeCopy(row.Card), row.Keeper})
+	}
+	for _, row := range away.Missing {
+		rows = append(rows, []string{fmt.Sprint(row.Quantity), row.Card.Name, chat.DescribeCopy(row.Card), "(missing)"})
+	}
+	return c.print(away, []string{"Qty", "Card", "Copy", "Keeper"}, rows)
+}
+
+func decksPlan(ctx context.Context, c *cli, args []string) error {
+	flags := newFlagSet("decks plan")
+	requestor := flags.String("requestor", "", "The user building the deck")
+	accept := flags.Bool("accept", false, "Open a request for the cards and a transfer from each lender")
+	err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if *requestor == "" || flags.NArg() > 1 {
+		return usageError("decks plan -requestor <user> [-accept] [<file>]")
+	}
+
+	lines, err := c.readDecklist(flags.Args())
+	if err != nil {
+		return err
+	}
+	sf, err := c.OpenScryfall()
+	if err != nil {
+		return err
+	}
+	wanted, err := importer.NewImporter(c.Backend, sf).Request(lines)
+	if err != nil {
+		return fmt.Errorf("error looking up decklist: %w", err)
+	}
+	plan, err := decks.PlanDeck(ctx, c.Backend, *requestor, wanted)
+	if err != nil {
+		return fmt.Errorf("error planning deck: %w", err)
+	}
+
+	var output any = plan
+	var accepted *decks.AcceptedPlan
+	if *accept {
+		accepted = &decks.AcceptedPlan{Plan: plan}
+		accepted.Request, accepted.Transfers, err = decks.AcceptPlan(ctx, c.Backend, plan)
+		if err != nil {
+			return fmt.Errorf("error accepting plan: %w", err)
+		}
+		output = accepted
+	}
+
+	if !c.JSON {
+		var total, kept uint
+		for _, want := range wanted {
+			total += want.Quantity
+		}
+		for _, want := range plan.Kept {
+			kept += want.Quantity
+		}
+		fmt.Fprintf(c.Stdout, "%s keeps %d of the %d cards.\n", plan.Requestor, kept, total)
+		for _, want := range plan.Missing {
+			fmt.Fprintf(c.Stdout, "Nobody can lend %d %s.\n", want.Quantity, want.Name)
+		}
+		if accepted != nil && accepted.Request != nil {
+			fmt.Fprintf(c.Stdout, "Opened request #%d.\n", accepted.Request.ID)
+			for _, transfer := range accepted.Transfers {
+				fmt.Fprintf(c.Stdout, "Opened transfer #%d from %s.\n", transfer.ID, transfer.FromUser)
+			}
+		}
+		fmt.Fprint(c.Stdout, "\n")
+	}
+
+	rows := make([][]string, 0)
+	for _, transfer := range plan.Transfers {
+		for _, card := range transfer.Cards {
+			rows = append(rows, []string{transfer.Lender, fmt.Sprint(card.Quantity), card.Card.Name, chat.DescribeCopy(card.Card), card.Owner})
+		}
+	}
+	return c.print(output, []string{"Lender", "Qty", "Card", "Copy", "Owner"}, rows)
+}
+
+func (c *cli) printDeck(deck *inventory.Deck) error {
+	if !c.JSON {
+		fmt.Fprintf(c.Stdout, "Deck #%d %q of %s, %d cards\n\n", deck.ID, deck.Name, deck.Owner, deck.Quantity)
+	}
+
+	rows := make([][]string, 0, len(deck.Cards))
+	for _, card := range deck.Cards {
+		rows = append(rows, []string{string(card.Zone), fmt.Sprint(card.Quantity), card.Card.Name, chat.DescribeCopy(card.Card)})
+	}
+	return c.print(deck, []string{"Zone", "Qty", "Card", "Copy"}, rows)
+}
@@ -0,0 +1,226 @@
+/*
+Executable mtg-inventory administers an inventory from the command line,
+either directly through a Backend or through the HTTP API.
+
+Usage:
+
+	mtg-inventory [flags] <command> <subcommand> [flags] [arguments]
+
+The commands are:
+
+	users add <username>...
+	users get <username>
+	cards add -owner <user> [-keeper <user>] [-quantity <n>] [-finish <finish>] [-condition <condition>] [-language <language>] [-set <set> [-number <collector number>] [-lang <language>]] <card name>
+	cards import -owner <user> [-keeper <user>] [-format <format>] [<file>]
+	cards export (-owner <user> | -keeper <user>) [-format <format>]
+	cards ls (-owner <user> | -keeper <user> | -name <card name>) [-limit <n>] [-offset <n>]
+	requests open -requestor <user> <card list>
+	requests ls -requestor <user> [-limit <n>] [-offset <n>]
+	requests get <id>
+	requests close <id>
+	transfers open -from <user> -to <user> [-request <id>] <card list>
+	transfers ls (-from <user> | -to <user> | -request <id>) [-limit <n>] [-offset <n>]
+	transfers get <id>
+	transfers export [-format <format>] <id>
+	transfers close <id>
+	transfers cancel <id>
+	decks create -owner <user> -name <name> [<file>]
+	decks ls -owner <user> [-limit <n>] [-offset <n>]
+	decks get <id>
+	decks update [-name <name>] <id> [<file>]
+	decks lend -to <user> <id>
+	decks away <id>
+	decks plan -requestor <user> [-accept] [<file>]
+
+Card lists are separated by commas or new lines, like "4 Lightning Bolt, 1
+Ragavan, Nimble Pilferer". Imports are read from standard input unless a
+file is given, either as a decklist with one card per line as deck builders
+and MTG Arena export them, or as a CSV file from Moxfield, Deckbox, Archidekt
+or TCGplayer. Exports are written as CSV files for those tools, or as an MTG
+Arena decklist or Magic Online .dek file with the formats arena and mtgo,
+which transfers are exported as to playtest them. Decks are read as
+decklists of cards their owner owns, with sections for the commander and
+sideboard. Lending a deck opens one transfer of all of its cards, and decks
+away lists the cards in a deck that someone other than its owner keeps.
+Planning a deck proposes who lends each card of a decklist that the
+requestor doesn't keep, preferring lenders with the fewest cards lent out,
+and accepting the plan opens a request for the cards and a transfer for it
+from each lender. Flags must come before arguments.
+*/
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/backends"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/http"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/scryfall"
+)
+
+var (
+	apiURL         = flag.String("api", "", "The base URL of the HTTP API to use instead of a backend, sending the bearer token in the environment variable API_TOKEN if it is set")
+	backendName    = flag.String("backend", backends.MySQL, "The backend to use, one of "+strings.Join(backends.Names, ", ")+", configured by the environment variable <BACKEND>_DSN")
+	bulkDataFile   = flag.String("bulk_data", "./all-cards.json", "The bulk data file containing all Scryfall data, loaded by the bulk layer or indexed by the index layer")
+	scryfallLayers = flag.String("scryfall", scryfall.LayerBulk, "A comma separated list of the layers to look up cards in, in order, from "+strings.Join(scryfall.LayerNames, ", "))
+	scryfallCache  = flag.String("scryfall_cache", "./scryfall-cache.jsonl", "The file that the file layer persists cards to")
+	scryfallIndex  = flag.String("scryfall_index", "./scryfall-index.db", "The index of the bulk data file that the index layer opens, built when missing or older than the bulk data file")
+	jsonOutput     = flag.Bool("json", false, "Print JSON instead of tables")
+	migrate        = flag.Bool("migrate", false, "Migrate the backend to the latest schema before running the command")
+)
+
+// errUsage is returned when a command is called incorrectly
+var errUsage = errors.New("usage")
+
+// cli contains everything the commands need
+type cli struct {
+	Backend inventory.Backend
+	Stdin   io.Reader
+	Stdout  io.Writer
+	JSON    bool
+
+	// OpenScryfall returns the Scryfall cache, which is slow to load, so
+	// it is only loaded by commands that need it
+	OpenScryfall func() (inventory.Scryfall, error)
+}
+
+// command runs a subcommand with its arguments
+type command func(ctx context.Context, c *cli, args []string) error
+
+var commands = map[string]map[string]command{
+	"users": {
+		"add": usersAdd,
+		"get": usersGet,
+	},
+	"cards": {
+		"add":    cardsAdd,
+		"import": cardsImport,
+		"export": cardsExport,
+		"ls":     cardsLs,
+	},
+	"requests": {
+		"open":  requestsOpen,
+		"ls":    requestsLs,
+		"get":   requestsGet,
+		"close": requestsClose,
+	},
+	"transfers": {
+		"open":   transfersOpen,
+		"ls":     transfersLs,
+		"get":    transfersGet,
+		"export": transfersExport,
+		"close":  transfersClose,
+		"cancel": transfersCancel,
+	},
+	"decks": {
+		"create": decksCreate,
+		"ls":     decksLs,
+		"get":    decksGet,
+		"update": decksUpdate,
+		"lend":   decksLend,
+		"away":   decksAway,
+		"plan":   decksPlan,
+	},
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] <command> <subcommand> [flags] [arguments]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	ctx := context.Background()
+	var backend inventory.Backend
+	if *apiURL != "" {
+		client := http.NewClient(*apiURL)
+		client.Token = os.Getenv("API_TOKEN")
+		backend = client
+	} else {
+		var err error
+		backend, err = backends.Open(ctx, *backendName, os.Getenv(backends.DSNEnvVar(*backendName)))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error opening backend: %s\n", err.Error())
+			os.Exit(1)
+		}
+		if *migrate {
+			err = backends.Migrate(ctx, backend)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error migrating backend: %s\n", err.Error())
+				os.Exit(1)
+			}
+		}
+	}
+
+	c := &cli{
+		Backend:      backend,
+		Stdin:        os.Stdin,
+		Stdout:       os.Stdout,
+		JSON:         *jsonOutput,
+		OpenScryfall: openScryfall,
+	}
+	err := c.run(ctx, flag.Args())
+	if errors.Is(err, errUsage) {
+		fmt.Fprintf(os.Stderr, "%s\n", err.Error())
+		os.Exit(2)
+	} else if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err.Error())
+		os.Exit(1)
+	}
+}
+
+func openScryfall() (inventory.Scryfall, error) {
+	sf, err := scryfall.OpenLayers(context.Background(), strings.Split(*scryfallLayers, ","), &scryfall.LayerConfig{
+		BulkDataFile: *bulkDataFile,
+		IndexFile:    *scryfallIndex,
+		CacheFile:    *scryfallCache,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error opening Scryfall: %w", err)
+	}
+	return sf, nil
+}
+
+// run looks up the command named by the first two arguments and runs it
+func (c *cli) run(ctx context.Context, args []string) error {
+	if len(args) < 2 {
+		return usageError("mtg-inventory [flags] <command> <subcommand> [flags] [arguments]")
+	}
+	subcommands, exists := commands[args[0]]
+	if !exists {
+		return usageError("unknown command %q", args[0])
+	}
+	cmd, exists := subcommands[args[1]]
+	if !exists {
+		return usageError("unknown subcommand %q of %s", args[1], args[0])
+	}
+	return cmd(ctx, c, args[2:])
+}
+
+func usageError(format string, a ...any) error {
+	return fmt.Errorf("%w: %s", errUsage, fmt.Sprintf(format, a...))
+}
+
+// newFlagSet returns a FlagSet for a subcommand that returns errors instead of
+// exiting
+func newFlagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	return flags
+}
+
+// parseFlags parses the flags of a subcommand, turning their errors into usage
+// errors
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	err := flags.Parse(args)
+	if err != nil {
+		return usageError("%s: %s", flags.Name(), err.Error())
+	}
+	return nil
+}
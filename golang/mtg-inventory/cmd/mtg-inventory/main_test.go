@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/backends/memory"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/chat/chattest"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/decks"
+)
+
+func newTestCLI(t *testing.T) (*cli, *bytes.Buffer) {
+	t.Helper()
+
+	cache := chattest.NewScryfall(t, `{"id": "bolt-2xm", "lang": "en", "oracle_id": "bolt-oracle", "name": "Lightning Bolt", "collector_number": "129", "released_at": "2020-08-07", "set": "2xm"}`)
+
+	var stdout bytes.Buffer
+	return &cli{
+		Backend: memory.NewBackend(),
+		Stdout:  &stdout,
+		OpenScryfall: func() (inventory.Scryfall, error) {
+			return cache, nil
+		},
+	}, &stdout
+}
+
+func run(t *testing.T, c *cli, stdout *bytes.Buffer, args ...string) string {
+	t.Helper()
+	stdout.Reset()
+	err := c.run(context.Background(), args)
+	if err != nil {
+		t.Fatalf("Failed to run %q: %s", args, err.Error())
+	}
+	return stdout.String()
+}
+
+func TestCLI(t *testing.T) {
+	c, stdout := newTestCLI(t)
+
+	run(t, c, stdout, "users", "add", "alice", "bob")
+	out := run(t, c, stdout, "cards", "add", "-owner", "alice", "-keeper", "bob", "-quantity", "4", "-set", "2xm", "-number", "129", "Lightning", "Bolt")
+	if !strings.Contains(out, "bolt-2xm") {
+		t.Fatalf("Expected the 2XM printing to be added: %s", out)
+	}
+
+	c.JSON = true
+	out = run(t, c, stdout, "cards", "ls", "-keeper", "bob")
+	var rows []*inventory.CardRow
+	err := json.Unmarshal([]byte(out), &rows)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal cards: %s", err.Error())
+	}
+	if len(rows) != 1 || rows[0].Quantity != 4 || rows[0].Owner != "alice" || rows[0].Card.ScryfallID != "bolt-2xm" {
+		t.Fatalf("Unexpected cards: %s", out)
+	}
+	c.JSON = false
+
+	out = run(t, c, stdout, "requests", "open", "-requestor", "alice", "2 Lightning Bolt, 1 Ragavan, Nimble Pilferer")
+	if !strings.HasPrefix(out, "Request #1 by alice") || !strings.Contains(out, "Ragavan, Nimble Pilferer") {
+		t.Fatalf("Unexpected request: %s", out)
+	}
+
+	stdout.Reset()
+	err = c.run(context.Background(), []string{"transfers", "open", "-from", "bob", "-to", "alice", "5 Lightning Bolt"})
+	if err == nil || !strings.Contains(err.Error(), "only keeping 4 of the 5") {
+		t.Fatalf("Expected transfer of too many cards to fail, got: %v", err)
+	}
+
+	out = run(t, c, stdout, "transfers", "open", "-from", "bob", "-to", "alice", "-request", "1", "2 Lightning Bolt")
+	if !strings.HasPrefix(out, "Transfer #1 from bob to alice for request #1") {
+		t.Fatalf("Unexpected transfer: %s", out)
+	}
+	run(t, c, stdout, "transfers", "close", "1")
+
+	out = run(t, c, stdout, "transfers", "export", "1")
+	if out != "Deck\n2 Lightning Bolt\n" {
+		t.Fatalf("Unexpected transfer export: %q", out)
+	}
+	out = run(t, c, stdout, "cards", "export", "-keeper", "bob", "-format", "mtgo")
+	if !strings.Contains(out, `<Cards Quantity="2" Sideboard="false" Name="Lightning Bolt"></Cards>`) {
+		t.Fatalf("Unexpected .dek export: %s", out)
+	}
+
+	out = run(t, c, stdout, "cards", "ls", "-name", "Lightning Bolt")
+	if !strings.Contains(out, "alice  alice") || !strings.Contains(out, "alice  bob") {
+		t.Fatalf("Expected cards to be split between keepers: %s", out)
+	}
+
+	out = run(t, c, stdout, "transfers", "ls", "-request", "1")
+	if !strings.Contains(out, "bob") || strings.Count(out, "\n") != 2 {
+		t.Fatalf("Unexpected transfers: %s", out)
+	}
+
+	for _, args := range [][]string{
+		{"cards"},
+		{"bogus", "ls"},
+		{"cards", "ls"},
+		{"cards", "ls", "-owner", "alice", "-keeper", "bob"},
+		{"transfers", "close", "one"},
+		{"transfers", "export", "-format", "cardkingdom", "1"},
+	} {
+		err = c.run(context.Background(), args)
+		if !errors.Is(err, errUsage) {
+			t.Fatalf("Expected usage error running %q, got: %v", args, err)
+		}
+	}
+}
+
+func TestCardsImport(t *testing.T) {
+	c, stdout := newTestCLI(t)
+	run(t, c, stdout, "users", "add", "alice")
+
+	c.Stdin = strings.NewReader("Deck\n4 Lightning Bolt (M10) 146\n\nSideboard\n1 Ragavan, Nimble Pilferer (MH2) 138\n")
+	out := run(t, c, stdout, "cards", "import", "-owner", "alice")
+	if !strings.Contains(out, "bolt-m10") || !strings.Contains(out, "ragavan-mh2") {
+		t.Fatalf("Expected both cards to be imported: %s", out)
+	}
+
+	c.Stdin = strings.NewReader("1 Lightnig Bolt\n")
+	err := c.run(context.Background(), []string{"cards", "import", "-owner", "alice"})
+	if err == nil || !strings.Contains(err.Error(), `line 1 "1 Lightnig Bolt"`) {
+		t.Fatalf("Expected import of a misspelled card to fail, got: %v", err)
+	}
+
+	c.Stdin = strings.NewReader("Quantity,Name,Set Code,Card Number,Printing,Condition,Language\n2,Lightning Bolt,2XM,129,Normal,Lightly Played,English\n")
+	run(t, c, stdout, "cards", "import", "-owner", "alice", "-format", "csv")
+
+	out = run(t, c, stdout, "cards", "export", "-owner", "alice", "-format", "archidekt")
+	for _, expected := range []string{"4,Lightning Bolt,Normal,NM,", "2,Lightning Bolt,Normal,LP,", ",2xm,,bolt-2xm,,129\n", "1,\"Ragavan, Nimble Pilferer\""} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("Expected %q in export: %s", expected, out)
+		}
+	}
+}
+
+func TestDecks(t *testing.T) {
+	c, stdout := newTestCLI(t)
+	run(t, c, stdout, "users", "add", "alice", "bob")
+	c.Stdin = strings.NewReader("4 Lightning Bolt (M10) 146\n1 Ragavan, Nimble Pilferer (MH2) 138\n")
+	run(t, c, stdout, "cards", "import", "-owner", "alice")
+
+	c.Stdin = strings.NewReader("Commander\n1 Ragavan, Nimble Pilferer\n\nDeck\n3 Lightning Bolt\n\nSideboard\n1 Lightning Bolt\n")
+	out := run(t, c, stdout, "decks", "create", "-owner", "alice", "-name", "Burn")
+	if !strings.HasPrefix(out, `Deck #1 "Burn" of alice, 5 cards`) || !strings.Contains(out, "commander") || !strings.Contains(out, "sideboard") {
+		t.Fatalf("Unexpected deck: %s", out)
+	}
+
+	out = run(t, c, stdout, "decks", "ls", "-owner", "alice")
+	if !strings.Contains(out, "Burn") {
+		t.Fatalf("Expected Burn in decks: %s", out)
+	}
+
+	out = run(t, c, stdout, "decks", "lend", "-to", "bob", "1")
+	if !strings.HasPrefix(out, "Transfer #1 from alice to bob") {
+		t.Fatalf("Unexpected transfer: %s", out)
+	}
+	run(t, c, stdout, "transfers", "close", "1")
+
+	c.JSON = true
+	out = run(t, c, stdout, "decks", "away", "1")
+	var away decks.Away
+	err := json.Unmarshal([]byte(out), &away)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal cards away: %s", err.Error())
+	}
+	if len(away.Away) != 2 || away.Away[0].Keeper != "bob" || len(away.Missing) != 0 {
+		t.Fatalf("Expected bob to keep the deck: %s", out)
+	}
+	c.JSON = false
+
+	stdout.Reset()
+	err = c.run(context.Background(), []string{"decks", "lend", "-to", "bob", "1"})
+	if !errors.Is(err, inventory.ErrTooFewCards) {
+		t.Fatalf("Expected lending a deck that's away to fail, got: %v", err)
+	}
+
+	c.Stdin = strings.NewReader("5 Lightning Bolt\n")
+	stdout.Reset()
+	err = c.run(context.Background(), []string{"decks", "update", "1"})
+	if err == nil || !strings.Contains(err.Error(), "alice owns 4 of them") {
+		t.Fatalf("Expected updating a deck with too many cards to fail, got: %v", err)
+	}
+	c.Stdin = strings.NewReader("4 Lightning Bolt\n")
+	out = run(t, c, stdout, "decks", "update", "-name", "Mono Red", "1")
+	if !strings.HasPrefix(out, `Deck #1 "Mono Red" of alice, 4 cards`) {
+		t.Fatalf("Unexpected updated deck: %s", out)
+	}
+}
+
+func TestDecksPlan(t *testing.T) {
+	c, stdout := newTestCLI(t)
+	run(t, c, stdout, "users", "add", "alice", "bob", "carol")
+	c.Stdin = strings.NewReader("1 Lightning Bolt (M10) 146\n")
+	run(t, c, stdout, "cards", "import", "-owner", "alice")
+	c.Stdin = strings.NewReader("4 Lightning Bolt (2XM) 129\n")
+	run(t, c, stdout, "cards", "import", "-owner", "bob")
+
+	c.Stdin = strings.NewReader("Deck\n3 Lightning Bolt\n\nSideboard\n1 Ragavan, Nimble Pilferer\n")
+	out := run(t, c, stdout, "decks", "plan", "-requestor", "alice")
+	for _, expected := range []string{"alice keeps 1 of the 4 cards.", "Nobody can lend 1 Ragavan, Nimble Pilferer.", "bob"} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("Expected %q in plan: %s", expected, out)
+		}
+	}
+
+	c.JSON = true
+	c.Stdin = strings.NewReader("3 Lightning Bolt\n")
+	out = run(t, c, stdout, "decks", "plan", "-requestor", "alice", "-accept")
+	var accepted decks.AcceptedPlan
+	err := json.Unmarshal([]byte(out), &accepted)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal accepted plan: %s", err.Error())
+	}
+	if accepted.Request == nil || accepted.Request.Quantity != 2 || len(accepted.Transfers) != 1 || accepted.Transfers[0].FromUser != "bob" {
+		t.Fatalf("Expected a request for 2 cards and a transfer from bob: %s", out)
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/chat"
+)
+
+// print writes v as JSON if requested, and otherwise as a table
+func (c *cli) print(v any, header []string, rows [][]string) error {
+	if c.JSON {
+		encoder := json.NewEncoder(c.Stdout)
+		encoder.SetIndent("", "  ")
+		err := encoder.Encode(v)
+		if err != nil {
+			return fmt.Errorf("error encoding JSON: %w", err)
+		}
+		return nil
+	}
+
+	_, err := fmt.Fprint(c.Stdout, chat.Table(header, rows))
+	if err != nil {
+		return fmt.Errorf("error writing table: %w", err)
+	}
+	return nil
+}
+
+func timeColumn(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Local().Format(time.DateTime)
+}
+
+func requestIDColumn(id *int64) string {
+	if id == nil {
+		return ""
+	}
+	return strconv.FormatInt(*id, 10)
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/chat"
+)
+
+// resolveCardList parses a card list and looks up every card in it
+func (c *cli) resolveCardList(text string) ([]*inventory.RequestedCards, error) {
+	entries, err := chat.ParseCardList(text)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing card list: %w", err)
+	}
+
+	sf, err := c.OpenScryfall()
+	if err != nil {
+		return nil, err
+	}
+	rows, problems := chat.ResolveCardList(sf, entries)
+	if len(problems) > 0 {
+		return nil, errors.New(strings.Join(problems, " "))
+	}
+	return rows, nil
+}
+
+// parseID parses the single ID argument of a subcommand
+func parseID(args []string, usage string) (int64, error) {
+	if len(args) != 1 {
+		return 0, usageError(usage)
+	}
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return 0, usageError("%q is not an ID", args[0])
+	}
+	return id, nil
+}
+
+func requestsOpen(ctx context.Context, c *cli, args []string) error {
+	flags := newFlagSet("requests open")
+	requestor := flags.String("requestor", "", "The user requesting the cards")
+	err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	text := strings.Join(flags.Args(), " ")
+	if *requestor == "" || text == "" {
+		return usageError("requests open -requestor <user> <card list>")
+	}
+
+	rows, err := c.resolveCardList(text)
+	if err != nil {
+		return err
+	}
+
+	request, err := c.Backend.OpenRequest(ctx, *requestor, rows)
+	if err != nil {
+		return fmt.Errorf("error opening request: %w", err)
+	}
+	return c.printRequest(request)
+}
+
+func requestsLs(ctx context.Context, c *cli, args []string) error {
+	flags := newFlagSet("requests ls")
+	requestor := flags.String("requestor", "", "List the requests this user opened")
+	limit := flags.Uint("limit", inventory.DefaultListLimit, "The maximum number of requests to list")
+	offset := flags.Uint("offset", 0, "The number of requests to skip")
+	err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if *requestor == "" {
+		return usageError("requests ls -requestor <user> [-limit <n>] [-offset <n>]")
+	}
+
+	requests, err := c.Backend.GetRequestsByRequestor(ctx, *requestor, *limit, *offset)
+	if err != nil {
+		return fmt.Errorf("error listing requests: %w", err)
+	}
+
+	rows := make([][]string, 0, len(requests))
+	for _, request := range requests {
+		rows = append(rows, []string{
+			strconv.FormatInt(request.ID, 10),
+			request.Requestor,
+			timeColumn(&request.Opened),
+			timeColumn(request.Closed),
+			fmt.Sprint(request.Quantity),
+		})
+	}
+	return c.print(requests, []string{"ID", "Requestor", "Opened", "Closed", "Qty"}, rows)
+}
+
+func requestsGet(ctx context.Context, c *cli, args []string) error {
+	id, err := parseID(args, "requests get <id>")
+	if err != nil {
+		return err
+	}
+
+	request, err := c.Backend.GetRequestByID(ctx, id, inventory.MaxListLimit, 0)
+	if err != nil {
+		return fmt.Errorf("error getting request: %w", err)
+	}
+	return c.printRequest(request)
+}
+
+func requestsClose(ctx context.Context, c *cli, args []string) error {
+	id, err := parseID(args, "requests close <id>")
+	if err != nil {
+		return err
+	}
+
+	err = c.Backend.CloseRequest(ctx, id)
+	if err != nil {
+		return fmt.Errorf("error closing request: %w", err)
+	}
+	return nil
+}
+
+func (c *cli) printRequest(request *inventory.Request) error {
+	if !c.JSON {
+		status := "open"
+		if request.Closed != nil {
+			status = "closed " + timeColumn(request.Closed)
+		}
+		fmt.Fprintf(c.Stdout, "Request #%d by %s, opened %s, %s\n\n", request.ID, request.Requestor, timeColumn(&request.Opened), status)
+	}
+
+	rows := make([][]string, 0, len(request.Cards))
+	for _, card := range request.Cards {
+		rows = append(rows, []string{fmt.Sprint(card.Quantity), card.Name})
+	}
+	return c.print(request, []string{"Qty", "Card"}, rows)
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/chat"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/importer"
+)
+
+func transfersOpen(ctx context.Context, c *cli, args []string) error {
+	flags := newFlagSet("transfers open")
+	from := flags.String("from", "", "The user sending the cards, who must keep them")
+	to := flags.String("to", "", "The user receiving the cards")
+	requestID := flags.Int64("request", 0, "The ID of the request the transfer fills")
+	err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	text := strings.Join(flags.Args(), " ")
+	if *from == "" || *to == "" || text == "" {
+		return usageError("transfers open -from <user> -to <user> [-request <id>] <card list>")
+	}
+
+	requested, err := c.resolveCardList(text)
+	if err != nil {
+		return err
+	}
+
+	rows, problems, err := chat.AllocateTransfer(ctx, c.Backend, *from, requested)
+	if err != nil {
+		return err
+	}
+	if len(problems) > 0 {
+		// The problems are phrased for the sender, so name them here
+		return fmt.Errorf("%s can't send those cards: %s", *from, strings.Join(problems, " "))
+	}
+
+	var request *int64
+	if *requestID != 0 {
+		request = requestID
+	}
+	transfer, err := c.Backend.OpenTransfer(ctx, *to, *from, request, rows)
+	if err != nil {
+		return fmt.Errorf("error opening transfer: %w", err)
+	}
+	return c.printTransfer(transfer)
+}
+
+func transfersLs(ctx context.Context, c *cli, args []string) error {
+	flags := newFlagSet("transfers ls")
+	from := flags.String("from", "", "List the transfers from this user")
+	to := flags.String("to", "", "List the transfers to this user")
+	requestID := flags.Int64("request", 0, "List the transfers filling this request")
+	limit := flags.Uint("limit", inventory.DefaultListLimit, "The maximum number of transfers to list")
+	offset := flags.Uint("offset", 0, "The number of transfers to skip")
+	err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+
+	var transfers []*inventory.Transfer
+	switch {
+	case *from != "" && *to == "" && *requestID == 0:
+		transfers, err = c.Backend.GetTransfersByFromUser(ctx, *from, *limit, *offset)
+	case *to != "" && *from == "" && *requestID == 0:
+		transfers, err = c.Backend.GetTransfersByToUser(ctx, *to, *limit, *offset)
+	case *requestID != 0 && *from == "" && *to == "":
+		transfers, err = c.Backend.GetTransfersByRequestID(ctx, *requestID, *limit, *offset)
+	default:
+		return usageError("transfers ls (-from <user> | -to <user> | -request <id>) [-limit <n>] [-offset <n>]")
+	}
+	if err != nil {
+		return fmt.Errorf("error listing transfers: %w", err)
+	}
+
+	rows := make([][]string, 0, len(transfers))
+	for _, transfer := range transfers {
+		rows = append(rows, []string{
+			strconv.FormatInt(transfer.ID, 10),
+			transfer.FromUser,
+			transfer.ToUser,
+			requestIDColumn(transfer.RequestID),
+			timeColumn(&transfer.Opened),
+			timeColumn(transfer.Closed),
+			fmt.Sprint(transfer.Quantity),
+		})
+	}
+	return c.print(transfers, []string{"ID", "From", "To", "Request", "Opened", "Closed", "Qty"}, rows)
+}
+
+func transfersGet(ctx context.Context, c *cli, args []string) error {
+	id, err := parseID(args, "transfers get <id>")
+	if err != nil {
+		return err
+	}
+
+	transfer, err := c.Backend.GetTransferByID(ctx, id, inventory.MaxListLimit, 0)
+	if err != nil {
+		return fmt.Errorf("error getting transfer: %w", err)
+	}
+	return c.printTransfer(transfer)
+}
+
+func transfersExport(ctx context.Context, c *cli, args []string) error {
+	flags := newFlagSet("transfers export")
+	formatName := flags.String("format", importer.FormatArena, "The format to export, one of "+strings.Join(importer.ExportFormatNames, ", "))
+	err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	id, err := parseID(flags.Args(), "transfers export [-format <format>] <id>")
+	if err != nil {
+		return err
+	}
+	format, err := importer.ParseExportFormat(*formatName)
+	if err != nil {
+		return usageError("%s", err)
+	}
+
+	transfer, err := importer.GetTransfer(ctx, c.Backend.GetTransferByID, id)
+	if err != nil {
+		return fmt.Errorf("error getting transfer: %w", err)
+	}
+
+	sf, err := c.OpenScryfall()
+	if err != nil {
+		return err
+	}
+	err = importer.Write(c.Stdout, format, sf, importer.TransferRows(transfer))
+	if err != nil {
+		return fmt.Errorf("error exporting transfer: %w", err)
+	}
+	return nil
+}
+
+func transfersClose(ctx context.Context, c *cli, args []string) error {
+	id, err := parseID(args, "transfers close <id>")
+	if err != nil {
+		return err
+	}
+
+	err = c.Backend.CloseTransfer(ctx, id)
+	if errors.Is(err, inventory.ErrTooFewCards) {
+		return fmt.Errorf("the sender no longer keeps enough of the cards: %w", err)
+	} else if err != nil {
+		return fmt.Errorf("error closing transfer: %w", err)
+	}
+	return nil
+}
+
+func transfersCancel(ctx context.Context, c *cli, args []string) error {
+	id, err := parseID(args, "transfers cancel <id>")
+	if err != nil {
+		return err
+	}
+
+	err = c.Backend.CancelTransfer(ctx, id)
+	if err != nil {
+		return fmt.Errorf("error canceling transfer: %w", err)
+	}
+	return nil
+}
+
+func (c *cli) printTransfer(transfer *inventory.Transfer) error {
+	if !c.JSON {
+		fmt.Fprintf(c.Stdout, "Transfer #%d from %s to %s", transfer.ID, transfer.FromUser, transfer.ToUser)
+		if transfer.RequestID != nil {
+			fmt.Fprintf(c.Stdout, " for request #%d", *transfer.RequestID)
+		}
+		status := "open"
+		if transfer.Closed != nil {
+			status = "closed " + timeColumn(transfer.Closed)
+		}
+		fmt.Fprintf(c.Stdout, ", opened %s, %s\n\n", timeColumn(&transfer.Opened), status)
+	}
+
+	rows := make([][]string, 0, len(transfer.Cards))
+	for _, card := range transfer.Cards {
+		rows = append(rows, []string{fmt.Sprint(card.Quantity), card.Card.Name, chat.DescribeCopy(card.Card), card.Owner})
+	}
+	return c.print(transfer, []string{"Qty", "Card", "Copy", "Owner"}, rows)
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+)
+
+func usersAdd(ctx context.Context, c *cli, args []string) error {
+	if len(args) == 0 {
+		return usageError("users add <username>...")
+	}
+
+	users := make([]*inventory.User, 0, len(args))
+	rows := make([][]string, 0, len(args))
+	for _, username := range args {
+		user, err := c.Backend.AddUserIfNotExist(ctx, username)
+		if err != nil {
+			return fmt.Errorf("error adding user %q: %w", username, err)
+		}
+		users = append(users, user)
+		rows = append(rows, []string{user.Username})
+	}
+	return c.print(users, []string{"Username"}, rows)
+}
+
+func usersGet(ctx context.Context, c *cli, args []string) error {
+	if len(args) != 1 {
+		return usageError("users get <username>")
+	}
+
+	user, err := c.Backend.GetUserByUsername(ctx, args[0])
+	if err != nil {
+		return fmt.Errorf("error getting user %q: %w", args[0], err)
+	}
+	return c.print(user, []string{"Username"}, [][]string{{user.Username}})
+}
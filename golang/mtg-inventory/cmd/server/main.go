@@ -4,23 +4,32 @@ Executable server runs an instance of the slack.Server.
 package main
 
 import (
-	"database/sql"
+	"context"
 	"flag"
 	"fmt"
 	"os"
 	"strings"
 
-	backend "github.com/benrm/mtg-inventory/golang/mtg-inventory/backends/sql"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/backends"
 	"github.com/benrm/mtg-inventory/golang/mtg-inventory/scryfall"
 	"github.com/benrm/mtg-inventory/golang/mtg-inventory/slack"
-	_ "github.com/go-sql-driver/mysql"
 )
 
 var (
-	bulkDataFile = flag.String("bulk_data", "./all-cards.json", "The bulk data file containing all Scryfall data")
+	bulkDataFile    = flag.String("bulk_data", "./all-cards.json", "The bulk data file containing all Scryfall data, loaded by the bulk layer or indexed by the index layer")
+	scryfallLayers  = flag.String("scryfall", scryfall.LayerBulk, "A comma separated list of the layers to look up cards in, in order, from "+strings.Join(scryfall.LayerNames, ", "))
+	scryfallCache   = flag.String("scryfall_cache", "./scryfall-cache.jsonl", "The file that the file layer persists cards to")
+	scryfallIndex   = flag.String("scryfall_index", "./scryfall-index.db", "The index of the bulk data file that the index layer opens, built when missing or older than the bulk data file")
+	bulkDataType    = flag.String("bulk_data_type", scryfall.BulkAllCards, "The type of bulk data the bulk layer downloads, one of "+strings.Join(scryfall.BulkTypes, ", "))
+	bulkDataRefresh = flag.Duration("bulk_data_refresh", 0, "How often the bulk layer downloads newer bulk data, e.g. 24h, or 0 to only load the bulk data file at startup")
+	backendName     = flag.String("backend", backends.MySQL, "The backend to use, one of "+strings.Join(backends.Names, ", ")+", configured by the environment variable <BACKEND>_DSN")
+	migrate         = flag.Bool("migrate", false, "Migrate the backend to the latest schema before serving")
+	slackAdmins     = flag.String("slack_admins", "", "A comma separated list of the Slack user IDs allowed to run admin commands")
 )
 
 func main() {
+	flag.Parse()
+
 	var failed bool
 	appToken := os.Getenv("SLACK_APP_TOKEN")
 	if appToken == "" {
@@ -44,27 +53,38 @@ func main() {
 		os.Exit(1)
 	}
 
-	db, err := sql.Open("mysql", os.Getenv("MYSQL_DSN"))
+	backend, err := backends.Open(context.Background(), *backendName, os.Getenv(backends.DSNEnvVar(*backendName)))
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error opening database: %s\n", err.Error())
+		fmt.Fprintf(os.Stderr, "Error opening backend: %s\n", err.Error())
 		os.Exit(1)
 	}
 
-	flag.Parse()
-
-	bulkData, err := os.Open(*bulkDataFile)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error opening bulk data file: %s\n", err.Error())
-		os.Exit(1)
+	if *migrate {
+		err = backends.Migrate(context.Background(), backend)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error migrating backend: %s\n", err.Error())
+			os.Exit(1)
+		}
 	}
 
-	jsonCache, err := scryfall.NewJSONCache(bulkData)
+	sf, err := scryfall.OpenLayers(context.Background(), strings.Split(*scryfallLayers, ","), &scryfall.LayerConfig{
+		BulkDataFile: *bulkDataFile,
+		BulkDataType: *bulkDataType,
+		Refresh:      *bulkDataRefresh,
+		IndexFile:    *scryfallIndex,
+		CacheFile:    *scryfallCache,
+	})
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error reading bulk data file: %s\n", err.Error())
+		fmt.Fprintf(os.Stderr, "Error opening Scryfall: %s\n", err.Error())
 		os.Exit(1)
 	}
 
-	server := slack.NewServer(backend.NewBackend(db), jsonCache, appToken, botToken)
+	server := slack.NewServer(backend, sf, appToken, botToken)
+	for _, admin := range strings.Split(*slackAdmins, ",") {
+		if admin = strings.TrimSpace(admin); admin != "" {
+			server.Admins[admin] = true
+		}
+	}
 
 	err = server.Serve()
 	if err != nil {
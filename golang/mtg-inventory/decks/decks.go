@@ -0,0 +1,134 @@
+/*
+Package decks contains the logic for lending decks, finding their cards and
+planning how to build them from the cards of a group, which the command-line
+client and the HTTP server share.
+*/
+package decks
+
+import (
+	"context"
+	"fmt"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+)
+
+// GetDeck returns the deck with id with all of its cards
+func GetDeck(ctx context.Context, backend inventory.Backend, id int64) (*inventory.Deck, error) {
+	var deck *inventory.Deck
+	cards, err := inventory.ListAll(ctx, func(ctx context.Context, id int64, limit, offset uint) ([]*inventory.DeckCards, error) {
+		page, err := backend.GetDeckByID(ctx, id, limit, offset)
+		if err != nil {
+			return nil, err
+		}
+		deck = page
+		return page.Cards, nil
+	}, id)
+	if err != nil {
+		return nil, err
+	}
+	deck.Cards = cards
+	return deck, nil
+}
+
+// deckCopies returns the copies of cards in a deck with the quantity needed
+// across every zone, in the order the deck first lists them
+func deckCopies(deck *inventory.Deck) []*inventory.TransferredCards {
+	copies := make([]*inventory.TransferredCards, 0, len(deck.Cards))
+	indexes := make(map[inventory.Card]int)
+	for _, row := range deck.Cards {
+		if i, exists := indexes[*row.Card]; exists {
+			copies[i].Quantity += row.Quantity
+			continue
+		}
+		indexes[*row.Card] = len(copies)
+		copies = append(copies, &inventory.TransferredCards{
+			Quantity: row.Quantity,
+			Card:     row.Card,
+			Owner:    deck.Owner,
+		})
+	}
+	return copies
+}
+
+// LendDeck opens a single transfer of every card in a deck from its owner to
+// toUser. The owner must be keeping every card.
+func LendDeck(ctx context.Context, backend inventory.Backend, deck *inventory.Deck, toUser string) (*inventory.Transfer, error) {
+	transfer, err := backend.OpenTransfer(ctx, toUser, deck.Owner, nil, deckCopies(deck))
+	if err != nil {
+		return nil, fmt.Errorf("error lending deck %q: %w", deck.Name, err)
+	}
+	return transfer, nil
+}
+
+// Away is where the cards in a deck are that its owner isn't keeping
+type Away struct {
+	// Away are the copies that others keep, with their keepers
+	Away []*inventory.CardRow `json:"away"`
+
+	// Missing are the copies the owner no longer has at all, with no
+	// keeper
+	Missing []*inventory.CardRow `json:"missing"`
+}
+
+// CardsAway reports where the cards in a deck are that its owner isn't
+// keeping
+func CardsAway(ctx context.Context, backend inventory.Backend, deck *inventory.Deck) (*Away, error) {
+	away := &Away{
+		Away:    make([]*inventory.CardRow, 0),
+		Missing: make([]*inventory.CardRow, 0),
+	}
+	for _, want := range deckCopies(deck) {
+		owned, err := ownedCopies(ctx, backend, deck.Owner, want.Card)
+		if err != nil {
+			return nil, err
+		}
+
+		// The owner's own copies count first, so only the rest are away
+		remaining := want.Quantity
+		for _, row := range owned {
+			if row.Keeper == deck.Owner {
+				remaining -= min(remaining, row.Quantity)
+			}
+		}
+		for _, row := range owned {
+			if remaining == 0 {
+				break
+			}
+			if row.Keeper == deck.Owner {
+				continue
+			}
+			quantity := min(remaining, row.Quantity)
+			away.Away = append(away.Away, &inventory.CardRow{
+				Quantity: quantity,
+				Card:     row.Card,
+				Owner:    row.Owner,
+				Keeper:   row.Keeper,
+			})
+			remaining -= quantity
+		}
+		if remaining > 0 {
+			away.Missing = append(away.Missing, &inventory.CardRow{
+				Quantity: remaining,
+				Card:     want.Card,
+				Owner:    deck.Owner,
+			})
+		}
+	}
+	return away, nil
+}
+
+// ownedCopies returns every card row of the same copy as card that owner owns
+func ownedCopies(ctx context.Context, backend inventory.Backend, owner string, card *inventory.Card) ([]*inventory.CardRow, error) {
+	rows, err := inventory.ListAll(ctx, backend.GetCardsByOracleID, card.OracleID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting cards with oracle ID %q: %w", card.OracleID, err)
+	}
+	owned := make([]*inventory.CardRow, 0)
+	for _, row := range rows {
+		if row.Owner == owner && row.Card.ScryfallID == card.ScryfallID && row.Card.Finish == card.Finish &&
+			row.Card.Condition == card.Condition && row.Card.Language == card.Language {
+			owned = append(owned, row)
+		}
+	}
+	return owned, nil
+}
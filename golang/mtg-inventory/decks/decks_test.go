@@ -0,0 +1,85 @@
+package decks
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/backends/memory"
+)
+
+func TestLendDeck(t *testing.T) {
+	ctx := context.Background()
+	backend := memory.NewBackend()
+	for _, username := range []string{"alice", "bob", "carol"} {
+		_, err := backend.AddUserIfNotExist(ctx, username)
+		if err != nil {
+			t.Fatalf("Failed to add user %q: %s", username, err.Error())
+		}
+	}
+
+	bolt := &inventory.Card{Name: "Lightning Bolt", OracleID: "bolt-oracle", ScryfallID: "bolt-m10", Finish: inventory.FinishNonfoil, Condition: inventory.ConditionNearMint}
+	ragavan := &inventory.Card{Name: "Ragavan, Nimble Pilferer", OracleID: "ragavan-oracle", ScryfallID: "ragavan-mh2", Finish: inventory.FinishNonfoil, Condition: inventory.ConditionNearMint}
+	err := backend.AddCards(ctx, []*inventory.CardRow{
+		{Quantity: 4, Card: bolt, Owner: "alice", Keeper: "alice"},
+		{Quantity: 2, Card: bolt, Owner: "bob", Keeper: "alice"},
+		{Quantity: 1, Card: ragavan, Owner: "alice", Keeper: "alice"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to add cards: %s", err.Error())
+	}
+	deck, err := backend.CreateDeck(ctx, "alice", "Burn", []*inventory.DeckCards{
+		{Zone: inventory.ZoneMainboard, Quantity: 3, Card: bolt},
+		{Zone: inventory.ZoneSideboard, Quantity: 1, Card: bolt},
+		{Zone: inventory.ZoneMainboard, Quantity: 1, Card: ragavan},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create deck: %s", err.Error())
+	}
+	deck, err = GetDeck(ctx, backend, deck.ID)
+	if err != nil {
+		t.Fatalf("Failed to get deck: %s", err.Error())
+	}
+
+	away, err := CardsAway(ctx, backend, deck)
+	if err != nil {
+		t.Fatalf("Failed to report cards away: %s", err.Error())
+	}
+	if len(away.Away) != 0 || len(away.Missing) != 0 {
+		t.Fatalf("Expected no cards away from alice, got %+v and missing %+v", away.Away, away.Missing)
+	}
+
+	transfer, err := LendDeck(ctx, backend, deck, "carol")
+	if err != nil {
+		t.Fatalf("Failed to lend deck: %s", err.Error())
+	}
+	if transfer.Quantity != 5 || len(transfer.Cards) != 2 || transfer.Cards[0].Owner != "alice" {
+		t.Fatalf("Expected a transfer of alice's 5 cards in 2 rows, got %+v", transfer)
+	}
+	err = backend.CloseTransfer(ctx, transfer.ID)
+	if err != nil {
+		t.Fatalf("Failed to close transfer: %s", err.Error())
+	}
+	err = backend.ModifyCardQuantity(ctx, "alice", "carol", ragavan, 0)
+	if err != nil {
+		t.Fatalf("Failed to modify card quantity: %s", err.Error())
+	}
+
+	away, err = CardsAway(ctx, backend, deck)
+	if err != nil {
+		t.Fatalf("Failed to report cards away: %s", err.Error())
+	}
+	if len(away.Away) != 1 || away.Away[0].Keeper != "carol" || away.Away[0].Quantity != 4 || away.Away[0].Card.Name != "Lightning Bolt" {
+		t.Fatalf("Expected carol to keep 4 Lightning Bolt, got %+v", away.Away)
+	}
+	if len(away.Missing) != 1 || away.Missing[0].Quantity != 1 || away.Missing[0].Card.Name != "Ragavan, Nimble Pilferer" {
+		t.Fatalf("Expected Ragavan to be missing, got %+v", away.Missing)
+	}
+
+	_, err = LendDeck(ctx, backend, deck, "bob")
+	var rowErr *inventory.RowError
+	if !errors.As(err, &rowErr) || !errors.Is(err, inventory.ErrTooFewCards) {
+		t.Fatalf("Expected ErrTooFewCards lending a deck that's away, got: %v", err)
+	}
+}
@@ -0,0 +1,204 @@
+package decks
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+)
+
+// PlannedTransfer is a transfer that a Plan proposes, of cards that Lender
+// keeps
+type PlannedTransfer struct {
+	Lender   string                        `json:"lender"`
+	Quantity uint                          `json:"quantity"`
+	Cards    []*inventory.TransferredCards `json:"cards"`
+}
+
+// Plan proposes how Requestor can build a deck from the cards of the group
+type Plan struct {
+	Requestor string `json:"requestor"`
+
+	// Kept are the cards that Requestor already keeps
+	Kept []*inventory.RequestedCards `json:"kept"`
+
+	// Requested are the cards that Requestor needs from others, whether or
+	// not anyone can lend them
+	Requested []*inventory.RequestedCards `json:"requested"`
+
+	// Transfers are grouped by lender, in the order lenders are preferred
+	Transfers []*PlannedTransfer `json:"transfers"`
+
+	// Missing are the cards that nobody can lend
+	Missing []*inventory.RequestedCards `json:"missing"`
+}
+
+// LentOut returns the number of cards that username owns and someone else
+// keeps
+func LentOut(ctx context.Context, backend inventory.Backend, username string) (uint, error) {
+	rows, err := inventory.ListAll(ctx, backend.GetCardsByOwner, username)
+	if err != nil {
+		return 0, fmt.Errorf("error getting cards of %q: %w", username, err)
+	}
+	var quantity uint
+	for _, row := range rows {
+		if row.Keeper != username {
+			quantity += row.Quantity
+		}
+	}
+	return quantity, nil
+}
+
+// mergeRequested merges the rows for the same card, keeping their order
+func mergeRequested(wanted []*inventory.RequestedCards) []*inventory.RequestedCards {
+	merged := make([]*inventory.RequestedCards, 0, len(wanted))
+	byOracleID := make(map[string]*inventory.RequestedCards)
+	for _, want := range wanted {
+		if existing, exists := byOracleID[want.OracleID]; exists {
+			existing.Quantity += want.Quantity
+			continue
+		}
+		wantCopy := *want
+		byOracleID[want.OracleID] = &wantCopy
+		merged = append(merged, &wantCopy)
+	}
+	return merged
+}
+
+// PlanDeck proposes how requestor can build a deck of the wanted cards. The
+// cards requestor keeps count first, then requestor's own cards that others
+// keep, and then cards from lenders with the fewest cards lent out already,
+// preferring cards lenders own over those they keep for someone else.
+func PlanDeck(ctx context.Context, backend inventory.Backend, requestor string, wanted []*inventory.RequestedCards) (*Plan, error) {
+	plan := &Plan{
+		Requestor: requestor,
+		Kept:      make([]*inventory.RequestedCards, 0),
+		Requested: make([]*inventory.RequestedCards, 0),
+		Transfers: make([]*PlannedTransfer, 0),
+		Missing:   make([]*inventory.RequestedCards, 0),
+	}
+	lentOut := make(map[string]uint)
+	byLender := make(map[string]*PlannedTransfer)
+	for _, want := range mergeRequested(wanted) {
+		rows, err := inventory.ListAll(ctx, backend.GetCardsByOracleID, want.OracleID)
+		if err != nil {
+			return nil, fmt.Errorf("error getting cards with oracle ID %q: %w", want.OracleID, err)
+		}
+
+		remaining := want.Quantity
+		candidates := make([]*inventory.CardRow, 0, len(rows))
+		for _, row := range rows {
+			if row.Keeper == requestor {
+				remaining -= min(remaining, row.Quantity)
+				continue
+			}
+			if _, exists := lentOut[row.Keeper]; !exists {
+				lentOut[row.Keeper], err = LentOut(ctx, backend, row.Keeper)
+				if err != nil {
+					return nil, err
+				}
+			}
+			candidates = append(candidates, row)
+		}
+		if kept := want.Quantity - remaining; kept > 0 {
+			plan.Kept = append(plan.Kept, &inventory.RequestedCards{Quantity: kept, Name: want.Name, OracleID: want.OracleID})
+		}
+		if remaining == 0 {
+			continue
+		}
+		plan.Requested = append(plan.Requested, &inventory.RequestedCards{Quantity: remaining, Name: want.Name, OracleID: want.OracleID})
+
+		sort.SliceStable(candidates, func(i, j int) bool {
+			a, b := candidates[i], candidates[j]
+			if (a.Owner == requestor) != (b.Owner == requestor) {
+				return a.Owner == requestor
+			}
+			if lentOut[a.Keeper] != lentOut[b.Keeper] {
+				return lentOut[a.Keeper] < lentOut[b.Keeper]
+			}
+			if a.Keeper != b.Keeper {
+				return a.Keeper < b.Keeper
+			}
+			return a.Owner == a.Keeper && b.Owner != b.Keeper
+		})
+		for _, row := range candidates {
+			if remaining == 0 {
+				break
+			}
+			quantity := min(remaining, row.Quantity)
+			transfer, exists := byLender[row.Keeper]
+			if !exists {
+				transfer = &PlannedTransfer{Lender: row.Keeper}
+				byLender[row.Keeper] = transfer
+				plan.Transfers = append(plan.Transfers, transfer)
+			}
+			transfer.Quantity += quantity
+			transfer.Cards = append(transfer.Cards, &inventory.TransferredCards{
+				Quantity: quantity,
+				Card:     row.Card,
+				Owner:    row.Owner,
+			})
+			remaining -= quantity
+		}
+		if remaining > 0 {
+			plan.Missing = append(plan.Missing, &inventory.RequestedCards{Quantity: remaining, Name: want.Name, OracleID: want.OracleID})
+		}
+	}
+
+	sort.SliceStable(plan.Transfers, func(i, j int) bool {
+		a, b := plan.Transfers[i], plan.Transfers[j]
+		if lentOut[a.Lender] != lentOut[b.Lender] {
+			return lentOut[a.Lender] < lentOut[b.Lender]
+		}
+		return a.Lender < b.Lender
+	})
+	return plan, nil
+}
+
+// AcceptedPlan is a Plan with the request and transfers that AcceptPlan
+// opened for it
+type AcceptedPlan struct {
+	Plan      *Plan                 `json:"plan"`
+	Request   *inventory.Request    `json:"request"`
+	Transfers []*inventory.Transfer `json:"transfers"`
+}
+
+// AcceptPlan opens a request for the cards that a plan's requestor needs from
+// others, and a transfer for the request from each lender. If a transfer
+// can't be opened, those already opened are canceled and the request is
+// closed. If the requestor needs nothing, nothing is opened.
+func AcceptPlan(ctx context.Context, backend inventory.Backend, plan *Plan) (_ *inventory.Request, _ []*inventory.Transfer, err error) {
+	if len(plan.Requested) == 0 {
+		return nil, nil, nil
+	}
+	request, err := backend.OpenRequest(ctx, plan.Requestor, plan.Requested)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error opening request: %w", err)
+	}
+
+	transfers := make([]*inventory.Transfer, 0, len(plan.Transfers))
+	defer func() {
+		if err == nil {
+			return
+		}
+		for _, transfer := range transfers {
+			cancelErr := backend.CancelTransfer(ctx, transfer.ID)
+			if cancelErr != nil {
+				err = fmt.Errorf("%w, unable to cancel transfer %d: %s", err, transfer.ID, cancelErr)
+			}
+		}
+		closeErr := backend.CloseRequest(ctx, request.ID)
+		if closeErr != nil {
+			err = fmt.Errorf("%w, unable to close request %d: %s", err, request.ID, closeErr)
+		}
+	}()
+	for _, planned := range plan.Transfers {
+		transfer, err := backend.OpenTransfer(ctx, plan.Requestor, planned.Lender, &request.ID, planned.Cards)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error opening transfer from %q: %w", planned.Lender, err)
+		}
+		transfers = append(transfers, transfer)
+	}
+	return request, transfers, nil
+}
@@ -0,0 +1,126 @@
+package decks
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/backends/memory"
+)
+
+func TestPlanDeck(t *testing.T) {
+	ctx := context.Background()
+	backend := memory.NewBackend()
+	for _, username := range []string{"alice", "bob", "carol", "dave"} {
+		_, err := backend.AddUserIfNotExist(ctx, username)
+		if err != nil {
+			t.Fatalf("Failed to add user %q: %s", username, err.Error())
+		}
+	}
+
+	bolt := &inventory.Card{Name: "Lightning Bolt", OracleID: "bolt-oracle", ScryfallID: "bolt-m10", Finish: inventory.FinishNonfoil, Condition: inventory.ConditionNearMint}
+	counterspell := &inventory.Card{Name: "Counterspell", OracleID: "counterspell-oracle", ScryfallID: "counterspell-mmq", Finish: inventory.FinishNonfoil, Condition: inventory.ConditionNearMint}
+	err := backend.AddCards(ctx, []*inventory.CardRow{
+		{Quantity: 1, Card: bolt, Owner: "alice", Keeper: "alice"},
+		{Quantity: 1, Card: bolt, Owner: "alice", Keeper: "dave"},
+		{Quantity: 3, Card: bolt, Owner: "bob", Keeper: "bob"},
+		{Quantity: 2, Card: bolt, Owner: "carol", Keeper: "carol"},
+		{Quantity: 2, Card: counterspell, Owner: "bob", Keeper: "carol"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to add cards: %s", err.Error())
+	}
+
+	plan, err := PlanDeck(ctx, backend, "alice", []*inventory.RequestedCards{
+		{Quantity: 3, Name: "Lightning Bolt", OracleID: "bolt-oracle"},
+		{Quantity: 1, Name: "Ragavan, Nimble Pilferer", OracleID: "ragavan-oracle"},
+		{Quantity: 1, Name: "Lightning Bolt", OracleID: "bolt-oracle"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to plan deck: %s", err.Error())
+	}
+	if len(plan.Kept) != 1 || plan.Kept[0].Quantity != 1 {
+		t.Fatalf("Expected alice to keep 1 Lightning Bolt, got %+v", plan.Kept)
+	}
+	if len(plan.Requested) != 2 || plan.Requested[0].Quantity != 3 || plan.Requested[1].Quantity != 1 {
+		t.Fatalf("Expected a request for 3 Lightning Bolt and 1 Ragavan, got %+v", plan.Requested)
+	}
+	if len(plan.Missing) != 1 || plan.Missing[0].OracleID != "ragavan-oracle" {
+		t.Fatalf("Expected Ragavan to be missing, got %+v", plan.Missing)
+	}
+	// alice's own card comes back from dave, and carol, who has lent out
+	// nothing, lends before bob
+	if len(plan.Transfers) != 2 {
+		t.Fatalf("Expected 2 transfers, got %+v", plan.Transfers)
+	}
+	if plan.Transfers[0].Lender != "carol" || plan.Transfers[0].Quantity != 2 || plan.Transfers[0].Cards[0].Owner != "carol" {
+		t.Errorf("Expected carol to lend 2 of her cards first, got %+v", plan.Transfers[0])
+	}
+	if plan.Transfers[1].Lender != "dave" || plan.Transfers[1].Quantity != 1 || plan.Transfers[1].Cards[0].Owner != "alice" {
+		t.Errorf("Expected dave to return 1 of alice's cards, got %+v", plan.Transfers[1])
+	}
+
+	request, transfers, err := AcceptPlan(ctx, backend, plan)
+	if err != nil {
+		t.Fatalf("Failed to accept plan: %s", err.Error())
+	}
+	if request.Quantity != 4 || len(transfers) != 2 {
+		t.Fatalf("Expected a request for 4 cards and 2 transfers, got %+v and %+v", request, transfers)
+	}
+	for _, transfer := range transfers {
+		if transfer.RequestID == nil || *transfer.RequestID != request.ID || transfer.ToUser != "alice" || transfer.Closed != nil {
+			t.Errorf("Expected an open transfer to alice for request %d, got %+v", request.ID, transfer)
+		}
+	}
+}
+
+func TestAcceptPlanFailure(t *testing.T) {
+	ctx := context.Background()
+	backend := memory.NewBackend()
+	for _, username := range []string{"alice", "bob", "carol"} {
+		_, err := backend.AddUserIfNotExist(ctx, username)
+		if err != nil {
+			t.Fatalf("Failed to add user %q: %s", username, err.Error())
+		}
+	}
+
+	bolt := &inventory.Card{Name: "Lightning Bolt", OracleID: "bolt-oracle", ScryfallID: "bolt-m10", Finish: inventory.FinishNonfoil, Condition: inventory.ConditionNearMint}
+	err := backend.AddCards(ctx, []*inventory.CardRow{
+		{Quantity: 1, Card: bolt, Owner: "bob", Keeper: "bob"},
+		{Quantity: 1, Card: bolt, Owner: "carol", Keeper: "carol"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to add cards: %s", err.Error())
+	}
+
+	plan, err := PlanDeck(ctx, backend, "alice", []*inventory.RequestedCards{
+		{Quantity: 2, Name: "Lightning Bolt", OracleID: "bolt-oracle"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to plan deck: %s", err.Error())
+	}
+	err = backend.ModifyCardQuantity(ctx, "carol", "carol", bolt, 0)
+	if err != nil {
+		t.Fatalf("Failed to modify card quantity: %s", err.Error())
+	}
+
+	_, _, err = AcceptPlan(ctx, backend, plan)
+	if !errors.Is(err, inventory.ErrTooFewCards) {
+		t.Fatalf("Expected ErrTooFewCards accepting a stale plan, got: %v", err)
+	}
+	transfers, err := backend.GetTransfersByToUser(ctx, "alice", inventory.MaxListLimit, 0)
+	if err != nil {
+		t.Fatalf("Failed to get transfers: %s", err.Error())
+	}
+	if len(transfers) != 0 {
+		t.Errorf("Expected the transfer from bob to be canceled, got %+v", transfers)
+	}
+	requests, err := backend.GetRequestsByRequestor(ctx, "alice", inventory.MaxListLimit, 0)
+	if err != nil {
+		t.Fatalf("Failed to get requests: %s", err.Error())
+	}
+	if len(requests) != 1 || requests[0].Closed == nil {
+		t.Errorf("Expected the request to be closed, got %+v", requests)
+	}
+}
@@ -0,0 +1,221 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/chat"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/scryfall"
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	viewOwned  = "owned"
+	viewKept   = "kept"
+	viewOracle = "oracle"
+)
+
+// actionCardsPage is the action of the buttons that page through cards
+const actionCardsPage = "cards"
+
+// cardsPageSize is the number of card rows shown per message
+const cardsPageSize = 10
+
+// autocompleteLimit is the most choices Discord accepts for autocompletion
+const autocompleteLimit = 25
+
+// cardsPage describes one page of a card listing
+type cardsPage struct {
+	View     string
+	User     string
+	OracleID string
+	Name     string
+	Offset   uint
+}
+
+// customID returns the custom ID of a button pointing to the page. Custom IDs
+// are limited to 100 characters, so the user is left out and taken from
+// whoever clicks the button, and the card name is looked up again.
+func (page *cardsPage) customID() string {
+	customID := fmt.Sprintf("%s:%s:%d", actionCardsPage, page.View, page.Offset)
+	if page.View == viewOracle {
+		customID += ":" + page.OracleID
+	}
+	return customID
+}
+
+// whoHas resolves a card name and lists everyone who owns or keeps the card
+func (s *Server) whoHas(ctx context.Context, name string) *discordgo.InteractionResponse {
+	card, problem := chat.FindCard(s.Scryfall, name)
+	if card == nil {
+		return reply(false, problem, nil)
+	}
+
+	content, components := s.renderCardsPage(ctx, &cardsPage{
+		View:     viewOracle,
+		OracleID: card.CardOracleID(),
+		Name:     card.Name,
+	})
+	return reply(false, content, components)
+}
+
+// handleAutocomplete suggests card names for the option being typed. The
+// choices' values are names rather than oracle IDs because the option can also
+// be sent without choosing one.
+func (s *Server) handleAutocomplete(i *discordgo.Interaction) *discordgo.InteractionResponse {
+	var query string
+	data := i.ApplicationCommandData()
+	if len(data.Options) > 0 {
+		for _, option := range data.Options[0].Options {
+			if option.Focused {
+				query = option.StringValue()
+			}
+		}
+	}
+
+	completions, err := scryfall.Autocomplete(s.Scryfall, query, autocompleteLimit)
+	if err != nil {
+		log.Printf("Error autocompleting %q: %s", query, err.Error())
+	}
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, 0, len(completions))
+	for _, completion := range completions {
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  completion.Name,
+			Value: completion.Name,
+		})
+	}
+	return &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{
+			Choices: choices,
+		},
+	}
+}
+
+// renderCardsPage fetches a page of cards and renders it as a table with
+// buttons for the previous and next pages
+func (s *Server) renderCardsPage(ctx context.Context, page *cardsPage) (string, []discordgo.MessageComponent) {
+	var title, empty string
+	var header []string
+	var columns func(*inventory.CardRow) []string
+	var rows []*inventory.CardRow
+	var err error
+	switch page.View {
+	case viewOwned:
+		title = "Cards you own"
+		empty = "You don't own any cards."
+		header = []string{"Qty", "Card", "Copy", "Keeper"}
+		columns = func(row *inventory.CardRow) []string {
+			return []string{fmt.Sprint(row.Quantity), row.Card.Name, chat.DescribeCopy(row.Card), row.Keeper}
+		}
+		rows, err = s.Backend.GetCardsByOwner(ctx, page.User, cardsPageSize+1, page.Offset)
+	case viewKept:
+		title = "Cards you are keeping"
+		empty = "You aren't keeping any cards."
+		header = []string{"Qty", "Card", "Copy", "Owner"}
+		columns = func(row *inventory.CardRow) []string {
+			return []string{fmt.Sprint(row.Quantity), row.Card.Name, chat.DescribeCopy(row.Card), row.Owner}
+		}
+		rows, err = s.Backend.GetCardsByKeeper(ctx, page.User, cardsPageSize+1, page.Offset)
+	case viewOracle:
+		title = "Who has " + page.Name
+		empty = "No one has " + page.Name + "."
+		header = []string{"Qty", "Copy", "Owner", "Keeper"}
+		columns = func(row *inventory.CardRow) []string {
+			return []string{fmt.Sprint(row.Quantity), chat.DescribeCopy(row.Card), row.Owner, row.Keeper}
+		}
+		rows, err = s.Backend.GetCardsByOracleID(ctx, page.OracleID, cardsPageSize+1, page.Offset)
+	default:
+		log.Printf("Unknown cards view %q", page.View)
+		return "Something went wrong looking up cards.", nil
+	}
+	if err != nil {
+		log.Printf("Error getting %s cards: %s", page.View, err.Error())
+		return "Something went wrong looking up cards.", nil
+	}
+
+	if len(rows) == 0 && page.Offset == 0 {
+		return empty, nil
+	}
+
+	hasNext := len(rows) > cardsPageSize
+	if hasNext {
+		rows = rows[:cardsPageSize]
+	}
+
+	tableRows := make([][]string, 0, len(rows))
+	for _, row := range rows {
+		tableRows = append(tableRows, columns(row))
+	}
+	content := fmt.Sprintf("**%s** (%d–%d)\n```\n%s```", title, page.Offset+1, page.Offset+uint(len(rows)), chat.Table(header, tableRows))
+
+	pageButtons := make([]discordgo.Button, 0, 2)
+	if page.Offset > 0 {
+		previous := *page
+		previous.Offset -= min(previous.Offset, cardsPageSize)
+		pageButtons = append(pageButtons, discordgo.Button{
+			Label:    "Previous",
+			Style:    discordgo.SecondaryButton,
+			CustomID: previous.customID(),
+		})
+	}
+	if hasNext {
+		next := *page
+		next.Offset += cardsPageSize
+		pageButtons = append(pageButtons, discordgo.Button{
+			Label:    "Next",
+			Style:    discordgo.SecondaryButton,
+			CustomID: next.customID(),
+		})
+	}
+	if len(pageButtons) == 0 {
+		return content, nil
+	}
+
+	return content, buttons(pageButtons...)
+}
+
+// handleCardsPage replaces a card listing with the page a button points to
+func (s *Server) handleCardsPage(ctx context.Context, i *discordgo.Interaction, args string) *discordgo.InteractionResponse {
+	parts := strings.SplitN(args, ":", 3)
+	if len(parts) < 2 {
+		log.Printf("Malformed cards page %q", args)
+		return updateMessage("Something went wrong looking up cards.", nil)
+	}
+	offset, err := strconv.ParseUint(parts[1], 10, 0)
+	if err != nil {
+		log.Printf("Error parsing offset of cards page %q: %s", args, err.Error())
+		return updateMessage("Something went wrong looking up cards.", nil)
+	}
+
+	page := &cardsPage{
+		View:   parts[0],
+		Offset: uint(offset),
+	}
+	switch page.View {
+	case viewOwned, viewKept:
+		page.User, err = s.username(ctx, interactionUser(i))
+		if err != nil {
+			log.Printf("Error getting username: %s", err.Error())
+			return updateMessage("Something went wrong looking you up.", nil)
+		}
+	case viewOracle:
+		if len(parts) != 3 {
+			log.Printf("Malformed cards page %q", args)
+			return updateMessage("Something went wrong looking up cards.", nil)
+		}
+		page.OracleID = parts[2]
+		card, err := s.Scryfall.GetCardByOracleID(page.OracleID)
+		if err != nil {
+			log.Printf("Error looking up card with oracle ID %q: %s", page.OracleID, err.Error())
+			return updateMessage("Something went wrong looking up that card.", nil)
+		}
+		page.Name = card.Name
+	}
+
+	return updateMessage(s.renderCardsPage(ctx, page))
+}
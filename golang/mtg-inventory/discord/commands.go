@@ -0,0 +1,202 @@
+package discord
+
+import (
+	"context"
+	"log"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// MTGCommand is the application command that all inventory subcommands hang
+// off of
+const MTGCommand = "mtg"
+
+// Commands are the application commands registered by the Server
+var Commands = []*discordgo.ApplicationCommand{
+	{
+		Name:        MTGCommand,
+		Description: "Manage the playgroup's Magic: the Gathering inventory",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "cards",
+				Description: "List the cards you own or are keeping",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "view",
+						Description: "Which cards to list",
+						Required:    true,
+						Choices: []*discordgo.ApplicationCommandOptionChoice{
+							{Name: "mine", Value: "mine"},
+							{Name: "held", Value: "held"},
+						},
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "who-has",
+				Description: "List who owns and keeps a card",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:         discordgo.ApplicationCommandOptionString,
+						Name:         "name",
+						Description:  "The card's name",
+						Required:     true,
+						Autocomplete: true,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "request",
+				Description: "Ask the channel for cards",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "cards",
+						Description: "The cards, e.g. 4 Lightning Bolt, 1 Ragavan, Nimble Pilferer",
+						Required:    true,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "transfer",
+				Description: "Open a transfer of cards you are keeping to someone else",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionUser,
+						Name:        "to",
+						Description: "Who the cards are going to",
+						Required:    true,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "cards",
+						Description: "The cards, e.g. 4 Lightning Bolt, 1 Ragavan, Nimble Pilferer",
+						Required:    true,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "request",
+						Description: "The request the transfer fills",
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "close-transfer",
+				Description: "Confirm you received the cards in a transfer",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "id",
+						Description: "The transfer's ID",
+						Required:    true,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "cancel-transfer",
+				Description: "Cancel a transfer you are part of",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "id",
+						Description: "The transfer's ID",
+						Required:    true,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "link",
+				Description: "Link a user to an existing username (admins only)",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "username",
+						Description: "The existing username",
+						Required:    true,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionUser,
+						Name:        "user",
+						Description: "The Discord user to link",
+						Required:    true,
+					},
+				},
+			},
+		},
+	},
+}
+
+// handleMTGCommand returns the response to a /mtg command
+func (s *Server) handleMTGCommand(ctx context.Context, i *discordgo.Interaction) *discordgo.InteractionResponse {
+	user := interactionUser(i)
+	username, err := s.username(ctx, user)
+	if err != nil {
+		log.Printf("Error getting username: %s", err.Error())
+		return reply(false, "Something went wrong looking you up.", nil)
+	}
+
+	data := i.ApplicationCommandData()
+	if len(data.Options) == 0 {
+		return reply(false, "Unknown command.", nil)
+	}
+	subcommand := data.Options[0]
+	options := make(map[string]*discordgo.ApplicationCommandInteractionDataOption)
+	for _, option := range subcommand.Options {
+		options[option.Name] = option
+	}
+
+	switch subcommand.Name {
+	case "cards":
+		view := viewOwned
+		if options["view"].StringValue() == "held" {
+			view = viewKept
+		}
+		content, components := s.renderCardsPage(ctx, &cardsPage{View: view, User: username})
+		return reply(false, content, components)
+	case "who-has":
+		return s.whoHas(ctx, options["name"].StringValue())
+	case "request":
+		return s.openRequest(ctx, username, options["cards"].StringValue())
+	case "transfer":
+		to, err := s.username(ctx, optionUser(&data, options["to"]))
+		if err != nil {
+			log.Printf("Error getting username: %s", err.Error())
+			return reply(false, "Something went wrong looking up who the cards are going to.", nil)
+		}
+		var requestID *int64
+		if option, ok := options["request"]; ok {
+			id := option.IntValue()
+			requestID = &id
+		}
+		return s.openTransfer(ctx, username, to, requestID, options["cards"].StringValue())
+	case "close-transfer":
+		return s.confirmTransfer(ctx, username, actionCloseTransfer, options["id"].IntValue())
+	case "cancel-transfer":
+		return s.confirmTransfer(ctx, username, actionCancelTransfer, options["id"].IntValue())
+	case "link":
+		return s.linkUser(ctx, user, options["username"].StringValue(), optionUser(&data, options["user"]))
+	default:
+		log.Printf("Unhandled subcommand: %s", subcommand.Name)
+		return reply(false, "Unknown command.", nil)
+	}
+}
+
+// optionUser returns the user chosen for a user option, filled in from the
+// resolved data when Discord sent it
+func optionUser(data *discordgo.ApplicationCommandInteractionData, option *discordgo.ApplicationCommandInteractionDataOption) *discordgo.User {
+	user := option.UserValue(nil)
+	if data.Resolved != nil {
+		if resolved, ok := data.Resolved.Users[user.ID]; ok {
+			return resolved
+		}
+	}
+	return user
+}
@@ -0,0 +1,249 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/chat/chattest"
+	"github.com/bwmarrin/discordgo"
+)
+
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+
+	return &Server{
+		Backend:  chattest.NewBackend(t, IdentityProvider, identityWorkspace, "1001", "1002"),
+		Scryfall: chattest.NewScryfall(t),
+		Admins:   map[string]bool{"1000": true},
+	}
+}
+
+// command returns the interaction for a /mtg subcommand run by a guild member
+func command(userID, subcommand string, options ...*discordgo.ApplicationCommandInteractionDataOption) *discordgo.Interaction {
+	return &discordgo.Interaction{
+		Type:    discordgo.InteractionApplicationCommand,
+		GuildID: "G1",
+		Member:  &discordgo.Member{User: &discordgo.User{ID: userID, Username: "user" + userID}},
+		Data: discordgo.ApplicationCommandInteractionData{
+			Name: MTGCommand,
+			Options: []*discordgo.ApplicationCommandInteractionDataOption{
+				{
+					Type:    discordgo.ApplicationCommandOptionSubCommand,
+					Name:    subcommand,
+					Options: options,
+				},
+			},
+		},
+	}
+}
+
+// click returns the interaction for a button clicked by a guild member
+func click(userID, customID string) *discordgo.Interaction {
+	return &discordgo.Interaction{
+		Type:    discordgo.InteractionMessageComponent,
+		GuildID: "G1",
+		Member:  &discordgo.Member{User: &discordgo.User{ID: userID, Username: "user" + userID}},
+		Data: discordgo.MessageComponentInteractionData{
+			CustomID:      customID,
+			ComponentType: discordgo.ButtonComponent,
+		},
+	}
+}
+
+func stringOption(name, value string) *discordgo.ApplicationCommandInteractionDataOption {
+	return &discordgo.ApplicationCommandInteractionDataOption{Type: discordgo.ApplicationCommandOptionString, Name: name, Value: value}
+}
+
+func intOption(name string, value int64) *discordgo.ApplicationCommandInteractionDataOption {
+	// Discord sends numbers as JSON, which decodes into float64
+	return &discordgo.ApplicationCommandInteractionDataOption{Type: discordgo.ApplicationCommandOptionInteger, Name: name, Value: float64(value)}
+}
+
+func userOption(name, userID string) *discordgo.ApplicationCommandInteractionDataOption {
+	return &discordgo.ApplicationCommandInteractionDataOption{Type: discordgo.ApplicationCommandOptionUser, Name: name, Value: userID}
+}
+
+// responseButtons returns the content of a response and the custom IDs of its
+// buttons
+func responseButtons(t *testing.T, response *discordgo.InteractionResponse) (string, []string) {
+	t.Helper()
+
+	if response == nil || response.Data == nil {
+		t.Fatalf("Response has no data: %+v", response)
+	}
+	customIDs := make([]string, 0)
+	for _, component := range response.Data.Components {
+		row, ok := component.(discordgo.ActionsRow)
+		if !ok {
+			continue
+		}
+		for _, component := range row.Components {
+			if button, ok := component.(discordgo.Button); ok {
+				customIDs = append(customIDs, button.CustomID)
+			}
+		}
+	}
+	return response.Data.Content, customIDs
+}
+
+func isEphemeral(response *discordgo.InteractionResponse) bool {
+	return response.Data.Flags&discordgo.MessageFlagsEphemeral != 0
+}
+
+func TestMTGCommandCards(t *testing.T) {
+	s := newTestServer(t)
+	ctx := context.Background()
+
+	content, _ := responseButtons(t, s.handleInteraction(ctx, command("1001", "cards", stringOption("view", "mine"))))
+	if content != "You don't own any cards." {
+		t.Fatalf("Unexpected empty listing: %s", content)
+	}
+
+	rows := make([]*inventory.CardRow, 0)
+	for i := 0; i < cardsPageSize+2; i++ {
+		rows = append(rows, &inventory.CardRow{
+			Quantity: 1,
+			Card: &inventory.Card{
+				Name:       fmt.Sprintf("Card %02d", i),
+				OracleID:   fmt.Sprintf("oracle-%02d", i),
+				ScryfallID: fmt.Sprintf("scryfall-%02d", i),
+				Finish:     inventory.FinishNonfoil,
+				Condition:  inventory.ConditionNearMint,
+			},
+			Owner:  "alice",
+			Keeper: "bob",
+		})
+	}
+	err := s.Backend.AddCards(ctx, rows)
+	if err != nil {
+		t.Fatalf("Failed to add cards: %s", err.Error())
+	}
+
+	response := s.handleInteraction(ctx, command("1001", "cards", stringOption("view", "mine")))
+	content, customIDs := responseButtons(t, response)
+	if !isEphemeral(response) {
+		t.Fatalf("Expected listing to be ephemeral")
+	}
+	if !strings.Contains(content, "Card 00") || strings.Contains(content, "Card 10") {
+		t.Fatalf("Unexpected first page: %s", content)
+	}
+	if len(customIDs) != 1 || customIDs[0] != "cards:owned:10" {
+		t.Fatalf("Expected only a next button, got: %v", customIDs)
+	}
+
+	response = s.handleInteraction(ctx, click("1001", customIDs[0]))
+	if response.Type != discordgo.InteractionResponseUpdateMessage {
+		t.Fatalf("Expected paging to update the message, got type %d", response.Type)
+	}
+	content, customIDs = responseButtons(t, response)
+	if !strings.Contains(content, "Card 10") || !strings.Contains(content, "Card 11") || strings.Contains(content, "Card 09") {
+		t.Fatalf("Unexpected second page: %s", content)
+	}
+	if len(customIDs) != 1 || customIDs[0] != "cards:owned:0" {
+		t.Fatalf("Expected only a previous button, got: %v", customIDs)
+	}
+
+	content, _ = responseButtons(t, s.handleInteraction(ctx, command("1002", "cards", stringOption("view", "held"))))
+	if !strings.Contains(content, "Cards you are keeping") || !strings.Contains(content, "alice") {
+		t.Fatalf("Unexpected held listing: %s", content)
+	}
+}
+
+func TestMTGCommandWhoHas(t *testing.T) {
+	s := newTestServer(t)
+	ctx := context.Background()
+
+	rows := make([]*inventory.CardRow, 0)
+	for i := 0; i < cardsPageSize+1; i++ {
+		rows = append(rows, &inventory.CardRow{
+			Quantity: 1,
+			Card: &inventory.Card{
+				Name:       "Lightning Bolt",
+				OracleID:   "bolt-oracle",
+				ScryfallID: "bolt-m10",
+				Finish:     inventory.FinishNonfoil,
+				Condition:  inventory.ConditionNearMint,
+			},
+			Owner:  fmt.Sprintf("owner%02d", i),
+			Keeper: "bob",
+		})
+		_, err := s.Backend.AddUserIfNotExist(ctx, rows[i].Owner)
+		if err != nil {
+			t.Fatalf("Failed to add user: %s", err.Error())
+		}
+	}
+	err := s.Backend.AddCards(ctx, rows)
+	if err != nil {
+		t.Fatalf("Failed to add cards: %s", err.Error())
+	}
+
+	content, customIDs := responseButtons(t, s.handleInteraction(ctx, command("1002", "who-has", stringOption("name", "Lightning Bolt"))))
+	if !strings.Contains(content, "Who has Lightning Bolt") || !strings.Contains(content, "owner00") {
+		t.Fatalf("Unexpected who-has listing: %s", content)
+	}
+	if len(customIDs) != 1 || customIDs[0] != "cards:oracle:10:bolt-oracle" {
+		t.Fatalf("Expected only a next button, got: %v", customIDs)
+	}
+
+	content, _ = responseButtons(t, s.handleInteraction(ctx, click("1002", customIDs[0])))
+	if !strings.Contains(content, "Who has Lightning Bolt") || !strings.Contains(content, "owner10") {
+		t.Fatalf("Unexpected second page: %s", content)
+	}
+
+	content, _ = responseButtons(t, s.handleInteraction(ctx, command("1002", "who-has", stringOption("name", "Ragavan, Nimble Pilferer"))))
+	if content != "No one has Ragavan, Nimble Pilferer." {
+		t.Fatalf("Unexpected who-has listing: %s", content)
+	}
+
+	content, _ = responseButtons(t, s.handleInteraction(ctx, command("1002", "who-has", stringOption("name", "Black Lotus"))))
+	if !strings.Contains(content, "couldn't find") {
+		t.Fatalf("Unexpected who-has listing: %s", content)
+	}
+}
+
+func TestMTGCommandLink(t *testing.T) {
+	s := newTestServer(t)
+	ctx := context.Background()
+
+	content, _ := responseButtons(t, s.handleInteraction(ctx, command("1001", "link", stringOption("username", "alice"), userOption("user", "2001"))))
+	if !strings.Contains(content, "Only admins") {
+		t.Fatalf("Expected non-admin to be refused: %s", content)
+	}
+
+	content, _ = responseButtons(t, s.handleInteraction(ctx, command("1000", "link", stringOption("username", "nobody"), userOption("user", "2001"))))
+	if !strings.Contains(content, `no user named "nobody"`) {
+		t.Fatalf("Expected unknown username to be refused: %s", content)
+	}
+
+	content, _ = responseButtons(t, s.handleInteraction(ctx, command("1000", "link", stringOption("username", "alice"), userOption("user", "2001"))))
+	if content != "Linked <@2001> to alice." {
+		t.Fatalf("Unexpected response to link: %s", content)
+	}
+
+	user, err := s.Backend.GetUserByIdentity(ctx, IdentityProvider, identityWorkspace, "2001")
+	if err != nil {
+		t.Fatalf("Failed to get linked user: %s", err.Error())
+	}
+	if user.Username != "alice" {
+		t.Fatalf("Expected alice, got %q", user.Username)
+	}
+}
+
+func TestAutocomplete(t *testing.T) {
+	s := newTestServer(t)
+
+	option := stringOption("name", "lightning")
+	option.Focused = true
+	i := command("1002", "who-has", option)
+	i.Type = discordgo.InteractionApplicationCommandAutocomplete
+	response := s.handleInteraction(context.Background(), i)
+	if response == nil || response.Type != discordgo.InteractionApplicationCommandAutocompleteResult {
+		t.Fatalf("Unexpected response: %+v", response)
+	}
+	if len(response.Data.Choices) != 1 || response.Data.Choices[0].Name != "Lightning Bolt" || response.Data.Choices[0].Value != "Lightning Bolt" {
+		t.Fatalf("Unexpected choices: %+v", response.Data.Choices)
+	}
+}
@@ -0,0 +1,161 @@
+package discord
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/chat"
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// actionFillRequest is the action of the button on a posted request
+	actionFillRequest = "fill_request"
+
+	// actionConfirmFill is the action of the button confirming the transfer
+	// that fills a request
+	actionConfirmFill = "confirm_fill"
+)
+
+// openRequest resolves a card list and opens a request for it, posting it in
+// the channel with a button to fill it
+func (s *Server) openRequest(ctx context.Context, requestor, text string) *discordgo.InteractionResponse {
+	entries, err := chat.ParseCardList(text)
+	if err != nil {
+		return reply(false, fmt.Sprintf("I couldn't read that list: %s.", err.Error()), nil)
+	}
+
+	rows, problems := chat.ResolveCardList(s.Scryfall, entries)
+	if len(problems) > 0 {
+		return reply(false, "I couldn't open that request:\n- "+strings.Join(problems, "\n- "), nil)
+	}
+
+	request, err := s.Backend.OpenRequest(ctx, requestor, rows)
+	if errors.Is(err, inventory.ErrUserNoExist) {
+		return reply(false, "You don't have an inventory yet.", nil)
+	} else if errors.Is(err, inventory.ErrTooManyRows) {
+		return reply(false, fmt.Sprintf("You can request at most %d different cards at once.", inventory.RowUploadLimit), nil)
+	} else if err != nil {
+		log.Printf("Error opening request for %q: %s", requestor, err.Error())
+		return reply(false, "Something went wrong opening that request.", nil)
+	}
+
+	lines := make([]string, 0, len(request.Cards))
+	for _, card := range request.Cards {
+		lines = append(lines, fmt.Sprintf("- %d %s", card.Quantity, card.Name))
+	}
+	return reply(true,
+		fmt.Sprintf("**%s** opened request #%d:\n%s", request.Requestor, request.ID, strings.Join(lines, "\n")),
+		buttons(discordgo.Button{
+			Label:    "I can fill this",
+			Style:    discordgo.PrimaryButton,
+			CustomID: fmt.Sprintf("%s:%d", actionFillRequest, request.ID),
+		}),
+	)
+}
+
+// allocateFill picks the cards username keeps to fill a request. If the
+// request can't be filled by username, it returns the reason instead.
+func (s *Server) allocateFill(ctx context.Context, username string, requestID int64) (*inventory.Request, []*inventory.TransferredCards, string) {
+	request, err := s.Backend.GetRequestByID(ctx, requestID, inventory.MaxListLimit, 0)
+	if errors.Is(err, inventory.ErrRequestNoExist) {
+		return nil, nil, fmt.Sprintf("Request #%d no longer exists.", requestID)
+	} else if err != nil {
+		log.Printf("Error getting request %d: %s", requestID, err.Error())
+		return nil, nil, "Something went wrong looking up that request."
+	}
+	if request.Closed != nil {
+		return nil, nil, fmt.Sprintf("Request #%d is closed.", requestID)
+	}
+	if request.Requestor == username {
+		return nil, nil, "You can't fill your own request."
+	}
+
+	rows, _, err := chat.AllocateTransfer(ctx, s.Backend, username, request.Cards)
+	if err != nil {
+		log.Printf("Error allocating cards to fill request %d: %s", requestID, err.Error())
+		return nil, nil, "Something went wrong looking up your cards."
+	}
+	if len(rows) == 0 {
+		return nil, nil, "You aren't keeping any of the requested cards."
+	}
+
+	return request, rows, ""
+}
+
+// handleFillRequest shows the user who clicked a request's button the cards
+// they can send, with a button to open the transfer
+func (s *Server) handleFillRequest(ctx context.Context, i *discordgo.Interaction, args string) *discordgo.InteractionResponse {
+	requestID, err := strconv.ParseInt(args, 10, 64)
+	if err != nil {
+		log.Printf("Error parsing request ID %q: %s", args, err.Error())
+		return reply(false, "Something went wrong looking up that request.", nil)
+	}
+
+	username, err := s.username(ctx, interactionUser(i))
+	if err != nil {
+		log.Printf("Error getting username: %s", err.Error())
+		return reply(false, "Something went wrong looking you up.", nil)
+	}
+
+	request, rows, reason := s.allocateFill(ctx, username, requestID)
+	if reason != "" {
+		return reply(false, reason, nil)
+	}
+
+	lines := make([]string, 0, len(rows))
+	for _, row := range rows {
+		line := fmt.Sprintf("- %d %s", row.Quantity, row.Card.Name)
+		if description := chat.DescribeCopy(row.Card); description != "" {
+			line += " (" + description + ")"
+		}
+		if row.Owner != username {
+			line += ", owned by " + row.Owner
+		}
+		lines = append(lines, line)
+	}
+	return reply(false,
+		fmt.Sprintf("You can send **%s** these cards for request #%d:\n%s", request.Requestor, requestID, strings.Join(lines, "\n")),
+		buttons(
+			discordgo.Button{
+				Label:    "Open transfer",
+				Style:    discordgo.PrimaryButton,
+				CustomID: fmt.Sprintf("%s:%d", actionConfirmFill, requestID),
+			},
+			discordgo.Button{
+				Label:    "Never mind",
+				Style:    discordgo.SecondaryButton,
+				CustomID: actionNeverMind,
+			},
+		),
+	)
+}
+
+// handleConfirmFill opens the transfer to fill a request once the user
+// confirms it
+func (s *Server) handleConfirmFill(ctx context.Context, i *discordgo.Interaction, args string) *discordgo.InteractionResponse {
+	requestID, err := strconv.ParseInt(args, 10, 64)
+	if err != nil {
+		log.Printf("Error parsing request ID %q: %s", args, err.Error())
+		return updateMessage("Something went wrong looking up that request.", nil)
+	}
+
+	username, err := s.username(ctx, interactionUser(i))
+	if err != nil {
+		log.Printf("Error getting username: %s", err.Error())
+		return updateMessage("Something went wrong looking you up.", nil)
+	}
+
+	// Allocate again, since cards may have moved since the preview
+	request, rows, reason := s.allocateFill(ctx, username, requestID)
+	if reason != "" {
+		return updateMessage(reason, nil)
+	}
+
+	return s.openAllocatedTransfer(ctx, username, request.Requestor, &request.ID, rows)
+}
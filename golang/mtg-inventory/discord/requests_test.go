@@ -0,0 +1,84 @@
+package discord
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+)
+
+func TestMTGCommandRequest(t *testing.T) {
+	s := newTestServer(t)
+	ctx := context.Background()
+
+	response := s.handleInteraction(ctx, command("1001", "request", stringOption("cards", "4 Lightning Bolt, 1 Black Lotus")))
+	content, _ := responseButtons(t, response)
+	if !isEphemeral(response) || !strings.Contains(content, `couldn't find a card named "Black Lotus"`) {
+		t.Fatalf("Unexpected response to unknown card: %s", content)
+	}
+
+	response = s.handleInteraction(ctx, command("1001", "request", stringOption("cards", "4 Lightning Bolt, 1 Ragavan, Nimble Pilferer")))
+	content, customIDs := responseButtons(t, response)
+	if isEphemeral(response) {
+		t.Fatalf("Expected request to be posted in the channel: %s", content)
+	}
+	if !strings.Contains(content, "- 4 Lightning Bolt") || !strings.Contains(content, "- 1 Ragavan, Nimble Pilferer") {
+		t.Fatalf("Unexpected request: %s", content)
+	}
+	if len(customIDs) != 1 || !strings.HasPrefix(customIDs[0], actionFillRequest+":") {
+		t.Fatalf("Expected a button to fill the request, got: %v", customIDs)
+	}
+	fillID := customIDs[0]
+
+	content, _ = responseButtons(t, s.handleInteraction(ctx, click("1001", fillID)))
+	if content != "You can't fill your own request." {
+		t.Fatalf("Unexpected response to filling own request: %s", content)
+	}
+
+	content, _ = responseButtons(t, s.handleInteraction(ctx, click("1002", fillID)))
+	if content != "You aren't keeping any of the requested cards." {
+		t.Fatalf("Unexpected response when keeping no cards: %s", content)
+	}
+
+	err := s.Backend.AddCards(ctx, []*inventory.CardRow{
+		{
+			Quantity: 2,
+			Card: &inventory.Card{
+				Name:       "Lightning Bolt",
+				OracleID:   "bolt-oracle",
+				ScryfallID: "bolt-m10",
+				Finish:     inventory.FinishNonfoil,
+				Condition:  inventory.ConditionNearMint,
+			},
+			Owner:  "bob",
+			Keeper: "bob",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to add cards: %s", err.Error())
+	}
+
+	response = s.handleInteraction(ctx, click("1002", fillID))
+	content, customIDs = responseButtons(t, response)
+	if !isEphemeral(response) || !strings.Contains(content, "- 2 Lightning Bolt") {
+		t.Fatalf("Unexpected preview: %s", content)
+	}
+	if len(customIDs) != 2 || !strings.HasPrefix(customIDs[0], actionConfirmFill+":") {
+		t.Fatalf("Expected confirm and never mind buttons, got: %v", customIDs)
+	}
+
+	response = s.handleInteraction(ctx, click("1002", customIDs[0]))
+	content, _ = responseButtons(t, response)
+	if isEphemeral(response) || !strings.Contains(content, "**bob** opened transfer #") || !strings.Contains(content, "for request #") {
+		t.Fatalf("Unexpected transfer: %s", content)
+	}
+
+	transfers, err := s.Backend.GetTransfersByFromUser(ctx, "bob", inventory.MaxListLimit, 0)
+	if err != nil {
+		t.Fatalf("Failed to get transfers: %s", err.Error())
+	}
+	if len(transfers) != 1 || transfers[0].ToUser != "alice" || transfers[0].Quantity != 2 || transfers[0].RequestID == nil {
+		t.Fatalf("Unexpected transfers: %+v", transfers)
+	}
+}
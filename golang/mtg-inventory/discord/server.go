@@ -0,0 +1,183 @@
+/*
+Package discord integrates the Backend and Scryfall into a Discord bot.
+*/
+package discord
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+	"github.com/bwmarrin/discordgo"
+)
+
+// Server contains everything necessary for a Discord bot integrated with the
+// Backend and Scryfall
+type Server struct {
+	Backend  inventory.Backend
+	Scryfall inventory.Scryfall
+	Session  *discordgo.Session
+
+	// GuildID is the guild to register commands in, which makes them
+	// available immediately. If empty, commands are registered globally.
+	GuildID string
+
+	// Admins contains the Discord user IDs allowed to run admin commands
+	Admins map[string]bool
+}
+
+// NewServer returns a new Server
+func NewServer(
+	backend inventory.Backend,
+	scryfall inventory.Scryfall,
+	botToken string,
+) (*Server, error) {
+	session, err := discordgo.New("Bot " + botToken)
+	if err != nil {
+		return nil, fmt.Errorf("error creating Discord session: %w", err)
+	}
+	session.Identify.Intents = discordgo.IntentsGuilds
+
+	server := &Server{
+		Backend:  backend,
+		Scryfall: scryfall,
+		Session:  session,
+		Admins:   make(map[string]bool),
+	}
+	return server, nil
+}
+
+// Serve registers the application commands and handles interactions until ctx
+// is done
+func (s *Server) Serve(ctx context.Context) (err error) {
+	s.Session.AddHandler(func(session *discordgo.Session, event *discordgo.InteractionCreate) {
+		response := s.handleInteraction(ctx, event.Interaction)
+		if response == nil {
+			return
+		}
+		err := session.InteractionRespond(event.Interaction, response)
+		if err != nil {
+			log.Printf("Error responding to interaction %s: %s", event.ID, err.Error())
+		}
+	})
+
+	err = s.Session.Open()
+	if err != nil {
+		return fmt.Errorf("error opening Discord session: %w", err)
+	}
+	defer func() {
+		closeErr := s.Session.Close()
+		if closeErr != nil && err == nil {
+			err = fmt.Errorf("error closing Discord session: %w", closeErr)
+		}
+	}()
+
+	_, err = s.Session.ApplicationCommandBulkOverwrite(s.Session.State.User.ID, s.GuildID, Commands)
+	if err != nil {
+		return fmt.Errorf("error registering commands: %w", err)
+	}
+	fmt.Println("Connected to Discord.")
+
+	<-ctx.Done()
+	return nil
+}
+
+// handleInteraction returns the response to an interaction, or nil if there
+// is nothing to respond with
+func (s *Server) handleInteraction(ctx context.Context, i *discordgo.Interaction) *discordgo.InteractionResponse {
+	switch i.Type {
+	case discordgo.InteractionApplicationCommand:
+		data := i.ApplicationCommandData()
+		if data.Name != MTGCommand {
+			log.Printf("Unhandled command: %s", data.Name)
+			return nil
+		}
+		return s.handleMTGCommand(ctx, i)
+	case discordgo.InteractionMessageComponent:
+		return s.handleComponent(ctx, i)
+	case discordgo.InteractionApplicationCommandAutocomplete:
+		return s.handleAutocomplete(i)
+	default:
+		log.Printf("Unhandled interaction type: %s", i.Type)
+		return nil
+	}
+}
+
+// handleComponent dispatches a button click on its custom ID, which is the
+// action followed by its colon separated arguments
+func (s *Server) handleComponent(ctx context.Context, i *discordgo.Interaction) *discordgo.InteractionResponse {
+	customID := i.MessageComponentData().CustomID
+	action, args, _ := strings.Cut(customID, ":")
+	switch action {
+	case actionCardsPage:
+		return s.handleCardsPage(ctx, i, args)
+	case actionFillRequest:
+		return s.handleFillRequest(ctx, i, args)
+	case actionConfirmFill:
+		return s.handleConfirmFill(ctx, i, args)
+	case actionCloseTransfer, actionCancelTransfer:
+		return s.handleTransferButton(ctx, i, action, args)
+	case actionConfirmClose, actionConfirmCancel:
+		return s.handleConfirmTransfer(ctx, i, action, args)
+	case actionNeverMind:
+		return updateMessage("Okay, nothing changed.", nil)
+	default:
+		log.Printf("Unhandled component: %s", customID)
+		return nil
+	}
+}
+
+// interactionUser returns the user who triggered an interaction, who is a
+// guild member unless the interaction happened in a direct message
+func interactionUser(i *discordgo.Interaction) *discordgo.User {
+	if i.Member != nil && i.Member.User != nil {
+		return i.Member.User
+	}
+	if i.User != nil {
+		return i.User
+	}
+	return &discordgo.User{}
+}
+
+// reply returns a response that posts a new message, visible to everyone or
+// only to the user who triggered the interaction
+func reply(public bool, content string, components []discordgo.MessageComponent) *discordgo.InteractionResponse {
+	data := &discordgo.InteractionResponseData{
+		Content:         content,
+		Components:      components,
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	}
+	if !public {
+		data.Flags = discordgo.MessageFlagsEphemeral
+	}
+	return &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: data,
+	}
+}
+
+// updateMessage returns a response that replaces the message a button is on
+func updateMessage(content string, components []discordgo.MessageComponent) *discordgo.InteractionResponse {
+	if components == nil {
+		components = []discordgo.MessageComponent{}
+	}
+	return &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Content:         content,
+			Components:      components,
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		},
+	}
+}
+
+// buttons returns a row of buttons
+func buttons(buttons ...discordgo.Button) []discordgo.MessageComponent {
+	row := discordgo.ActionsRow{}
+	for _, button := range buttons {
+		row.Components = append(row.Components, button)
+	}
+	return []discordgo.MessageComponent{row}
+}
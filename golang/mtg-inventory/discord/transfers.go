@@ -0,0 +1,240 @@
+package discord
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/chat"
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// actionCloseTransfer is the action of the button to close a transfer
+	actionCloseTransfer = "close_transfer"
+
+	// actionCancelTransfer is the action of the button to cancel a transfer
+	actionCancelTransfer = "cancel_transfer"
+
+	// actionConfirmClose is the action of the button confirming a close
+	actionConfirmClose = "confirm_close"
+
+	// actionConfirmCancel is the action of the button confirming a cancel
+	actionConfirmCancel = "confirm_cancel"
+
+	// actionNeverMind is the action of the button backing out of a
+	// confirmation
+	actionNeverMind = "never_mind"
+)
+
+// openTransfer resolves a card list and opens a transfer of the cards
+// fromUser keeps to toUser
+func (s *Server) openTransfer(ctx context.Context, fromUser, toUser string, requestID *int64, text string) *discordgo.InteractionResponse {
+	if fromUser == toUser {
+		return reply(false, "You can't transfer cards to yourself.", nil)
+	}
+
+	entries, err := chat.ParseCardList(text)
+	if err != nil {
+		return reply(false, fmt.Sprintf("I couldn't read that list: %s.", err.Error()), nil)
+	}
+
+	requested, problems := chat.ResolveCardList(s.Scryfall, entries)
+	if len(problems) > 0 {
+		return reply(false, "I couldn't open that transfer:\n- "+strings.Join(problems, "\n- "), nil)
+	}
+
+	if requestID != nil {
+		_, err = s.Backend.GetRequestByID(ctx, *requestID, 1, 0)
+		if errors.Is(err, inventory.ErrRequestNoExist) {
+			return reply(false, fmt.Sprintf("Request #%d doesn't exist.", *requestID), nil)
+		} else if err != nil {
+			log.Printf("Error getting request %d: %s", *requestID, err.Error())
+			return reply(false, "Something went wrong looking up that request.", nil)
+		}
+	}
+
+	rows, problems, err := chat.AllocateTransfer(ctx, s.Backend, fromUser, requested)
+	if err != nil {
+		log.Printf("Error allocating transfer from %q: %s", fromUser, err.Error())
+		return reply(false, "Something went wrong looking up your cards.", nil)
+	}
+	if len(problems) > 0 {
+		return reply(false, "I couldn't open that transfer:\n- "+strings.Join(problems, "\n- "), nil)
+	}
+
+	return s.openAllocatedTransfer(ctx, fromUser, toUser, requestID, rows)
+}
+
+// openAllocatedTransfer opens a transfer of rows and announces it with
+// buttons to close or cancel it
+func (s *Server) openAllocatedTransfer(ctx context.Context, fromUser, toUser string, requestID *int64, rows []*inventory.TransferredCards) *discordgo.InteractionResponse {
+	transfer, err := s.Backend.OpenTransfer(ctx, toUser, fromUser, requestID, rows)
+	if errors.Is(err, inventory.ErrTooFewCards) {
+		return reply(false, "You aren't keeping enough of those cards anymore.", nil)
+	} else if errors.Is(err, inventory.ErrTooManyRows) {
+		return reply(false, fmt.Sprintf("You can transfer at most %d different cards at once.", inventory.RowUploadLimit), nil)
+	} else if err != nil {
+		log.Printf("Error opening transfer from %q to %q: %s", fromUser, toUser, err.Error())
+		return reply(false, "Something went wrong opening that transfer.", nil)
+	}
+
+	return reply(true, transferContent(transfer), buttons(
+		discordgo.Button{
+			Label:    "Received",
+			Style:    discordgo.SuccessButton,
+			CustomID: fmt.Sprintf("%s:%d", actionCloseTransfer, transfer.ID),
+		},
+		discordgo.Button{
+			Label:    "Cancel",
+			Style:    discordgo.SecondaryButton,
+			CustomID: fmt.Sprintf("%s:%d", actionCancelTransfer, transfer.ID),
+		},
+	))
+}
+
+// transferContent describes an open transfer and its cards
+func transferContent(transfer *inventory.Transfer) string {
+	var content strings.Builder
+	fmt.Fprintf(&content, "**%s** opened transfer #%d of %d cards to **%s**", transfer.FromUser, transfer.ID, transfer.Quantity, transfer.ToUser)
+	if transfer.RequestID != nil {
+		fmt.Fprintf(&content, " for request #%d", *transfer.RequestID)
+	}
+	content.WriteString(":")
+	for _, card := range transfer.Cards {
+		fmt.Fprintf(&content, "\n- %d %s", card.Quantity, card.Card.Name)
+		if description := chat.DescribeCopy(card.Card); description != "" {
+			fmt.Fprintf(&content, " (%s)", description)
+		}
+		if card.Owner != transfer.FromUser {
+			fmt.Fprintf(&content, ", owned by %s", card.Owner)
+		}
+	}
+	return content.String()
+}
+
+// confirmTransfer asks username to confirm closing or canceling a transfer.
+// Only the recipient can close a transfer, since closing it records that they
+// received the cards, while either side can cancel it.
+func (s *Server) confirmTransfer(ctx context.Context, username, action string, id int64) *discordgo.InteractionResponse {
+	transfer, err := s.Backend.GetTransferByID(ctx, id, inventory.MaxListLimit, 0)
+	if errors.Is(err, inventory.ErrTransferNoExist) {
+		return reply(false, fmt.Sprintf("Transfer #%d doesn't exist.", id), nil)
+	} else if err != nil {
+		log.Printf("Error getting transfer %d: %s", id, err.Error())
+		return reply(false, "Something went wrong looking up that transfer.", nil)
+	}
+	if transfer.Closed != nil {
+		return reply(false, fmt.Sprintf("Transfer #%d is already closed.", id), nil)
+	}
+
+	var prompt, label, confirmAction string
+	var style discordgo.ButtonStyle
+	switch action {
+	case actionCloseTransfer:
+		if username != transfer.ToUser {
+			return reply(false, fmt.Sprintf("Only %s can close transfer #%d.", transfer.ToUser, id), nil)
+		}
+		prompt = fmt.Sprintf("Did you receive the %d cards in transfer #%d from %s? Closing it moves them to you.", transfer.Quantity, id, transfer.FromUser)
+		label = "Close transfer"
+		style = discordgo.SuccessButton
+		confirmAction = actionConfirmClose
+	case actionCancelTransfer:
+		if username != transfer.ToUser && username != transfer.FromUser {
+			return reply(false, fmt.Sprintf("Only %s or %s can cancel transfer #%d.", transfer.FromUser, transfer.ToUser, id), nil)
+		}
+		prompt = fmt.Sprintf("Cancel transfer #%d of %d cards from %s to %s?", id, transfer.Quantity, transfer.FromUser, transfer.ToUser)
+		label = "Cancel transfer"
+		style = discordgo.DangerButton
+		confirmAction = actionConfirmCancel
+	default:
+		log.Printf("Unknown transfer action %q", action)
+		return reply(false, "Something went wrong.", nil)
+	}
+
+	return reply(false, prompt, buttons(
+		discordgo.Button{
+			Label:    label,
+			Style:    style,
+			CustomID: fmt.Sprintf("%s:%d", confirmAction, id),
+		},
+		discordgo.Button{
+			Label:    "Never mind",
+			Style:    discordgo.SecondaryButton,
+			CustomID: actionNeverMind,
+		},
+	))
+}
+
+// handleTransferButton asks the user who clicked a button on an announced
+// transfer to confirm closing or canceling it
+func (s *Server) handleTransferButton(ctx context.Context, i *discordgo.Interaction, action, args string) *discordgo.InteractionResponse {
+	id, err := strconv.ParseInt(args, 10, 64)
+	if err != nil {
+		log.Printf("Error parsing transfer ID %q: %s", args, err.Error())
+		return reply(false, "Something went wrong looking up that transfer.", nil)
+	}
+
+	username, err := s.username(ctx, interactionUser(i))
+	if err != nil {
+		log.Printf("Error getting username: %s", err.Error())
+		return reply(false, "Something went wrong looking you up.", nil)
+	}
+
+	return s.confirmTransfer(ctx, username, action, id)
+}
+
+// handleConfirmTransfer closes or cancels a transfer once the user confirms,
+// announcing it in the channel
+func (s *Server) handleConfirmTransfer(ctx context.Context, i *discordgo.Interaction, action, args string) *discordgo.InteractionResponse {
+	id, err := strconv.ParseInt(args, 10, 64)
+	if err != nil {
+		log.Printf("Error parsing transfer ID %q: %s", args, err.Error())
+		return updateMessage("Something went wrong looking up that transfer.", nil)
+	}
+
+	username, err := s.username(ctx, interactionUser(i))
+	if err != nil {
+		log.Printf("Error getting username: %s", err.Error())
+		return updateMessage("Something went wrong looking you up.", nil)
+	}
+
+	// Check again, since the transfer may have changed since the prompt
+	transfer, err := s.Backend.GetTransferByID(ctx, id, 1, 0)
+	if errors.Is(err, inventory.ErrTransferNoExist) {
+		return updateMessage(fmt.Sprintf("Transfer #%d doesn't exist.", id), nil)
+	} else if err != nil {
+		log.Printf("Error getting transfer %d: %s", id, err.Error())
+		return updateMessage("Something went wrong looking up that transfer.", nil)
+	}
+
+	var announcement string
+	switch action {
+	case actionConfirmClose:
+		if username != transfer.ToUser {
+			return updateMessage(fmt.Sprintf("Only %s can close transfer #%d.", transfer.ToUser, id), nil)
+		}
+		err = s.Backend.CloseTransfer(ctx, id)
+		announcement = fmt.Sprintf("**%s** received transfer #%d of %d cards from **%s**.", username, id, transfer.Quantity, transfer.FromUser)
+	case actionConfirmCancel:
+		if username != transfer.ToUser && username != transfer.FromUser {
+			return updateMessage(fmt.Sprintf("Only %s or %s can cancel transfer #%d.", transfer.FromUser, transfer.ToUser, id), nil)
+		}
+		err = s.Backend.CancelTransfer(ctx, id)
+		announcement = fmt.Sprintf("**%s** canceled transfer #%d of %d cards from **%s** to **%s**.", username, id, transfer.Quantity, transfer.FromUser, transfer.ToUser)
+	}
+	if errors.Is(err, inventory.ErrTransferClosed) {
+		return updateMessage(fmt.Sprintf("Transfer #%d is already closed.", id), nil)
+	} else if errors.Is(err, inventory.ErrTooFewCards) {
+		return updateMessage(fmt.Sprintf("%s isn't keeping enough of the cards in transfer #%d anymore.", transfer.FromUser, id), nil)
+	} else if err != nil {
+		log.Printf("Error with %s of transfer %d: %s", action, id, err.Error())
+		return updateMessage("Something went wrong updating that transfer.", nil)
+	}
+
+	return reply(true, announcement, nil)
+}
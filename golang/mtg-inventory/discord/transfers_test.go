@@ -0,0 +1,166 @@
+package discord
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+)
+
+func TestMTGCommandTransfer(t *testing.T) {
+	s := newTestServer(t)
+	ctx := context.Background()
+
+	err := s.Backend.AddCards(ctx, []*inventory.CardRow{
+		{
+			Quantity: 4,
+			Card: &inventory.Card{
+				Name:       "Lightning Bolt",
+				OracleID:   "bolt-oracle",
+				ScryfallID: "bolt-m10",
+				Finish:     inventory.FinishNonfoil,
+				Condition:  inventory.ConditionNearMint,
+			},
+			Owner:  "bob",
+			Keeper: "bob",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to add cards: %s", err.Error())
+	}
+
+	content, _ := responseButtons(t, s.handleInteraction(ctx, command("1002", "transfer", userOption("to", "1001"), stringOption("cards", "5 Lightning Bolt"))))
+	if !strings.Contains(content, "only keeping 4 of the 5 Lightning Bolt") {
+		t.Fatalf("Unexpected response to transferring too many: %s", content)
+	}
+
+	response := s.handleInteraction(ctx, command("1002", "transfer", userOption("to", "1001"), stringOption("cards", "3 Lightning Bolt")))
+	content, customIDs := responseButtons(t, response)
+	if isEphemeral(response) {
+		t.Fatalf("Expected transfer to be announced in the channel")
+	}
+	if !strings.Contains(content, "**bob** opened transfer #") || !strings.Contains(content, "- 3 Lightning Bolt") {
+		t.Fatalf("Unexpected transfer: %s", content)
+	}
+	if len(customIDs) != 2 || !strings.HasPrefix(customIDs[0], actionCloseTransfer+":") || !strings.HasPrefix(customIDs[1], actionCancelTransfer+":") {
+		t.Fatalf("Expected close and cancel buttons, got: %v", customIDs)
+	}
+	closeID := customIDs[0]
+	confirmID := strings.Replace(closeID, actionCloseTransfer, actionConfirmClose, 1)
+
+	content, _ = responseButtons(t, s.handleInteraction(ctx, click("1002", closeID)))
+	if !strings.Contains(content, "Only alice can close") {
+		t.Fatalf("Expected sender to be refused: %s", content)
+	}
+	content, _ = responseButtons(t, s.handleInteraction(ctx, click("1002", confirmID)))
+	if !strings.Contains(content, "Only alice can close") {
+		t.Fatalf("Expected sender to be refused: %s", content)
+	}
+
+	response = s.handleInteraction(ctx, click("1001", closeID))
+	content, customIDs = responseButtons(t, response)
+	if !isEphemeral(response) || !strings.Contains(content, "Did you receive the 3 cards") {
+		t.Fatalf("Unexpected confirmation: %s", content)
+	}
+	if len(customIDs) != 2 || customIDs[0] != confirmID || customIDs[1] != actionNeverMind {
+		t.Fatalf("Expected confirm and never mind buttons, got: %v", customIDs)
+	}
+
+	content, _ = responseButtons(t, s.handleInteraction(ctx, click("1001", actionNeverMind)))
+	if content != "Okay, nothing changed." {
+		t.Fatalf("Unexpected response to never mind: %s", content)
+	}
+
+	content, _ = responseButtons(t, s.handleInteraction(ctx, click("1001", confirmID)))
+	if !strings.Contains(content, "**alice** received transfer") {
+		t.Fatalf("Unexpected response to close: %s", content)
+	}
+
+	rows, err := s.Backend.GetCardsByKeeper(ctx, "alice", inventory.MaxListLimit, 0)
+	if err != nil {
+		t.Fatalf("Failed to get cards: %s", err.Error())
+	}
+	if len(rows) != 1 || rows[0].Quantity != 3 || rows[0].Owner != "bob" {
+		t.Fatalf("Expected alice to keep 3 of bob's cards, got %+v", rows)
+	}
+
+	content, _ = responseButtons(t, s.handleInteraction(ctx, click("1001", confirmID)))
+	if !strings.Contains(content, "already closed") {
+		t.Fatalf("Unexpected response to closing twice: %s", content)
+	}
+}
+
+func TestMTGCommandCancelTransfer(t *testing.T) {
+	s := newTestServer(t)
+	ctx := context.Background()
+
+	_, err := s.Backend.AddUserIfNotExist(ctx, "carol")
+	if err != nil {
+		t.Fatalf("Failed to add user: %s", err.Error())
+	}
+	err = s.Backend.LinkIdentity(ctx, &inventory.Identity{
+		Provider:   IdentityProvider,
+		Workspace:  identityWorkspace,
+		ExternalID: "1003",
+		Username:   "carol",
+	})
+	if err != nil {
+		t.Fatalf("Failed to link carol: %s", err.Error())
+	}
+
+	err = s.Backend.AddCards(ctx, []*inventory.CardRow{
+		{
+			Quantity: 1,
+			Card: &inventory.Card{
+				Name:       "Lightning Bolt",
+				OracleID:   "bolt-oracle",
+				ScryfallID: "bolt-m10",
+				Finish:     inventory.FinishNonfoil,
+				Condition:  inventory.ConditionNearMint,
+			},
+			Owner:  "bob",
+			Keeper: "bob",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to add cards: %s", err.Error())
+	}
+
+	transfer, err := s.Backend.OpenTransfer(ctx, "alice", "bob", nil, []*inventory.TransferredCards{
+		{
+			Quantity: 1,
+			Card: &inventory.Card{
+				Name:       "Lightning Bolt",
+				OracleID:   "bolt-oracle",
+				ScryfallID: "bolt-m10",
+				Finish:     inventory.FinishNonfoil,
+				Condition:  inventory.ConditionNearMint,
+			},
+			Owner: "bob",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to open transfer: %s", err.Error())
+	}
+
+	content, _ := responseButtons(t, s.handleInteraction(ctx, command("1003", "cancel-transfer", intOption("id", transfer.ID))))
+	if !strings.Contains(content, "Only bob or alice can cancel") {
+		t.Fatalf("Expected bystander to be refused: %s", content)
+	}
+
+	content, customIDs := responseButtons(t, s.handleInteraction(ctx, command("1002", "cancel-transfer", intOption("id", transfer.ID))))
+	if !strings.Contains(content, "Cancel transfer #") || len(customIDs) != 2 {
+		t.Fatalf("Unexpected confirmation: %s %v", content, customIDs)
+	}
+
+	content, _ = responseButtons(t, s.handleInteraction(ctx, click("1002", customIDs[0])))
+	if !strings.Contains(content, "**bob** canceled transfer") {
+		t.Fatalf("Unexpected response to cancel: %s", content)
+	}
+
+	content, _ = responseButtons(t, s.handleInteraction(ctx, command("1002", "close-transfer", intOption("id", transfer.ID))))
+	if !strings.Contains(content, "doesn't exist") {
+		t.Fatalf("Expected canceled transfer to be gone: %s", content)
+	}
+}
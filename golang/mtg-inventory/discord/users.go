@@ -0,0 +1,48 @@
+package discord
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/chat"
+	"github.com/bwmarrin/discordgo"
+)
+
+// IdentityProvider is the provider of the identities linked by the Discord bot
+const IdentityProvider = "discord"
+
+// identityWorkspace is the workspace of every Discord identity, since Discord
+// user IDs are the same in every guild
+const identityWorkspace = ""
+
+// username returns the username linked to a Discord user, creating and
+// linking a User on their first interaction
+func (s *Server) username(ctx context.Context, user *discordgo.User) (string, error) {
+	return chat.Username(ctx, s.Backend, IdentityProvider, identityWorkspace, user.ID, user.Username)
+}
+
+// linkUser handles /mtg link, which lets an admin link a Discord user to an
+// existing username
+func (s *Server) linkUser(ctx context.Context, admin *discordgo.User, username string, user *discordgo.User) *discordgo.InteractionResponse {
+	if !s.Admins[admin.ID] {
+		return reply(false, "Only admins can link accounts.", nil)
+	}
+
+	err := s.Backend.LinkIdentity(ctx, &inventory.Identity{
+		Provider:   IdentityProvider,
+		Workspace:  identityWorkspace,
+		ExternalID: user.ID,
+		Username:   username,
+	})
+	if errors.Is(err, inventory.ErrUserNoExist) {
+		return reply(false, fmt.Sprintf("There is no user named %q.", username), nil)
+	} else if err != nil {
+		log.Printf("Error linking %s to %q: %s", user.ID, username, err.Error())
+		return reply(false, "Something went wrong linking that account.", nil)
+	}
+
+	return reply(false, fmt.Sprintf("Linked <@%s> to %s.", user.ID, username), nil)
+}
@@ -9,6 +9,14 @@ var (
 	// ErrUserNoExist is the error returned when a user does not exist
 	ErrUserNoExist = errors.New("user does not exist")
 
+	// ErrUserExists is the error returned when a user that already exists
+	// is added with AddUser
+	ErrUserExists = errors.New("user already exists")
+
+	// ErrIdentityNoExist is the error returned when an identity is not
+	// linked to a user
+	ErrIdentityNoExist = errors.New("identity does not exist")
+
 	// ErrRequestNoExist is the error returned when a request does not
 	// exist
 	ErrRequestNoExist = errors.New("request does not exist")
@@ -17,6 +25,12 @@ var (
 	// exist
 	ErrTransferNoExist = errors.New("transfer does not exist")
 
+	// ErrDeckNoExist is the error returned when a deck does not exist
+	ErrDeckNoExist = errors.New("deck does not exist")
+
+	// ErrNoDeckName is returned when a deck is given an empty name
+	ErrNoDeckName = errors.New("deck has no name")
+
 	// ErrTooManyRows is returned when too many rows are submitted
 	ErrTooManyRows = fmt.Errorf("more than %d rows", RowUploadLimit)
 
@@ -24,10 +38,45 @@ var (
 	// fewer cards
 	ErrZeroCards = errors.New("zero cards")
 
+	// ErrNoCard is returned when an uploaded row has no card
+	ErrNoCard = errors.New("no card")
+
 	// ErrTooFewCards is returned when there are not enough cards to
-	// complete a transfer
+	// complete a transfer or fill a deck
 	ErrTooFewCards = errors.New("too few cards")
 
+	// ErrTransferClosed is returned when a transfer that has already been
+	// closed is closed or canceled
+	ErrTransferClosed = errors.New("transfer is closed")
+
+	// ErrInvalidFinish is returned when a card has a finish that is not
+	// one of Finishes
+	ErrInvalidFinish = errors.New("invalid finish")
+
+	// ErrInvalidCondition is returned when a card has a condition that is
+	// not one of Conditions
+	ErrInvalidCondition = errors.New("invalid condition")
+
+	// ErrInvalidZone is returned when a card in a deck is in a zone that
+	// is not one of Zones
+	ErrInvalidZone = errors.New("invalid zone")
+
+	// ErrUnknownCard is returned when a submitted card does not exist in
+	// Scryfall
+	ErrUnknownCard = errors.New("card does not exist")
+
+	// ErrOracleIDMismatch is returned when the oracle ID of a submitted
+	// card is not the oracle ID of its printing
+	ErrOracleIDMismatch = errors.New("oracle ID does not match card")
+
+	// ErrNameMismatch is returned when the name of a submitted card is not
+	// the name of its printing or oracle ID
+	ErrNameMismatch = errors.New("name does not match card")
+
+	// ErrFinishUnavailable is returned when a submitted card has a finish
+	// its printing was not printed in
+	ErrFinishUnavailable = errors.New("card is not printed in that finish")
+
 	// ErrUnimplemented is returned when a function is not implemented
 	ErrUnimplemented = errors.New("unimplemented")
 )
@@ -0,0 +1,211 @@
+package http
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/importer"
+)
+
+// ModifyCardQuantityBody is the body of a request to modify the quantity of a
+// card row
+type ModifyCardQuantityBody struct {
+	Owner      string              `json:"owner"`
+	Keeper     string              `json:"keeper"`
+	ScryfallID string              `json:"scryfall_id"`
+	Finish     inventory.Finish    `json:"finish"`
+	Condition  inventory.Condition `json:"condition"`
+	Language   string              `json:"language,omitempty"`
+	Quantity   uint                `json:"quantity"`
+}
+
+func (s *Server) getCardsByOracleID(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := getLimitAndOffset(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	cardRows, err := s.Backend.GetCardsByOracleID(r.Context(), r.PathValue("oracleID"), limit, offset)
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, cardRows)
+}
+
+func (s *Server) getCardsByOwner(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := getLimitAndOffset(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	cardRows, err := s.Backend.GetCardsByOwner(r.Context(), r.PathValue("owner"), limit, offset)
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, cardRows)
+}
+
+func (s *Server) getCardsByKeeper(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := getLimitAndOffset(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	cardRows, err := s.Backend.GetCardsByKeeper(r.Context(), r.PathValue("keeper"), limit, offset)
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, cardRows)
+}
+
+func (s *Server) addCards(w http.ResponseWriter, r *http.Request) {
+	var cardRows []*inventory.CardRow
+	err := readJSON(w, r, &cardRows)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	ok := checkRows(w, cardRows, func(row *inventory.CardRow) error {
+		return checkCard(row.Quantity, row.Card)
+	})
+	if !ok {
+		return
+	}
+
+	err = s.Backend.AddCards(r.Context(), cardRows)
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) modifyCardQuantity(w http.ResponseWriter, r *http.Request) {
+	var body ModifyCardQuantityBody
+	err := readJSON(w, r, &body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	err = s.Backend.ModifyCardQuantity(r.Context(), body.Owner, body.Keeper, &inventory.Card{
+		ScryfallID: body.ScryfallID,
+		Finish:     body.Finish,
+		Condition:  body.Condition,
+		Language:   body.Language,
+	}, body.Quantity)
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// importCards adds the cards in a decklist or CSV file in the body for the
+// owner and keeper in the query, and responds with the rows added
+func (s *Server) importCards(w http.ResponseWriter, r *http.Request) {
+	if s.Scryfall == nil {
+		writeError(w, http.StatusNotImplemented, fmt.Errorf("importing cards: %w", inventory.ErrUnimplemented))
+		return
+	}
+	query := r.URL.Query()
+	owner, keeper, format := query.Get("owner"), query.Get("keeper"), query.Get("format")
+	if owner == "" {
+		writeError(w, http.StatusBadRequest, errors.New("missing owner"))
+		return
+	}
+	if keeper == "" {
+		keeper = owner
+	}
+	if format == "" {
+		format = importer.FormatDecklist
+	}
+
+	lines, err := importer.Read(http.MaxBytesReader(w, r.Body, MaxBodyBytes), format)
+	var rowErr *inventory.RowError
+	if errors.As(err, &rowErr) {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("error reading %s: %w", format, err))
+		return
+	}
+
+	cardRows, err := importer.NewImporter(s.Backend, s.Scryfall).Import(r.Context(), lines, owner, keeper)
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, cardRows)
+}
+
+// exportCards responds with the cards of the owner or keeper in the query as
+// a CSV file in the format in the query
+func (s *Server) exportCards(w http.ResponseWriter, r *http.Request) {
+	if s.Scryfall == nil {
+		writeError(w, http.StatusNotImplemented, fmt.Errorf("exporting cards: %w", inventory.ErrUnimplemented))
+		return
+	}
+	query := r.URL.Query()
+	owner, keeper, formatName := query.Get("owner"), query.Get("keeper"), query.Get("format")
+	if (owner == "") == (keeper == "") {
+		writeError(w, http.StatusBadRequest, errors.New("exactly one of owner and keeper is required"))
+		return
+	}
+	if formatName == "" {
+		formatName = importer.Moxfield.Name
+	}
+	format, err := importer.ParseExportFormat(formatName)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var cardRows []*inventory.CardRow
+	if owner != "" {
+		cardRows, err = inventory.ListAll(r.Context(), s.Backend.GetCardsByOwner, owner)
+	} else {
+		cardRows, err = inventory.ListAll(r.Context(), s.Backend.GetCardsByKeeper, keeper)
+	}
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+
+	writeExport(w, owner+keeper+"-"+format, format, s.Scryfall, cardRows)
+}
+
+// writeExport responds with rows as a file in the named format. The file is
+// written in full before it's sent, so that an error partway through is
+// responded with instead of a truncated file.
+func writeExport(w http.ResponseWriter, basename, formatName string, sf inventory.Scryfall, rows []*inventory.CardRow) {
+	var file bytes.Buffer
+	err := importer.Write(&file, formatName, sf, rows)
+	if err != nil {
+		writeBackendError(w, fmt.Errorf("error exporting cards: %w", err))
+		return
+	}
+
+	w.Header().Set("Content-Type", importer.ContentType(formatName))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", basename+importer.Extension(formatName)))
+	_, err = file.WriteTo(w)
+	if err != nil {
+		log.Printf("Error writing export: %s", err.Error())
+	}
+}
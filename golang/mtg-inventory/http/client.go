@@ -0,0 +1,452 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+)
+
+// ResponseError is returned by a Client when the Server responds with an error
+type ResponseError struct {
+	StatusCode int
+	Message    string
+
+	// Err is the Backend error the message describes, if it is known
+	Err error
+}
+
+// Error returns a string form of the error
+func (re *ResponseError) Error() string {
+	return re.Message
+}
+
+// Unwrap returns the Backend error the message describes
+func (re *ResponseError) Unwrap() error {
+	return re.Err
+}
+
+// Client implements inventory.Backend by calling a Server
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+
+	// Token is the bearer token sent with every request, if it isn't empty
+	Token string
+}
+
+// NewClient returns a new Client for the Server at baseURL
+func NewClient(baseURL string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		HTTPClient: http.DefaultClient,
+	}
+}
+
+// do sends a request with body encoded as JSON, if it isn't nil, and decodes
+// the response into out, if it isn't nil. If the Server rejects one of rows,
+// the returned RowError points to it.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out any, rows []any) error {
+	u := c.BaseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("error encoding request body: %w", err)
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, reader)
+	if err != nil {
+		return fmt.Errorf("error creating request to %s %s: %w", method, path, err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending request to %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return responseError(resp, rows)
+	}
+
+	if out == nil {
+		return nil
+	}
+	err = json.NewDecoder(resp.Body).Decode(out)
+	if err != nil {
+		return fmt.Errorf("error decoding response from %s %s: %w", method, path, err)
+	}
+	return nil
+}
+
+// responseError decodes the error in a response
+func responseError(resp *http.Response, rows []any) error {
+	var httpErr struct {
+		Error string          `json:"error"`
+		Code  string          `json:"code"`
+		Row   json.RawMessage `json:"row"`
+	}
+	err := json.NewDecoder(resp.Body).Decode(&httpErr)
+	if err != nil || httpErr.Error == "" {
+		return &ResponseError{
+			StatusCode: resp.StatusCode,
+			Message:    fmt.Sprintf("unexpected status %d", resp.StatusCode),
+		}
+	}
+
+	respErr := &ResponseError{
+		StatusCode: resp.StatusCode,
+		Message:    httpErr.Error,
+	}
+	if httpErr.Code != "" {
+		for _, backendErr := range backendErrors {
+			if backendErr.Code == httpErr.Code {
+				respErr.Err = backendErr.Err
+				break
+			}
+		}
+	}
+
+	if len(httpErr.Row) == 0 {
+		return respErr
+	}
+	return &inventory.RowError{
+		Err: respErr,
+		Row: findRow(httpErr.Row, rows),
+	}
+}
+
+// findRow returns the row that encodes to the same JSON as raw, or the decoded
+// JSON if there is none
+func findRow(raw json.RawMessage, rows []any) any {
+	var compact bytes.Buffer
+	err := json.Compact(&compact, raw)
+	if err == nil {
+		for _, row := range rows {
+			encoded, err := json.Marshal(row)
+			if err == nil && bytes.Equal(encoded, compact.Bytes()) {
+				return row
+			}
+		}
+	}
+
+	var decoded any
+	err = json.Unmarshal(raw, &decoded)
+	if err != nil {
+		return raw
+	}
+	return decoded
+}
+
+func pageQuery(limit, offset uint) url.Values {
+	query := url.Values{}
+	if limit > 0 {
+		query.Set("limit", strconv.FormatUint(uint64(limit), 10))
+	}
+	if offset > 0 {
+		query.Set("offset", strconv.FormatUint(uint64(offset), 10))
+	}
+	return query
+}
+
+func idPath(prefix string, id int64) string {
+	return prefix + strconv.FormatInt(id, 10)
+}
+
+func anyRows[T any](rows []T) []any {
+	converted := make([]any, 0, len(rows))
+	for _, row := range rows {
+		converted = append(converted, row)
+	}
+	return converted
+}
+
+// GetCardsByOracleID implements inventory.Backend
+func (c *Client) GetCardsByOracleID(ctx context.Context, oracleID string, limit, offset uint) ([]*inventory.CardRow, error) {
+	var cardRows []*inventory.CardRow
+	err := c.do(ctx, http.MethodGet, "/cards/by-oracle-id/"+url.PathEscape(oracleID), pageQuery(limit, offset), nil, &cardRows, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error getting cards by oracle ID %q: %w", oracleID, err)
+	}
+	return cardRows, nil
+}
+
+// GetCardsByOwner implements inventory.Backend
+func (c *Client) GetCardsByOwner(ctx context.Context, owner string, limit, offset uint) ([]*inventory.CardRow, error) {
+	var cardRows []*inventory.CardRow
+	err := c.do(ctx, http.MethodGet, "/cards/by-owner/"+url.PathEscape(owner), pageQuery(limit, offset), nil, &cardRows, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error getting cards by owner %q: %w", owner, err)
+	}
+	return cardRows, nil
+}
+
+// GetCardsByKeeper implements inventory.Backend
+func (c *Client) GetCardsByKeeper(ctx context.Context, keeper string, limit, offset uint) ([]*inventory.CardRow, error) {
+	var cardRows []*inventory.CardRow
+	err := c.do(ctx, http.MethodGet, "/cards/by-keeper/"+url.PathEscape(keeper), pageQuery(limit, offset), nil, &cardRows, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error getting cards by keeper %q: %w", keeper, err)
+	}
+	return cardRows, nil
+}
+
+// AddCards implements inventory.Backend
+func (c *Client) AddCards(ctx context.Context, cardRows []*inventory.CardRow) error {
+	err := c.do(ctx, http.MethodPost, "/cards", nil, cardRows, nil, anyRows(cardRows))
+	if err != nil {
+		return fmt.Errorf("error adding cards: %w", err)
+	}
+	return nil
+}
+
+// ModifyCardQuantity implements inventory.Backend
+func (c *Client) ModifyCardQuantity(ctx context.Context, owner, keeper string, card *inventory.Card, quantity uint) error {
+	err := c.do(ctx, http.MethodPut, "/cards/quantity", nil, &ModifyCardQuantityBody{
+		Owner:      owner,
+		Keeper:     keeper,
+		ScryfallID: card.ScryfallID,
+		Finish:     card.Finish,
+		Condition:  card.Condition,
+		Language:   card.Language,
+		Quantity:   quantity,
+	}, nil, nil)
+	if err != nil {
+		return fmt.Errorf("error modifying quantity of %q: %w", card.ScryfallID, err)
+	}
+	return nil
+}
+
+// GetRequestsByRequestor implements inventory.Backend
+func (c *Client) GetRequestsByRequestor(ctx context.Context, requestor string, limit, offset uint) ([]*inventory.Request, error) {
+	var requests []*inventory.Request
+	err := c.do(ctx, http.MethodGet, "/requests/by-requestor/"+url.PathEscape(requestor), pageQuery(limit, offset), nil, &requests, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error getting requests by requestor %q: %w", requestor, err)
+	}
+	return requests, nil
+}
+
+// GetRequestByID implements inventory.Backend
+func (c *Client) GetRequestByID(ctx context.Context, id int64, limit, offset uint) (*inventory.Request, error) {
+	var request inventory.Request
+	err := c.do(ctx, http.MethodGet, idPath("/requests/", id), pageQuery(limit, offset), nil, &request, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error getting request %d: %w", id, err)
+	}
+	return &request, nil
+}
+
+// OpenRequest implements inventory.Backend
+func (c *Client) OpenRequest(ctx context.Context, requestor string, rows []*inventory.RequestedCards) (*inventory.Request, error) {
+	var request inventory.Request
+	err := c.do(ctx, http.MethodPost, "/requests", nil, &OpenRequestBody{
+		Requestor: requestor,
+		Cards:     rows,
+	}, &request, anyRows(rows))
+	if err != nil {
+		return nil, fmt.Errorf("error opening request for %q: %w", requestor, err)
+	}
+	return &request, nil
+}
+
+// CloseRequest implements inventory.Backend
+func (c *Client) CloseRequest(ctx context.Context, id int64) error {
+	err := c.do(ctx, http.MethodPost, idPath("/requests/", id)+"/close", nil, nil, nil, nil)
+	if err != nil {
+		return fmt.Errorf("error closing request %d: %w", id, err)
+	}
+	return nil
+}
+
+// GetTransfersByToUser implements inventory.Backend
+func (c *Client) GetTransfersByToUser(ctx context.Context, toUser string, limit, offset uint) ([]*inventory.Transfer, error) {
+	var transfers []*inventory.Transfer
+	err := c.do(ctx, http.MethodGet, "/transfers/by-to-user/"+url.PathEscape(toUser), pageQuery(limit, offset), nil, &transfers, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error getting transfers to %q: %w", toUser, err)
+	}
+	return transfers, nil
+}
+
+// GetTransfersByFromUser implements inventory.Backend
+func (c *Client) GetTransfersByFromUser(ctx context.Context, fromUser string, limit, offset uint) ([]*inventory.Transfer, error) {
+	var transfers []*inventory.Transfer
+	err := c.do(ctx, http.MethodGet, "/transfers/by-from-user/"+url.PathEscape(fromUser), pageQuery(limit, offset), nil, &transfers, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error getting transfers from %q: %w", fromUser, err)
+	}
+	return transfers, nil
+}
+
+// GetTransfersByRequestID implements inventory.Backend
+func (c *Client) GetTransfersByRequestID(ctx context.Context, requestID int64, limit, offset uint) ([]*inventory.Transfer, error) {
+	var transfers []*inventory.Transfer
+	err := c.do(ctx, http.MethodGet, idPath("/transfers/by-request-id/", requestID), pageQuery(limit, offset), nil, &transfers, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error getting transfers for request %d: %w", requestID, err)
+	}
+	return transfers, nil
+}
+
+// GetTransferByID implements inventory.Backend
+func (c *Client) GetTransferByID(ctx context.Context, id int64, limit, offset uint) (*inventory.Transfer, error) {
+	var transfer inventory.Transfer
+	err := c.do(ctx, http.MethodGet, idPath("/transfers/", id), pageQuery(limit, offset), nil, &transfer, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error getting transfer %d: %w", id, err)
+	}
+	return &transfer, nil
+}
+
+// OpenTransfer implements inventory.Backend
+func (c *Client) OpenTransfer(ctx context.Context, toUser, fromUser string, request *int64, rows []*inventory.TransferredCards) (*inventory.Transfer, error) {
+	var transfer inventory.Transfer
+	err := c.do(ctx, http.MethodPost, "/transfers", nil, &OpenTransferBody{
+		ToUser:    toUser,
+		FromUser:  fromUser,
+		RequestID: request,
+		Cards:     rows,
+	}, &transfer, anyRows(rows))
+	if err != nil {
+		return nil, fmt.Errorf("error opening transfer from %q to %q: %w", fromUser, toUser, err)
+	}
+	return &transfer, nil
+}
+
+// CloseTransfer implements inventory.Backend
+func (c *Client) CloseTransfer(ctx context.Context, id int64) error {
+	err := c.do(ctx, http.MethodPost, idPath("/transfers/", id)+"/close", nil, nil, nil, nil)
+	if err != nil {
+		return fmt.Errorf("error closing transfer %d: %w", id, err)
+	}
+	return nil
+}
+
+// CancelTransfer implements inventory.Backend
+func (c *Client) CancelTransfer(ctx context.Context, id int64) error {
+	err := c.do(ctx, http.MethodDelete, idPath("/transfers/", id), nil, nil, nil, nil)
+	if err != nil {
+		return fmt.Errorf("error canceling transfer %d: %w", id, err)
+	}
+	return nil
+}
+
+// GetDecksByOwner implements inventory.Backend
+func (c *Client) GetDecksByOwner(ctx context.Context, owner string, limit, offset uint) ([]*inventory.Deck, error) {
+	var decks []*inventory.Deck
+	err := c.do(ctx, http.MethodGet, "/decks/by-owner/"+url.PathEscape(owner), pageQuery(limit, offset), nil, &decks, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error getting decks of %q: %w", owner, err)
+	}
+	return decks, nil
+}
+
+// GetDeckByID implements inventory.Backend
+func (c *Client) GetDeckByID(ctx context.Context, id int64, limit, offset uint) (*inventory.Deck, error) {
+	var deck inventory.Deck
+	err := c.do(ctx, http.MethodGet, idPath("/decks/", id), pageQuery(limit, offset), nil, &deck, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error getting deck %d: %w", id, err)
+	}
+	return &deck, nil
+}
+
+// CreateDeck implements inventory.Backend
+func (c *Client) CreateDeck(ctx context.Context, owner, name string, rows []*inventory.DeckCards) (*inventory.Deck, error) {
+	var deck inventory.Deck
+	err := c.do(ctx, http.MethodPost, "/decks", nil, &CreateDeckBody{
+		Owner: owner,
+		Name:  name,
+		Cards: rows,
+	}, &deck, anyRows(rows))
+	if err != nil {
+		return nil, fmt.Errorf("error creating deck %q of %q: %w", name, owner, err)
+	}
+	return &deck, nil
+}
+
+// UpdateDeck implements inventory.Backend
+func (c *Client) UpdateDeck(ctx context.Context, id int64, name string, rows []*inventory.DeckCards) error {
+	err := c.do(ctx, http.MethodPut, idPath("/decks/", id), nil, &UpdateDeckBody{
+		Name:  name,
+		Cards: rows,
+	}, nil, anyRows(rows))
+	if err != nil {
+		return fmt.Errorf("error updating deck %d: %w", id, err)
+	}
+	return nil
+}
+
+// GetUserByUsername implements inventory.Backend
+func (c *Client) GetUserByUsername(ctx context.Context, username string) (*inventory.User, error) {
+	var user inventory.User
+	err := c.do(ctx, http.MethodGet, "/users/"+url.PathEscape(username), nil, nil, &user, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error getting user %q: %w", username, err)
+	}
+	return &user, nil
+}
+
+// AddUser implements inventory.Backend
+func (c *Client) AddUser(ctx context.Context, username string) (*inventory.User, error) {
+	var user inventory.User
+	err := c.do(ctx, http.MethodPost, "/users/new", nil, &AddUserBody{Username: username}, &user, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error adding user %q: %w", username, err)
+	}
+	return &user, nil
+}
+
+// AddUserIfNotExist implements inventory.Backend
+func (c *Client) AddUserIfNotExist(ctx context.Context, username string) (*inventory.User, error) {
+	var user inventory.User
+	err := c.do(ctx, http.MethodPost, "/users", nil, &AddUserBody{Username: username}, &user, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error adding user %q: %w", username, err)
+	}
+	return &user, nil
+}
+
+// GetUserByIdentity implements inventory.Backend
+func (c *Client) GetUserByIdentity(ctx context.Context, provider, workspace, externalID string) (*inventory.User, error) {
+	var user inventory.User
+	err := c.do(ctx, http.MethodGet, "/identities", url.Values{
+		"provider":    {provider},
+		"workspace":   {workspace},
+		"external_id": {externalID},
+	}, nil, &user, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error getting user for %s/%s/%s: %w", provider, workspace, externalID, err)
+	}
+	return &user, nil
+}
+
+// LinkIdentity implements inventory.Backend
+func (c *Client) LinkIdentity(ctx context.Context, identity *inventory.Identity) error {
+	err := c.do(ctx, http.MethodPut, "/identities", nil, identity, nil, nil)
+	if err != nil {
+		return fmt.Errorf("error linking %s/%s/%s to %q: %w", identity.Provider, identity.Workspace, identity.ExternalID, identity.Username, err)
+	}
+	return nil
+}
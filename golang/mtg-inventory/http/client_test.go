@@ -0,0 +1,73 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/backends/backendtest"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/backends/memory"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/backends/validate"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/scryfall"
+)
+
+func TestClient(t *testing.T) {
+	server := httptest.NewServer(NewServer(memory.NewBackend()))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	backendtest.RunConformance(t, func() inventory.Backend {
+		return client
+	})
+}
+
+func TestClientValidationErrors(t *testing.T) {
+	sf, err := scryfall.NewJSONCache(strings.NewReader(`[{"object": "card", "id": "bolt-4ed", "lang": "en", "oracle_id": "bolt-oracle", "name": "Lightning Bolt", "collector_number": "208", "released_at": "1995-04-01", "set": "4ed", "finishes": ["nonfoil"]}]`))
+	if err != nil {
+		t.Fatalf("Error loading JSON cache: %s", err.Error())
+	}
+	server := httptest.NewServer(NewServer(validate.NewBackend(memory.NewBackend(), sf)))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	row := &inventory.CardRow{
+		Quantity: 1,
+		Card:     &inventory.Card{Name: "Lightning Bolt", OracleID: "bolt-oracle", ScryfallID: "bolt-4ed", Finish: inventory.FinishFoil, Condition: inventory.ConditionNearMint},
+		Owner:    "user1",
+		Keeper:   "user1",
+	}
+	err = client.AddCards(context.Background(), []*inventory.CardRow{row})
+	if !errors.Is(err, inventory.ErrFinishUnavailable) {
+		t.Fatalf("Expected error %q, got: %v", inventory.ErrFinishUnavailable, err)
+	}
+	var rowErr *inventory.RowError
+	if !errors.As(err, &rowErr) || rowErr.Row != row {
+		t.Fatalf("Expected RowError for row %+v, got: %v", row, err)
+	}
+}
+
+func TestClientErrorCodes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusConflict, &inventory.HTTPError{
+			Error: "transfer 7 was closed yesterday",
+			Code:  CodeFromError(inventory.ErrTransferClosed),
+		})
+	}))
+	defer server.Close()
+
+	err := NewClient(server.URL).CloseTransfer(context.Background(), 7)
+	if !errors.Is(err, inventory.ErrTransferClosed) {
+		t.Fatalf("Expected the code to be turned back into ErrTransferClosed, got: %v", err)
+	}
+
+	for _, backendErr := range backendErrors {
+		if CodeFromError(fmt.Errorf("wrapped: %w", backendErr.Err)) != backendErr.Code {
+			t.Errorf("Expected code %q for %v", backendErr.Code, backendErr.Err)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package http
+
+import (
+	"net/http"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/decks"
+)
+
+// CreateDeckBody is the body of a request to create a Deck
+type CreateDeckBody struct {
+	Owner string                 `json:"owner"`
+	Name  string                 `json:"name"`
+	Cards []*inventory.DeckCards `json:"cards"`
+}
+
+// UpdateDeckBody is the body of a request to update a Deck
+type UpdateDeckBody struct {
+	Name  string                 `json:"name"`
+	Cards []*inventory.DeckCards `json:"cards"`
+}
+
+// LendDeckBody is the body of a request to lend a Deck
+type LendDeckBody struct {
+	ToUser string `json:"to_user"`
+}
+
+// PlanBody is the body of a request to plan how to build a deck of Cards for
+// Requestor, and to accept the plan if Accept is set
+type PlanBody struct {
+	Requestor string                      `json:"requestor"`
+	Cards     []*inventory.RequestedCards `json:"cards"`
+	Accept    bool                        `json:"accept"`
+}
+
+func (s *Server) getDecksByOwner(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := getLimitAndOffset(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	decks, err := s.Backend.GetDecksByOwner(r.Context(), r.PathValue("owner"), limit, offset)
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, decks)
+}
+
+func (s *Server) getDeckByID(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDPathValue(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	limit, offset, err := getLimitAndOffset(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	deck, err := s.Backend.GetDeckByID(r.Context(), id, limit, offset)
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, deck)
+}
+
+func (s *Server) createDeck(w http.ResponseWriter, r *http.Request) {
+	var body CreateDeckBody
+	err := readJSON(w, r, &body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	ok := checkRows(w, body.Cards, func(row *inventory.DeckCards) error {
+		return checkCard(row.Quantity, row.Card)
+	})
+	if !ok {
+		return
+	}
+
+	deck, err := s.Backend.CreateDeck(r.Context(), body.Owner, body.Name, body.Cards)
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, deck)
+}
+
+func (s *Server) updateDeck(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDPathValue(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	var body UpdateDeckBody
+	err = readJSON(w, r, &body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	ok := checkRows(w, body.Cards, func(row *inventory.DeckCards) error {
+		return checkCard(row.Quantity, row.Card)
+	})
+	if !ok {
+		return
+	}
+
+	err = s.Backend.UpdateDeck(r.Context(), id, body.Name, body.Cards)
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) lendDeck(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDPathValue(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	var body LendDeckBody
+	err = readJSON(w, r, &body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	deck, err := decks.GetDeck(r.Context(), s.Backend, id)
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+	transfer, err := decks.LendDeck(r.Context(), s.Backend, deck, body.ToUser)
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, transfer)
+}
+
+func (s *Server) getCardsAway(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDPathValue(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	deck, err := decks.GetDeck(r.Context(), s.Backend, id)
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+	away, err := decks.CardsAway(r.Context(), s.Backend, deck)
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, away)
+}
+
+func (s *Server) planDeck(w http.ResponseWriter, r *http.Request) {
+	var body PlanBody
+	err := readJSON(w, r, &body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	ok := checkRows(w, body.Cards, checkRequested)
+	if !ok {
+		return
+	}
+
+	plan, err := decks.PlanDeck(r.Context(), s.Backend, body.Requestor, body.Cards)
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+	if !body.Accept {
+		writeJSON(w, http.StatusOK, plan)
+		return
+	}
+
+	accepted := &decks.AcceptedPlan{Plan: plan}
+	accepted.Request, accepted.Transfers, err = decks.AcceptPlan(r.Context(), s.Backend, plan)
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, accepted)
+}
@@ -0,0 +1,95 @@
+package http
+
+import (
+	"net/http"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+)
+
+// OpenRequestBody is the body of a request to open a Request
+type OpenRequestBody struct {
+	Requestor string                      `json:"requestor"`
+	Cards     []*inventory.RequestedCards `json:"cards"`
+}
+
+func (s *Server) getRequestsByRequestor(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := getLimitAndOffset(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	requests, err := s.Backend.GetRequestsByRequestor(r.Context(), r.PathValue("requestor"), limit, offset)
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, requests)
+}
+
+func (s *Server) getRequestByID(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDPathValue(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	limit, offset, err := getLimitAndOffset(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	request, err := s.Backend.GetRequestByID(r.Context(), id, limit, offset)
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, request)
+}
+
+// checkRequested checks that a row of a request asks for at least one card
+func checkRequested(row *inventory.RequestedCards) error {
+	if row.Quantity == 0 {
+		return inventory.ErrZeroCards
+	}
+	return nil
+}
+
+func (s *Server) openRequest(w http.ResponseWriter, r *http.Request) {
+	var body OpenRequestBody
+	err := readJSON(w, r, &body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	ok := checkRows(w, body.Cards, checkRequested)
+	if !ok {
+		return
+	}
+
+	request, err := s.Backend.OpenRequest(r.Context(), body.Requestor, body.Cards)
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, request)
+}
+
+func (s *Server) closeRequest(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDPathValue(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	err = s.Backend.CloseRequest(r.Context(), id)
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,281 @@
+/*
+Package http exposes a Backend as a JSON REST API.
+*/
+package http
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+)
+
+// MaxBodyBytes is the largest request body the Server will read
+const MaxBodyBytes = 1 << 20
+
+// Server serves the methods of a Backend as JSON endpoints
+type Server struct {
+	Backend inventory.Backend
+	Mux     *http.ServeMux
+
+	// Scryfall looks up the cards of imports and exports, which aren't
+	// served if it is nil
+	Scryfall inventory.Scryfall
+
+	// Token is the bearer token every request must be authorized with. The
+	// API is open to anyone who can reach it if it is empty.
+	Token string
+}
+
+// errUnauthorized is returned when a request doesn't carry the bearer token
+var errUnauthorized = errors.New("unauthorized")
+
+// NewServer returns a new Server with all of its routes registered
+func NewServer(backend inventory.Backend) *Server {
+	server := &Server{
+		Backend: backend,
+		Mux:     http.NewServeMux(),
+	}
+
+	server.Mux.HandleFunc("GET /cards/by-oracle-id/{oracleID}", server.getCardsByOracleID)
+	server.Mux.HandleFunc("GET /cards/by-owner/{owner}", server.getCardsByOwner)
+	server.Mux.HandleFunc("GET /cards/by-keeper/{keeper}", server.getCardsByKeeper)
+	server.Mux.HandleFunc("POST /cards", server.addCards)
+	server.Mux.HandleFunc("PUT /cards/quantity", server.modifyCardQuantity)
+	server.Mux.HandleFunc("POST /cards/import", server.importCards)
+	server.Mux.HandleFunc("GET /cards/export", server.exportCards)
+
+	server.Mux.HandleFunc("GET /requests/by-requestor/{requestor}", server.getRequestsByRequestor)
+	server.Mux.HandleFunc("GET /requests/{id}", server.getRequestByID)
+	server.Mux.HandleFunc("POST /requests", server.openRequest)
+	server.Mux.HandleFunc("POST /requests/{id}/close", server.closeRequest)
+
+	server.Mux.HandleFunc("GET /transfers/by-to-user/{toUser}", server.getTransfersByToUser)
+	server.Mux.HandleFunc("GET /transfers/by-from-user/{fromUser}", server.getTransfersByFromUser)
+	server.Mux.HandleFunc("GET /transfers/by-request-id/{requestID}", server.getTransfersByRequestID)
+	server.Mux.HandleFunc("GET /transfers/export/{id}", server.exportTransfer)
+	server.Mux.HandleFunc("GET /transfers/{id}", server.getTransferByID)
+	server.Mux.HandleFunc("POST /transfers", server.openTransfer)
+	server.Mux.HandleFunc("POST /transfers/{id}/close", server.closeTransfer)
+	server.Mux.HandleFunc("DELETE /transfers/{id}", server.cancelTransfer)
+
+	server.Mux.HandleFunc("GET /decks/by-owner/{owner}", server.getDecksByOwner)
+	server.Mux.HandleFunc("GET /decks/away/{id}", server.getCardsAway)
+	server.Mux.HandleFunc("GET /decks/{id}", server.getDeckByID)
+	server.Mux.HandleFunc("POST /decks", server.createDeck)
+	server.Mux.HandleFunc("PUT /decks/{id}", server.updateDeck)
+	server.Mux.HandleFunc("POST /decks/{id}/lend", server.lendDeck)
+
+	server.Mux.HandleFunc("POST /plans", server.planDeck)
+
+	server.Mux.HandleFunc("GET /users/{username}", server.getUserByUsername)
+	server.Mux.HandleFunc("POST /users", server.addUserIfNotExist)
+	server.Mux.HandleFunc("POST /users/new", server.addUser)
+
+	server.Mux.HandleFunc("GET /identities", server.getUserByIdentity)
+	server.Mux.HandleFunc("PUT /identities", server.linkIdentity)
+
+	return server
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.Token != "" && !s.authorized(r) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		writeError(w, http.StatusUnauthorized, errUnauthorized)
+		return
+	}
+	s.Mux.ServeHTTP(w, r)
+}
+
+// authorized returns whether r carries the Server's bearer token
+func (s *Server) authorized(r *http.Request) bool {
+	token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return found && subtle.ConstantTimeCompare([]byte(token), []byte(s.Token)) == 1
+}
+
+// StatusFromError returns the HTTP status code that best describes an error
+// returned by a Backend
+func StatusFromError(err error) int {
+	var rowErr *inventory.RowError
+	switch {
+	case errors.Is(err, inventory.ErrUserNoExist),
+		errors.Is(err, inventory.ErrIdentityNoExist),
+		errors.Is(err, inventory.ErrRequestNoExist),
+		errors.Is(err, inventory.ErrTransferNoExist),
+		errors.Is(err, inventory.ErrDeckNoExist):
+		return http.StatusNotFound
+	case errors.Is(err, inventory.ErrNoDeckName):
+		return http.StatusBadRequest
+	case errors.Is(err, inventory.ErrTooManyRows):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, inventory.ErrTransferClosed),
+		errors.Is(err, inventory.ErrUserExists):
+		return http.StatusConflict
+	case errors.As(err, &rowErr):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, inventory.ErrUnimplemented):
+		return http.StatusNotImplemented
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// backendErrors are the errors a Server may report, with the code that
+// identifies each in a response so that a Client can turn it back into an
+// error matching it with errors.Is. Codes must never change.
+var backendErrors = []struct {
+	Code string
+	Err  error
+}{
+	{"user_no_exist", inventory.ErrUserNoExist},
+	{"user_exists", inventory.ErrUserExists},
+	{"identity_no_exist", inventory.ErrIdentityNoExist},
+	{"request_no_exist", inventory.ErrRequestNoExist},
+	{"transfer_no_exist", inventory.ErrTransferNoExist},
+	{"deck_no_exist", inventory.ErrDeckNoExist},
+	{"no_deck_name", inventory.ErrNoDeckName},
+	{"too_many_rows", inventory.ErrTooManyRows},
+	{"zero_cards", inventory.ErrZeroCards},
+	{"no_card", inventory.ErrNoCard},
+	{"too_few_cards", inventory.ErrTooFewCards},
+	{"transfer_closed", inventory.ErrTransferClosed},
+	{"invalid_finish", inventory.ErrInvalidFinish},
+	{"invalid_condition", inventory.ErrInvalidCondition},
+	{"invalid_zone", inventory.ErrInvalidZone},
+	{"unknown_card", inventory.ErrUnknownCard},
+	{"oracle_id_mismatch", inventory.ErrOracleIDMismatch},
+	{"name_mismatch", inventory.ErrNameMismatch},
+	{"finish_unavailable", inventory.ErrFinishUnavailable},
+	{"unimplemented", inventory.ErrUnimplemented},
+}
+
+// CodeFromError returns the code that identifies an error returned by a
+// Backend, or an empty string if it isn't one of the errors with a code
+func CodeFromError(err error) string {
+	for _, backendErr := range backendErrors {
+		if errors.Is(err, backendErr.Err) {
+			return backendErr.Code
+		}
+	}
+	return ""
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	err := json.NewEncoder(w).Encode(v)
+	if err != nil {
+		log.Printf("Error encoding response: %s", err.Error())
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	httpErr := &inventory.HTTPError{
+		Error: err.Error(),
+		Code:  CodeFromError(err),
+	}
+	var rowErr *inventory.RowError
+	if errors.As(err, &rowErr) {
+		httpErr.Row = rowErr.Row
+	}
+	writeJSON(w, status, httpErr)
+}
+
+func writeBackendError(w http.ResponseWriter, err error) {
+	status := StatusFromError(err)
+	if status == http.StatusInternalServerError {
+		log.Printf("Error from backend: %s", err.Error())
+	}
+	writeError(w, status, err)
+}
+
+// errNullRow is returned when a row in a request body is null
+var errNullRow = errors.New("row is null")
+
+// checkRows rejects a request body with too many rows, or with a row that is
+// null or that check rejects, by writing an error response and returning
+// false, so that malformed rows never reach the Backend. Rejected rows are
+// responded to with the same status as a RowError from the Backend.
+func checkRows[T any](w http.ResponseWriter, rows []*T, check func(row *T) error) bool {
+	if len(rows) > inventory.RowUploadLimit {
+		writeError(w, http.StatusRequestEntityTooLarge, inventory.ErrTooManyRows)
+		return false
+	}
+	for i, row := range rows {
+		if row == nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("error checking row %d: %w", i, errNullRow))
+			return false
+		}
+		err := check(row)
+		if err != nil {
+			writeBackendError(w, &inventory.RowError{
+				Err: err,
+				Row: row,
+			})
+			return false
+		}
+	}
+	return true
+}
+
+// checkCard returns ErrNoCard or ErrZeroCards for a row without a card or
+// without any copies of it
+func checkCard(quantity uint, card *inventory.Card) error {
+	if card == nil {
+		return inventory.ErrNoCard
+	}
+	if quantity == 0 {
+		return inventory.ErrZeroCards
+	}
+	return nil
+}
+
+func readJSON(w http.ResponseWriter, r *http.Request, v any) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxBodyBytes))
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(v)
+	if err != nil {
+		return fmt.Errorf("error decoding request body: %w", err)
+	}
+	return nil
+}
+
+func getUintParam(r *http.Request, name string) (uint, error) {
+	str := r.URL.Query().Get(name)
+	if str == "" {
+		return 0, nil
+	}
+	value, err := strconv.ParseUint(str, 10, 0)
+	if err != nil {
+		return 0, fmt.Errorf("error parsing %s %q: %w", name, str, err)
+	}
+	return uint(value), nil
+}
+
+func getLimitAndOffset(r *http.Request) (limit, offset uint, err error) {
+	limit, err = getUintParam(r, "limit")
+	if err != nil {
+		return 0, 0, err
+	}
+	offset, err = getUintParam(r, "offset")
+	if err != nil {
+		return 0, 0, err
+	}
+	return limit, offset, nil
+}
+
+func getIDPathValue(r *http.Request, name string) (int64, error) {
+	str := r.PathValue(name)
+	id, err := strconv.ParseInt(str, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("error parsing %s %q: %w", name, str, err)
+	}
+	return id, nil
+}
@@ -0,0 +1,449 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/backends/memory"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/scryfall"
+)
+
+// stubBackend implements the few Backend methods exercised below and panics
+// on the rest through the embedded nil interface
+type stubBackend struct {
+	inventory.Backend
+}
+
+func (sb *stubBackend) GetCardsByOwner(_ context.Context, owner string, limit, offset uint) ([]*inventory.CardRow, error) {
+	return []*inventory.CardRow{
+		{
+			Quantity: limit + offset,
+			Card: &inventory.Card{
+				Name: "fake-card-name-1",
+			},
+			Owner:  owner,
+			Keeper: owner,
+		},
+	}, nil
+}
+
+func (sb *stubBackend) GetRequestByID(_ context.Context, id int64, _, _ uint) (*inventory.Request, error) {
+	if id != 1 {
+		return nil, fmt.Errorf("error getting request \"%d\": %w", id, inventory.ErrRequestNoExist)
+	}
+	return &inventory.Request{
+		ID: id,
+	}, nil
+}
+
+func (sb *stubBackend) AddCards(_ context.Context, rows []*inventory.CardRow) error {
+	if len(rows) > inventory.RowUploadLimit {
+		return inventory.ErrTooManyRows
+	}
+	for _, row := range rows {
+		if row.Quantity == 0 {
+			return &inventory.RowError{
+				Err: inventory.ErrZeroCards,
+				Row: row,
+			}
+		}
+	}
+	return nil
+}
+
+func TestServer(t *testing.T) {
+	server := httptest.NewServer(NewServer(&stubBackend{}))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/cards/by-owner/user1?limit=3&offset=4")
+	if err != nil {
+		t.Fatalf("Failed to get cards by owner: %s", err.Error())
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Unexpected status getting cards by owner: %d", resp.StatusCode)
+	}
+	var cardRows []*inventory.CardRow
+	err = json.NewDecoder(resp.Body).Decode(&cardRows)
+	if err != nil {
+		t.Fatalf("Failed to decode cards by owner: %s", err.Error())
+	}
+	if len(cardRows) != 1 || cardRows[0].Owner != "user1" || cardRows[0].Quantity != 7 {
+		t.Fatalf("Unexpected cards by owner: %+v", cardRows)
+	}
+
+	resp, err = http.Get(server.URL + "/cards/by-owner/user1?limit=-1")
+	if err != nil {
+		t.Fatalf("Failed to get cards by owner: %s", err.Error())
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Unexpected status getting cards with bad limit: %d", resp.StatusCode)
+	}
+
+	resp, err = http.Get(server.URL + "/requests/2")
+	if err != nil {
+		t.Fatalf("Failed to get request by ID: %s", err.Error())
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("Unexpected status getting missing request: %d", resp.StatusCode)
+	}
+	var httpErr inventory.HTTPError
+	err = json.NewDecoder(resp.Body).Decode(&httpErr)
+	if err != nil {
+		t.Fatalf("Failed to decode error: %s", err.Error())
+	}
+	if !strings.Contains(httpErr.Error, inventory.ErrRequestNoExist.Error()) {
+		t.Fatalf("Unexpected error getting missing request: %q", httpErr.Error)
+	}
+	if httpErr.Code != "request_no_exist" {
+		t.Fatalf("Unexpected code getting missing request: %q", httpErr.Code)
+	}
+
+	resp, err = http.Post(server.URL+"/cards", "application/json", strings.NewReader(`[{"quantity": 0, "owner": "user1", "keeper": "user1"}]`))
+	if err != nil {
+		t.Fatalf("Failed to add cards: %s", err.Error())
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("Unexpected status adding zero cards: %d", resp.StatusCode)
+	}
+	httpErr = inventory.HTTPError{}
+	err = json.NewDecoder(resp.Body).Decode(&httpErr)
+	if err != nil {
+		t.Fatalf("Failed to decode error: %s", err.Error())
+	}
+	if httpErr.Row == nil {
+		t.Fatalf("No row returned with row error")
+	}
+
+	for _, test := range []struct {
+		path   string
+		body   string
+		status int
+	}{
+		{"/cards", `[null]`, http.StatusBadRequest},
+		{"/cards", `[{"quantity": 1, "owner": "user1", "keeper": "user1"}]`, http.StatusUnprocessableEntity},
+		{"/transfers", `{"to_user": "user2", "from_user": "user1", "cards": [{"quantity": 1, "owner": "user1"}]}`, http.StatusUnprocessableEntity},
+		{"/transfers", `{"to_user": "user2", "from_user": "user1", "cards": [null]}`, http.StatusBadRequest},
+		{"/requests", `{"requestor": "user1", "cards": [{"name": "Lightning Bolt", "oracle_id": "bolt-oracle"}]}`, http.StatusUnprocessableEntity},
+		{"/decks", `{"owner": "user1", "name": "Burn", "cards": [{"zone": "main", "quantity": 1}]}`, http.StatusUnprocessableEntity},
+	} {
+		resp, err = http.Post(server.URL+test.path, "application/json", strings.NewReader(test.body))
+		if err != nil {
+			t.Fatalf("Failed to post to %s: %s", test.path, err.Error())
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != test.status {
+			t.Fatalf("Unexpected status posting %s to %s: %d", test.body, test.path, resp.StatusCode)
+		}
+	}
+
+	body := "[" + strings.Repeat(`{"quantity": 1},`, inventory.RowUploadLimit) + `{"quantity": 1}]`
+	resp, err = http.Post(server.URL+"/cards", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to add cards: %s", err.Error())
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Fatalf("Unexpected status adding too many cards: %d", resp.StatusCode)
+	}
+}
+
+func TestImportExport(t *testing.T) {
+	sf, err := scryfall.NewJSONCache(strings.NewReader(`[{"object": "card", "id": "bolt-4ed", "lang": "en", "oracle_id": "bolt-oracle", "name": "Lightning Bolt", "collector_number": "208", "released_at": "1995-04-01", "set": "4ed", "set_name": "Fourth Edition", "finishes": ["nonfoil"]}]`))
+	if err != nil {
+		t.Fatalf("Error loading JSON cache: %s", err.Error())
+	}
+	backend := memory.NewBackend()
+	_, err = backend.AddUserIfNotExist(context.Background(), "user1")
+	if err != nil {
+		t.Fatalf("Failed to add user: %s", err.Error())
+	}
+	handler := NewServer(backend)
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+"/cards/import?owner=user1", "text/plain", strings.NewReader("4 Lightning Bolt"))
+	if err != nil {
+		t.Fatalf("Failed to import cards: %s", err.Error())
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotImplemented {
+		t.Fatalf("Expected import without Scryfall to be unimplemented, got status %d", resp.StatusCode)
+	}
+
+	handler.Scryfall = sf
+	resp, err = http.Post(server.URL+"/cards/import?owner=user1&format=deckbox", "text/csv", strings.NewReader("Count,Name,Edition Code,Card Number,Condition,Foil\n3,Lightning Bolt,4ED,208,Played,\n"))
+	if err != nil {
+		t.Fatalf("Failed to import cards: %s", err.Error())
+	}
+	var cardRows []*inventory.CardRow
+	err = json.NewDecoder(resp.Body).Decode(&cardRows)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatalf("Failed to decode imported cards: %s", err.Error())
+	}
+	if resp.StatusCode != http.StatusOK || len(cardRows) != 1 || cardRows[0].Card.Condition != inventory.ConditionModeratelyPlayed {
+		t.Fatalf("Unexpected import with status %d: %+v", resp.StatusCode, cardRows)
+	}
+
+	resp, err = http.Post(server.URL+"/cards/import?owner=user1", "text/plain", strings.NewReader("4 Lightnig Bolt"))
+	if err != nil {
+		t.Fatalf("Failed to import cards: %s", err.Error())
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected import of a misspelled card to be unprocessable, got status %d", resp.StatusCode)
+	}
+
+	resp, err = http.Get(server.URL + "/cards/export?keeper=user1&format=tcgplayer")
+	if err != nil {
+		t.Fatalf("Failed to export cards: %s", err.Error())
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read export: %s", err.Error())
+	}
+	expected := "Quantity,Name,Simple Name,Set,Card Number,Set Code,Printing,Condition,Language,Rarity,Product ID,SKU\n3,Lightning Bolt,,Fourth Edition,208,4ed,Normal,Moderately Played,English,,,\n"
+	if resp.StatusCode != http.StatusOK || string(body) != expected {
+		t.Fatalf("Unexpected export with status %d: %s", resp.StatusCode, body)
+	}
+
+	_, err = backend.AddUserIfNotExist(context.Background(), "user2")
+	if err != nil {
+		t.Fatalf("Failed to add user: %s", err.Error())
+	}
+	transfer, err := backend.OpenTransfer(context.Background(), "user2", "user1", nil, []*inventory.TransferredCards{
+		{Quantity: 2, Card: cardRows[0].Card, Owner: "user1"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to open transfer: %s", err.Error())
+	}
+	resp, err = http.Get(fmt.Sprintf("%s/transfers/export/%d", server.URL, transfer.ID))
+	if err != nil {
+		t.Fatalf("Failed to export transfer: %s", err.Error())
+	}
+	defer resp.Body.Close()
+	body, err = io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read export: %s", err.Error())
+	}
+	if resp.StatusCode != http.StatusOK || string(body) != "Deck\n2 Lightning Bolt\n" {
+		t.Fatalf("Unexpected transfer export with status %d: %s", resp.StatusCode, body)
+	}
+	if disposition := resp.Header.Get("Content-Disposition"); disposition != fmt.Sprintf(`attachment; filename="transfer-%d.txt"`, transfer.ID) {
+		t.Errorf("Unexpected Content-Disposition %q", disposition)
+	}
+
+	handler.Scryfall = &failingScryfall{Scryfall: sf}
+	for _, path := range []string{"/cards/export?keeper=user1", fmt.Sprintf("/transfers/export/%d", transfer.ID)} {
+		resp, err = http.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("Failed to export: %s", err.Error())
+		}
+		var httpErr inventory.HTTPError
+		err = json.NewDecoder(resp.Body).Decode(&httpErr)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusInternalServerError || err != nil || resp.Header.Get("Content-Disposition") != "" {
+			t.Errorf("Expected a failed export of %s to be an error with status 500, got status %d: %v", path, resp.StatusCode, err)
+		}
+	}
+}
+
+// failingScryfall fails to get cards by ID, as if Scryfall were down
+type failingScryfall struct {
+	inventory.Scryfall
+}
+
+func (fs *failingScryfall) GetCardByID(string) (*inventory.ScryfallCard, error) {
+	return nil, errors.New("scryfall is down")
+}
+
+func TestDecks(t *testing.T) {
+	ctx := context.Background()
+	backend := memory.NewBackend()
+	for _, username := range []string{"alice", "bob"} {
+		_, err := backend.AddUserIfNotExist(ctx, username)
+		if err != nil {
+			t.Fatalf("Failed to add user %q: %s", username, err.Error())
+		}
+	}
+	bolt := &inventory.Card{Name: "Lightning Bolt", OracleID: "bolt-oracle", ScryfallID: "bolt-4ed", Finish: inventory.FinishNonfoil, Condition: inventory.ConditionNearMint}
+	err := backend.AddCards(ctx, []*inventory.CardRow{{Quantity: 4, Card: bolt, Owner: "alice", Keeper: "alice"}})
+	if err != nil {
+		t.Fatalf("Failed to add cards: %s", err.Error())
+	}
+	deck, err := backend.CreateDeck(ctx, "alice", "Burn", []*inventory.DeckCards{{Zone: inventory.ZoneMainboard, Quantity: 4, Card: bolt}})
+	if err != nil {
+		t.Fatalf("Failed to create deck: %s", err.Error())
+	}
+	server := httptest.NewServer(NewServer(backend))
+	defer server.Close()
+
+	lend := func() *http.Response {
+		t.Helper()
+		resp, err := http.Post(fmt.Sprintf("%s/decks/%d/lend", server.URL, deck.ID), "application/json", strings.NewReader(`{"to_user": "bob"}`))
+		if err != nil {
+			t.Fatalf("Failed to lend deck: %s", err.Error())
+		}
+		return resp
+	}
+	resp := lend()
+	var transfer inventory.Transfer
+	err = json.NewDecoder(resp.Body).Decode(&transfer)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatalf("Failed to decode transfer: %s", err.Error())
+	}
+	if resp.StatusCode != http.StatusCreated || transfer.ToUser != "bob" || len(transfer.Cards) != 1 || transfer.Cards[0].Quantity != 4 {
+		t.Fatalf("Unexpected lend with status %d: %+v", resp.StatusCode, transfer)
+	}
+	err = backend.CloseTransfer(ctx, transfer.ID)
+	if err != nil {
+		t.Fatalf("Failed to close transfer: %s", err.Error())
+	}
+
+	resp, err = http.Get(fmt.Sprintf("%s/decks/away/%d", server.URL, deck.ID))
+	if err != nil {
+		t.Fatalf("Failed to get cards away: %s", err.Error())
+	}
+	var away struct {
+		Away    []*inventory.CardRow `json:"away"`
+		Missing []*inventory.CardRow `json:"missing"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&away)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatalf("Failed to decode cards away: %s", err.Error())
+	}
+	if resp.StatusCode != http.StatusOK || len(away.Away) != 1 || away.Away[0].Keeper != "bob" || len(away.Missing) != 0 {
+		t.Fatalf("Unexpected cards away with status %d: %+v", resp.StatusCode, away)
+	}
+
+	resp = lend()
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected lending a deck that's away to be unprocessable, got status %d", resp.StatusCode)
+	}
+
+	resp, err = http.Get(server.URL + "/decks/away/999")
+	if err != nil {
+		t.Fatalf("Failed to get cards away: %s", err.Error())
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("Expected cards away from a missing deck to be not found, got status %d", resp.StatusCode)
+	}
+}
+
+func TestPlans(t *testing.T) {
+	ctx := context.Background()
+	backend := memory.NewBackend()
+	for _, username := range []string{"alice", "bob"} {
+		_, err := backend.AddUserIfNotExist(ctx, username)
+		if err != nil {
+			t.Fatalf("Failed to add user %q: %s", username, err.Error())
+		}
+	}
+	bolt := &inventory.Card{Name: "Lightning Bolt", OracleID: "bolt-oracle", ScryfallID: "bolt-4ed", Finish: inventory.FinishNonfoil, Condition: inventory.ConditionNearMint}
+	err := backend.AddCards(ctx, []*inventory.CardRow{{Quantity: 4, Card: bolt, Owner: "bob", Keeper: "bob"}})
+	if err != nil {
+		t.Fatalf("Failed to add cards: %s", err.Error())
+	}
+	server := httptest.NewServer(NewServer(backend))
+	defer server.Close()
+
+	plan := func(body string) *http.Response {
+		t.Helper()
+		resp, err := http.Post(server.URL+"/plans", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("Failed to plan deck: %s", err.Error())
+		}
+		return resp
+	}
+	resp := plan(`{"requestor": "alice", "cards": [{"quantity": 4, "name": "Lightning Bolt", "oracle_id": "bolt-oracle"}]}`)
+	var proposed struct {
+		Transfers []struct {
+			Lender   string `json:"lender"`
+			Quantity uint   `json:"quantity"`
+		} `json:"transfers"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&proposed)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatalf("Failed to decode plan: %s", err.Error())
+	}
+	if resp.StatusCode != http.StatusOK || len(proposed.Transfers) != 1 || proposed.Transfers[0].Lender != "bob" || proposed.Transfers[0].Quantity != 4 {
+		t.Fatalf("Unexpected plan with status %d: %+v", resp.StatusCode, proposed)
+	}
+	transfers, err := backend.GetTransfersByToUser(ctx, "alice", inventory.MaxListLimit, 0)
+	if err != nil || len(transfers) != 0 {
+		t.Fatalf("Expected planning to open no transfers, got %+v: %v", transfers, err)
+	}
+
+	resp = plan(`{"requestor": "alice", "cards": [{"quantity": 4, "name": "Lightning Bolt", "oracle_id": "bolt-oracle"}], "accept": true}`)
+	var accepted struct {
+		Request   *inventory.Request    `json:"request"`
+		Transfers []*inventory.Transfer `json:"transfers"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&accepted)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatalf("Failed to decode accepted plan: %s", err.Error())
+	}
+	if resp.StatusCode != http.StatusCreated || accepted.Request == nil || len(accepted.Transfers) != 1 || accepted.Transfers[0].FromUser != "bob" {
+		t.Fatalf("Unexpected accepted plan with status %d: %+v", resp.StatusCode, accepted)
+	}
+
+	resp = plan(`{"requestor": "alice", "cards": [{"quantity": 0, "name": "Lightning Bolt", "oracle_id": "bolt-oracle"}]}`)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected a plan for zero cards to be unprocessable, got status %d", resp.StatusCode)
+	}
+}
+
+func TestServerToken(t *testing.T) {
+	handler := NewServer(&stubBackend{})
+	handler.Token = "secret"
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	for _, authorization := range []string{"", "Bearer wrong", "secret"} {
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/cards/by-owner/user1", nil)
+		if err != nil {
+			t.Fatalf("Failed to create request: %s", err.Error())
+		}
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to get cards by owner: %s", err.Error())
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Fatalf("Expected status %d with authorization %q, got %d", http.StatusUnauthorized, authorization, resp.StatusCode)
+		}
+	}
+
+	client := NewClient(server.URL)
+	client.Token = "secret"
+	cardRows, err := client.GetCardsByOwner(context.Background(), "user1", 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to get cards by owner with the token: %s", err.Error())
+	}
+	if len(cardRows) != 1 {
+		t.Fatalf("Unexpected cards by owner: %+v", cardRows)
+	}
+}
@@ -0,0 +1,175 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/importer"
+)
+
+// OpenTransferBody is the body of a request to open a Transfer
+type OpenTransferBody struct {
+	ToUser    string                        `json:"to_user"`
+	FromUser  string                        `json:"from_user"`
+	RequestID *int64                        `json:"request_id"`
+	Cards     []*inventory.TransferredCards `json:"cards"`
+}
+
+func (s *Server) getTransfersByToUser(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := getLimitAndOffset(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	transfers, err := s.Backend.GetTransfersByToUser(r.Context(), r.PathValue("toUser"), limit, offset)
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, transfers)
+}
+
+func (s *Server) getTransfersByFromUser(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := getLimitAndOffset(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	transfers, err := s.Backend.GetTransfersByFromUser(r.Context(), r.PathValue("fromUser"), limit, offset)
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, transfers)
+}
+
+func (s *Server) getTransfersByRequestID(w http.ResponseWriter, r *http.Request) {
+	requestID, err := getIDPathValue(r, "requestID")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	limit, offset, err := getLimitAndOffset(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	transfers, err := s.Backend.GetTransfersByRequestID(r.Context(), requestID, limit, offset)
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, transfers)
+}
+
+func (s *Server) getTransferByID(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDPathValue(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	limit, offset, err := getLimitAndOffset(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	transfer, err := s.Backend.GetTransferByID(r.Context(), id, limit, offset)
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, transfer)
+}
+
+func (s *Server) exportTransfer(w http.ResponseWriter, r *http.Request) {
+	if s.Scryfall == nil {
+		writeError(w, http.StatusNotImplemented, fmt.Errorf("exporting transfers: %w", inventory.ErrUnimplemented))
+		return
+	}
+	id, err := getIDPathValue(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	formatName := r.URL.Query().Get("format")
+	if formatName == "" {
+		formatName = importer.FormatArena
+	}
+	format, err := importer.ParseExportFormat(formatName)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	transfer, err := importer.GetTransfer(r.Context(), s.Backend.GetTransferByID, id)
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+
+	writeExport(w, fmt.Sprintf("transfer-%d", transfer.ID), format, s.Scryfall, importer.TransferRows(transfer))
+}
+
+func (s *Server) openTransfer(w http.ResponseWriter, r *http.Request) {
+	var body OpenTransferBody
+	err := readJSON(w, r, &body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	ok := checkRows(w, body.Cards, func(row *inventory.TransferredCards) error {
+		return checkCard(row.Quantity, row.Card)
+	})
+	if !ok {
+		return
+	}
+
+	transfer, err := s.Backend.OpenTransfer(r.Context(), body.ToUser, body.FromUser, body.RequestID, body.Cards)
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, transfer)
+}
+
+func (s *Server) closeTransfer(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDPathValue(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	err = s.Backend.CloseTransfer(r.Context(), id)
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) cancelTransfer(w http.ResponseWriter, r *http.Request) {
+	id, err := getIDPathValue(r, "id")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	err = s.Backend.CancelTransfer(r.Context(), id)
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
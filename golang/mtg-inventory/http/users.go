@@ -0,0 +1,82 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+)
+
+// AddUserBody is the body of a request to add a User
+type AddUserBody struct {
+	Username string `json:"username"`
+}
+
+func (s *Server) getUserByUsername(w http.ResponseWriter, r *http.Request) {
+	user, err := s.Backend.GetUserByUsername(r.Context(), r.PathValue("username"))
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, user)
+}
+
+func (s *Server) addUser(w http.ResponseWriter, r *http.Request) {
+	s.addUserWith(w, r, s.Backend.AddUser)
+}
+
+func (s *Server) addUserIfNotExist(w http.ResponseWriter, r *http.Request) {
+	s.addUserWith(w, r, s.Backend.AddUserIfNotExist)
+}
+
+// addUserWith adds the User in the body of r with add
+func (s *Server) addUserWith(w http.ResponseWriter, r *http.Request, add func(ctx context.Context, username string) (*inventory.User, error)) {
+	var body AddUserBody
+	err := readJSON(w, r, &body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if body.Username == "" {
+		writeError(w, http.StatusBadRequest, errors.New("username must not be empty"))
+		return
+	}
+
+	user, err := add(r.Context(), body.Username)
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, user)
+}
+
+func (s *Server) getUserByIdentity(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	user, err := s.Backend.GetUserByIdentity(r.Context(), query.Get("provider"), query.Get("workspace"), query.Get("external_id"))
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, user)
+}
+
+func (s *Server) linkIdentity(w http.ResponseWriter, r *http.Request) {
+	var identity inventory.Identity
+	err := readJSON(w, r, &identity)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	err = s.Backend.LinkIdentity(r.Context(), &identity)
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
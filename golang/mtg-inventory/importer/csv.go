@@ -0,0 +1,492 @@
+package importer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+)
+
+var (
+	// ErrUnknownFormat is returned when a CSV file isn't in any of Formats
+	ErrUnknownFormat = errors.New("unknown CSV format")
+
+	// ErrInvalidQuantity is returned for a quantity that isn't a number
+	ErrInvalidQuantity = errors.New("invalid quantity")
+
+	// ErrUnknownLanguage is returned for a language that isn't one of
+	// Scryfall's
+	ErrUnknownLanguage = errors.New("unknown language")
+)
+
+// Format is the CSV format that a collection tool imports and exports. Its
+// columns are named as in the tool's header, or "" if the tool has none.
+type Format struct {
+	// Name is the tool's name in lower case
+	Name string
+	// Title is the tool's name as it writes it
+	Title string
+	// Header is the header that the tool exports, which is written on
+	// export with the columns that aren't below left empty
+	Header []string
+
+	Quantity        string
+	CardName        string
+	SetCode         string
+	SetName         string
+	CollectorNumber string
+	Finish          string
+	Condition       string
+	Language        string
+	ScryfallID      string
+
+	// Finishes is how the tool writes each finish
+	Finishes map[inventory.Finish]string
+	// Conditions is how the tool writes each condition
+	Conditions map[inventory.Condition]string
+	// LanguageNames is whether the tool writes languages by name, like
+	// "Japanese", rather than by code, like "JA"
+	LanguageNames bool
+}
+
+// conditionNames are the names of conditions on most tools
+var conditionNames = map[inventory.Condition]string{
+	inventory.ConditionNearMint:         "Near Mint",
+	inventory.ConditionLightlyPlayed:    "Lightly Played",
+	inventory.ConditionModeratelyPlayed: "Moderately Played",
+	inventory.ConditionHeavilyPlayed:    "Heavily Played",
+	inventory.ConditionDamaged:          "Damaged",
+}
+
+var (
+	// Moxfield is the format of Moxfield's collection import and export
+	Moxfield = &Format{
+		Name:            "moxfield",
+		Title:           "Moxfield",
+		Header:          []string{"Count", "Tradelist Count", "Name", "Edition", "Condition", "Language", "Foil", "Tags", "Last Modified", "Collector Number", "Alter", "Proxy", "Purchase Price"},
+		Quantity:        "Count",
+		CardName:        "Name",
+		SetCode:         "Edition",
+		CollectorNumber: "Collector Number",
+		Finish:          "Foil",
+		Condition:       "Condition",
+		Language:        "Language",
+		Finishes: map[inventory.Finish]string{
+			inventory.FinishNonfoil: "",
+			inventory.FinishFoil:    "foil",
+			inventory.FinishEtched:  "etched",
+		},
+		Conditions:    conditionNames,
+		LanguageNames: true,
+	}
+
+	// Deckbox is the format of Deckbox's inventory import and export
+	Deckbox = &Format{
+		Name:            "deckbox",
+		Title:           "Deckbox",
+		Header:          []string{"Count", "Tradelist Count", "Name", "Edition", "Edition Code", "Card Number", "Condition", "Language", "Foil", "Signed", "Artist Proof", "Altered Art", "Misprint", "Promo", "Textless", "Printing Id", "Printing Note", "Tags", "My Price"},
+		Quantity:        "Count",
+		CardName:        "Name",
+		SetCode:         "Edition Code",
+		SetName:         "Edition",
+		CollectorNumber: "Card Number",
+		Finish:          "Foil",
+		Condition:       "Condition",
+		Language:        "Language",
+		Finishes: map[inventory.Finish]string{
+			inventory.FinishNonfoil: "",
+			inventory.FinishFoil:    "foil",
+			inventory.FinishEtched:  "foil",
+		},
+		Conditions: map[inventory.Condition]string{
+			inventory.ConditionNearMint:         "Near Mint",
+			inventory.ConditionLightlyPlayed:    "Good (Lightly Played)",
+			inventory.ConditionModeratelyPlayed: "Played",
+			inventory.ConditionHeavilyPlayed:    "Heavily Played",
+			inventory.ConditionDamaged:          "Poor",
+		},
+		LanguageNames: true,
+	}
+
+	// Archidekt is the format of Archidekt's collection import and export
+	Archidekt = &Format{
+		Name:            "archidekt",
+		Title:           "Archidekt",
+		Header:          []string{"Quantity", "Name", "Finish", "Condition", "Date Added", "Language", "Purchase Price", "Tags", "Edition Name", "Edition Code", "Multiverse Id", "Scryfall ID", "MTGO ID", "Collector Number"},
+		Quantity:        "Quantity",
+		CardName:        "Name",
+		SetCode:         "Edition Code",
+		SetName:         "Edition Name",
+		CollectorNumber: "Collector Number",
+		Finish:          "Finish",
+		Condition:       "Condition",
+		Language:        "Language",
+		ScryfallID:      "Scryfall ID",
+		Finishes: map[inventory.Finish]string{
+			inventory.FinishNonfoil: "Normal",
+			inventory.FinishFoil:    "Foil",
+			inventory.FinishEtched:  "Etched",
+		},
+		Conditions: map[inventory.Condition]string{
+			inventory.ConditionNearMint:         "NM",
+			inventory.ConditionLightlyPlayed:    "LP",
+			inventory.ConditionModeratelyPlayed: "MP",
+			inventory.ConditionHeavilyPlayed:    "HP",
+			inventory.ConditionDamaged:          "D",
+		},
+	}
+
+	// TCGplayer is the format of TCGplayer's collection import and export
+	TCGplayer = &Format{
+		Name:            "tcgplayer",
+		Title:           "TCGplayer",
+		Header:          []string{"Quantity", "Name", "Simple Name", "Set", "Card Number", "Set Code", "Printing", "Condition", "Language", "Rarity", "Product ID", "SKU"},
+		Quantity:        "Quantity",
+		CardName:        "Name",
+		SetCode:         "Set Code",
+		SetName:         "Set",
+		CollectorNumber: "Card Number",
+		Finish:          "Printing",
+		Condition:       "Condition",
+		Language:        "Language",
+		Finishes: map[inventory.Finish]string{
+			inventory.FinishNonfoil: "Normal",
+			inventory.FinishFoil:    "Foil",
+			inventory.FinishEtched:  "Foil",
+		},
+		Conditions:    conditionNames,
+		LanguageNames: true,
+	}
+)
+
+// Formats contains every Format
+var Formats = []*Format{Moxfield, Deckbox, Archidekt, TCGplayer}
+
+// FormatNames contains the name of every Format
+var FormatNames = []string{Moxfield.Name, Deckbox.Name, Archidekt.Name, TCGplayer.Name}
+
+// FormatByName returns the Format named name, ignoring case
+func FormatByName(name string) (*Format, error) {
+	for _, format := range Formats {
+		if strings.EqualFold(format.Name, name) {
+			return format, nil
+		}
+	}
+	return nil, fmt.Errorf("format %q: %w", name, ErrUnknownFormat)
+}
+
+// columns returns the columns of the format that are read
+func (f *Format) columns() []string {
+	return []string{f.Quantity, f.CardName, f.SetCode, f.SetName, f.CollectorNumber, f.Finish, f.Condition, f.Language, f.ScryfallID}
+}
+
+// detectFormat returns the format with the most of its columns in header,
+// among those with quantity and name columns
+func detectFormat(header map[string]int) (*Format, error) {
+	var best *Format
+	bestMatches := 0
+	for _, format := range Formats {
+		if _, exists := header[strings.ToLower(format.Quantity)]; !exists {
+			continue
+		}
+		if _, exists := header[strings.ToLower(format.CardName)]; !exists {
+			continue
+		}
+		matches := 0
+		for _, column := range format.columns() {
+			if _, exists := header[strings.ToLower(column)]; column != "" && exists {
+				matches++
+			}
+		}
+		if matches > bestMatches {
+			best, bestMatches = format, matches
+		}
+	}
+	if best == nil {
+		return nil, ErrUnknownFormat
+	}
+	return best, nil
+}
+
+// languageNames are the names of Scryfall's language codes
+var languageNames = map[string]string{
+	"en":  "English",
+	"es":  "Spanish",
+	"fr":  "French",
+	"de":  "German",
+	"it":  "Italian",
+	"pt":  "Portuguese",
+	"ja":  "Japanese",
+	"ko":  "Korean",
+	"ru":  "Russian",
+	"zhs": "Chinese Simplified",
+	"zht": "Chinese Traditional",
+	"he":  "Hebrew",
+	"la":  "Latin",
+	"grc": "Ancient Greek",
+	"ar":  "Arabic",
+	"sa":  "Sanskrit",
+	"ph":  "Phyrexian",
+}
+
+// languageAliases are the codes and names that tools use for Scryfall's
+// language codes, other than those codes and their names
+var languageAliases = map[string]string{
+	"jp":                  "ja",
+	"kr":                  "ko",
+	"cs":                  "zhs",
+	"ct":                  "zht",
+	"simplified chinese":  "zhs",
+	"traditional chinese": "zht",
+	"portuguese (brazil)": "pt",
+}
+
+// parseLanguage returns the Scryfall code of a language written as a code or
+// a name, or "" if none is written
+func parseLanguage(s string) (string, error) {
+	language := strings.ToLower(strings.TrimSpace(s))
+	if language == "" {
+		return "", nil
+	}
+	if _, exists := languageNames[language]; exists {
+		return language, nil
+	}
+	if code, exists := languageAliases[language]; exists {
+		return code, nil
+	}
+	for code, name := range languageNames {
+		if strings.EqualFold(name, language) {
+			return code, nil
+		}
+	}
+	return "", fmt.Errorf("language %q: %w", s, ErrUnknownLanguage)
+}
+
+// formatLanguage writes a Scryfall language code as the format does
+func (f *Format) formatLanguage(code string) string {
+	if f.LanguageNames {
+		if name, exists := languageNames[code]; exists {
+			return name
+		}
+	}
+	return strings.ToUpper(code)
+}
+
+// parseFinish returns the finish written as the format or Finishes do, or ""
+// if none is written
+func (f *Format) parseFinish(s string) (inventory.Finish, error) {
+	s = strings.TrimSpace(s)
+	for _, finish := range inventory.Finishes {
+		if strings.EqualFold(f.Finishes[finish], s) {
+			return finish, nil
+		}
+	}
+	if s == "" {
+		return "", nil
+	}
+	return inventory.ParseFinish(s)
+}
+
+// parseCondition returns the condition written as the format, most tools or
+// Conditions do, or "" if none is written
+func (f *Format) parseCondition(s string) (inventory.Condition, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", nil
+	}
+	for _, condition := range inventory.Conditions {
+		if strings.EqualFold(f.Conditions[condition], s) || strings.EqualFold(conditionNames[condition], s) {
+			return condition, nil
+		}
+	}
+	return inventory.ParseCondition(s)
+}
+
+const (
+	// FormatDecklist names the plain-text decklist format read by Parse
+	FormatDecklist = "decklist"
+
+	// FormatCSV names a CSV file in whichever of Formats matches its header
+	FormatCSV = "csv"
+)
+
+// Read reads the lines of a decklist or CSV file in the named format, which
+// is FormatDecklist, FormatCSV or the name of one of Formats
+func Read(r io.Reader, formatName string) ([]*Line, error) {
+	switch formatName {
+	case FormatDecklist:
+		return Parse(r)
+	case FormatCSV:
+		return ReadCSV(r, nil)
+	}
+	format, err := FormatByName(formatName)
+	if err != nil {
+		return nil, err
+	}
+	return ReadCSV(r, format)
+}
+
+// ReadCSV reads a CSV file exported by a collection tool, in format or, if
+// format is nil, in whichever of Formats matches its header. Each record is
+// returned as a Line. Records with values that can't be read are returned as
+// LineErrors.
+func ReadCSV(r io.Reader, format *Format) ([]*Line, error) {
+	// Excel and some tools start files with a byte order mark
+	buffered := bufio.NewReader(r)
+	if bom, err := buffered.Peek(3); err == nil && string(bom) == "\ufeff" {
+		_, _ = buffered.Discard(3)
+	}
+	reader := csv.NewReader(buffered)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+	record, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, ErrEmptyDecklist
+	} else if err != nil {
+		return nil, fmt.Errorf("error reading CSV header: %w", err)
+	}
+
+	header := make(map[string]int)
+	for i, column := range record {
+		column = strings.ToLower(strings.TrimSpace(column))
+		if _, exists := header[column]; !exists {
+			header[column] = i
+		}
+	}
+	if format == nil {
+		format, err = detectFormat(header)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	lines := make([]*Line, 0)
+	lineErrs := make(LineErrors, 0)
+	for {
+		record, err = reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("error reading CSV: %w", err)
+		}
+		field := func(column string) string {
+			i, exists := header[strings.ToLower(column)]
+			if column == "" || !exists || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		number, _ := reader.FieldPos(0)
+		line := &Line{
+			Number:          number,
+			Text:            strings.Join(record, ","),
+			Name:            field(format.CardName),
+			Set:             strings.ToLower(field(format.SetCode)),
+			CollectorNumber: field(format.CollectorNumber),
+			ScryfallID:      field(format.ScryfallID),
+		}
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+		lines = append(lines, line)
+
+		err = readLineValues(line, format, field)
+		if err != nil {
+			lineErrs = append(lineErrs, lineError(line, err))
+		}
+	}
+	if len(lineErrs) > 0 {
+		return nil, lineErrs
+	}
+
+	if len(lines) == 0 {
+		return nil, ErrEmptyDecklist
+	}
+	return lines, nil
+}
+
+// readLineValues fills in the values of line that must be parsed
+func readLineValues(line *Line, format *Format, field func(column string) string) error {
+	quantity := field(format.Quantity)
+	if quantity == "" {
+		line.Quantity = 1
+	} else {
+		value, err := strconv.ParseUint(quantity, 10, 0)
+		if err != nil {
+			return fmt.Errorf("quantity %q: %w", quantity, ErrInvalidQuantity)
+		} else if value == 0 {
+			return inventory.ErrZeroCards
+		}
+		line.Quantity = uint(value)
+	}
+
+	var err error
+	line.Finish, err = format.parseFinish(field(format.Finish))
+	if err != nil {
+		return err
+	}
+	line.Condition, err = format.parseCondition(field(format.Condition))
+	if err != nil {
+		return err
+	}
+	line.Language, err = parseLanguage(field(format.Language))
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// WriteCSV writes rows in format, looking up their printings with sf. Rows
+// whose printings can't be found are written with only their names and
+// Scryfall IDs.
+func WriteCSV(w io.Writer, format *Format, sf inventory.Scryfall, rows []*inventory.CardRow) error {
+	writer := csv.NewWriter(w)
+	err := writer.Write(format.Header)
+	if err != nil {
+		return fmt.Errorf("error writing CSV header: %w", err)
+	}
+
+	record := make([]string, len(format.Header))
+	for _, row := range rows {
+		printing, err := lookUpPrinting(sf, row.Card.ScryfallID)
+		if err != nil {
+			return err
+		}
+		language := row.Card.Language
+		if language == "" {
+			language = printing.Language
+		}
+
+		values := map[string]string{
+			format.Quantity:        strconv.FormatUint(uint64(row.Quantity), 10),
+			format.CardName:        row.Card.Name,
+			format.SetCode:         printing.Set,
+			format.SetName:         printing.SetName,
+			format.CollectorNumber: printing.CollectorNumber,
+			format.Finish:          format.Finishes[row.Card.Finish],
+			format.Condition:       format.Conditions[row.Card.Condition],
+			format.Language:        format.formatLanguage(language),
+			format.ScryfallID:      row.Card.ScryfallID,
+		}
+		for i, column := range format.Header {
+			record[i] = values[column]
+		}
+		err = writer.Write(record)
+		if err != nil {
+			return fmt.Errorf("error writing CSV: %w", err)
+		}
+	}
+
+	writer.Flush()
+	err = writer.Error()
+	if err != nil {
+		return fmt.Errorf("error writing CSV: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package importer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+)
+
+func TestReadCSV(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		csv      string
+		format   *Format
+		expected Line
+	}{
+		{
+			"Moxfield",
+			"\ufeff\"Count\",\"Tradelist Count\",\"Name\",\"Edition\",\"Condition\",\"Language\",\"Foil\",\"Tags\",\"Last Modified\",\"Collector Number\",\"Alter\",\"Proxy\",\"Purchase Price\"\n" +
+				"\"2\",\"0\",\"Ragavan, Nimble Pilferer\",\"mh2\",\"Lightly Played\",\"Japanese\",\"foil\",\"\",\"2024-01-01 00:00:00.000000\",\"138\",\"False\",\"False\",\"\"\n",
+			Moxfield,
+			Line{Quantity: 2, Name: "Ragavan, Nimble Pilferer", Set: "mh2", CollectorNumber: "138", Finish: inventory.FinishFoil, Condition: inventory.ConditionLightlyPlayed, Language: "ja"},
+		},
+		{
+			"Deckbox",
+			"Count,Tradelist Count,Name,Edition,Edition Code,Card Number,Condition,Language,Foil,Signed,Artist Proof,Altered Art,Misprint,Promo,Textless,Printing Id,Printing Note,Tags,My Price\n" +
+				"4,0,Lightning Bolt,Magic 2010,M10,146,Good (Lightly Played),English,,,,,,,,,,,$1.00\n",
+			Deckbox,
+			Line{Quantity: 4, Name: "Lightning Bolt", Set: "m10", CollectorNumber: "146", Finish: inventory.FinishNonfoil, Condition: inventory.ConditionLightlyPlayed, Language: "en"},
+		},
+		{
+			"Archidekt",
+			"Quantity,Name,Finish,Condition,Date Added,Language,Purchase Price,Tags,Edition Name,Edition Code,Multiverse Id,Scryfall ID,MTGO ID,Collector Number\n" +
+				"1,Lightning Bolt,Etched,D,2024-01-01,EN,,,Secret Lair Drop,SLD,,bolt-sld,,999\n",
+			Archidekt,
+			Line{Quantity: 1, Name: "Lightning Bolt", Set: "sld", CollectorNumber: "999", ScryfallID: "bolt-sld", Finish: inventory.FinishEtched, Condition: inventory.ConditionDamaged, Language: "en"},
+		},
+		{
+			"TCGplayer",
+			"Quantity,Name,Simple Name,Set,Card Number,Set Code,Printing,Condition,Language,Rarity,Product ID,SKU\n" +
+				"3,Lightning Bolt,Lightning Bolt,Magic 2010,146,M10,Normal,Heavily Played,English,C,1,2\n",
+			TCGplayer,
+			Line{Quantity: 3, Name: "Lightning Bolt", Set: "m10", CollectorNumber: "146", Finish: inventory.FinishNonfoil, Condition: inventory.ConditionHeavilyPlayed, Language: "en"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			for _, format := range []*Format{test.format, nil} {
+				lines, err := ReadCSV(strings.NewReader(test.csv), format)
+				if err != nil {
+					t.Fatalf("Failed to read CSV: %s", err.Error())
+				}
+				if len(lines) != 1 {
+					t.Fatalf("Expected 1 line, got %d", len(lines))
+				}
+				line := *lines[0]
+				if line.Number != 2 {
+					t.Errorf("Expected line 2, got %d", line.Number)
+				}
+				line.Number, line.Text = 0, ""
+				if line != test.expected {
+					t.Errorf("Expected %+v, got %+v", test.expected, line)
+				}
+			}
+		})
+	}
+
+	_, err := ReadCSV(strings.NewReader("Card,Amount\nLightning Bolt,4\n"), nil)
+	if !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("Expected ErrUnknownFormat, got: %v", err)
+	}
+
+	lines, err := ReadCSV(strings.NewReader("Count,Name,Condition,Language\nfour,Lightning Bolt,,\n0,Lightning Bolt,,\n1,Lightning Bolt,Mint-ish,\n1,Lightning Bolt,,Klingon\n,,,\n"), Moxfield)
+	var lineErrs LineErrors
+	if !errors.As(err, &lineErrs) {
+		t.Fatalf("Expected LineErrors, got %d lines and error: %v", len(lines), err)
+	}
+	targets := []error{ErrInvalidQuantity, inventory.ErrZeroCards, inventory.ErrInvalidCondition, ErrUnknownLanguage}
+	if len(lineErrs) != len(targets) {
+		t.Fatalf("Expected %d line errors, got: %v", len(targets), err)
+	}
+	for i, target := range targets {
+		if !errors.Is(lineErrs[i], target) {
+			t.Errorf("Expected line error %d to be %q, got: %v", i, target, lineErrs[i])
+		}
+	}
+}
+
+func TestCSVRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	im, _ := newTestImporter(t, 0)
+
+	rows := []*inventory.CardRow{
+		{Quantity: 4, Card: &inventory.Card{Name: "Lightning Bolt", OracleID: "bolt-oracle", ScryfallID: "bolt-m10", Finish: inventory.FinishNonfoil, Condition: inventory.ConditionModeratelyPlayed}, Owner: "user1", Keeper: "user1"},
+		{Quantity: 1, Card: &inventory.Card{Name: "Lightning Bolt", OracleID: "bolt-oracle", ScryfallID: "bolt-sld", Finish: inventory.FinishEtched, Condition: inventory.ConditionNearMint}, Owner: "user1", Keeper: "user1"},
+		{Quantity: 2, Card: &inventory.Card{Name: "Ragavan, Nimble Pilferer", OracleID: "ragavan-oracle", ScryfallID: "ragavan-mh2", Finish: inventory.FinishFoil, Condition: inventory.ConditionLightlyPlayed, Language: "ja"}, Owner: "user1", Keeper: "user1"},
+	}
+	for _, format := range Formats {
+		t.Run(format.Name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := WriteCSV(&buf, format, im.Scryfall, rows)
+			if err != nil {
+				t.Fatalf("Failed to write CSV: %s", err.Error())
+			}
+			lines, err := ReadCSV(&buf, nil)
+			if err != nil {
+				t.Fatalf("Failed to read CSV: %s", err.Error())
+			}
+			if len(lines) != len(rows) {
+				t.Fatalf("Expected %d lines, got %d", len(rows), len(lines))
+			}
+			for i, line := range lines {
+				want := *rows[i].Card
+				if format.Finishes[inventory.FinishEtched] == format.Finishes[inventory.FinishFoil] && want.Finish == inventory.FinishEtched {
+					want.Finish = inventory.FinishFoil
+				}
+				card, err := im.resolve(line)
+				if err != nil {
+					t.Fatalf("Failed to resolve line %q: %s", line.Text, err.Error())
+				}
+				if *card != want || line.Quantity != rows[i].Quantity {
+					t.Errorf("Expected %d %+v, got %d %+v from %q", rows[i].Quantity, want, line.Quantity, *card, line.Text)
+				}
+			}
+		})
+	}
+
+	_, err := im.Import(ctx, rowsToLines(rows), "user1", "user1")
+	if err != nil {
+		t.Fatalf("Failed to import rows: %s", err.Error())
+	}
+	all, err := inventory.ListAll(ctx, im.Backend.GetCardsByOwner, "user1")
+	if err != nil {
+		t.Fatalf("Failed to list cards: %s", err.Error())
+	} else if len(all) != len(rows) {
+		t.Errorf("Expected %d rows, got %d", len(rows), len(all))
+	}
+}
+
+// rowsToLines returns a line for the copy on each row
+func rowsToLines(rows []*inventory.CardRow) []*Line {
+	lines := make([]*Line, 0, len(rows))
+	for i, row := range rows {
+		lines = append(lines, &Line{
+			Number:     i + 1,
+			Quantity:   row.Quantity,
+			ScryfallID: row.Card.ScryfallID,
+			Finish:     row.Card.Finish,
+			Condition:  row.Card.Condition,
+			Language:   row.Card.Language,
+		})
+	}
+	return lines
+}
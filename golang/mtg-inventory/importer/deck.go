@@ -0,0 +1,261 @@
+package importer
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/scryfall"
+)
+
+const (
+	// FormatArena names the decklist format that MTG Arena imports
+	FormatArena = "arena"
+
+	// FormatMTGO names the .dek XML format that Magic Online imports
+	FormatMTGO = "mtgo"
+)
+
+// ExportFormatNames contains the name of every format that Write writes
+var ExportFormatNames = append([]string{FormatArena, FormatMTGO}, FormatNames...)
+
+// ParseExportFormat returns the name of the format that Write writes named
+// name, ignoring case
+func ParseExportFormat(name string) (string, error) {
+	formatName := strings.ToLower(name)
+	if !slices.Contains(ExportFormatNames, formatName) {
+		return "", fmt.Errorf("format %q: %w", name, ErrUnknownFormat)
+	}
+	return formatName, nil
+}
+
+// Write writes rows in the named format, which is FormatArena, FormatMTGO or
+// the name of one of Formats, looking up their printings with sf
+func Write(w io.Writer, formatName string, sf inventory.Scryfall, rows []*inventory.CardRow) error {
+	switch strings.ToLower(formatName) {
+	case FormatArena:
+		return WriteArena(w, sf, rows)
+	case FormatMTGO:
+		return WriteMTGO(w, sf, rows)
+	}
+	format, err := FormatByName(formatName)
+	if err != nil {
+		return err
+	}
+	return WriteCSV(w, format, sf, rows)
+}
+
+// Extension returns the file extension of the named format
+func Extension(formatName string) string {
+	switch strings.ToLower(formatName) {
+	case FormatArena:
+		return ".txt"
+	case FormatMTGO:
+		return ".dek"
+	}
+	return ".csv"
+}
+
+// ContentType returns the media type of the named format
+func ContentType(formatName string) string {
+	switch strings.ToLower(formatName) {
+	case FormatArena:
+		return "text/plain; charset=utf-8"
+	case FormatMTGO:
+		return "application/xml"
+	}
+	return "text/csv"
+}
+
+// lookUpPrinting returns the printing with scryfallID, or an empty card if sf
+// doesn't have it
+func lookUpPrinting(sf inventory.Scryfall, scryfallID string) (*inventory.ScryfallCard, error) {
+	printing, err := sf.GetCardByID(scryfallID)
+	if errors.Is(err, scryfall.ErrNotInCache) {
+		return &inventory.ScryfallCard{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error getting card with Scryfall ID %q: %w", scryfallID, err)
+	}
+	return printing, nil
+}
+
+// clientName returns the name of a card as game clients write it: the faces
+// of split cards are joined by separator, and other cards with several faces
+// are named after their front face
+func clientName(name string, printing *inventory.ScryfallCard, separator string) string {
+	if len(printing.CardFaces) < 2 {
+		return name
+	}
+	if printing.Layout == "split" {
+		names := make([]string, 0, len(printing.CardFaces))
+		for _, face := range printing.CardFaces {
+			names = append(names, face.Name)
+		}
+		return strings.Join(names, separator)
+	}
+	return printing.CardFaces[0].Name
+}
+
+// arenaSetCodes are the codes that MTG Arena uses for sets whose Scryfall
+// codes it doesn't
+var arenaSetCodes = map[string]string{
+	"dom": "DAR",
+}
+
+// WriteArena writes rows as a decklist that MTG Arena imports, merging the
+// copies of each printing. Printings that aren't on Arena are written by name
+// only, so that Arena chooses one.
+func WriteArena(w io.Writer, sf inventory.Scryfall, rows []*inventory.CardRow) error {
+	texts := make([]string, 0, len(rows))
+	quantities := make(map[string]uint)
+	for _, row := range rows {
+		printing, err := lookUpPrinting(sf, row.Card.ScryfallID)
+		if err != nil {
+			return err
+		}
+		text := clientName(row.Card.Name, printing, " // ")
+		if slices.Contains(printing.Games, "arena") {
+			set, exists := arenaSetCodes[printing.Set]
+			if !exists {
+				set = strings.ToUpper(printing.Set)
+			}
+			text = fmt.Sprintf("%s (%s) %s", text, set, printing.CollectorNumber)
+		}
+		if _, exists := quantities[text]; !exists {
+			texts = append(texts, text)
+		}
+		quantities[text] += row.Quantity
+	}
+
+	_, err := io.WriteString(w, "Deck\n")
+	if err != nil {
+		return fmt.Errorf("error writing decklist: %w", err)
+	}
+	for _, text := range texts {
+		_, err = fmt.Fprintf(w, "%d %s\n", quantities[text], text)
+		if err != nil {
+			return fmt.Errorf("error writing decklist: %w", err)
+		}
+	}
+	return nil
+}
+
+// mtgoDeck is the root element of a Magic Online .dek file
+type mtgoDeck struct {
+	XMLName              xml.Name   `xml:"Deck"`
+	XSD                  string     `xml:"xmlns:xsd,attr"`
+	XSI                  string     `xml:"xmlns:xsi,attr"`
+	NetDeckID            int        `xml:"NetDeckID"`
+	PreconstructedDeckID int        `xml:"PreconstructedDeckID"`
+	Cards                []mtgoCard `xml:"Cards"`
+}
+
+// mtgoCard is a number of copies of a card in a Magic Online .dek file,
+// identified by its catalog ID
+type mtgoCard struct {
+	CatID     int    `xml:"CatID,attr,omitempty"`
+	Quantity  uint   `xml:"Quantity,attr"`
+	Sideboard bool   `xml:"Sideboard,attr"`
+	Name      string `xml:"Name,attr"`
+}
+
+// mtgoID returns the Magic Online catalog ID of a printing in a finish, or 0
+// if it isn't on Magic Online
+func mtgoID(printing *inventory.ScryfallCard, finish inventory.Finish) int {
+	if finish != inventory.FinishNonfoil && printing.MTGOFoilID != 0 {
+		return printing.MTGOFoilID
+	}
+	return printing.MTGOID
+}
+
+// WriteMTGO writes rows as a .dek file that Magic Online imports, merging the
+// copies of each printing. Printings that aren't on Magic Online are written
+// as the card's default printing if that is, or else by name only.
+func WriteMTGO(w io.Writer, sf inventory.Scryfall, rows []*inventory.CardRow) error {
+	deck := mtgoDeck{
+		XSD:   "http://www.w3.org/2001/XMLSchema",
+		XSI:   "http://www.w3.org/2001/XMLSchema-instance",
+		Cards: make([]mtgoCard, 0, len(rows)),
+	}
+	indexes := make(map[mtgoCard]int)
+	for _, row := range rows {
+		printing, err := lookUpPrinting(sf, row.Card.ScryfallID)
+		if err != nil {
+			return err
+		}
+		card := mtgoCard{
+			CatID: mtgoID(printing, row.Card.Finish),
+			Name:  clientName(row.Card.Name, printing, "/"),
+		}
+		if card.CatID == 0 && row.Card.OracleID != "" {
+			defaultPrinting, err := sf.GetCardByOracleID(row.Card.OracleID)
+			if err != nil && !errors.Is(err, scryfall.ErrNotInCache) {
+				return fmt.Errorf("error getting card with oracle ID %q: %w", row.Card.OracleID, err)
+			} else if err == nil {
+				card.CatID = mtgoID(defaultPrinting, row.Card.Finish)
+			}
+		}
+
+		if i, exists := indexes[card]; exists {
+			deck.Cards[i].Quantity += row.Quantity
+			continue
+		}
+		indexes[card] = len(deck.Cards)
+		card.Quantity = row.Quantity
+		deck.Cards = append(deck.Cards, card)
+	}
+
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return fmt.Errorf("error writing .dek file: %w", err)
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	err = encoder.Encode(deck)
+	if err != nil {
+		return fmt.Errorf("error writing .dek file: %w", err)
+	}
+	_, err = io.WriteString(w, "\n")
+	if err != nil {
+		return fmt.Errorf("error writing .dek file: %w", err)
+	}
+	return nil
+}
+
+// GetTransfer returns the transfer with id from get, such as a Backend's
+// GetTransferByID, with all of its cards
+func GetTransfer(ctx context.Context, get func(ctx context.Context, id int64, limit, offset uint) (*inventory.Transfer, error), id int64) (*inventory.Transfer, error) {
+	var transfer *inventory.Transfer
+	cards, err := inventory.ListAll(ctx, func(ctx context.Context, id int64, limit, offset uint) ([]*inventory.TransferredCards, error) {
+		page, err := get(ctx, id, limit, offset)
+		if err != nil {
+			return nil, err
+		}
+		transfer = page
+		return page.Cards, nil
+	}, id)
+	if err != nil {
+		return nil, err
+	}
+	transfer.Cards = cards
+	return transfer, nil
+}
+
+// TransferRows returns the cards of a transfer as rows kept by its recipient
+func TransferRows(transfer *inventory.Transfer) []*inventory.CardRow {
+	rows := make([]*inventory.CardRow, 0, len(transfer.Cards))
+	for _, cards := range transfer.Cards {
+		rows = append(rows, &inventory.CardRow{
+			Quantity: cards.Quantity,
+			Card:     cards.Card,
+			Owner:    cards.Owner,
+			Keeper:   transfer.ToUser,
+		})
+	}
+	return rows
+}
@@ -0,0 +1,100 @@
+package importer
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+)
+
+// testDeckRows are rows with several copies of a printing, printings that
+// are and aren't on each client, and a printing that isn't cached
+var testDeckRows = []*inventory.CardRow{
+	{Quantity: 4, Card: &inventory.Card{Name: "Lightning Bolt", OracleID: "bolt-oracle", ScryfallID: "bolt-m10", Finish: inventory.FinishNonfoil, Condition: inventory.ConditionNearMint}, Owner: "user1", Keeper: "user2"},
+	{Quantity: 2, Card: &inventory.Card{Name: "Lightning Bolt", OracleID: "bolt-oracle", ScryfallID: "bolt-m10", Finish: inventory.FinishNonfoil, Condition: inventory.ConditionModeratelyPlayed}, Owner: "user3", Keeper: "user2"},
+	{Quantity: 1, Card: &inventory.Card{Name: "Lightning Bolt", OracleID: "bolt-oracle", ScryfallID: "bolt-m10", Finish: inventory.FinishFoil, Condition: inventory.ConditionNearMint}, Owner: "user1", Keeper: "user2"},
+	{Quantity: 1, Card: &inventory.Card{Name: "Lightning Bolt", OracleID: "bolt-oracle", ScryfallID: "bolt-sld", Finish: inventory.FinishEtched, Condition: inventory.ConditionNearMint}, Owner: "user1", Keeper: "user2"},
+	{Quantity: 2, Card: &inventory.Card{Name: "Ragavan, Nimble Pilferer", OracleID: "ragavan-oracle", ScryfallID: "ragavan-mh2", Finish: inventory.FinishNonfoil, Condition: inventory.ConditionNearMint}, Owner: "user1", Keeper: "user2"},
+	{Quantity: 1, Card: &inventory.Card{Name: "Fire // Ice", OracleID: "fire-ice-oracle", ScryfallID: "fire-ice-dom", Finish: inventory.FinishNonfoil, Condition: inventory.ConditionNearMint}, Owner: "user1", Keeper: "user2"},
+	{Quantity: 3, Card: &inventory.Card{Name: "Counterspell", OracleID: "counterspell-oracle", ScryfallID: "counterspell-mmq", Finish: inventory.FinishNonfoil, Condition: inventory.ConditionNearMint}, Owner: "user1", Keeper: "user2"},
+}
+
+func TestWriteArena(t *testing.T) {
+	im, _ := newTestImporter(t, 0)
+
+	var buf bytes.Buffer
+	err := Write(&buf, FormatArena, im.Scryfall, testDeckRows)
+	if err != nil {
+		t.Fatalf("Failed to write decklist: %s", err.Error())
+	}
+	expected := "Deck\n" +
+		"8 Lightning Bolt\n" +
+		"2 Ragavan, Nimble Pilferer (MH2) 138\n" +
+		"1 Fire // Ice (DAR) 128\n" +
+		"3 Counterspell\n"
+	if buf.String() != expected {
+		t.Fatalf("Expected decklist:\n%s\ngot:\n%s", expected, buf.String())
+	}
+
+	lines, err := Parse(&buf)
+	if err != nil {
+		t.Fatalf("Failed to parse decklist: %s", err.Error())
+	} else if len(lines) != 4 || lines[1].Set != "mh2" || lines[1].CollectorNumber != "138" {
+		t.Errorf("Unexpected lines parsed from decklist: %+v", lines)
+	}
+}
+
+func TestWriteMTGO(t *testing.T) {
+	im, _ := newTestImporter(t, 0)
+
+	var buf bytes.Buffer
+	err := Write(&buf, FormatMTGO, im.Scryfall, testDeckRows)
+	if err != nil {
+		t.Fatalf("Failed to write .dek file: %s", err.Error())
+	}
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<Deck xmlns:xsd="http://www.w3.org/2001/XMLSchema" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">
+  <NetDeckID>0</NetDeckID>
+  <PreconstructedDeckID>0</PreconstructedDeckID>
+  <Cards CatID="33000" Quantity="6" Sideboard="false" Name="Lightning Bolt"></Cards>
+  <Cards CatID="33001" Quantity="1" Sideboard="false" Name="Lightning Bolt"></Cards>
+  <Cards Quantity="1" Sideboard="false" Name="Lightning Bolt"></Cards>
+  <Cards CatID="91000" Quantity="2" Sideboard="false" Name="Ragavan, Nimble Pilferer"></Cards>
+  <Cards Quantity="1" Sideboard="false" Name="Fire/Ice"></Cards>
+  <Cards Quantity="3" Sideboard="false" Name="Counterspell"></Cards>
+</Deck>
+`
+	if buf.String() != expected {
+		t.Fatalf("Expected .dek file:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+func TestGetTransfer(t *testing.T) {
+	ctx := context.Background()
+	pages := 0
+	get := func(ctx context.Context, id int64, limit, offset uint) (*inventory.Transfer, error) {
+		pages++
+		transfer := &inventory.Transfer{ID: id, ToUser: "user2", FromUser: "user1"}
+		for i := offset; i < offset+limit && i < inventory.MaxListLimit+1; i++ {
+			transfer.Cards = append(transfer.Cards, &inventory.TransferredCards{
+				Quantity: 1,
+				Card:     &inventory.Card{Name: "Lightning Bolt"},
+				Owner:    "user1",
+			})
+		}
+		return transfer, nil
+	}
+
+	transfer, err := GetTransfer(ctx, get, 7)
+	if err != nil {
+		t.Fatalf("Failed to get transfer: %s", err.Error())
+	}
+	if pages != 2 || len(transfer.Cards) != inventory.MaxListLimit+1 {
+		t.Fatalf("Expected %d cards in 2 pages, got %d in %d", inventory.MaxListLimit+1, len(transfer.Cards), pages)
+	}
+	rows := TransferRows(transfer)
+	if len(rows) != len(transfer.Cards) || rows[0].Keeper != "user2" || rows[0].Owner != "user1" {
+		t.Errorf("Expected rows kept by the recipient, got %+v", rows[0])
+	}
+}
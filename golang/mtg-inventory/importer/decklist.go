@@ -0,0 +1,171 @@
+/*
+Package importer reads decklists in the plain-text formats that deck builders
+and game clients export, and the CSV files of collection tools, resolves their
+cards with Scryfall and adds them to an inventory. It also writes inventories
+as those CSV files, so they can be taken back to the tools, and as MTG Arena
+decklists and Magic Online .dek files, so borrowed cards can be playtested.
+*/
+package importer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+)
+
+// ErrEmptyDecklist is returned when a decklist has no cards
+var ErrEmptyDecklist = errors.New("no cards in decklist")
+
+// Line is a card on a line of a decklist
+type Line struct {
+	// Number is the line's number in the decklist, starting at 1
+	Number int
+	// Text is the line as it was written
+	Text string
+
+	Quantity        uint
+	Name            string
+	Set             string
+	CollectorNumber string
+	ScryfallID      string
+	// Finish is the finish marked on the line, or "" if none was
+	Finish inventory.Finish
+	// Condition is the condition on the line, or "" if there is none
+	Condition inventory.Condition
+	// Language is the Scryfall code of the language on the line, or "" if
+	// there is none
+	Language  string
+	Sideboard bool
+	Commander bool
+}
+
+// section is the part of a decklist that a line is in
+type section int
+
+const (
+	sectionMain section = iota
+	sectionSideboard
+	sectionCommander
+	sectionSkipped
+)
+
+// sectionHeaders are the lowercased headers that start sections, as written
+// by MTG Arena and deck builders
+var sectionHeaders = map[string]section{
+	"deck":      sectionMain,
+	"main":      sectionMain,
+	"maindeck":  sectionMain,
+	"mainboard": sectionMain,
+	"commander": sectionCommander,
+	"companion": sectionMain,
+	"sideboard": sectionSideboard,
+	"about":     sectionSkipped,
+}
+
+// printingPattern matches a name followed by a set code in parentheses and
+// optionally a collector number, e.g. "Ragavan, Nimble Pilferer (MH2) 138"
+var printingPattern = regexp.MustCompile(`^(.+?)\s+\(([A-Za-z0-9]+)\)(?:\s+(\S+))?$`)
+
+// finishMarkers are the markers deck builders put after a card for its finish
+var finishMarkers = map[string]inventory.Finish{
+	"*F*": inventory.FinishFoil,
+	"*E*": inventory.FinishEtched,
+}
+
+// Parse reads a decklist with one card per line, like "4 Lightning Bolt",
+// "4x Lightning Bolt" or "1 Ragavan, Nimble Pilferer (MH2) 138 *F*". Lines
+// after a "Sideboard" header, prefixed with "SB:", or after the first blank
+// line of a list without headers, as MTG Arena used to export, are in the
+// sideboard, and lines after a "Commander" header are commanders. Comments
+// starting with "#" or "//" are skipped.
+func Parse(r io.Reader) ([]*Line, error) {
+	lines := make([]*Line, 0)
+	current := sectionMain
+	sawHeader := false
+	scanner := bufio.NewScanner(r)
+	for number := 1; scanner.Scan(); number++ {
+		text := scanner.Text()
+		trimmed := strings.TrimSpace(text)
+		if trimmed == "" {
+			if !sawHeader && current == sectionMain && len(lines) > 0 {
+				current = sectionSideboard
+			}
+			continue
+		}
+
+		header := strings.ToLower(strings.TrimSuffix(strings.TrimSpace(strings.TrimPrefix(trimmed, "//")), ":"))
+		if next, exists := sectionHeaders[header]; exists {
+			current = next
+			sawHeader = true
+			continue
+		}
+		if current == sectionSkipped || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "//") {
+			continue
+		}
+
+		line := &Line{
+			Number:    number,
+			Text:      text,
+			Sideboard: current == sectionSideboard,
+			Commander: current == sectionCommander,
+		}
+		if rest, cut := cutPrefixFold(trimmed, "SB:"); cut {
+			line.Sideboard = true
+			trimmed = strings.TrimSpace(rest)
+		}
+		parseCard(line, trimmed)
+		lines = append(lines, line)
+	}
+	err := scanner.Err()
+	if err != nil {
+		return nil, fmt.Errorf("error reading decklist: %w", err)
+	}
+
+	if len(lines) == 0 {
+		return nil, ErrEmptyDecklist
+	}
+	return lines, nil
+}
+
+// parseCard fills in line from the card written on it, without a prefix
+func parseCard(line *Line, text string) {
+	line.Quantity = 1
+	first, rest, _ := strings.Cut(text, " ")
+	quantity, err := strconv.ParseUint(strings.TrimSuffix(strings.ToLower(first), "x"), 10, 0)
+	if err == nil && quantity > 0 {
+		line.Quantity = uint(quantity)
+		text = strings.TrimSpace(rest)
+	}
+
+	for {
+		i := strings.LastIndexByte(text, ' ')
+		finish, exists := finishMarkers[strings.ToUpper(text[i+1:])]
+		if i < 0 || !exists {
+			break
+		}
+		line.Finish = finish
+		text = strings.TrimSpace(text[:i])
+	}
+
+	if match := printingPattern.FindStringSubmatch(text); match != nil {
+		line.Name = match[1]
+		line.Set = strings.ToLower(match[2])
+		line.CollectorNumber = match[3]
+	} else {
+		line.Name = text
+	}
+}
+
+// cutPrefixFold is strings.CutPrefix ignoring case
+func cutPrefixFold(s, prefix string) (string, bool) {
+	if len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix) {
+		return s[len(prefix):], true
+	}
+	return s, false
+}
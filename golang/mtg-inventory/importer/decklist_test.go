@@ -0,0 +1,94 @@
+package importer
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+)
+
+func TestParse(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		decklist string
+		expected []*Line
+	}{
+		{
+			"Plain",
+			"4 Lightning Bolt\n4x Counterspell\nRagavan, Nimble Pilferer\n",
+			[]*Line{
+				{Number: 1, Text: "4 Lightning Bolt", Quantity: 4, Name: "Lightning Bolt"},
+				{Number: 2, Text: "4x Counterspell", Quantity: 4, Name: "Counterspell"},
+				{Number: 3, Text: "Ragavan, Nimble Pilferer", Quantity: 1, Name: "Ragavan, Nimble Pilferer"},
+			},
+		},
+		{
+			"Printings",
+			"1 Ragavan, Nimble Pilferer (MH2) 138 *F*\n2 Lightning Bolt (SLD) 999 *e*\n1 Fire // Ice (MH3)\n",
+			[]*Line{
+				{Number: 1, Text: "1 Ragavan, Nimble Pilferer (MH2) 138 *F*", Quantity: 1, Name: "Ragavan, Nimble Pilferer", Set: "mh2", CollectorNumber: "138", Finish: inventory.FinishFoil},
+				{Number: 2, Text: "2 Lightning Bolt (SLD) 999 *e*", Quantity: 2, Name: "Lightning Bolt", Set: "sld", CollectorNumber: "999", Finish: inventory.FinishEtched},
+				{Number: 3, Text: "1 Fire // Ice (MH3)", Quantity: 1, Name: "Fire // Ice", Set: "mh3"},
+			},
+		},
+		{
+			"Arena",
+			"About\nName Burn\n\nDeck\n4 Lightning Bolt (M10) 146\n\nSideboard\n2 Counterspell (MMQ) 61\n",
+			[]*Line{
+				{Number: 5, Text: "4 Lightning Bolt (M10) 146", Quantity: 4, Name: "Lightning Bolt", Set: "m10", CollectorNumber: "146"},
+				{Number: 8, Text: "2 Counterspell (MMQ) 61", Quantity: 2, Name: "Counterspell", Set: "mmq", CollectorNumber: "61", Sideboard: true},
+			},
+		},
+		{
+			"Commander",
+			"Commander\n1 Ragavan, Nimble Pilferer\n\nDeck\n1 Lightning Bolt\n",
+			[]*Line{
+				{Number: 2, Text: "1 Ragavan, Nimble Pilferer", Quantity: 1, Name: "Ragavan, Nimble Pilferer", Commander: true},
+				{Number: 5, Text: "1 Lightning Bolt", Quantity: 1, Name: "Lightning Bolt"},
+			},
+		},
+		{
+			"OldArena",
+			"4 Lightning Bolt (M10) 146\n\n2 Counterspell (MMQ) 61\n",
+			[]*Line{
+				{Number: 1, Text: "4 Lightning Bolt (M10) 146", Quantity: 4, Name: "Lightning Bolt", Set: "m10", CollectorNumber: "146"},
+				{Number: 3, Text: "2 Counterspell (MMQ) 61", Quantity: 2, Name: "Counterspell", Set: "mmq", CollectorNumber: "61", Sideboard: true},
+			},
+		},
+		{
+			"Comments",
+			"# Burn\n// Creatures\n1 Goblin Guide\n\n// Sideboard\nSB: 2 Smash to Smithereens\n",
+			[]*Line{
+				{Number: 3, Text: "1 Goblin Guide", Quantity: 1, Name: "Goblin Guide"},
+				{Number: 6, Text: "SB: 2 Smash to Smithereens", Quantity: 2, Name: "Smash to Smithereens", Sideboard: true},
+			},
+		},
+		{
+			"NoName",
+			"4\n",
+			[]*Line{
+				{Number: 1, Text: "4", Quantity: 4},
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			lines, err := Parse(strings.NewReader(test.decklist))
+			if err != nil {
+				t.Fatalf("Failed to parse decklist: %s", err.Error())
+			}
+			if !reflect.DeepEqual(lines, test.expected) {
+				for _, line := range lines {
+					t.Logf("Got %+v", line)
+				}
+				t.Fatalf("Expected %d lines, got %d that differ", len(test.expected), len(lines))
+			}
+		})
+	}
+
+	_, err := Parse(strings.NewReader("Deck\n\n// nothing\n"))
+	if !errors.Is(err, ErrEmptyDecklist) {
+		t.Errorf("Expected ErrEmptyDecklist, got: %v", err)
+	}
+}
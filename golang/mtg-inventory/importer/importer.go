@@ -0,0 +1,378 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/scryfall"
+)
+
+// ErrNoCardName is returned for a line with a quantity but no card
+var ErrNoCardName = errors.New("no card name")
+
+// LineErrors is returned when lines of a decklist can't be imported, with a
+// RowError whose Row is the *Line for each of them
+type LineErrors []*inventory.RowError
+
+// Error returns a string form of the errors
+func (le LineErrors) Error() string {
+	messages := make([]string, 0, len(le))
+	for _, rowErr := range le {
+		messages = append(messages, rowErr.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Unwrap returns the RowErrors
+func (le LineErrors) Unwrap() []error {
+	errs := make([]error, 0, len(le))
+	for _, rowErr := range le {
+		errs = append(errs, rowErr)
+	}
+	return errs
+}
+
+// Importer adds the cards on decklists to a Backend
+type Importer struct {
+	Backend  inventory.Backend
+	Scryfall inventory.Scryfall
+}
+
+// NewImporter returns an Importer that resolves cards with sf and adds them
+// to backend
+func NewImporter(backend inventory.Backend, sf inventory.Scryfall) *Importer {
+	return &Importer{
+		Backend:  backend,
+		Scryfall: sf,
+	}
+}
+
+// lineError returns a RowError for line
+func lineError(line *Line, err error) *inventory.RowError {
+	return &inventory.RowError{
+		Err: fmt.Errorf("line %d %q: %w", line.Number, strings.TrimSpace(line.Text), err),
+		Row: line,
+	}
+}
+
+// resolve looks up the printing on line and returns the copy of it to add.
+// Lookups that fail because of the line are returned as a RowError.
+func (im *Importer) resolve(line *Line) (*inventory.Card, error) {
+	card, err := im.lookUp(line)
+	if err != nil {
+		return nil, err
+	}
+
+	finish := line.Finish
+	if finish == "" {
+		finish = inventory.FinishNonfoil
+		if !card.HasFinish(finish) {
+			finish = card.Finishes[0]
+		}
+	} else if !card.HasFinish(finish) {
+		return nil, lineError(line, fmt.Errorf("%s %q in %s: %w", finish, card.Name, card.Set, inventory.ErrFinishUnavailable))
+	}
+	condition := line.Condition
+	if condition == "" {
+		condition = inventory.ConditionNearMint
+	}
+	var language string
+	if line.Language != "" && line.Language != card.Language {
+		language = line.Language
+	}
+	return &inventory.Card{
+		Name:       card.Name,
+		OracleID:   card.CardOracleID(),
+		ScryfallID: card.ID,
+		Finish:     finish,
+		Condition:  condition,
+		Language:   language,
+	}, nil
+}
+
+// lookUp looks up the printing on line by its Scryfall ID, or by its name
+// and, if it has one, its set. A printing in the line's language is preferred
+// if Scryfall has one.
+func (im *Importer) lookUp(line *Line) (*inventory.ScryfallCard, error) {
+	if line.ScryfallID != "" {
+		card, err := im.Scryfall.GetCardByID(line.ScryfallID)
+		if errors.Is(err, scryfall.ErrNotInCache) {
+			return nil, lineError(line, fmt.Errorf("no card has Scryfall ID %q: %w", line.ScryfallID, inventory.ErrUnknownCard))
+		} else if err != nil {
+			return nil, fmt.Errorf("error getting card with Scryfall ID %q: %w", line.ScryfallID, err)
+		}
+		return card, nil
+	}
+
+	if line.Name == "" {
+		return nil, lineError(line, ErrNoCardName)
+	}
+	card, err := scryfall.FindCardByName(im.Scryfall, line.Name)
+	if errors.Is(err, scryfall.ErrNotInCache) || errors.Is(err, scryfall.ErrMultipleCacheHits) {
+		return nil, lineError(line, err)
+	} else if err != nil {
+		return nil, fmt.Errorf("error looking up card %q: %w", line.Name, err)
+	}
+	if line.Set == "" {
+		return card, nil
+	}
+
+	name := card.Name
+	if line.Language != "" && line.Language != "en" {
+		card, err = im.Scryfall.GetCard(name, line.Set, line.Language, line.CollectorNumber)
+		if err == nil {
+			return card, nil
+		} else if !errors.Is(err, scryfall.ErrNotInCache) {
+			return nil, fmt.Errorf("error looking up printing of %q: %w", name, err)
+		}
+	}
+	card, err = im.Scryfall.GetCard(name, line.Set, "en", line.CollectorNumber)
+	if errors.Is(err, scryfall.ErrNotInCache) {
+		return nil, lineError(line, err)
+	} else if err != nil {
+		return nil, fmt.Errorf("error looking up printing of %q: %w", name, err)
+	}
+	return card, nil
+}
+
+// Import resolves the card on every line and adds them for owner, kept by
+// keeper. Lines with the same copy of a printing are added as one row. If
+// any line can't be resolved, nothing is added and LineErrors are returned.
+// Rows are added RowUploadLimit at a time, so if adding fails, the rows added
+// before the failure are removed again. Only if removing them fails too are
+// they returned along with the error.
+func (im *Importer) Import(ctx context.Context, lines []*Line, owner, keeper string) ([]*inventory.CardRow, error) {
+	for _, username := range []string{owner, keeper} {
+		_, err := im.Backend.GetUserByUsername(ctx, username)
+		if err != nil {
+			return nil, fmt.Errorf("error getting user %q: %w", username, err)
+		}
+	}
+
+	rows := make([]*inventory.CardRow, 0, len(lines))
+	rowLines := make(map[*inventory.CardRow]*Line)
+	byCard := make(map[inventory.Card]*inventory.CardRow)
+	lineErrs := make(LineErrors, 0)
+	for _, line := range lines {
+		card, err := im.resolve(line)
+		var rowErr *inventory.RowError
+		if errors.As(err, &rowErr) {
+			lineErrs = append(lineErrs, rowErr)
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		key := *card
+		if row, exists := byCard[key]; exists {
+			row.Quantity += line.Quantity
+			continue
+		}
+		row := &inventory.CardRow{
+			Quantity: line.Quantity,
+			Card:     &key,
+			Owner:    owner,
+			Keeper:   keeper,
+		}
+		byCard[key] = row
+		rowLines[row] = line
+		rows = append(rows, row)
+	}
+	if len(lineErrs) > 0 {
+		return nil, lineErrs
+	}
+
+	for start := 0; start < len(rows); start += inventory.RowUploadLimit {
+		chunk := rows[start:min(start+inventory.RowUploadLimit, len(rows))]
+		err := im.Backend.AddCards(ctx, chunk)
+		var rowErr *inventory.RowError
+		if errors.As(err, &rowErr) {
+			if row, ok := rowErr.Row.(*inventory.CardRow); ok && rowLines[row] != nil {
+				err = LineErrors{lineError(rowLines[row], rowErr.Err)}
+			}
+		}
+		if err != nil {
+			rollbackErr := im.rollBack(ctx, owner, rows[:start])
+			if rollbackErr != nil {
+				return rows[:start], fmt.Errorf("error adding cards: %w, then error removing those added: %w", err, rollbackErr)
+			}
+			if errors.As(err, &lineErrs) {
+				return nil, lineErrs
+			}
+			return nil, fmt.Errorf("error adding cards: %w", err)
+		}
+	}
+	return rows, nil
+}
+
+// copyKey identifies a copy of a printing that an owner has with a keeper
+type copyKey struct {
+	ScryfallID string
+	Finish     inventory.Finish
+	Condition  inventory.Condition
+	Language   string
+	Keeper     string
+}
+
+// newCopyKey returns the copyKey of row
+func newCopyKey(row *inventory.CardRow) copyKey {
+	return copyKey{
+		ScryfallID: row.Card.ScryfallID,
+		Finish:     row.Card.Finish,
+		Condition:  row.Card.Condition,
+		Language:   row.Card.Language,
+		Keeper:     row.Keeper,
+	}
+}
+
+// rollBack removes the quantities of added, which Import added for owner,
+// from the quantities owner has now
+func (im *Importer) rollBack(ctx context.Context, owner string, added []*inventory.CardRow) error {
+	if len(added) == 0 {
+		return nil
+	}
+	owned, err := inventory.ListAll(ctx, im.Backend.GetCardsByOwner, owner)
+	if err != nil {
+		return fmt.Errorf("error getting cards: %w", err)
+	}
+	quantities := make(map[copyKey]uint, len(owned))
+	for _, row := range owned {
+		quantities[newCopyKey(row)] = row.Quantity
+	}
+	for _, row := range added {
+		quantity := quantities[newCopyKey(row)]
+		quantity -= min(quantity, row.Quantity)
+		err = im.Backend.ModifyCardQuantity(ctx, row.Owner, row.Keeper, row.Card, quantity)
+		if err != nil {
+			return fmt.Errorf("error modifying quantity of %q: %w", row.Card.Name, err)
+		}
+	}
+	return nil
+}
+
+// lineZone returns the zone of a deck that line is in
+func lineZone(line *Line) inventory.Zone {
+	switch {
+	case line.Commander:
+		return inventory.ZoneCommander
+	case line.Sideboard:
+		return inventory.ZoneSideboard
+	}
+	return inventory.ZoneMainboard
+}
+
+// matchesLine returns whether owned is a copy of the card on line, which
+// resolved to card. Lines that name a printing, finish, condition or language
+// only match copies with it.
+func matchesLine(line *Line, card, owned *inventory.Card) bool {
+	if owned.OracleID != card.OracleID {
+		return false
+	}
+	if (line.Set != "" || line.ScryfallID != "") && owned.ScryfallID != card.ScryfallID {
+		return false
+	}
+	if line.Finish != "" && owned.Finish != card.Finish {
+		return false
+	}
+	if line.Condition != "" && owned.Condition != card.Condition {
+		return false
+	}
+	return line.Language == "" || owned.Language == card.Language
+}
+
+// ResolveDeck resolves the card on every line to copies that owner owns, in
+// the zone of the deck the line is in. Lines that don't name a printing take
+// copies of any printing, preferring those that owner keeps. If any line
+// can't be resolved or owner doesn't own enough copies for it, nothing is
+// returned but LineErrors.
+func (im *Importer) ResolveDeck(ctx context.Context, lines []*Line, owner string) ([]*inventory.DeckCards, error) {
+	owned, err := inventory.ListAll(ctx, im.Backend.GetCardsByOwner, owner)
+	if err != nil {
+		return nil, fmt.Errorf("error getting cards: %w", err)
+	}
+	// Take the cards owner keeps before those lent out
+	sort.SliceStable(owned, func(i, j int) bool {
+		return owned[i].Keeper == owner && owned[j].Keeper != owner
+	})
+	remaining := make(map[*inventory.CardRow]uint, len(owned))
+	for _, row := range owned {
+		remaining[row] = row.Quantity
+	}
+
+	rows := make([]*inventory.DeckCards, 0, len(lines))
+	lineErrs := make(LineErrors, 0)
+	for _, line := range lines {
+		card, err := im.resolve(line)
+		var rowErr *inventory.RowError
+		if errors.As(err, &rowErr) {
+			lineErrs = append(lineErrs, rowErr)
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		needed := line.Quantity
+		for _, row := range owned {
+			if needed == 0 {
+				break
+			}
+			if remaining[row] == 0 || !matchesLine(line, card, row.Card) {
+				continue
+			}
+			quantity := min(needed, remaining[row])
+			rows = append(rows, &inventory.DeckCards{
+				Zone:     lineZone(line),
+				Quantity: quantity,
+				Card:     row.Card,
+			})
+			remaining[row] -= quantity
+			needed -= quantity
+		}
+		if needed > 0 {
+			lineErrs = append(lineErrs, lineError(line, fmt.Errorf("%s owns %d of them: %w", owner, line.Quantity-needed, inventory.ErrTooFewCards)))
+		}
+	}
+	if len(lineErrs) > 0 {
+		return nil, lineErrs
+	}
+	return rows, nil
+}
+
+// Request looks up the card on every line and returns the number of each
+// card that the lines want, whatever their printings, zones or copies. If any
+// line can't be looked up, LineErrors are returned.
+func (im *Importer) Request(lines []*Line) ([]*inventory.RequestedCards, error) {
+	rows := make([]*inventory.RequestedCards, 0, len(lines))
+	byOracleID := make(map[string]*inventory.RequestedCards)
+	lineErrs := make(LineErrors, 0)
+	for _, line := range lines {
+		card, err := im.lookUp(line)
+		var rowErr *inventory.RowError
+		if errors.As(err, &rowErr) {
+			lineErrs = append(lineErrs, rowErr)
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		if row, exists := byOracleID[card.CardOracleID()]; exists {
+			row.Quantity += line.Quantity
+			continue
+		}
+		row := &inventory.RequestedCards{
+			Quantity: line.Quantity,
+			Name:     card.Name,
+			OracleID: card.CardOracleID(),
+		}
+		byOracleID[row.OracleID] = row
+		rows = append(rows, row)
+	}
+	if len(lineErrs) > 0 {
+		return nil, lineErrs
+	}
+	return rows, nil
+}
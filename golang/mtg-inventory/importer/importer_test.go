@@ -0,0 +1,260 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/backends/memory"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/chat/chattest"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/scryfall"
+)
+
+// countingBackend counts calls to AddCards and fails the call numbered failOn
+type countingBackend struct {
+	inventory.Backend
+
+	calls  int
+	failOn int
+}
+
+func (cb *countingBackend) AddCards(ctx context.Context, rows []*inventory.CardRow) error {
+	cb.calls++
+	if cb.calls == cb.failOn {
+		return &inventory.RowError{
+			Err: inventory.ErrUnknownCard,
+			Row: rows[len(rows)-1],
+		}
+	}
+	return cb.Backend.AddCards(ctx, rows)
+}
+
+func newTestImporter(t *testing.T, cards int) (*Importer, *countingBackend) {
+	t.Helper()
+
+	extra := []string{chattest.BoltSLD, chattest.FireIce}
+	for i := 0; i < cards; i++ {
+		extra = append(extra, fmt.Sprintf(`{"object": "card", "id": "card-%03d", "lang": "en", "oracle_id": "oracle-%03d", "name": "Card %03d", "collector_number": "%d", "released_at": "2020-01-01", "set": "tst"}`, i, i, i, i))
+	}
+	sf := chattest.NewScryfall(t, extra...)
+	backend := &countingBackend{Backend: memory.NewBackend()}
+	_, err := backend.AddUserIfNotExist(context.Background(), "user1")
+	if err != nil {
+		t.Fatalf("Failed to add user: %s", err.Error())
+	}
+	return NewImporter(backend, sf), backend
+}
+
+func TestImport(t *testing.T) {
+	ctx := context.Background()
+	im, _ := newTestImporter(t, 0)
+
+	lines, err := Parse(strings.NewReader("3 lightning bolt\n1 Lightning Bolt (M10) 146\n2 Lightning Bolt (SLD) 999\n1 Lightning Bolt (SLD) *E*\nSB: 1 Ragavan, Nimble Pilferer (MH2) 138 *F*\n"))
+	if err != nil {
+		t.Fatalf("Failed to parse decklist: %s", err.Error())
+	}
+	rows, err := im.Import(ctx, lines, "user1", "user1")
+	if err != nil {
+		t.Fatalf("Failed to import decklist: %s", err.Error())
+	}
+	expected := []struct {
+		quantity   uint
+		scryfallID string
+		finish     inventory.Finish
+	}{
+		{5, "bolt-sld", inventory.FinishFoil},
+		{1, "bolt-m10", inventory.FinishNonfoil},
+		{1, "bolt-sld", inventory.FinishEtched},
+		{1, "ragavan-mh2", inventory.FinishFoil},
+	}
+	if len(rows) != len(expected) {
+		t.Fatalf("Expected %d rows, got %d", len(expected), len(rows))
+	}
+	for i, want := range expected {
+		row := rows[i]
+		if row.Quantity != want.quantity || row.Card.ScryfallID != want.scryfallID || row.Card.Finish != want.finish {
+			t.Errorf("Expected %d %s %s, got %d %s %s", want.quantity, want.scryfallID, want.finish, row.Quantity, row.Card.ScryfallID, row.Card.Finish)
+		}
+	}
+
+	cardRows, err := im.Backend.GetCardsByOracleID(ctx, "bolt-oracle", 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to get cards by oracle ID: %s", err.Error())
+	} else if len(cardRows) != 3 {
+		t.Errorf("Expected 3 rows of Lightning Bolt, got %d", len(cardRows))
+	}
+}
+
+func TestImportLineErrors(t *testing.T) {
+	ctx := context.Background()
+	im, backend := newTestImporter(t, 0)
+
+	lines, err := Parse(strings.NewReader("4 Lightning Bolt\n1 Lightnig Bolt\n2 Ragavan, Nimble Pilferer (M10)\n1 Lightning Bolt (M10) *E*\n3\n"))
+	if err != nil {
+		t.Fatalf("Failed to parse decklist: %s", err.Error())
+	}
+	rows, err := im.Import(ctx, lines, "user1", "user1")
+	var lineErrs LineErrors
+	if !errors.As(err, &lineErrs) {
+		t.Fatalf("Expected LineErrors, got: %v", err)
+	}
+	if len(rows) != 0 || backend.calls != 0 {
+		t.Errorf("Expected nothing to be added, got %d rows in %d calls", len(rows), backend.calls)
+	}
+
+	targets := []error{scryfall.ErrNotInCache, scryfall.ErrNotInCache, inventory.ErrFinishUnavailable, ErrNoCardName}
+	if len(lineErrs) != len(targets) {
+		t.Fatalf("Expected %d line errors, got: %v", len(targets), err)
+	}
+	for i, target := range targets {
+		if !errors.Is(lineErrs[i], target) {
+			t.Errorf("Expected line error %d to be %q, got: %v", i, target, lineErrs[i])
+		}
+		if lineErrs[i].Row != lines[i+1] {
+			t.Errorf("Expected line error %d for line %d, got %+v", i, lines[i+1].Number, lineErrs[i].Row)
+		}
+	}
+	var nameErr *scryfall.NameError
+	if !errors.As(lineErrs[0], &nameErr) || len(nameErr.Suggestions) == 0 {
+		t.Errorf("Expected suggestions for a misspelled name, got: %v", lineErrs[0])
+	}
+}
+
+func TestImportChunks(t *testing.T) {
+	ctx := context.Background()
+	cards := inventory.RowUploadLimit*2 + 1
+	im, backend := newTestImporter(t, cards)
+
+	var decklist strings.Builder
+	for i := 0; i < cards; i++ {
+		fmt.Fprintf(&decklist, "1 Card %03d\n", i)
+	}
+	lines, err := Parse(strings.NewReader(decklist.String()))
+	if err != nil {
+		t.Fatalf("Failed to parse decklist: %s", err.Error())
+	}
+	rows, err := im.Import(ctx, lines, "user1", "user1")
+	if err != nil {
+		t.Fatalf("Failed to import decklist: %s", err.Error())
+	}
+	if len(rows) != cards || backend.calls != 3 {
+		t.Errorf("Expected %d rows in 3 calls, got %d rows in %d calls", cards, len(rows), backend.calls)
+	}
+
+	im, backend = newTestImporter(t, cards)
+	backend.failOn = 2
+	card := &inventory.Card{Name: "Card 000", OracleID: "oracle-000", ScryfallID: "card-000", Finish: inventory.FinishNonfoil, Condition: inventory.ConditionNearMint}
+	err = backend.Backend.AddCards(ctx, []*inventory.CardRow{{Quantity: 2, Card: card, Owner: "user1", Keeper: "user1"}})
+	if err != nil {
+		t.Fatalf("Failed to add cards: %s", err.Error())
+	}
+	rows, err = im.Import(ctx, lines, "user1", "user1")
+	var rowErr *inventory.RowError
+	if !errors.As(err, &rowErr) || !errors.Is(err, inventory.ErrUnknownCard) {
+		t.Fatalf("Expected a RowError, got: %v", err)
+	}
+	if rowErr.Row != lines[inventory.RowUploadLimit*2-1] {
+		t.Errorf("Expected RowError for line %d, got %+v", inventory.RowUploadLimit*2, rowErr.Row)
+	}
+	if len(rows) != 0 {
+		t.Errorf("Expected no rows to be added, got %d", len(rows))
+	}
+	owned, err := im.Backend.GetCardsByOwner(ctx, "user1", 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to get cards: %s", err.Error())
+	}
+	if len(owned) != 1 || owned[0].Card.ScryfallID != "card-000" || owned[0].Quantity != 2 {
+		t.Errorf("Expected the rows added before the failure to be removed, got %+v", owned)
+	}
+
+	im, backend = newTestImporter(t, cards)
+	_, err = im.Import(ctx, lines, "user1", "user2")
+	if !errors.Is(err, inventory.ErrUserNoExist) || backend.calls != 0 {
+		t.Errorf("Expected ErrUserNoExist before adding anything, got %v in %d calls", err, backend.calls)
+	}
+}
+
+func TestResolveDeck(t *testing.T) {
+	ctx := context.Background()
+	im, _ := newTestImporter(t, 0)
+	_, err := im.Backend.AddUserIfNotExist(ctx, "user2")
+	if err != nil {
+		t.Fatalf("Failed to add user: %s", err.Error())
+	}
+	bolt := &inventory.Card{Name: "Lightning Bolt", OracleID: "bolt-oracle", ScryfallID: "bolt-m10", Finish: inventory.FinishNonfoil, Condition: inventory.ConditionNearMint}
+	ragavan := &inventory.Card{Name: "Ragavan, Nimble Pilferer", OracleID: "ragavan-oracle", ScryfallID: "ragavan-mh2", Finish: inventory.FinishFoil, Condition: inventory.ConditionNearMint}
+	err = im.Backend.AddCards(ctx, []*inventory.CardRow{
+		{Quantity: 2, Card: bolt, Owner: "user1", Keeper: "user2"},
+		{Quantity: 4, Card: bolt, Owner: "user1", Keeper: "user1"},
+		{Quantity: 1, Card: ragavan, Owner: "user1", Keeper: "user1"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to add cards: %s", err.Error())
+	}
+
+	lines, err := Parse(strings.NewReader("Commander\n1 Ragavan, Nimble Pilferer\n\nDeck\n5 Lightning Bolt\n\nSideboard\n1 Lightning Bolt (M10)\n"))
+	if err != nil {
+		t.Fatalf("Failed to parse decklist: %s", err.Error())
+	}
+	rows, err := im.ResolveDeck(ctx, lines, "user1")
+	if err != nil {
+		t.Fatalf("Failed to resolve deck: %s", err.Error())
+	}
+	expected := []*inventory.DeckCards{
+		{Zone: inventory.ZoneCommander, Quantity: 1, Card: ragavan},
+		{Zone: inventory.ZoneMainboard, Quantity: 4, Card: bolt},
+		{Zone: inventory.ZoneMainboard, Quantity: 1, Card: bolt},
+		{Zone: inventory.ZoneSideboard, Quantity: 1, Card: bolt},
+	}
+	if len(rows) != len(expected) {
+		t.Fatalf("Expected %d rows, got %d", len(expected), len(rows))
+	}
+	for i, want := range expected {
+		row := rows[i]
+		if row.Zone != want.Zone || row.Quantity != want.Quantity || *row.Card != *want.Card {
+			t.Errorf("Expected %d %+v in %s, got %d %+v in %s", want.Quantity, want.Card, want.Zone, row.Quantity, row.Card, row.Zone)
+		}
+	}
+
+	lines, err = Parse(strings.NewReader("6 Lightning Bolt\n1 Lightning Bolt (SLD)\n"))
+	if err != nil {
+		t.Fatalf("Failed to parse decklist: %s", err.Error())
+	}
+	_, err = im.ResolveDeck(ctx, lines, "user1")
+	var lineErrs LineErrors
+	if !errors.As(err, &lineErrs) || len(lineErrs) != 1 || lineErrs[0].Row != lines[1] || !errors.Is(lineErrs[0], inventory.ErrTooFewCards) {
+		t.Fatalf("Expected ErrTooFewCards for the second line, got: %v", err)
+	}
+}
+
+func TestRequest(t *testing.T) {
+	im, _ := newTestImporter(t, 0)
+
+	lines, err := Parse(strings.NewReader("Commander\n1 Ragavan, Nimble Pilferer\n\nDeck\n3 Lightning Bolt (M10)\n1 Lightning Bolt (SLD) *E*\n\nSideboard\n1 Lightning Bolt\n"))
+	if err != nil {
+		t.Fatalf("Failed to parse decklist: %s", err.Error())
+	}
+	rows, err := im.Request(lines)
+	if err != nil {
+		t.Fatalf("Failed to request decklist: %s", err.Error())
+	}
+	if len(rows) != 2 {
+		t.Fatalf("Expected 2 rows, got %d", len(rows))
+	}
+	if rows[0].OracleID != "ragavan-oracle" || rows[0].Quantity != 1 || rows[1].OracleID != "bolt-oracle" || rows[1].Quantity != 5 {
+		t.Fatalf("Expected 1 Ragavan and 5 Lightning Bolt, got %+v and %+v", rows[0], rows[1])
+	}
+
+	lines, err = Parse(strings.NewReader("1 Lightning Bolt\n1 Lightnig Bolt\n"))
+	if err != nil {
+		t.Fatalf("Failed to parse decklist: %s", err.Error())
+	}
+	_, err = im.Request(lines)
+	var lineErrs LineErrors
+	if !errors.As(err, &lineErrs) || len(lineErrs) != 1 || lineErrs[0].Row != lines[1] {
+		t.Fatalf("Expected a line error for the misspelled card, got: %v", err)
+	}
+}
@@ -3,6 +3,8 @@ package inventory
 import (
 	"encoding/json"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 )
 
@@ -30,31 +32,139 @@ func (sd *ScryfallDate) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+// MarshalJSON implements json.Marshaler
+func (sd ScryfallDate) MarshalJSON() ([]byte, error) {
+	return json.Marshal(sd.Value)
+}
+
+// ScryfallImageURIs are the URIs of the images of a card or card face in
+// different sizes
+type ScryfallImageURIs struct {
+	Small      string `json:"small,omitempty"`
+	Normal     string `json:"normal,omitempty"`
+	Large      string `json:"large,omitempty"`
+	PNG        string `json:"png,omitempty"`
+	ArtCrop    string `json:"art_crop,omitempty"`
+	BorderCrop string `json:"border_crop,omitempty"`
+}
+
+// ScryfallPrices are the prices of a card in each currency and finish, as
+// decimal strings. Prices Scryfall doesn't know are empty.
+type ScryfallPrices struct {
+	USD       string `json:"usd,omitempty"`
+	USDFoil   string `json:"usd_foil,omitempty"`
+	USDEtched string `json:"usd_etched,omitempty"`
+	EUR       string `json:"eur,omitempty"`
+	EURFoil   string `json:"eur_foil,omitempty"`
+	EUREtched string `json:"eur_etched,omitempty"`
+	Tix       string `json:"tix,omitempty"`
+}
+
 // ScryfallCardFace represents one of the faces of a Card
 type ScryfallCardFace struct {
-	Name     string `json:"name"`
-	OracleID string `json:"oracle_id"`
+	Name        string             `json:"name"`
+	OracleID    string             `json:"oracle_id"`
+	PrintedName string             `json:"printed_name,omitempty"`
+	ManaCost    string             `json:"mana_cost,omitempty"`
+	TypeLine    string             `json:"type_line,omitempty"`
+	Colors      []string           `json:"colors"`
+	ImageURIs   *ScryfallImageURIs `json:"image_uris,omitempty"`
 }
 
 // ScryfallCard represents a card object retrieved from Scryfall
 type ScryfallCard struct {
 	// Core Card Fields
-	ID       string `json:"id"`
-	Language string `json:"lang"`
-	OracleID string `json:"oracle_id"`
+	ID         string `json:"id"`
+	Language   string `json:"lang"`
+	MTGOID     int    `json:"mtgo_id,omitempty"`
+	MTGOFoilID int    `json:"mtgo_foil_id,omitempty"`
+	OracleID   string `json:"oracle_id"`
 
 	// Gameplay fields
-	Name string `json:"name"`
+	Name          string            `json:"name"`
+	Layout        string            `json:"layout,omitempty"`
+	ManaCost      string            `json:"mana_cost,omitempty"`
+	TypeLine      string            `json:"type_line,omitempty"`
+	Colors        []string          `json:"colors"`
+	ColorIdentity []string          `json:"color_identity"`
+	Legalities    map[string]string `json:"legalities,omitempty"`
+	Reserved      bool              `json:"reserved,omitempty"`
 
 	// Print fields
-	CollectorNumber string       `json:"collector_number"`
-	ReleasedAt      ScryfallDate `json:"released_at"`
-	Set             string       `json:"set"`
+	CollectorNumber string             `json:"collector_number"`
+	Finishes        []Finish           `json:"finishes"`
+	Games           []string           `json:"games,omitempty"`
+	ImageURIs       *ScryfallImageURIs `json:"image_uris,omitempty"`
+	Prices          ScryfallPrices     `json:"prices"`
+	PrintedName     string             `json:"printed_name,omitempty"`
+	Rarity          string             `json:"rarity,omitempty"`
+	ReleasedAt      ScryfallDate       `json:"released_at"`
+	Set             string             `json:"set"`
+	SetName         string             `json:"set_name,omitempty"`
 
 	// Card Face Objects
 	CardFaces []ScryfallCardFace `json:"card_faces"`
 }
 
+// HasFinish returns whether the card was printed in a finish. Cards without
+// finishes, such as those cached before Scryfall had them, have every finish.
+func (sc *ScryfallCard) HasFinish(finish Finish) bool {
+	if len(sc.Finishes) == 0 {
+		return true
+	}
+	return slices.Contains(sc.Finishes, finish)
+}
+
+// CardOracleID returns the oracle ID of the card, which is on the first face
+// for cards whose faces have separate oracle IDs
+func (sc *ScryfallCard) CardOracleID() string {
+	if sc.OracleID == "" && len(sc.CardFaces) > 0 {
+		return sc.CardFaces[0].OracleID
+	}
+	return sc.OracleID
+}
+
+// HasName returns whether name is the name of the card or of one of its
+// faces, ignoring case
+func (sc *ScryfallCard) HasName(name string) bool {
+	if strings.EqualFold(sc.Name, name) {
+		return true
+	}
+	for _, face := range sc.CardFaces {
+		if strings.EqualFold(face.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Images returns the images of the card, which are those of its front face
+// for cards with a different image on each face, or nil if it has none
+func (sc *ScryfallCard) Images() *ScryfallImageURIs {
+	if sc.ImageURIs != nil {
+		return sc.ImageURIs
+	}
+	for _, face := range sc.CardFaces {
+		if face.ImageURIs != nil {
+			return face.ImageURIs
+		}
+	}
+	return nil
+}
+
+// USDPrice returns the price of the card in US dollars in a finish, or an
+// empty string if the price isn't known
+func (sc *ScryfallCard) USDPrice(finish Finish) string {
+	switch finish {
+	case FinishFoil:
+		return sc.Prices.USDFoil
+	case FinishEtched:
+		return sc.Prices.USDEtched
+	default:
+		return sc.Prices.USD
+	}
+}
+
 // Scryfall describes the interface with something that returns Scryfall data,
 // whether it's a cache or the REST API.
 type Scryfall interface {
@@ -0,0 +1,113 @@
+package scryfall
+
+import (
+	"sort"
+	"strings"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+)
+
+// Completion is a card whose name completes what a person is typing
+type Completion struct {
+	Name     string `json:"name"`
+	OracleID string `json:"oracle_id"`
+}
+
+// Autocompleter is implemented by layers that can complete card names
+type Autocompleter interface {
+	// Autocomplete returns up to limit cards with a name or face name that
+	// has a word starting with query, best first
+	Autocomplete(query string, limit int) ([]Completion, error)
+}
+
+// Autocomplete completes card names with sf if it is an Autocompleter, and
+// otherwise returns no completions
+func Autocomplete(sf inventory.Scryfall, query string, limit int) ([]Completion, error) {
+	if autocompleter, ok := sf.(Autocompleter); ok {
+		return autocompleter.Autocomplete(query, limit)
+	}
+	return []Completion{}, nil
+}
+
+// completionKey is a normalized name, or the part of it from one of its later
+// words on, and the card it completes
+type completionKey struct {
+	key        string
+	later      bool
+	completion Completion
+}
+
+// completionKeys returns the keys of a normalized name of a card
+func completionKeys(normalized string, completion Completion) []completionKey {
+	keys := []completionKey{{key: normalized, completion: completion}}
+	for i, r := range normalized {
+		if r == ' ' {
+			keys = append(keys, completionKey{key: normalized[i+1:], later: true, completion: completion})
+		}
+	}
+	return keys
+}
+
+// rankCompletions sorts the matching keys, preferring matches of the start of
+// a name and then shorter names, and returns up to limit distinct cards
+func rankCompletions(matches []completionKey, limit int) []Completion {
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.later != b.later {
+			return !a.later
+		}
+		if len(a.completion.Name) != len(b.completion.Name) {
+			return len(a.completion.Name) < len(b.completion.Name)
+		}
+		if a.completion.Name != b.completion.Name {
+			return a.completion.Name < b.completion.Name
+		}
+		return a.completion.OracleID < b.completion.OracleID
+	})
+
+	completions := make([]Completion, 0, limit)
+	seen := make(map[Completion]bool)
+	for _, match := range matches {
+		if len(completions) == limit {
+			break
+		}
+		if !seen[match.completion] {
+			seen[match.completion] = true
+			completions = append(completions, match.completion)
+		}
+	}
+	return completions
+}
+
+// completionIndex is the completion keys of every name in a JSONCache, sorted
+// by key so that the keys starting with a query are adjacent
+type completionIndex []completionKey
+
+// newCompletionIndex indexes the names in a JSONCache, which must be locked or
+// not yet shared
+func newCompletionIndex(jc *JSONCache) completionIndex {
+	index := make(completionIndex, 0, len(jc.names)*3)
+	for normalized, cardNames := range jc.names {
+		for _, cardName := range cardNames {
+			for _, card := range jc.NameToOracleMap[cardName] {
+				completion := Completion{Name: cardName, OracleID: card.CardOracleID()}
+				index = append(index, completionKeys(normalized, completion)...)
+			}
+		}
+	}
+	sort.Slice(index, func(i, j int) bool {
+		return index[i].key < index[j].key
+	})
+	return index
+}
+
+func (ci completionIndex) complete(query string, limit int) []Completion {
+	start := sort.Search(len(ci), func(i int) bool {
+		return ci[i].key >= query
+	})
+	matches := make([]completionKey, 0)
+	for i := start; i < len(ci) && strings.HasPrefix(ci[i].key, query); i++ {
+		matches = append(matches, ci[i])
+	}
+	return rankCompletions(matches, limit)
+}
@@ -0,0 +1,130 @@
+package scryfall
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+)
+
+func TestAutocomplete(t *testing.T) {
+	cache, err := NewJSONCache(strings.NewReader(namesBulkData))
+	if err != nil {
+		t.Fatalf("Error loading JSON cache: %s", err.Error())
+	}
+	if cache.completions.Load() == nil {
+		t.Fatalf("Expected names to be indexed when the cache loads")
+	}
+	path := filepath.Join(t.TempDir(), "index.db")
+	err = BuildIndex(strings.NewReader(namesBulkData), path)
+	if err != nil {
+		t.Fatalf("Error building index: %s", err.Error())
+	}
+	index, err := OpenIndex(path)
+	if err != nil {
+		t.Fatalf("Error opening index: %s", err.Error())
+	}
+	defer index.Close()
+
+	tests := []struct {
+		query    string
+		limit    int
+		expected []Completion
+	}{
+		{
+			query: "light",
+			limit: 10,
+			expected: []Completion{
+				{Name: "Lightning Bolt", OracleID: "bolt-oracle"},
+				{Name: "Lightning Helix", OracleID: "helix-oracle"},
+			},
+		},
+		{
+			query: "JACE",
+			limit: 1,
+			expected: []Completion{
+				{Name: "Jace Beleren", OracleID: "jace-bb-oracle"},
+			},
+		},
+		{
+			// Later words match after the starts of names
+			query: "mind scu",
+			limit: 10,
+			expected: []Completion{
+				{Name: "Jace, the Mind Sculptor", OracleID: "jace-tms-oracle"},
+			},
+		},
+		{
+			// Faces complete to their cards
+			query: "ice",
+			limit: 10,
+			expected: []Completion{
+				{Name: "Fire // Ice", OracleID: "fire-ice-oracle"},
+			},
+		},
+		{
+			query: "stomp",
+			limit: 10,
+			expected: []Completion{
+				{Name: "Stomp", OracleID: "stomp-oracle"},
+				{Name: "Bonecrusher Giant // Stomp", OracleID: "giant-oracle"},
+			},
+		},
+		{
+			query:    "lim-dul",
+			limit:    10,
+			expected: []Completion{{Name: "Lim-Dûl's Vault", OracleID: "vault-oracle"}},
+		},
+		{
+			query:    "black lotus",
+			limit:    10,
+			expected: []Completion{},
+		},
+		{
+			query:    " ",
+			limit:    10,
+			expected: []Completion{},
+		},
+	}
+	for _, autocompleter := range []Autocompleter{cache, index} {
+		for _, test := range tests {
+			completions, err := autocompleter.Autocomplete(test.query, test.limit)
+			if err != nil {
+				t.Errorf("%T: error completing %q: %s", autocompleter, test.query, err.Error())
+			} else if !reflect.DeepEqual(completions, test.expected) {
+				t.Errorf("%T: expected %q to complete to %+v, got %+v", autocompleter, test.query, test.expected, completions)
+			}
+		}
+	}
+
+	// Cards added to a JSONCache are completed
+	err = cache.Add(&inventory.ScryfallCard{ID: "lightning-serpent", OracleID: "serpent-oracle", Name: "Lightning Serpent"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	completions, err := cache.Autocomplete("lightning s", 10)
+	if err != nil || len(completions) != 1 || completions[0].Name != "Lightning Serpent" {
+		t.Errorf("Expected added card to be completed, got %+v, %v", completions, err)
+	}
+}
+
+func TestClientAutocomplete(t *testing.T) {
+	client, server := newTestClient(t)
+	defer server.Close()
+
+	completions, err := Autocomplete(NewLayered(client), "Lightning", 10)
+	if err != nil {
+		t.Fatalf("Error completing: %s", err.Error())
+	}
+	expected := []Completion{{Name: "Lightning Bolt", OracleID: "bolt-oracle"}}
+	if !reflect.DeepEqual(completions, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, completions)
+	}
+
+	completions, err = client.Autocomplete("Black Lotus", 10)
+	if err != nil || len(completions) != 0 {
+		t.Errorf("Expected no completions, got %+v, %v", completions, err)
+	}
+}
@@ -0,0 +1,306 @@
+package scryfall
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+const (
+	// BulkDefaultCards is the bulk data containing every card in English or
+	// else its printed language
+	BulkDefaultCards = "default_cards"
+
+	// BulkAllCards is the bulk data containing every card in every language
+	BulkAllCards = "all_cards"
+
+	// BulkOracleCards is the bulk data containing one card per oracle ID
+	BulkOracleCards = "oracle_cards"
+)
+
+// BulkTypes contains every type of bulk data a Downloader can download
+var BulkTypes = []string{BulkDefaultCards, BulkAllCards, BulkOracleCards}
+
+// BulkData describes a bulk data file in the Scryfall manifest
+type BulkData struct {
+	ID              string    `json:"id"`
+	Type            string    `json:"type"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	DownloadURI     string    `json:"download_uri"`
+	Size            int64     `json:"size"`
+	ContentType     string    `json:"content_type"`
+	ContentEncoding string    `json:"content_encoding"`
+}
+
+// Downloader keeps a bulk data file up to date with Scryfall. The file's
+// modification time is set to the time Scryfall updated it, which is how the
+// Downloader knows whether it is current.
+type Downloader struct {
+	// API is used to fetch the manifest
+	API *Client
+
+	// HTTPClient is used to download the file, which can take minutes, so
+	// it has no timeout by default
+	HTTPClient *http.Client
+
+	// Type is the type of bulk data to download
+	Type string
+
+	// Path is where the decompressed bulk data is kept
+	Path string
+}
+
+// NewDownloader returns a Downloader for the type of bulk data that keeps it
+// at path
+func NewDownloader(bulkType, path string) *Downloader {
+	return &Downloader{
+		API:        NewClient(),
+		HTTPClient: &http.Client{},
+		Type:       bulkType,
+		Path:       path,
+	}
+}
+
+// Manifest fetches the description of the newest bulk data of the type
+func (d *Downloader) Manifest() (*BulkData, error) {
+	var bulk BulkData
+	err := d.API.get("/bulk-data/"+url.PathEscape(d.Type), nil, &bulk)
+	if err != nil {
+		return nil, fmt.Errorf("error getting %s manifest: %w", d.Type, err)
+	}
+	if bulk.DownloadURI == "" {
+		return nil, fmt.Errorf("%s manifest has no download URI", d.Type)
+	}
+	return &bulk, nil
+}
+
+// Current returns whether the file at Path is at least as new as bulk
+func (d *Downloader) Current(bulk *BulkData) bool {
+	info, err := os.Stat(d.Path)
+	if err != nil {
+		return false
+	}
+	return !info.ModTime().Before(bulk.UpdatedAt.Truncate(time.Second))
+}
+
+// Update downloads the newest bulk data unless the file at Path is already
+// current, and returns whether it did
+func (d *Downloader) Update(ctx context.Context) (bool, error) {
+	bulk, err := d.Manifest()
+	if err != nil {
+		return false, err
+	}
+	if d.Current(bulk) {
+		return false, nil
+	}
+	err = d.Download(ctx, bulk)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// partPath returns the file that bulk is downloaded to before it's complete,
+// which is named for its update time so that a partial download is only
+// resumed with the same data
+func (d *Downloader) partPath(bulk *BulkData) string {
+	return d.Path + "." + strconv.FormatInt(bulk.UpdatedAt.Unix(), 10) + ".part"
+}
+
+// Download downloads bulk to Path. If a previous download of the same data
+// was interrupted, it's resumed. The data is verified to be complete, to
+// match its gzip checksum and to be a JSON array before it replaces the file
+// at Path.
+func (d *Downloader) Download(ctx context.Context, bulk *BulkData) error {
+	partPath := d.partPath(bulk)
+	d.removeStaleParts(partPath)
+
+	err := d.fetch(ctx, bulk, partPath)
+	if err != nil {
+		return fmt.Errorf("error downloading %s: %w", bulk.DownloadURI, err)
+	}
+
+	err = d.install(bulk, partPath)
+	if err != nil {
+		// The data is bad, so don't resume from it
+		os.Remove(partPath)
+		return fmt.Errorf("error installing %s: %w", bulk.DownloadURI, err)
+	}
+	return nil
+}
+
+// removeStaleParts removes partial downloads of older bulk data
+func (d *Downloader) removeStaleParts(keep string) {
+	parts, err := filepath.Glob(d.Path + ".*.part")
+	if err != nil {
+		return
+	}
+	for _, part := range parts {
+		if part != keep {
+			os.Remove(part)
+		}
+	}
+}
+
+// fetch downloads the raw, possibly gzipped, bulk data to partPath, starting
+// from the end of whatever is already there
+func (d *Downloader) fetch(ctx context.Context, bulk *BulkData, partPath string) (err error) {
+	part, err := os.OpenFile(partPath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("error opening %q: %w", partPath, err)
+	}
+	defer func() {
+		closeErr := part.Close()
+		if err == nil && closeErr != nil {
+			err = fmt.Errorf("error closing %q: %w", partPath, closeErr)
+		}
+	}()
+
+	info, err := part.Stat()
+	if err != nil {
+		return fmt.Errorf("error getting size of %q: %w", partPath, err)
+	}
+	offset := info.Size()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, bulk.DownloadURI, nil)
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("User-Agent", d.API.UserAgent)
+	// Setting Accept-Encoding keeps the transport from decompressing, so
+	// that ranges are of the gzipped bytes that are saved
+	req.Header.Set("Accept-Encoding", "gzip")
+	if offset > 0 {
+		req.Header.Set("Range", "bytes="+strconv.FormatInt(offset, 10)+"-")
+	}
+
+	resp, err := d.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error requesting: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		var start int64
+		_, err = fmt.Sscanf(resp.Header.Get("Content-Range"), "bytes %d-", &start)
+		if err != nil || start != offset {
+			return fmt.Errorf("asked for bytes from %d, got %q", offset, resp.Header.Get("Content-Range"))
+		}
+	case http.StatusOK:
+		// The server ignored the range, so start over
+		err = part.Truncate(0)
+		if err != nil {
+			return fmt.Errorf("error truncating %q: %w", partPath, err)
+		}
+		offset = 0
+	case http.StatusRequestedRangeNotSatisfiable:
+		// The partial file isn't a prefix of the data, so start over next
+		// time
+		part.Truncate(0)
+		return fmt.Errorf("range from %d not satisfiable", offset)
+	default:
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	written, err := io.Copy(part, resp.Body)
+	if err != nil {
+		return fmt.Errorf("error after %d bytes: %w", offset+written, err)
+	}
+	if resp.ContentLength >= 0 && written != resp.ContentLength {
+		return fmt.Errorf("got %d of %d bytes", written, resp.ContentLength)
+	}
+	return nil
+}
+
+// install decompresses and verifies the data at partPath and moves it to Path
+func (d *Downloader) install(bulk *BulkData, partPath string) (err error) {
+	part, err := os.Open(partPath)
+	if err != nil {
+		return fmt.Errorf("error opening %q: %w", partPath, err)
+	}
+	defer part.Close()
+
+	tmpPath := d.Path + ".tmp"
+	tmp, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("error creating %q: %w", tmpPath, err)
+	}
+	defer func() {
+		tmp.Close()
+		if err != nil {
+			os.Remove(tmpPath)
+		}
+	}()
+
+	buffered := bufio.NewReader(part)
+	var reader io.Reader = buffered
+	magic, err := buffered.Peek(2)
+	if err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gzipReader, err := gzip.NewReader(reader)
+		if err != nil {
+			return fmt.Errorf("error reading gzip header: %w", err)
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	}
+
+	// Reading through the gzip reader to the end verifies its checksum
+	err = verifyJSONArray(io.TeeReader(reader, tmp))
+	if err != nil {
+		return err
+	}
+
+	err = tmp.Close()
+	if err != nil {
+		return fmt.Errorf("error closing %q: %w", tmpPath, err)
+	}
+	err = os.Chtimes(tmpPath, bulk.UpdatedAt, bulk.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("error setting time of %q: %w", tmpPath, err)
+	}
+	err = os.Rename(tmpPath, d.Path)
+	if err != nil {
+		return fmt.Errorf("error renaming %q to %q: %w", tmpPath, d.Path, err)
+	}
+	os.Remove(partPath)
+	return nil
+}
+
+// verifyJSONArray reads a JSON array of objects to the end without keeping it
+// in memory
+func verifyJSONArray(reader io.Reader) error {
+	decoder := json.NewDecoder(reader)
+	token, err := decoder.Token()
+	if err != nil {
+		return fmt.Errorf("error reading first token: %w", err)
+	} else if token != json.Delim('[') {
+		return fmt.Errorf("bulk data isn't a JSON array")
+	}
+	for decoder.More() {
+		var element json.RawMessage
+		err = decoder.Decode(&element)
+		if err != nil {
+			return fmt.Errorf("error reading after %d bytes: %w", decoder.InputOffset(), err)
+		}
+	}
+	_, err = decoder.Token()
+	if err != nil {
+		return fmt.Errorf("error reading last token: %w", err)
+	}
+	_, err = decoder.Token()
+	if !errors.Is(err, io.EOF) {
+		return fmt.Errorf("bulk data continues after the JSON array")
+	}
+	return nil
+}
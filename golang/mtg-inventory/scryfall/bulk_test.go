@@ -0,0 +1,210 @@
+package scryfall
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// bulkStandIn is a stand-in for the Scryfall bulk data manifest and downloads
+type bulkStandIn struct {
+	mu        sync.Mutex
+	updatedAt time.Time
+	gzipped   []byte
+	ranges    []string
+}
+
+func (b *bulkStandIn) publish(t *testing.T, updatedAt time.Time, cards ...string) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	fmt.Fprintf(writer, "[%s]", strings.Join(cards, ","))
+	err := writer.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.updatedAt = updatedAt
+	b.gzipped = buf.Bytes()
+}
+
+func newTestDownloader(t *testing.T) (*Downloader, *bulkStandIn) {
+	t.Helper()
+
+	standIn := &bulkStandIn{}
+	var server *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /bulk-data/default_cards", func(w http.ResponseWriter, _ *http.Request) {
+		standIn.mu.Lock()
+		defer standIn.mu.Unlock()
+		fmt.Fprintf(w, `{"object": "bulk_data", "type": "default_cards", "updated_at": %q, "download_uri": "%s/default-cards.json", "content_encoding": "gzip"}`,
+			standIn.updatedAt.Format(time.RFC3339), server.URL)
+	})
+	mux.HandleFunc("GET /default-cards.json", func(w http.ResponseWriter, r *http.Request) {
+		standIn.mu.Lock()
+		defer standIn.mu.Unlock()
+		if r.Header.Get("Accept-Encoding") != "gzip" {
+			w.WriteHeader(http.StatusNotAcceptable)
+			return
+		}
+		standIn.ranges = append(standIn.ranges, r.Header.Get("Range"))
+		w.Header().Set("Content-Encoding", "gzip")
+		http.ServeContent(w, r, "", standIn.updatedAt, bytes.NewReader(standIn.gzipped))
+	})
+	server = httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	downloader := NewDownloader(BulkDefaultCards, filepath.Join(t.TempDir(), "default-cards.json"))
+	downloader.API.BaseURL = server.URL
+	downloader.API.Interval = 0
+	return downloader, standIn
+}
+
+func TestDownloaderUpdate(t *testing.T) {
+	ctx := context.Background()
+	downloader, standIn := newTestDownloader(t)
+	updatedAt := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	standIn.publish(t, updatedAt, boltM10, bolt2XM)
+
+	updated, err := downloader.Update(ctx)
+	if err != nil {
+		t.Fatalf("Error updating: %s", err.Error())
+	} else if !updated {
+		t.Fatalf("Expected first update to download")
+	}
+	info, err := os.Stat(downloader.Path)
+	if err != nil {
+		t.Fatal(err)
+	} else if !info.ModTime().Equal(updatedAt) {
+		t.Errorf("Expected modification time %s, got %s", updatedAt, info.ModTime())
+	}
+	file, err := os.Open(downloader.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	cache, err := NewJSONCache(file)
+	if err != nil {
+		t.Fatalf("Error reading downloaded file: %s", err.Error())
+	}
+	if _, err = cache.GetCardByID("bolt-2xm"); err != nil {
+		t.Errorf("Error getting downloaded card: %s", err.Error())
+	}
+
+	updated, err = downloader.Update(ctx)
+	if err != nil {
+		t.Fatalf("Error updating again: %s", err.Error())
+	} else if updated {
+		t.Errorf("Expected current file not to be downloaded again")
+	}
+}
+
+func TestDownloaderResume(t *testing.T) {
+	downloader, standIn := newTestDownloader(t)
+	updatedAt := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	standIn.publish(t, updatedAt, boltM10, bolt2XM)
+
+	bulk, err := downloader.Manifest()
+	if err != nil {
+		t.Fatalf("Error getting manifest: %s", err.Error())
+	}
+	half := len(standIn.gzipped) / 2
+	err = os.WriteFile(downloader.partPath(bulk), standIn.gzipped[:half], 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = downloader.Download(context.Background(), bulk)
+	if err != nil {
+		t.Fatalf("Error resuming download: %s", err.Error())
+	}
+	if len(standIn.ranges) != 1 || standIn.ranges[0] != fmt.Sprintf("bytes=%d-", half) {
+		t.Errorf("Expected one request for bytes from %d, got %q", half, standIn.ranges)
+	}
+	if _, err = os.Stat(downloader.partPath(bulk)); !os.IsNotExist(err) {
+		t.Errorf("Expected partial file to be removed")
+	}
+}
+
+func TestDownloaderCorrupt(t *testing.T) {
+	downloader, standIn := newTestDownloader(t)
+	updatedAt := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	standIn.publish(t, updatedAt, boltM10)
+	// Flip a byte of the compressed cards so the checksum fails
+	standIn.gzipped[len(standIn.gzipped)/2] ^= 0xff
+
+	_, err := downloader.Update(context.Background())
+	if err == nil {
+		t.Fatalf("Expected corrupt download to fail")
+	}
+	if _, err = os.Stat(downloader.Path); !os.IsNotExist(err) {
+		t.Errorf("Expected corrupt download not to be installed")
+	}
+	parts, _ := filepath.Glob(downloader.Path + ".*")
+	if len(parts) != 0 {
+		t.Errorf("Expected corrupt download to be cleaned up, found %q", parts)
+	}
+}
+
+func TestReloader(t *testing.T) {
+	ctx := context.Background()
+	downloader, standIn := newTestDownloader(t)
+	reloader := NewReloader(downloader)
+
+	_, err := reloader.GetCardByID("bolt-m10")
+	if err == nil {
+		t.Fatalf("Expected lookup before loading to fail")
+	}
+
+	first := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	standIn.publish(t, first, boltM10)
+	err = reloader.Reload(ctx)
+	if err != nil {
+		t.Fatalf("Error reloading: %s", err.Error())
+	}
+	if !reloader.UpdatedAt().Equal(first) {
+		t.Errorf("Expected data from %s, got %s", first, reloader.UpdatedAt())
+	}
+	if _, err = reloader.GetCardByID("bolt-2xm"); err == nil {
+		t.Errorf("Expected unpublished card not to be found")
+	}
+
+	second := first.Add(24 * time.Hour)
+	standIn.publish(t, second, boltM10, bolt2XM)
+	err = reloader.Reload(ctx)
+	if err != nil {
+		t.Fatalf("Error reloading: %s", err.Error())
+	}
+	if !reloader.UpdatedAt().Equal(second) {
+		t.Errorf("Expected data from %s, got %s", second, reloader.UpdatedAt())
+	}
+	if _, err = reloader.GetCardByID("bolt-2xm"); err != nil {
+		t.Errorf("Error getting newly published card: %s", err.Error())
+	}
+
+	// A failed reload keeps the old data
+	standIn.publish(t, second.Add(24*time.Hour), boltM10)
+	standIn.gzipped = standIn.gzipped[:len(standIn.gzipped)/2]
+	err = reloader.Reload(ctx)
+	if err == nil {
+		t.Fatalf("Expected reloading truncated data to fail")
+	}
+	if !reloader.UpdatedAt().Equal(second) {
+		t.Errorf("Expected data from %s to be kept, got %s", second, reloader.UpdatedAt())
+	}
+	if _, err = reloader.GetCardByID("bolt-2xm"); err != nil {
+		t.Errorf("Error getting card after failed reload: %s", err.Error())
+	}
+}
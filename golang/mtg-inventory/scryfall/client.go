@@ -0,0 +1,308 @@
+package scryfall
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+)
+
+const (
+	// DefaultBaseURL is the base URL of the Scryfall REST API
+	DefaultBaseURL = "https://api.scryfall.com"
+
+	// DefaultInterval is the time to wait between requests, which Scryfall
+	// asks to be 50 to 100 milliseconds
+	DefaultInterval = 100 * time.Millisecond
+
+	// DefaultUserAgent identifies the Client to Scryfall, which requires a
+	// User-Agent on every request
+	DefaultUserAgent = "mtg-inventory/1.0"
+
+	// maxRetries bounds the retries of a request that was rate limited
+	maxRetries = 3
+
+	// defaultRetryAfter is waited after being rate limited without a
+	// Retry-After header
+	defaultRetryAfter = time.Second
+)
+
+// APIError is an error object returned by the Scryfall REST API
+type APIError struct {
+	Status  int    `json:"status"`
+	Code    string `json:"code"`
+	Type    string `json:"type"`
+	Details string `json:"details"`
+}
+
+// Error returns a string form of the error
+func (ae *APIError) Error() string {
+	return fmt.Sprintf("Scryfall error %d %s: %s", ae.Status, ae.Code, ae.Details)
+}
+
+// Client implements inventory.Scryfall with the Scryfall REST API
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	UserAgent  string
+
+	// Interval is the minimum time between the starts of requests
+	Interval time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+// NewClient returns a new Client for the Scryfall REST API
+func NewClient() *Client {
+	return &Client{
+		BaseURL:    DefaultBaseURL,
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+		UserAgent:  DefaultUserAgent,
+		Interval:   DefaultInterval,
+	}
+}
+
+// wait blocks until the next request is allowed to start
+func (c *Client) wait() {
+	c.mu.Lock()
+	now := time.Now()
+	start := now
+	if c.next.After(now) {
+		start = c.next
+	}
+	c.next = start.Add(c.Interval)
+	c.mu.Unlock()
+
+	time.Sleep(time.Until(start))
+}
+
+// get requests a path from the API and decodes the response into out. Errors
+// from the API are mapped to ErrNotInCache and ErrMultipleCacheHits where
+// they mean the same thing.
+func (c *Client) get(path string, query url.Values, out any) error {
+	u := c.BaseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	for attempt := 0; ; attempt++ {
+		c.wait()
+
+		req, err := http.NewRequest(http.MethodGet, u, nil)
+		if err != nil {
+			return fmt.Errorf("error creating request to %s: %w", path, err)
+		}
+		req.Header.Set("User-Agent", c.UserAgent)
+		req.Header.Set("Accept", "application/json")
+
+		resp, err := c.HTTPClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("error requesting %s: %w", path, err)
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests && attempt < maxRetries {
+			resp.Body.Close()
+			retryAfter := defaultRetryAfter
+			if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+				retryAfter = time.Duration(seconds) * time.Second
+			}
+			c.backOff(retryAfter)
+			continue
+		}
+
+		err = decodeResponse(resp, out)
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("error getting %s: %w", path, err)
+		}
+		return nil
+	}
+}
+
+// backOff delays the next request by at least d
+func (c *Client) backOff(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if next := time.Now().Add(d); next.After(c.next) {
+		c.next = next
+	}
+}
+
+func decodeResponse(resp *http.Response, out any) error {
+	decoder := json.NewDecoder(resp.Body)
+	if resp.StatusCode == http.StatusOK {
+		err := decoder.Decode(out)
+		if err != nil {
+			return fmt.Errorf("error decoding response: %w", err)
+		}
+		return nil
+	}
+
+	apiErr := &APIError{}
+	err := decoder.Decode(apiErr)
+	if err != nil || apiErr.Status == 0 {
+		apiErr = &APIError{
+			Status:  resp.StatusCode,
+			Details: http.StatusText(resp.StatusCode),
+		}
+	}
+	if apiErr.Status == http.StatusNotFound {
+		if apiErr.Type == "ambiguous" {
+			return fmt.Errorf("%w: %w", ErrMultipleCacheHits, apiErr)
+		}
+		return fmt.Errorf("%w: %w", ErrNotInCache, apiErr)
+	}
+	return apiErr
+}
+
+// search returns the first card matching a Scryfall search query
+func (c *Client) search(query, unique string) (*inventory.ScryfallCard, error) {
+	var list struct {
+		Data []*inventory.ScryfallCard `json:"data"`
+	}
+	err := c.get("/cards/search", url.Values{
+		"q":      {query},
+		"unique": {unique},
+		"order":  {"released"},
+		"dir":    {"desc"},
+	}, &list)
+	if err != nil {
+		return nil, err
+	}
+	if len(list.Data) == 0 {
+		return nil, fmt.Errorf("no results for %q: %w", query, ErrNotInCache)
+	}
+	return list.Data[0], nil
+}
+
+// GetCard implements inventory.Scryfall
+func (c *Client) GetCard(name, set, language, collectorNumber string) (*inventory.ScryfallCard, error) {
+	if collectorNumber == "" {
+		query := fmt.Sprintf("!%q set:%s", name, set)
+		if language != "" {
+			query += " lang:" + language
+		}
+		card, err := c.search(query, "prints")
+		if err != nil {
+			return nil, fmt.Errorf("error getting %q|%q|%q: %w", name, set, language, err)
+		}
+		return card, nil
+	}
+
+	path := "/cards/" + url.PathEscape(set) + "/" + url.PathEscape(collectorNumber)
+	if language != "" {
+		path += "/" + url.PathEscape(language)
+	}
+	var card inventory.ScryfallCard
+	err := c.get(path, nil, &card)
+	if err != nil {
+		return nil, fmt.Errorf("error getting %q|%q|%q|%q: %w", name, set, language, collectorNumber, err)
+	}
+	if !card.HasName(name) {
+		return nil, fmt.Errorf("%s/%s is %q, not %q: %w", set, collectorNumber, card.Name, name, ErrNotInCache)
+	}
+	return &card, nil
+}
+
+// GetCardByName implements inventory.Scryfall
+func (c *Client) GetCardByName(name string) (*inventory.ScryfallCard, error) {
+	var card inventory.ScryfallCard
+	err := c.get("/cards/named", url.Values{"exact": {name}}, &card)
+	if err != nil {
+		return nil, fmt.Errorf("error getting card named %q: %w", name, err)
+	}
+	return &card, nil
+}
+
+// FindCardByName implements Finder with Scryfall's fuzzy name search, which
+// also corrects small typos. Names that don't resolve are autocompleted into
+// suggestions.
+func (c *Client) FindCardByName(name string) (*inventory.ScryfallCard, error) {
+	var card inventory.ScryfallCard
+	err := c.get("/cards/named", url.Values{"fuzzy": {name}}, &card)
+	if err == nil {
+		return &card, nil
+	}
+	if !errors.Is(err, ErrNotInCache) && !errors.Is(err, ErrMultipleCacheHits) {
+		return nil, fmt.Errorf("error finding card named %q: %w", name, err)
+	}
+
+	var catalog struct {
+		Data []string `json:"data"`
+	}
+	autocompleteErr := c.get("/cards/autocomplete", url.Values{"q": {name}}, &catalog)
+	if autocompleteErr != nil {
+		return nil, fmt.Errorf("error finding card named %q: %w", name, err)
+	}
+	suggestions := catalog.Data
+	if len(suggestions) > maxSuggestions {
+		suggestions = suggestions[:maxSuggestions]
+	}
+	nameErr := &NameError{
+		Name:        name,
+		Suggestions: suggestions,
+		Err:         ErrNotInCache,
+	}
+	if errors.Is(err, ErrMultipleCacheHits) {
+		nameErr.Err = ErrMultipleCacheHits
+	}
+	return nil, nameErr
+}
+
+// GetCardByOracleID implements inventory.Scryfall
+func (c *Client) GetCardByOracleID(oracleID string) (*inventory.ScryfallCard, error) {
+	card, err := c.search("oracleid:"+oracleID, "cards")
+	if err != nil {
+		return nil, fmt.Errorf("error getting card with oracle ID %q: %w", oracleID, err)
+	}
+	return card, nil
+}
+
+// GetCardByID implements inventory.Scryfall
+func (c *Client) GetCardByID(scryfallID string) (*inventory.ScryfallCard, error) {
+	var card inventory.ScryfallCard
+	err := c.get("/cards/"+url.PathEscape(scryfallID), nil, &card)
+	if err != nil {
+		return nil, fmt.Errorf("error getting card with Scryfall ID %q: %w", scryfallID, err)
+	}
+	return &card, nil
+}
+
+// Autocomplete implements Autocompleter with Scryfall's search, which matches
+// the words of names in any order
+func (c *Client) Autocomplete(query string, limit int) ([]Completion, error) {
+	if strings.TrimSpace(query) == "" || limit <= 0 {
+		return []Completion{}, nil
+	}
+
+	var list struct {
+		Data []*inventory.ScryfallCard `json:"data"`
+	}
+	err := c.get("/cards/search", url.Values{
+		"q":      {query},
+		"unique": {"cards"},
+		"order":  {"name"},
+	}, &list)
+	if errors.Is(err, ErrNotInCache) {
+		return []Completion{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error completing %q: %w", query, err)
+	}
+
+	completions := make([]Completion, 0, limit)
+	for _, card := range list.Data {
+		if len(completions) == limit {
+			break
+		}
+		completions = append(completions, Completion{Name: card.Name, OracleID: card.CardOracleID()})
+	}
+	return completions, nil
+}
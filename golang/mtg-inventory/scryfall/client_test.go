@@ -0,0 +1,228 @@
+package scryfall
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const (
+	boltM10 = `{"object": "card", "id": "bolt-m10", "lang": "en", "oracle_id": "bolt-oracle", "name": "Lightning Bolt", "collector_number": "146", "released_at": "2009-07-17", "set": "m10"}`
+	bolt2XM = `{"object": "card", "id": "bolt-2xm", "lang": "en", "oracle_id": "bolt-oracle", "name": "Lightning Bolt", "collector_number": "129", "released_at": "2020-08-07", "set": "2xm"}`
+
+	delverISD = `{"object": "card", "id": "delver-isd", "lang": "en", "mtgo_id": 42000, "mtgo_foil_id": 42001, "oracle_id": "delver-oracle", "name": "Delver of Secrets // Insectile Aberration", "layout": "transform", "mana_cost": "", "type_line": "Creature — Human Wizard // Creature — Human Insect", "colors": [], "color_identity": ["U"], "legalities": {"legacy": "legal", "modern": "legal", "standard": "not_legal"}, "reserved": false, "collector_number": "51", "finishes": ["nonfoil", "foil"], "games": ["paper", "mtgo"], "prices": {"usd": "0.50", "usd_foil": "4.25", "usd_etched": null, "eur": "0.30", "eur_foil": null, "tix": "0.03"}, "rarity": "common", "released_at": "2011-09-30", "set": "isd", "set_name": "Innistrad", "card_faces": [{"object": "card_face", "name": "Delver of Secrets", "mana_cost": "{U}", "type_line": "Creature — Human Wizard", "colors": ["U"], "image_uris": {"small": "https://cards.example/small/front/delver.jpg", "normal": "https://cards.example/normal/front/delver.jpg"}}, {"object": "card_face", "name": "Insectile Aberration", "mana_cost": "", "type_line": "Creature — Human Insect", "colors": ["U"], "image_uris": {"small": "https://cards.example/small/back/delver.jpg", "normal": "https://cards.example/normal/back/delver.jpg"}}]}`
+
+	notFound  = `{"object": "error", "code": "not_found", "status": 404, "details": "No cards found matching \"Black Lotus\""}`
+	ambiguous = `{"object": "error", "code": "not_found", "status": 404, "type": "ambiguous", "details": "Too many cards match ambiguous name \"Bolt\"."}`
+)
+
+// newTestClient returns a Client for a stand-in for the Scryfall API with a
+// few fixed responses
+func newTestClient(t *testing.T) (*Client, *httptest.Server) {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /cards/named", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Has("fuzzy") {
+			switch r.URL.Query().Get("fuzzy") {
+			case "lightning blot":
+				fmt.Fprint(w, bolt2XM)
+			case "Bolt":
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, ambiguous)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, notFound)
+			}
+			return
+		}
+		switch r.URL.Query().Get("exact") {
+		case "Lightning Bolt":
+			fmt.Fprint(w, bolt2XM)
+		case "Bolt":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, ambiguous)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, notFound)
+		}
+	})
+	mux.HandleFunc("GET /cards/autocomplete", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("q") == "Bolt" {
+			fmt.Fprint(w, `{"object": "catalog", "data": ["Lightning Bolt"]}`)
+			return
+		}
+		fmt.Fprint(w, `{"object": "catalog", "data": []}`)
+	})
+	mux.HandleFunc("GET /cards/m10/146/en", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, boltM10)
+	})
+	mux.HandleFunc("GET /cards/isd/51/en", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, delverISD)
+	})
+	mux.HandleFunc("GET /cards/bolt-m10", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, boltM10)
+	})
+	mux.HandleFunc("GET /cards/delver-isd", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, delverISD)
+	})
+	mux.HandleFunc("GET /cards/search", func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("q") {
+		case "oracleid:bolt-oracle", `!"Lightning Bolt" set:2xm lang:en`, "Lightning":
+			fmt.Fprintf(w, `{"object": "list", "data": [%s]}`, bolt2XM)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, notFound)
+		}
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, notFound)
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("User-Agent") != DefaultUserAgent || r.Header.Get("Accept") != "application/json" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"object": "error", "code": "bad_request", "status": 400, "details": "Missing headers"}`)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+
+	client := NewClient()
+	client.BaseURL = server.URL
+	client.Interval = 0
+	return client, server
+}
+
+func TestClient(t *testing.T) {
+	client, server := newTestClient(t)
+	defer server.Close()
+
+	card, err := client.GetCardByName("Lightning Bolt")
+	if err != nil {
+		t.Fatalf("Failed to get card by name: %s", err.Error())
+	}
+	if card.ID != "bolt-2xm" {
+		t.Fatalf("Unexpected card by name: %+v", card)
+	}
+
+	_, err = client.GetCardByName("Black Lotus")
+	if !errors.Is(err, ErrNotInCache) {
+		t.Fatalf("Expected ErrNotInCache for a missing name, got: %v", err)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != "not_found" {
+		t.Fatalf("Expected an APIError, got: %v", err)
+	}
+
+	_, err = client.GetCardByName("Bolt")
+	if !errors.Is(err, ErrMultipleCacheHits) {
+		t.Fatalf("Expected ErrMultipleCacheHits for an ambiguous name, got: %v", err)
+	}
+
+	card, err = client.GetCard("Lightning Bolt", "m10", "en", "146")
+	if err != nil {
+		t.Fatalf("Failed to get card by set and collector number: %s", err.Error())
+	}
+	if card.ID != "bolt-m10" {
+		t.Fatalf("Unexpected card by set and collector number: %+v", card)
+	}
+
+	card, err = client.GetCard("lightning bolt", "m10", "en", "146")
+	if err != nil || card.ID != "bolt-m10" {
+		t.Fatalf("Expected names to match ignoring case, got: %+v, %v", card, err)
+	}
+
+	card, err = client.GetCard("Insectile Aberration", "isd", "en", "51")
+	if err != nil || card.ID != "delver-isd" {
+		t.Fatalf("Expected the name of a face to match, got: %+v, %v", card, err)
+	}
+
+	_, err = client.GetCard("Shock", "m10", "en", "146")
+	if !errors.Is(err, ErrNotInCache) {
+		t.Fatalf("Expected ErrNotInCache for the wrong name, got: %v", err)
+	}
+
+	card, err = client.GetCard("Lightning Bolt", "2xm", "en", "")
+	if err != nil {
+		t.Fatalf("Failed to get card by set: %s", err.Error())
+	}
+	if card.ID != "bolt-2xm" {
+		t.Fatalf("Unexpected card by set: %+v", card)
+	}
+
+	card, err = client.GetCardByOracleID("bolt-oracle")
+	if err != nil {
+		t.Fatalf("Failed to get card by oracle ID: %s", err.Error())
+	}
+	if card.OracleID != "bolt-oracle" {
+		t.Fatalf("Unexpected card by oracle ID: %+v", card)
+	}
+
+	card, err = client.GetCardByID("bolt-m10")
+	if err != nil {
+		t.Fatalf("Failed to get card by ID: %s", err.Error())
+	}
+	if card.Name != "Lightning Bolt" || card.Set != "m10" {
+		t.Fatalf("Unexpected card by ID: %+v", card)
+	}
+
+	_, err = client.GetCardByID("missing")
+	if !errors.Is(err, ErrNotInCache) {
+		t.Fatalf("Expected ErrNotInCache for a missing ID, got: %v", err)
+	}
+
+	card, err = client.FindCardByName("lightning blot")
+	if err != nil {
+		t.Fatalf("Failed to find card by fuzzy name: %s", err.Error())
+	}
+	if card.ID != "bolt-2xm" {
+		t.Fatalf("Unexpected card by fuzzy name: %+v", card)
+	}
+
+	_, err = client.FindCardByName("Black Lotus")
+	var nameErr *NameError
+	if !errors.Is(err, ErrNotInCache) || !errors.As(err, &nameErr) || len(nameErr.Suggestions) != 0 {
+		t.Fatalf("Expected a NameError without suggestions for a missing fuzzy name, got: %v", err)
+	}
+}
+
+func TestClientRateLimit(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		// Rate limit the first request to check that it is retried
+		if requests.Add(1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, `{"object": "error", "code": "rate_limited", "status": 429, "details": "Slow down"}`)
+			return
+		}
+		fmt.Fprint(w, boltM10)
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	client.BaseURL = server.URL
+	client.Interval = 20 * time.Millisecond
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		_, err := client.GetCardByID("bolt-m10")
+		if err != nil {
+			t.Fatalf("Failed to get card: %s", err.Error())
+		}
+	}
+	elapsed := time.Since(start)
+
+	if requests.Load() != 4 {
+		t.Fatalf("Expected 4 requests, got %d", requests.Load())
+	}
+	if elapsed < 3*client.Interval {
+		t.Fatalf("Expected 4 requests to take at least %s, took %s", 3*client.Interval, elapsed)
+	}
+}
@@ -0,0 +1,91 @@
+package scryfall
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+)
+
+// FileCache is a JSONCache persisted to a file of JSON cards, one per line, so
+// that cards added to it survive restarts
+type FileCache struct {
+	*JSONCache
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+// OpenFileCache loads the cards in the file at path, creating it if it doesn't
+// exist, and opens it to append cards added later
+func OpenFileCache(path string) (_ *FileCache, err error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("error opening %q: %w", path, err)
+	}
+	defer func() {
+		if err != nil {
+			file.Close()
+		}
+	}()
+
+	cache := newJSONCache()
+	decoder := json.NewDecoder(file)
+	for {
+		var card inventory.ScryfallCard
+		err = decoder.Decode(&card)
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("error reading %q after %d bytes: %w", path, decoder.InputOffset(), err)
+		}
+
+		err = cache.add(&card)
+		if err != nil {
+			return nil, fmt.Errorf("error adding card from %q: %w", path, err)
+		}
+	}
+	cache.indexCompletions()
+
+	return &FileCache{
+		JSONCache: cache,
+		file:      file,
+	}, nil
+}
+
+// Add adds a card to the cache and appends it to the file
+func (fc *FileCache) Add(card *inventory.ScryfallCard) error {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	_, err := fc.GetCardByID(card.ID)
+	if err == nil {
+		return nil
+	}
+
+	line, err := json.Marshal(card)
+	if err != nil {
+		return fmt.Errorf("error marshaling card %q: %w", card.ID, err)
+	}
+
+	// Add to memory first, which rejects cards that couldn't be loaded again
+	err = fc.JSONCache.Add(card)
+	if err != nil {
+		return err
+	}
+
+	_, err = fc.file.Write(append(line, '\n'))
+	if err != nil {
+		return fmt.Errorf("error writing card %q: %w", card.ID, err)
+	}
+	return nil
+}
+
+// Close closes the file
+func (fc *FileCache) Close() error {
+	return fc.file.Close()
+}
@@ -0,0 +1,406 @@
+package scryfall
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	// indexCards maps Scryfall IDs to cards
+	indexCards = []byte("cards")
+
+	// indexKeys maps name, set and language to the ID of the preferred card
+	indexKeys = []byte("keys")
+
+	// indexNumbers maps name, set, language and collector number to the ID
+	// of the first card
+	indexNumbers = []byte("numbers")
+
+	// indexOracleIDs maps oracle IDs to the ID of the preferred card
+	indexOracleIDs = []byte("oracle_ids")
+
+	// indexNames maps name and oracle ID to the ID of the preferred card, so
+	// that the cards with a name are adjacent
+	indexNames = []byte("names")
+
+	// indexNormalizedNames contains normalized names of cards and faces and
+	// the names of the cards they name, with empty values
+	indexNormalizedNames = []byte("normalized_names")
+
+	// indexCompletions contains the completion keys of names and the cards
+	// they complete, with whether they start at a later word as values
+	indexCompletions = []byte("completions")
+
+	indexBuckets = [][]byte{indexCards, indexKeys, indexNumbers, indexOracleIDs, indexNames, indexNormalizedNames, indexCompletions}
+)
+
+// indexBatchSize is the number of cards added to an index per transaction,
+// which bounds the memory used to build it
+const indexBatchSize = 10000
+
+// indexKey joins the parts of a key with a separator that can't be in them
+func indexKey(parts ...string) []byte {
+	return []byte(strings.Join(parts, "\x00"))
+}
+
+// Index implements inventory.Scryfall with an index of bulk data in a bbolt
+// file. The file is memory mapped, so opening it is fast and cards are only
+// decoded when they're looked up. It gives the same answers as a JSONCache of
+// the same bulk data.
+type Index struct {
+	db *bolt.DB
+}
+
+// BuildIndex reads bulk data and writes an index of it to path, replacing
+// anything there
+func BuildIndex(reader io.Reader, path string) (err error) {
+	tmpPath := path + ".tmp"
+	os.Remove(tmpPath)
+	db, err := bolt.Open(tmpPath, 0o644, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return fmt.Errorf("error creating %q: %w", tmpPath, err)
+	}
+	defer func() {
+		if db != nil {
+			db.Close()
+		}
+		if err != nil {
+			os.Remove(tmpPath)
+		}
+	}()
+	// The index is only renamed into place after it's synced at the end
+	db.NoSync = true
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range indexBuckets {
+			_, err := tx.CreateBucket(bucket)
+			if err != nil {
+				return fmt.Errorf("error creating bucket %q: %w", bucket, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(reader)
+	_, err = decoder.Token()
+	if err != nil {
+		return fmt.Errorf("error reading first token: %w", err)
+	}
+	for decoder.More() {
+		err = db.Update(func(tx *bolt.Tx) error {
+			for i := 0; i < indexBatchSize && decoder.More(); i++ {
+				var card inventory.ScryfallCard
+				err := decoder.Decode(&card)
+				if err != nil {
+					return fmt.Errorf("error reading after %d bytes: %w", decoder.InputOffset(), err)
+				}
+				err = indexCard(tx, &card)
+				if err != nil {
+					return fmt.Errorf("error indexing card after %d bytes: %w", decoder.InputOffset(), err)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	err = db.Sync()
+	if err != nil {
+		return fmt.Errorf("error syncing %q: %w", tmpPath, err)
+	}
+	err = db.Close()
+	db = nil
+	if err != nil {
+		return fmt.Errorf("error closing %q: %w", tmpPath, err)
+	}
+	err = os.Rename(tmpPath, path)
+	if err != nil {
+		return fmt.Errorf("error renaming %q to %q: %w", tmpPath, path, err)
+	}
+	return nil
+}
+
+// indexCard adds a card to the index the same way JSONCache.add adds it to the
+// maps of a JSONCache
+func indexCard(tx *bolt.Tx, card *inventory.ScryfallCard) error {
+	cards := tx.Bucket(indexCards)
+	if cards.Get([]byte(card.ID)) != nil {
+		return nil
+	}
+
+	if card.OracleID == "" && len(card.CardFaces) == 0 {
+		return fmt.Errorf("card %q has an empty oracle ID", card.ID)
+	}
+	oracleID := card.CardOracleID()
+
+	data, err := json.Marshal(card)
+	if err != nil {
+		return fmt.Errorf("error marshaling card %q: %w", card.ID, err)
+	}
+	err = cards.Put([]byte(card.ID), data)
+	if err != nil {
+		return fmt.Errorf("error putting card %q: %w", card.ID, err)
+	}
+
+	err = putPreferred(tx, indexKeys, indexKey(card.Name, card.Set, card.Language), card)
+	if err != nil {
+		return err
+	}
+
+	numbers := tx.Bucket(indexNumbers)
+	numberKey := indexKey(card.Name, card.Set, card.Language, card.CollectorNumber)
+	if numbers.Get(numberKey) == nil {
+		err = numbers.Put(numberKey, []byte(card.ID))
+		if err != nil {
+			return fmt.Errorf("error putting collector number of %q: %w", card.ID, err)
+		}
+	}
+
+	err = putPreferred(tx, indexOracleIDs, []byte(oracleID), card)
+	if err != nil {
+		return err
+	}
+
+	err = putPreferred(tx, indexNames, indexKey(card.Name, card.OracleID), card)
+	if err != nil {
+		return err
+	}
+
+	normalizedNames := tx.Bucket(indexNormalizedNames)
+	names := make(nameIndex)
+	names.add(card)
+	for normalized, cardNames := range names {
+		for _, cardName := range cardNames {
+			err = normalizedNames.Put(indexKey(normalized, cardName), []byte{})
+			if err != nil {
+				return fmt.Errorf("error putting normalized name of %q: %w", card.ID, err)
+			}
+		}
+	}
+
+	return putCompletions(tx, card)
+}
+
+// putPreferred points key in bucket at card unless it points at a card that is
+// preferred to it
+func putPreferred(tx *bolt.Tx, bucket, key []byte, card *inventory.ScryfallCard) error {
+	b := tx.Bucket(bucket)
+	if currentID := b.Get(key); currentID != nil {
+		current, err := getIndexed(tx, currentID)
+		if err != nil {
+			return err
+		}
+		if getPreferredCard(current, card) == current {
+			return nil
+		}
+	}
+	err := b.Put(key, []byte(card.ID))
+	if err != nil {
+		return fmt.Errorf("error putting %q in %s: %w", card.ID, bucket, err)
+	}
+	return nil
+}
+
+// getIndexed decodes the card with a Scryfall ID
+func getIndexed(tx *bolt.Tx, scryfallID []byte) (*inventory.ScryfallCard, error) {
+	data := tx.Bucket(indexCards).Get(scryfallID)
+	if data == nil {
+		return nil, fmt.Errorf("didn't find scryfall ID %q: %w", scryfallID, ErrNotInCache)
+	}
+	var card inventory.ScryfallCard
+	err := json.Unmarshal(data, &card)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshaling card %q: %w", scryfallID, err)
+	}
+	return &card, nil
+}
+
+// OpenIndex opens an index written by BuildIndex
+func OpenIndex(path string) (_ *Index, err error) {
+	db, err := bolt.Open(path, 0o644, &bolt.Options{
+		ReadOnly: true,
+		Timeout:  time.Second,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error opening %q: %w", path, err)
+	}
+	defer func() {
+		if err != nil {
+			db.Close()
+		}
+	}()
+
+	err = db.View(func(tx *bolt.Tx) error {
+		for _, bucket := range indexBuckets {
+			if tx.Bucket(bucket) == nil {
+				return fmt.Errorf("%q is missing bucket %q", path, bucket)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Index{db: db}, nil
+}
+
+// Close closes the index file
+func (idx *Index) Close() error {
+	return idx.db.Close()
+}
+
+// lookup gets the card whose ID is at key in bucket
+func (idx *Index) lookup(bucket, key []byte) (card *inventory.ScryfallCard, err error) {
+	err = idx.db.View(func(tx *bolt.Tx) error {
+		id := tx.Bucket(bucket).Get(key)
+		if id == nil {
+			return ErrNotInCache
+		}
+		card, err = getIndexed(tx, id)
+		return err
+	})
+	return card, err
+}
+
+// GetCard implements inventory.Scryfall
+func (idx *Index) GetCard(name, set, language, collectorNumber string) (*inventory.ScryfallCard, error) {
+	var card *inventory.ScryfallCard
+	var err error
+	if collectorNumber == "" {
+		card, err = idx.lookup(indexKeys, indexKey(name, set, language))
+	} else {
+		card, err = idx.lookup(indexNumbers, indexKey(name, set, language, collectorNumber))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("didn't find %q|%q|%q|%q: %w", name, set, language, collectorNumber, err)
+	}
+	return card, nil
+}
+
+// GetCardByName implements inventory.Scryfall
+func (idx *Index) GetCardByName(name string) (card *inventory.ScryfallCard, err error) {
+	prefix := indexKey(name, "")
+	err = idx.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(indexNames).Cursor()
+		key, id := cursor.Seek(prefix)
+		if key == nil || !bytes.HasPrefix(key, prefix) {
+			return fmt.Errorf("didn't find name %q: %w", name, ErrNotInCache)
+		}
+		if next, _ := cursor.Next(); next != nil && bytes.HasPrefix(next, prefix) {
+			return fmt.Errorf("found multiple cards named %q: %w", name, ErrMultipleCacheHits)
+		}
+		card, err = getIndexed(tx, id)
+		return err
+	})
+	return card, err
+}
+
+// GetCardByOracleID implements inventory.Scryfall
+func (idx *Index) GetCardByOracleID(oracleID string) (*inventory.ScryfallCard, error) {
+	card, err := idx.lookup(indexOracleIDs, []byte(oracleID))
+	if err != nil {
+		return nil, fmt.Errorf("didn't find oracle ID %q: %w", oracleID, err)
+	}
+	return card, nil
+}
+
+// GetCardByID implements inventory.Scryfall
+func (idx *Index) GetCardByID(scryfallID string) (card *inventory.ScryfallCard, err error) {
+	err = idx.db.View(func(tx *bolt.Tx) error {
+		card, err = getIndexed(tx, []byte(scryfallID))
+		return err
+	})
+	return card, err
+}
+
+// FindCardByName implements Finder
+func (idx *Index) FindCardByName(name string) (*inventory.ScryfallCard, error) {
+	return findByName(name, &nameSource{
+		exact: func(normalized string) []string {
+			prefix := indexKey(normalized, "")
+			var cardNames []string
+			idx.db.View(func(tx *bolt.Tx) error {
+				cursor := tx.Bucket(indexNormalizedNames).Cursor()
+				for key, _ := cursor.Seek(prefix); key != nil && bytes.HasPrefix(key, prefix); key, _ = cursor.Next() {
+					cardNames = append(cardNames, string(key[len(prefix):]))
+				}
+				return nil
+			})
+			return cardNames
+		},
+		each: func(visit func(normalized, cardName string)) {
+			idx.db.View(func(tx *bolt.Tx) error {
+				return tx.Bucket(indexNormalizedNames).ForEach(func(key, _ []byte) error {
+					normalized, cardName, _ := strings.Cut(string(key), "\x00")
+					visit(normalized, cardName)
+					return nil
+				})
+			})
+		},
+		get: idx.GetCardByName,
+	})
+}
+
+// Autocomplete implements Autocompleter
+func (idx *Index) Autocomplete(query string, limit int) ([]Completion, error) {
+	normalized := normalizeName(query)
+	if normalized == "" || limit <= 0 {
+		return []Completion{}, nil
+	}
+
+	prefix := []byte(normalized)
+	matches := make([]completionKey, 0)
+	err := idx.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(indexCompletions).Cursor()
+		for key, value := cursor.Seek(prefix); key != nil && bytes.HasPrefix(key, prefix); key, value = cursor.Next() {
+			parts := strings.Split(string(key), "\x00")
+			if len(parts) != 3 || len(value) != 1 {
+				return fmt.Errorf("malformed completion %q", key)
+			}
+			matches = append(matches, completionKey{
+				key:        parts[0],
+				later:      value[0] == 1,
+				completion: Completion{Name: parts[1], OracleID: parts[2]},
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error completing %q: %w", query, err)
+	}
+	return rankCompletions(matches, limit), nil
+}
+
+// putCompletions adds the completion keys of a card to an index
+func putCompletions(tx *bolt.Tx, card *inventory.ScryfallCard) error {
+	completions := tx.Bucket(indexCompletions)
+	completion := Completion{Name: card.Name, OracleID: card.CardOracleID()}
+	names := make(nameIndex)
+	names.add(card)
+	for normalized := range names {
+		for _, key := range completionKeys(normalized, completion) {
+			var later byte
+			if key.later {
+				later = 1
+			}
+			err := completions.Put(indexKey(key.key, completion.Name, completion.OracleID), []byte{later})
+			if err != nil {
+				return fmt.Errorf("error putting completion of %q: %w", card.ID, err)
+			}
+		}
+	}
+	return nil
+}
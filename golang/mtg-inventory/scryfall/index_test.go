@@ -0,0 +1,117 @@
+package scryfall
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+)
+
+func TestIndex(t *testing.T) {
+	scryfallBulkData, err := os.Open("./testdata/all-cards.json")
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			t.Skip("Download Scryfall bulk data and install in ./testdata/all-cards.json for full testing")
+		} else {
+			t.Fatalf("Error opening Scryfall bulk data file: %s", err.Error())
+		}
+	}
+	defer scryfallBulkData.Close()
+
+	path := filepath.Join(t.TempDir(), "index.db")
+	err = BuildIndex(scryfallBulkData, path)
+	if err != nil {
+		t.Fatalf("Error building index: %s", err.Error())
+	}
+	index, err := OpenIndex(path)
+	if err != nil {
+		t.Fatalf("Error opening index: %s", err.Error())
+	}
+	defer index.Close()
+
+	testBulkDataLookups(t, index)
+}
+
+func TestIndexMatchesJSONCache(t *testing.T) {
+	bulkData := "[" + strings.Join([]string{
+		boltM10,
+		bolt2XM,
+		`{"object": "card", "id": "bolt-2xm-ja", "lang": "ja", "oracle_id": "bolt-oracle", "name": "Lightning Bolt", "collector_number": "129", "released_at": "2020-08-07", "set": "2xm"}`,
+		`{"object": "card", "id": "bolt-2xm-alt", "lang": "en", "oracle_id": "bolt-oracle", "name": "Lightning Bolt", "collector_number": "129", "released_at": "2020-08-07", "set": "2xm"}`,
+		`{"object": "card", "id": "command-a", "lang": "en", "oracle_id": "command-oracle-a", "name": "Very Cryptic Command", "collector_number": "1", "released_at": "2019-11-15", "set": "und"}`,
+		`{"object": "card", "id": "command-b", "lang": "en", "oracle_id": "command-oracle-b", "name": "Very Cryptic Command", "collector_number": "2", "released_at": "2019-11-15", "set": "und"}`,
+		`{"object": "card", "id": "reversible", "lang": "en", "name": "Zndrsplt // Zndrsplt", "collector_number": "3", "released_at": "2021-01-01", "set": "sld", "card_faces": [{"name": "Zndrsplt", "oracle_id": "zndrsplt-oracle"}, {"name": "Zndrsplt", "oracle_id": "zndrsplt-oracle"}]}`,
+		boltM10,
+	}, ",") + "]"
+
+	cache, err := NewJSONCache(strings.NewReader(bulkData))
+	if err != nil {
+		t.Fatalf("Error loading JSON cache: %s", err.Error())
+	}
+	path := filepath.Join(t.TempDir(), "index.db")
+	err = BuildIndex(strings.NewReader(bulkData), path)
+	if err != nil {
+		t.Fatalf("Error building index: %s", err.Error())
+	}
+	index, err := OpenIndex(path)
+	if err != nil {
+		t.Fatalf("Error opening index: %s", err.Error())
+	}
+	defer index.Close()
+
+	lookups := map[string]func(sf inventory.Scryfall) (*inventory.ScryfallCard, error){
+		"name Lightning Bolt": func(sf inventory.Scryfall) (*inventory.ScryfallCard, error) {
+			return sf.GetCardByName("Lightning Bolt")
+		},
+		"name Very Cryptic Command": func(sf inventory.Scryfall) (*inventory.ScryfallCard, error) {
+			return sf.GetCardByName("Very Cryptic Command")
+		},
+		"name Lightning": func(sf inventory.Scryfall) (*inventory.ScryfallCard, error) { return sf.GetCardByName("Lightning") },
+		"name Zndrsplt // Zndrsplt": func(sf inventory.Scryfall) (*inventory.ScryfallCard, error) {
+			return sf.GetCardByName("Zndrsplt // Zndrsplt")
+		},
+		"set 2xm en": func(sf inventory.Scryfall) (*inventory.ScryfallCard, error) {
+			return sf.GetCard("Lightning Bolt", "2xm", "en", "")
+		},
+		"set 2xm ja 129": func(sf inventory.Scryfall) (*inventory.ScryfallCard, error) {
+			return sf.GetCard("Lightning Bolt", "2xm", "ja", "129")
+		},
+		"set 2xm en 129": func(sf inventory.Scryfall) (*inventory.ScryfallCard, error) {
+			return sf.GetCard("Lightning Bolt", "2xm", "en", "129")
+		},
+		"set m10 en 1": func(sf inventory.Scryfall) (*inventory.ScryfallCard, error) {
+			return sf.GetCard("Lightning Bolt", "m10", "en", "1")
+		},
+		"oracle bolt-oracle": func(sf inventory.Scryfall) (*inventory.ScryfallCard, error) {
+			return sf.GetCardByOracleID("bolt-oracle")
+		},
+		"oracle zndrsplt-oracle": func(sf inventory.Scryfall) (*inventory.ScryfallCard, error) {
+			return sf.GetCardByOracleID("zndrsplt-oracle")
+		},
+		"oracle missing": func(sf inventory.Scryfall) (*inventory.ScryfallCard, error) { return sf.GetCardByOracleID("missing") },
+		"ID bolt-2xm-ja": func(sf inventory.Scryfall) (*inventory.ScryfallCard, error) { return sf.GetCardByID("bolt-2xm-ja") },
+		"ID missing":     func(sf inventory.Scryfall) (*inventory.ScryfallCard, error) { return sf.GetCardByID("missing") },
+	}
+	for name, lookup := range lookups {
+		want, wantErr := lookup(cache)
+		got, gotErr := lookup(index)
+		switch {
+		case wantErr != nil:
+			if gotErr == nil {
+				t.Errorf("%s: expected error like %q, got card %q", name, wantErr.Error(), got.ID)
+			} else if errors.Is(wantErr, ErrNotInCache) != errors.Is(gotErr, ErrNotInCache) ||
+				errors.Is(wantErr, ErrMultipleCacheHits) != errors.Is(gotErr, ErrMultipleCacheHits) {
+				t.Errorf("%s: expected error like %q, got %q", name, wantErr.Error(), gotErr.Error())
+			}
+		case gotErr != nil:
+			t.Errorf("%s: expected card %q, got error %q", name, want.ID, gotErr.Error())
+		case got.ID != want.ID:
+			t.Errorf("%s: expected card %q, got %q", name, want.ID, got.ID)
+		case got.ReleasedAt.Value != want.ReleasedAt.Value || len(got.CardFaces) != len(want.CardFaces):
+			t.Errorf("%s: expected %+v, got %+v", name, want, got)
+		}
+	}
+}
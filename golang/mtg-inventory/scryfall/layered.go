@@ -0,0 +1,146 @@
+package scryfall
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+)
+
+// Adder is implemented by layers that can remember cards found by the layers
+// after them
+type Adder interface {
+	Add(card *inventory.ScryfallCard) error
+}
+
+// Layered implements inventory.Scryfall by asking each of its layers in turn
+// until one has the card. A card found by a later layer is added to every
+// earlier layer that implements Adder, so the next lookup is answered sooner.
+type Layered struct {
+	Layers []inventory.Scryfall
+}
+
+// NewLayered returns a Layered that asks layers in the order given
+func NewLayered(layers ...inventory.Scryfall) *Layered {
+	return &Layered{
+		Layers: layers,
+	}
+}
+
+// UpdatedAt returns the latest time the data of any Dated layer was updated
+func (l *Layered) UpdatedAt() time.Time {
+	var latest time.Time
+	for _, layer := range l.Layers {
+		if dated, ok := layer.(Dated); ok && dated.UpdatedAt().After(latest) {
+			latest = dated.UpdatedAt()
+		}
+	}
+	return latest
+}
+
+// lookup calls get on each layer until one doesn't return ErrNotInCache, and
+// memoizes the card it returns in the layers before it. If no layer has the
+// card, the first error with suggestions is returned.
+func (l *Layered) lookup(get func(inventory.Scryfall) (*inventory.ScryfallCard, error)) (*inventory.ScryfallCard, error) {
+	var suggested error
+	for i, layer := range l.Layers {
+		card, err := get(layer)
+		if errors.Is(err, ErrNotInCache) {
+			var nameErr *NameError
+			if suggested == nil && errors.As(err, &nameErr) && len(nameErr.Suggestions) > 0 {
+				suggested = err
+			}
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		for _, earlier := range l.Layers[:i] {
+			if adder, ok := earlier.(Adder); ok {
+				err = adder.Add(card)
+				if err != nil {
+					// The card was still found, so only log
+					log.Printf("Error memoizing card %q: %s", card.ID, err.Error())
+				}
+			}
+		}
+		return card, nil
+	}
+	if suggested != nil {
+		return nil, suggested
+	}
+	return nil, fmt.Errorf("not in any of %d layers: %w", len(l.Layers), ErrNotInCache)
+}
+
+// GetCard implements inventory.Scryfall
+func (l *Layered) GetCard(name, set, language, collectorNumber string) (*inventory.ScryfallCard, error) {
+	card, err := l.lookup(func(layer inventory.Scryfall) (*inventory.ScryfallCard, error) {
+		return layer.GetCard(name, set, language, collectorNumber)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting %q|%q|%q|%q: %w", name, set, language, collectorNumber, err)
+	}
+	return card, nil
+}
+
+// GetCardByName implements inventory.Scryfall
+func (l *Layered) GetCardByName(name string) (*inventory.ScryfallCard, error) {
+	card, err := l.lookup(func(layer inventory.Scryfall) (*inventory.ScryfallCard, error) {
+		return layer.GetCardByName(name)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting card named %q: %w", name, err)
+	}
+	return card, nil
+}
+
+// FindCardByName implements Finder with the layers that are Finders, and finds
+// exact names with the others
+func (l *Layered) FindCardByName(name string) (*inventory.ScryfallCard, error) {
+	card, err := l.lookup(func(layer inventory.Scryfall) (*inventory.ScryfallCard, error) {
+		return FindCardByName(layer, name)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error finding card named %q: %w", name, err)
+	}
+	return card, nil
+}
+
+// GetCardByOracleID implements inventory.Scryfall
+func (l *Layered) GetCardByOracleID(oracleID string) (*inventory.ScryfallCard, error) {
+	card, err := l.lookup(func(layer inventory.Scryfall) (*inventory.ScryfallCard, error) {
+		return layer.GetCardByOracleID(oracleID)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting card with oracle ID %q: %w", oracleID, err)
+	}
+	return card, nil
+}
+
+// GetCardByID implements inventory.Scryfall
+func (l *Layered) GetCardByID(scryfallID string) (*inventory.ScryfallCard, error) {
+	card, err := l.lookup(func(layer inventory.Scryfall) (*inventory.ScryfallCard, error) {
+		return layer.GetCardByID(scryfallID)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting card with Scryfall ID %q: %w", scryfallID, err)
+	}
+	return card, nil
+}
+
+// Autocomplete implements Autocompleter with the first layer that completes
+// the query
+func (l *Layered) Autocomplete(query string, limit int) ([]Completion, error) {
+	for _, layer := range l.Layers {
+		completions, err := Autocomplete(layer, query, limit)
+		if err != nil {
+			return nil, err
+		}
+		if len(completions) > 0 {
+			return completions, nil
+		}
+	}
+	return []Completion{}, nil
+}
@@ -0,0 +1,128 @@
+package scryfall
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLayered(t *testing.T) {
+	bulk, err := NewJSONCache(strings.NewReader("[" + bolt2XM + "]"))
+	if err != nil {
+		t.Fatalf("Failed to load JSON cache: %s", err.Error())
+	}
+
+	path := filepath.Join(t.TempDir(), "cache.jsonl")
+	file, err := OpenFileCache(path)
+	if err != nil {
+		t.Fatalf("Failed to open file cache: %s", err.Error())
+	}
+	defer file.Close()
+
+	client, server := newTestClient(t)
+	defer server.Close()
+
+	layered := NewLayered(bulk, file, client)
+
+	card, err := layered.GetCardByName("Lightning Bolt")
+	if err != nil {
+		t.Fatalf("Failed to get card by name: %s", err.Error())
+	}
+	if card.ID != "bolt-2xm" {
+		t.Fatalf("Unexpected card by name: %+v", card)
+	}
+	_, err = file.GetCardByID("bolt-2xm")
+	if !errors.Is(err, ErrNotInCache) {
+		t.Fatalf("Expected card found in the first layer not to be memoized, got: %v", err)
+	}
+
+	card, err = layered.GetCardByID("bolt-m10")
+	if err != nil {
+		t.Fatalf("Failed to get card from the API: %s", err.Error())
+	}
+	if card.Set != "m10" {
+		t.Fatalf("Unexpected card from the API: %+v", card)
+	}
+	for name, layer := range map[string]*JSONCache{"bulk": bulk, "file": file.JSONCache} {
+		_, err = layer.GetCard("Lightning Bolt", "m10", "en", "146")
+		if err != nil {
+			t.Fatalf("Expected card from the API to be memoized in the %s layer: %s", name, err.Error())
+		}
+	}
+
+	_, err = layered.GetCardByName("Bolt")
+	if !errors.Is(err, ErrMultipleCacheHits) {
+		t.Fatalf("Expected ErrMultipleCacheHits from the API, got: %v", err)
+	}
+	_, err = layered.GetCardByName("Black Lotus")
+	if !errors.Is(err, ErrNotInCache) {
+		t.Fatalf("Expected ErrNotInCache from every layer, got: %v", err)
+	}
+
+	err = file.Close()
+	if err != nil {
+		t.Fatalf("Failed to close file cache: %s", err.Error())
+	}
+	reopened, err := OpenFileCache(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen file cache: %s", err.Error())
+	}
+	defer reopened.Close()
+	card, err = reopened.GetCardByID("bolt-m10")
+	if err != nil {
+		t.Fatalf("Expected memoized card to be persisted: %s", err.Error())
+	}
+	if card.ReleasedAt.Value != "2009-07-17" || card.CollectorNumber != "146" {
+		t.Fatalf("Unexpected persisted card: %+v", card)
+	}
+}
+
+func TestOpenLayers(t *testing.T) {
+	_, err := OpenLayers(context.Background(), nil, &LayerConfig{})
+	if err == nil {
+		t.Fatalf("Expected an error opening no layers")
+	}
+	_, err = OpenLayers(context.Background(), []string{"bogus"}, &LayerConfig{})
+	if err == nil {
+		t.Fatalf("Expected an error opening an unknown layer")
+	}
+
+	sf, err := OpenLayers(context.Background(), []string{LayerAPI}, &LayerConfig{})
+	if err != nil {
+		t.Fatalf("Failed to open API layer: %s", err.Error())
+	}
+	if _, ok := sf.(*Client); !ok {
+		t.Fatalf("Expected a single layer to be returned alone, got %T", sf)
+	}
+
+	sf, err = OpenLayers(context.Background(), []string{LayerFile, LayerAPI}, &LayerConfig{CacheFile: filepath.Join(t.TempDir(), "cache.jsonl")})
+	if err != nil {
+		t.Fatalf("Failed to open layers: %s", err.Error())
+	}
+	layered, ok := sf.(*Layered)
+	if !ok || len(layered.Layers) != 2 {
+		t.Fatalf("Expected two layers, got %+v", sf)
+	}
+	layered.Layers[0].(*FileCache).Close()
+
+	dir := t.TempDir()
+	config := &LayerConfig{
+		BulkDataFile: filepath.Join(dir, "all-cards.json"),
+		IndexFile:    filepath.Join(dir, "index.db"),
+	}
+	err = os.WriteFile(config.BulkDataFile, []byte("["+boltM10+"]"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sf, err = OpenLayers(context.Background(), []string{LayerIndex}, config)
+	if err != nil {
+		t.Fatalf("Failed to open index layer: %s", err.Error())
+	}
+	if _, err = sf.GetCardByID("bolt-m10"); err != nil {
+		t.Errorf("Error getting card from built index: %s", err.Error())
+	}
+	sf.(*Index).Close()
+}
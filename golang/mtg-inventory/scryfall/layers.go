@@ -0,0 +1,164 @@
+package scryfall
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+)
+
+const (
+	// LayerBulk is the name of a JSONCache loaded from the bulk data file
+	LayerBulk = "bulk"
+
+	// LayerIndex is the name of an Index of the bulk data file
+	LayerIndex = "index"
+
+	// LayerFile is the name of a FileCache
+	LayerFile = "file"
+
+	// LayerAPI is the name of a Client for the REST API
+	LayerAPI = "api"
+)
+
+// LayerNames contains every name that OpenLayers accepts
+var LayerNames = []string{LayerBulk, LayerIndex, LayerFile, LayerAPI}
+
+// LayerConfig configures the layers opened by OpenLayers
+type LayerConfig struct {
+	// BulkDataFile is the bulk data file loaded by the bulk layer and
+	// indexed by the index layer
+	BulkDataFile string
+
+	// IndexFile is the index opened by the index layer, which is rebuilt
+	// from BulkDataFile when it is missing or older
+	IndexFile string
+
+	// BulkDataType is the type of bulk data the bulk layer downloads
+	BulkDataType string
+
+	// Refresh is how often the bulk layer checks for newer bulk data, or
+	// zero to only load BulkDataFile once
+	Refresh time.Duration
+
+	// CacheFile is the file the file layer is persisted to
+	CacheFile string
+}
+
+// OpenLayers opens the named layers and returns them as a Layered, or alone if
+// only one is named. If the bulk layer is refreshed, it is refreshed until ctx
+// is done.
+func OpenLayers(ctx context.Context, names []string, config *LayerConfig) (inventory.Scryfall, error) {
+	layers := make([]inventory.Scryfall, 0, len(names))
+	for _, name := range names {
+		switch name {
+		case LayerBulk:
+			if config.Refresh > 0 {
+				reloader, err := openReloader(ctx, config)
+				if err != nil {
+					return nil, err
+				}
+				layers = append(layers, reloader)
+				continue
+			}
+
+			bulkData, err := os.Open(config.BulkDataFile)
+			if err != nil {
+				return nil, fmt.Errorf("error opening bulk data file: %w", err)
+			}
+			jsonCache, err := NewJSONCache(bulkData)
+			bulkData.Close()
+			if err != nil {
+				return nil, fmt.Errorf("error reading bulk data file: %w", err)
+			}
+			layers = append(layers, jsonCache)
+		case LayerIndex:
+			index, err := openIndex(config)
+			if err != nil {
+				return nil, err
+			}
+			layers = append(layers, index)
+		case LayerFile:
+			fileCache, err := OpenFileCache(config.CacheFile)
+			if err != nil {
+				return nil, fmt.Errorf("error opening cache file: %w", err)
+			}
+			layers = append(layers, fileCache)
+		case LayerAPI:
+			layers = append(layers, NewClient())
+		default:
+			return nil, fmt.Errorf("unknown Scryfall layer %q, must be one of %s", name, strings.Join(LayerNames, ", "))
+		}
+	}
+
+	if len(layers) == 0 {
+		return nil, fmt.Errorf("no Scryfall layers, must name at least one of %s", strings.Join(LayerNames, ", "))
+	} else if len(layers) == 1 {
+		return layers[0], nil
+	}
+	return NewLayered(layers...), nil
+}
+
+// openReloader returns a Reloader of the bulk data file that is refreshed in
+// the background. If there is no bulk data file yet, it is downloaded first.
+func openReloader(ctx context.Context, config *LayerConfig) (*Reloader, error) {
+	reloader := NewReloader(NewDownloader(config.BulkDataType, config.BulkDataFile))
+	err := reloader.Load()
+	if err != nil {
+		err = reloader.Reload(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error loading bulk data: %w", err)
+		}
+	}
+	go reloader.Run(ctx, config.Refresh)
+	return reloader, nil
+}
+
+// openIndex opens the index file, building it first if the bulk data file is
+// newer
+func openIndex(config *LayerConfig) (*Index, error) {
+	indexInfo, err := os.Stat(config.IndexFile)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("error checking index file: %w", err)
+	}
+	bulkInfo, bulkErr := os.Stat(config.BulkDataFile)
+	if indexInfo == nil || (bulkErr == nil && bulkInfo.ModTime().After(indexInfo.ModTime())) {
+		err = buildIndexFile(config)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	index, err := OpenIndex(config.IndexFile)
+	if err != nil {
+		// The index may be from an older version, so build it again
+		log.Printf("Rebuilding index file: %s", err.Error())
+		err = buildIndexFile(config)
+		if err != nil {
+			return nil, err
+		}
+		index, err = OpenIndex(config.IndexFile)
+		if err != nil {
+			return nil, fmt.Errorf("error opening index file: %w", err)
+		}
+	}
+	return index, nil
+}
+
+func buildIndexFile(config *LayerConfig) error {
+	bulkData, err := os.Open(config.BulkDataFile)
+	if err != nil {
+		return fmt.Errorf("error opening bulk data file to index: %w", err)
+	}
+	defer bulkData.Close()
+	err = BuildIndex(bulkData, config.IndexFile)
+	if err != nil {
+		return fmt.Errorf("error indexing bulk data file: %w", err)
+	}
+	return nil
+}
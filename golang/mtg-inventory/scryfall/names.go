@@ -0,0 +1,252 @@
+package scryfall
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+	"golang.org/x/text/unicode/norm"
+)
+
+// maxSuggestions bounds the names suggested for a name that doesn't resolve
+const maxSuggestions = 5
+
+// NameError is returned when a name doesn't resolve to exactly one card. It
+// wraps ErrNotInCache or ErrMultipleCacheHits and suggests the names of the
+// cards that were probably meant, best first.
+type NameError struct {
+	Name        string
+	Suggestions []string
+	Err         error
+}
+
+// Error returns a string form of the error
+func (ne *NameError) Error() string {
+	var msg string
+	if errors.Is(ne.Err, ErrMultipleCacheHits) {
+		msg = fmt.Sprintf("found multiple cards named %q", ne.Name)
+	} else {
+		msg = fmt.Sprintf("didn't find name %q", ne.Name)
+	}
+	if len(ne.Suggestions) > 0 {
+		msg += ", did you mean " + QuoteNames(ne.Suggestions, "or")
+	}
+	return msg + ": " + ne.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (ne *NameError) Unwrap() error {
+	return ne.Err
+}
+
+// QuoteNames quotes names and joins them into a list like "A", "B" or "C"
+func QuoteNames(names []string, conjunction string) string {
+	quoted := make([]string, 0, len(names))
+	for _, name := range names {
+		quoted = append(quoted, fmt.Sprintf("%q", name))
+	}
+	if len(quoted) < 2 {
+		return strings.Join(quoted, "")
+	}
+	return strings.Join(quoted[:len(quoted)-1], ", ") + " " + conjunction + " " + quoted[len(quoted)-1]
+}
+
+// Finder is implemented by layers that can find cards by names that aren't
+// exact
+type Finder interface {
+	FindCardByName(name string) (*inventory.ScryfallCard, error)
+}
+
+// FindCardByName finds a card by a name typed by a person, which can differ
+// from the card's name in case, punctuation and diacritics, or be the name of
+// one of its faces. If sf isn't a Finder, only exact names are found.
+func FindCardByName(sf inventory.Scryfall, name string) (*inventory.ScryfallCard, error) {
+	if finder, ok := sf.(Finder); ok {
+		return finder.FindCardByName(name)
+	}
+	return sf.GetCardByName(name)
+}
+
+// normalizeName folds case, drops diacritics and punctuation, and separates
+// words with single spaces, so that "Lim-Dûl's Vault" is "lim duls vault"
+func normalizeName(name string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range norm.NFD.String(name) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// Diacritics are separate marks after NFD
+		case r == 'æ' || r == 'Æ':
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			space = false
+			b.WriteString("ae")
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			space = false
+			b.WriteRune(unicode.ToLower(r))
+		case unicode.IsSpace(r) || r == '-' || r == '/' || r == '_':
+			space = true
+		}
+	}
+	if b.Len() == 0 {
+		// Names like "_____" are all punctuation
+		return strings.ToLower(strings.TrimSpace(name))
+	}
+	return b.String()
+}
+
+// nameIndex maps normalized names to the names of the cards they name. The
+// names of faces map to the names of their cards.
+type nameIndex map[string][]string
+
+func (ni nameIndex) add(card *inventory.ScryfallCard) {
+	ni.addName(card.Name, card.Name)
+	for _, face := range card.CardFaces {
+		ni.addName(face.Name, card.Name)
+	}
+}
+
+func (ni nameIndex) addName(name, cardName string) {
+	normalized := normalizeName(name)
+	if !slices.Contains(ni[normalized], cardName) {
+		ni[normalized] = append(ni[normalized], cardName)
+	}
+}
+
+// nameSource is what findByName needs from a cache
+type nameSource struct {
+	// exact returns the names of the cards a normalized name names
+	exact func(normalized string) []string
+
+	// each calls visit with every normalized name and the name of a card it
+	// names
+	each func(visit func(normalized, cardName string))
+
+	// get gets a card by its exact name
+	get func(cardName string) (*inventory.ScryfallCard, error)
+}
+
+// findByName resolves a name typed by a person with source
+func findByName(name string, source *nameSource) (*inventory.ScryfallCard, error) {
+	normalized := normalizeName(name)
+	cardNames := source.exact(normalized)
+	switch len(cardNames) {
+	case 0:
+		return nil, &NameError{
+			Name:        name,
+			Suggestions: suggestNames(normalized, source.each),
+			Err:         ErrNotInCache,
+		}
+	case 1:
+		return source.get(cardNames[0])
+	default:
+		sort.Strings(cardNames)
+		if len(cardNames) > maxSuggestions {
+			cardNames = cardNames[:maxSuggestions]
+		}
+		return nil, &NameError{
+			Name:        name,
+			Suggestions: cardNames,
+			Err:         ErrMultipleCacheHits,
+		}
+	}
+}
+
+// suggestNames ranks the names of the cards that start with, contain, or are
+// a few typos away from a normalized name
+func suggestNames(normalized string, each func(visit func(normalized, cardName string))) []string {
+	type suggestion struct {
+		cardName string
+		score    int
+	}
+	maxDistance := 1
+	if len(normalized) > 8 {
+		maxDistance = 3
+	} else if len(normalized) > 4 {
+		maxDistance = 2
+	}
+
+	best := make(map[string]int)
+	each(func(candidate, cardName string) {
+		score := -1
+		switch {
+		case strings.HasPrefix(candidate, normalized):
+			score = 0
+		case strings.Contains(candidate, " "+normalized):
+			score = 1
+		case strings.Contains(candidate, normalized):
+			score = 2
+		default:
+			if distance := editDistance(normalized, candidate, maxDistance); distance <= maxDistance {
+				score = 2 + distance
+			}
+		}
+		if current, exists := best[cardName]; score >= 0 && (!exists || score < current) {
+			best[cardName] = score
+		}
+	})
+
+	suggestions := make([]suggestion, 0, len(best))
+	for cardName, score := range best {
+		suggestions = append(suggestions, suggestion{cardName: cardName, score: score})
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].score != suggestions[j].score {
+			return suggestions[i].score < suggestions[j].score
+		}
+		// Prefer shorter names, which are closer to what was typed
+		if len(suggestions[i].cardName) != len(suggestions[j].cardName) {
+			return len(suggestions[i].cardName) < len(suggestions[j].cardName)
+		}
+		return suggestions[i].cardName < suggestions[j].cardName
+	})
+	if len(suggestions) > maxSuggestions {
+		suggestions = suggestions[:maxSuggestions]
+	}
+
+	names := make([]string, 0, len(suggestions))
+	for _, s := range suggestions {
+		names = append(names, s.cardName)
+	}
+	return names
+}
+
+// editDistance returns the Levenshtein distance between a and b, or a number
+// greater than limit once it's known to be
+func editDistance(a, b string, limit int) int {
+	ra, rb := []rune(a), []rune(b)
+	if diff := len(ra) - len(rb); diff > limit || -diff > limit {
+		return limit + 1
+	}
+
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		rowMin := current[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+			rowMin = min(rowMin, current[j])
+		}
+		if rowMin > limit {
+			return limit + 1
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
@@ -0,0 +1,126 @@
+package scryfall
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeName(t *testing.T) {
+	for name, normalized := range map[string]string{
+		"Lightning Bolt":          "lightning bolt",
+		"Jace, the Mind Sculptor": "jace the mind sculptor",
+		"Jace the Mind Sculptor":  "jace the mind sculptor",
+		"Lim-Dûl's Vault":         "lim duls vault",
+		"Æther Vial":              "aether vial",
+		"Fire // Ice":             "fire ice",
+		"  Séance  ":              "seance",
+		"_____":                   "_____",
+	} {
+		if got := normalizeName(name); got != normalized {
+			t.Errorf("Expected %q to normalize to %q, got %q", name, normalized, got)
+		}
+	}
+}
+
+const namesBulkData = `[
+	{"id": "bolt", "lang": "en", "oracle_id": "bolt-oracle", "name": "Lightning Bolt", "collector_number": "1", "released_at": "2020-01-01", "set": "a"},
+	{"id": "helix", "lang": "en", "oracle_id": "helix-oracle", "name": "Lightning Helix", "collector_number": "2", "released_at": "2020-01-01", "set": "a"},
+	{"id": "jace-tms", "lang": "en", "oracle_id": "jace-tms-oracle", "name": "Jace, the Mind Sculptor", "collector_number": "3", "released_at": "2020-01-01", "set": "a"},
+	{"id": "jace-bb", "lang": "en", "oracle_id": "jace-bb-oracle", "name": "Jace Beleren", "collector_number": "4", "released_at": "2020-01-01", "set": "a"},
+	{"id": "fire-ice", "lang": "en", "name": "Fire // Ice", "collector_number": "5", "released_at": "2020-01-01", "set": "a", "card_faces": [{"name": "Fire", "oracle_id": "fire-ice-oracle"}, {"name": "Ice", "oracle_id": "fire-ice-oracle"}]},
+	{"id": "giant", "lang": "en", "oracle_id": "giant-oracle", "name": "Bonecrusher Giant // Stomp", "collector_number": "6", "released_at": "2020-01-01", "set": "a", "card_faces": [{"name": "Bonecrusher Giant"}, {"name": "Stomp"}]},
+	{"id": "stomp", "lang": "en", "oracle_id": "stomp-oracle", "name": "Stomp", "collector_number": "7", "released_at": "2020-01-01", "set": "a"},
+	{"id": "vault", "lang": "en", "oracle_id": "vault-oracle", "name": "Lim-Dûl's Vault", "collector_number": "8", "released_at": "2020-01-01", "set": "a"}
+]`
+
+func TestFindCardByName(t *testing.T) {
+	cache, err := NewJSONCache(strings.NewReader(namesBulkData))
+	if err != nil {
+		t.Fatalf("Error loading JSON cache: %s", err.Error())
+	}
+	path := filepath.Join(t.TempDir(), "index.db")
+	err = BuildIndex(strings.NewReader(namesBulkData), path)
+	if err != nil {
+		t.Fatalf("Error building index: %s", err.Error())
+	}
+	index, err := OpenIndex(path)
+	if err != nil {
+		t.Fatalf("Error opening index: %s", err.Error())
+	}
+	defer index.Close()
+
+	for _, finder := range []Finder{cache, index} {
+		for name, id := range map[string]string{
+			"lightning bolt":         "bolt",
+			"LIGHTNING BOLT":         "bolt",
+			"Jace the Mind Sculptor": "jace-tms",
+			"Fire":                   "fire-ice",
+			"ice":                    "fire-ice",
+			"fire//ice":              "fire-ice",
+			"Bonecrusher Giant":      "giant",
+			"Lim-Dul's Vault":        "vault",
+			"lim duls vault":         "vault",
+		} {
+			card, err := finder.FindCardByName(name)
+			if err != nil {
+				t.Errorf("%T: error finding %q: %s", finder, name, err.Error())
+			} else if card.ID != id {
+				t.Errorf("%T: expected %q to find %q, got %q", finder, name, id, card.ID)
+			}
+		}
+
+		for name, suggestions := range map[string][]string{
+			"Lightning Blot": {"Lightning Bolt"},
+			"Lightning":      {"Lightning Bolt", "Lightning Helix"},
+			"jace":           {"Jace Beleren", "Jace, the Mind Sculptor"},
+			"Black Lotus":    nil,
+		} {
+			_, err := finder.FindCardByName(name)
+			var nameErr *NameError
+			if !errors.Is(err, ErrNotInCache) || !errors.As(err, &nameErr) {
+				t.Errorf("%T: expected a NameError wrapping ErrNotInCache finding %q, got %v", finder, name, err)
+			} else if !slices.Equal(nameErr.Suggestions, suggestions) {
+				t.Errorf("%T: expected %q to suggest %q, got %q", finder, name, suggestions, nameErr.Suggestions)
+			}
+		}
+
+		// Stomp is both a card and the face of another card
+		_, err = finder.FindCardByName("stomp")
+		var nameErr *NameError
+		if !errors.Is(err, ErrMultipleCacheHits) || !errors.As(err, &nameErr) {
+			t.Errorf("%T: expected a NameError wrapping ErrMultipleCacheHits, got %v", finder, err)
+		} else if !slices.Equal(nameErr.Suggestions, []string{"Bonecrusher Giant // Stomp", "Stomp"}) {
+			t.Errorf("%T: unexpected suggestions for an ambiguous name: %q", finder, nameErr.Suggestions)
+		}
+	}
+}
+
+func TestLayeredFindCardByName(t *testing.T) {
+	cache, err := NewJSONCache(strings.NewReader(namesBulkData))
+	if err != nil {
+		t.Fatalf("Error loading JSON cache: %s", err.Error())
+	}
+	client, server := newTestClient(t)
+	defer server.Close()
+	layered := NewLayered(cache, client)
+
+	card, err := layered.FindCardByName("jace the mind sculptor")
+	if err != nil || card.ID != "jace-tms" {
+		t.Fatalf("Expected to find a card in the first layer, got %+v, %v", card, err)
+	}
+
+	// The cache suggests names before the client is asked
+	_, err = layered.FindCardByName("Lightning Blot")
+	var nameErr *NameError
+	if !errors.As(err, &nameErr) || !slices.Equal(nameErr.Suggestions, []string{"Lightning Bolt"}) {
+		t.Fatalf("Expected suggestions from the first layer, got %v", err)
+	}
+
+	_, err = FindCardByName(NewLayered(client), "Bolt")
+	if !errors.Is(err, ErrMultipleCacheHits) || !errors.As(err, &nameErr) || !slices.Equal(nameErr.Suggestions, []string{"Lightning Bolt"}) {
+		t.Fatalf("Expected suggestions from the client, got %v", err)
+	}
+}
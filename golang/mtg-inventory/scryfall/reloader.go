@@ -0,0 +1,171 @@
+package scryfall
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync/atomic"
+	"time"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+)
+
+// Dated is implemented by layers that know when their data was updated
+type Dated interface {
+	UpdatedAt() time.Time
+}
+
+// loadedBulkData is a JSONCache and the time its bulk data was updated
+type loadedBulkData struct {
+	cache     *JSONCache
+	updatedAt time.Time
+}
+
+// Reloader implements inventory.Scryfall with a JSONCache of the bulk data
+// kept by its Downloader, which it swaps for a new JSONCache whenever the
+// Downloader downloads newer data. Lookups are never blocked by a reload.
+type Reloader struct {
+	Downloader *Downloader
+
+	loaded atomic.Pointer[loadedBulkData]
+}
+
+// NewReloader returns a Reloader for the bulk data kept by downloader
+func NewReloader(downloader *Downloader) *Reloader {
+	return &Reloader{
+		Downloader: downloader,
+	}
+}
+
+// UpdatedAt returns the time Scryfall updated the loaded bulk data, or the
+// zero time if none is loaded
+func (r *Reloader) UpdatedAt() time.Time {
+	loaded := r.loaded.Load()
+	if loaded == nil {
+		return time.Time{}
+	}
+	return loaded.updatedAt
+}
+
+// Load loads the bulk data already on disk and swaps it in
+func (r *Reloader) Load() error {
+	path := r.Downloader.Path
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("error opening bulk data file: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("error getting time of %q: %w", path, err)
+	}
+	cache, err := NewJSONCache(file)
+	if err != nil {
+		return fmt.Errorf("error reading bulk data file: %w", err)
+	}
+
+	r.loaded.Store(&loadedBulkData{
+		cache:     cache,
+		updatedAt: info.ModTime(),
+	})
+	return nil
+}
+
+// Reload downloads newer bulk data if there is any and swaps it in. Nothing
+// is swapped in if the download fails, so the old data keeps being used.
+func (r *Reloader) Reload(ctx context.Context) error {
+	updated, err := r.Downloader.Update(ctx)
+	if err != nil {
+		return fmt.Errorf("error updating bulk data: %w", err)
+	}
+	if !updated && r.loaded.Load() != nil {
+		return nil
+	}
+	return r.Load()
+}
+
+// Run reloads the bulk data now and then every interval until ctx is done
+func (r *Reloader) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		before := r.UpdatedAt()
+		err := r.Reload(ctx)
+		if err != nil {
+			log.Printf("Error reloading Scryfall bulk data: %s", err.Error())
+		} else if after := r.UpdatedAt(); !after.Equal(before) {
+			log.Printf("Loaded Scryfall bulk data updated at %s", after.Format(time.RFC3339))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// cache returns the loaded JSONCache
+func (r *Reloader) cache() (*JSONCache, error) {
+	loaded := r.loaded.Load()
+	if loaded == nil {
+		return nil, fmt.Errorf("no bulk data loaded: %w", ErrNotInCache)
+	}
+	return loaded.cache, nil
+}
+
+// GetCard implements inventory.Scryfall
+func (r *Reloader) GetCard(name, set, language, collectorNumber string) (*inventory.ScryfallCard, error) {
+	cache, err := r.cache()
+	if err != nil {
+		return nil, err
+	}
+	return cache.GetCard(name, set, language, collectorNumber)
+}
+
+// GetCardByName implements inventory.Scryfall
+func (r *Reloader) GetCardByName(name string) (*inventory.ScryfallCard, error) {
+	cache, err := r.cache()
+	if err != nil {
+		return nil, err
+	}
+	return cache.GetCardByName(name)
+}
+
+// GetCardByOracleID implements inventory.Scryfall
+func (r *Reloader) GetCardByOracleID(oracleID string) (*inventory.ScryfallCard, error) {
+	cache, err := r.cache()
+	if err != nil {
+		return nil, err
+	}
+	return cache.GetCardByOracleID(oracleID)
+}
+
+// GetCardByID implements inventory.Scryfall
+func (r *Reloader) GetCardByID(scryfallID string) (*inventory.ScryfallCard, error) {
+	cache, err := r.cache()
+	if err != nil {
+		return nil, err
+	}
+	return cache.GetCardByID(scryfallID)
+}
+
+// FindCardByName implements Finder
+func (r *Reloader) FindCardByName(name string) (*inventory.ScryfallCard, error) {
+	cache, err := r.cache()
+	if err != nil {
+		return nil, err
+	}
+	return cache.FindCardByName(name)
+}
+
+// Autocomplete implements Autocompleter
+func (r *Reloader) Autocomplete(query string, limit int) ([]Completion, error) {
+	cache, err := r.cache()
+	if err != nil {
+		return []Completion{}, nil
+	}
+	return cache.Autocomplete(query, limit)
+}
@@ -1,6 +1,6 @@
 /*
-Package scryfall contains code used to interact with Scryfall data, generally
-through a local cache of the bulk data.
+Package scryfall contains code used to interact with Scryfall data, either
+through a local cache of the bulk data or through the REST API.
 */
 package scryfall
 
@@ -9,7 +9,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 
 	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
 )
@@ -70,6 +73,20 @@ type JSONCache struct {
 	OracleIDMap     map[string]*inventory.ScryfallCard
 	ScryfallIDMap   map[string]*inventory.ScryfallCard
 	NameToOracleMap map[string]map[string]*inventory.ScryfallCard
+
+	names       nameIndex
+	completions atomic.Pointer[completionIndex]
+	mu          sync.RWMutex
+}
+
+func newJSONCache() *JSONCache {
+	return &JSONCache{
+		KeyMap:          make(map[cardKey]*cardsWithDefault),
+		OracleIDMap:     make(map[string]*inventory.ScryfallCard),
+		ScryfallIDMap:   make(map[string]*inventory.ScryfallCard),
+		NameToOracleMap: make(map[string]map[string]*inventory.ScryfallCard),
+		names:           make(nameIndex),
+	}
 }
 
 // NewJSONCache creates a JSONCache
@@ -80,12 +97,7 @@ func NewJSONCache(reader io.Reader) (*JSONCache, error) {
 		return nil, fmt.Errorf("error reading first token: %w", err)
 	}
 
-	cache := &JSONCache{
-		KeyMap:          make(map[cardKey]*cardsWithDefault),
-		OracleIDMap:     make(map[string]*inventory.ScryfallCard),
-		ScryfallIDMap:   make(map[string]*inventory.ScryfallCard),
-		NameToOracleMap: make(map[string]map[string]*inventory.ScryfallCard),
-	}
+	cache := newJSONCache()
 
 	for decoder.More() {
 		var card inventory.ScryfallCard
@@ -94,57 +106,94 @@ func NewJSONCache(reader io.Reader) (*JSONCache, error) {
 			return nil, fmt.Errorf("error reading after %d bytes: %w", decoder.InputOffset(), err)
 		}
 
-		key := cardKey{
-			Name:     card.Name,
-			Set:      card.Set,
-			Language: card.Language,
-		}
-		if _, exists := cache.KeyMap[key]; !exists {
-			cache.KeyMap[key] = &cardsWithDefault{
-				Default:            &card,
-				CollectorNumberMap: make(map[string][]*inventory.ScryfallCard),
-			}
-		} else {
-			cache.KeyMap[key].Default = getPreferredCard(cache.KeyMap[key].Default, &card)
-		}
-		if _, exists := cache.KeyMap[key].CollectorNumberMap[card.CollectorNumber]; !exists {
-			cache.KeyMap[key].CollectorNumberMap[card.CollectorNumber] = make([]*inventory.ScryfallCard, 0)
-		}
-		cache.KeyMap[key].CollectorNumberMap[card.CollectorNumber] = append(cache.KeyMap[key].CollectorNumberMap[card.CollectorNumber], &card)
-
-		var oracleID string
-		if card.OracleID == "" {
-			if len(card.CardFaces) > 0 {
-				oracleID = card.CardFaces[0].OracleID
-			} else {
-				return nil, fmt.Errorf("card with empty oracle ID after %d bytes", decoder.InputOffset())
-			}
-		} else {
-			oracleID = card.OracleID
-		}
-		if current, exists := cache.OracleIDMap[oracleID]; !exists {
-			cache.OracleIDMap[oracleID] = &card
-		} else {
-			cache.OracleIDMap[oracleID] = getPreferredCard(current, &card)
+		err = cache.add(&card)
+		if err != nil {
+			return nil, fmt.Errorf("error adding card after %d bytes: %w", decoder.InputOffset(), err)
 		}
+	}
+	cache.indexCompletions()
+
+	return cache, nil
+}
 
-		cache.ScryfallIDMap[card.ID] = &card
+// Add adds a card to the cache, such as one fetched from the REST API. Cards
+// already in the cache are ignored.
+func (jc *JSONCache) Add(card *inventory.ScryfallCard) error {
+	jc.mu.Lock()
+	defer jc.mu.Unlock()
+	if _, exists := jc.ScryfallIDMap[card.ID]; exists {
+		return nil
+	}
+	err := jc.add(card)
+	if err != nil {
+		return err
+	}
+	jc.indexCompletions()
+	return nil
+}
 
-		if _, exists := cache.NameToOracleMap[card.Name]; !exists {
-			cache.NameToOracleMap[card.Name] = make(map[string]*inventory.ScryfallCard)
-		}
-		if current, exists := cache.NameToOracleMap[card.Name][card.OracleID]; !exists {
-			cache.NameToOracleMap[card.Name][card.OracleID] = &card
-		} else {
-			cache.NameToOracleMap[card.Name][card.OracleID] = getPreferredCard(current, &card)
+// indexCompletions rebuilds the index of names that Autocomplete searches. The
+// cache must be locked or not yet shared.
+func (jc *JSONCache) indexCompletions() {
+	index := newCompletionIndex(jc)
+	jc.completions.Store(&index)
+}
+
+func (jc *JSONCache) add(card *inventory.ScryfallCard) error {
+	if _, exists := jc.ScryfallIDMap[card.ID]; exists {
+		return nil
+	}
+
+	if card.OracleID == "" && len(card.CardFaces) == 0 {
+		return fmt.Errorf("card %q has an empty oracle ID", card.ID)
+	}
+	oracleID := card.CardOracleID()
+
+	key := cardKey{
+		Name:     card.Name,
+		Set:      card.Set,
+		Language: card.Language,
+	}
+	if _, exists := jc.KeyMap[key]; !exists {
+		jc.KeyMap[key] = &cardsWithDefault{
+			Default:            card,
+			CollectorNumberMap: make(map[string][]*inventory.ScryfallCard),
 		}
+	} else {
+		jc.KeyMap[key].Default = getPreferredCard(jc.KeyMap[key].Default, card)
 	}
+	if _, exists := jc.KeyMap[key].CollectorNumberMap[card.CollectorNumber]; !exists {
+		jc.KeyMap[key].CollectorNumberMap[card.CollectorNumber] = make([]*inventory.ScryfallCard, 0)
+	}
+	jc.KeyMap[key].CollectorNumberMap[card.CollectorNumber] = append(jc.KeyMap[key].CollectorNumberMap[card.CollectorNumber], card)
 
-	return cache, nil
+	if current, exists := jc.OracleIDMap[oracleID]; !exists {
+		jc.OracleIDMap[oracleID] = card
+	} else {
+		jc.OracleIDMap[oracleID] = getPreferredCard(current, card)
+	}
+
+	jc.ScryfallIDMap[card.ID] = card
+
+	if _, exists := jc.NameToOracleMap[card.Name]; !exists {
+		jc.NameToOracleMap[card.Name] = make(map[string]*inventory.ScryfallCard)
+	}
+	if current, exists := jc.NameToOracleMap[card.Name][card.OracleID]; !exists {
+		jc.NameToOracleMap[card.Name][card.OracleID] = card
+	} else {
+		jc.NameToOracleMap[card.Name][card.OracleID] = getPreferredCard(current, card)
+	}
+
+	jc.names.add(card)
+
+	return nil
 }
 
 // GetCard implements inventory.Scryfall
 func (jc *JSONCache) GetCard(name, set, language, collectorNumber string) (*inventory.ScryfallCard, error) {
+	jc.mu.RLock()
+	defer jc.mu.RUnlock()
+
 	key := cardKey{
 		Name:     name,
 		Set:      set,
@@ -163,6 +212,9 @@ func (jc *JSONCache) GetCard(name, set, language, collectorNumber string) (*inve
 
 // GetCardByName implements inventory.Scryfall
 func (jc *JSONCache) GetCardByName(name string) (*inventory.ScryfallCard, error) {
+	jc.mu.RLock()
+	defer jc.mu.RUnlock()
+
 	if oracleMap, exists := jc.NameToOracleMap[name]; exists {
 		if len(oracleMap) == 1 {
 			for _, card := range oracleMap {
@@ -177,6 +229,9 @@ func (jc *JSONCache) GetCardByName(name string) (*inventory.ScryfallCard, error)
 
 // GetCardByOracleID implements inventory.Scryfall
 func (jc *JSONCache) GetCardByOracleID(oracleID string) (*inventory.ScryfallCard, error) {
+	jc.mu.RLock()
+	defer jc.mu.RUnlock()
+
 	if card, exists := jc.OracleIDMap[oracleID]; exists {
 		return card, nil
 	}
@@ -185,8 +240,46 @@ func (jc *JSONCache) GetCardByOracleID(oracleID string) (*inventory.ScryfallCard
 
 // GetCardByID implements inventory.Scryfall
 func (jc *JSONCache) GetCardByID(scryfallID string) (*inventory.ScryfallCard, error) {
+	jc.mu.RLock()
+	defer jc.mu.RUnlock()
+
 	if card, exists := jc.ScryfallIDMap[scryfallID]; exists {
 		return card, nil
 	}
 	return nil, fmt.Errorf("didn't find scryfall ID %q: %w", scryfallID, ErrNotInCache)
 }
+
+// FindCardByName implements Finder
+func (jc *JSONCache) FindCardByName(name string) (*inventory.ScryfallCard, error) {
+	return findByName(name, &nameSource{
+		exact: func(normalized string) []string {
+			jc.mu.RLock()
+			defer jc.mu.RUnlock()
+			return slices.Clone(jc.names[normalized])
+		},
+		each: func(visit func(normalized, cardName string)) {
+			jc.mu.RLock()
+			defer jc.mu.RUnlock()
+			for normalized, cardNames := range jc.names {
+				for _, cardName := range cardNames {
+					visit(normalized, cardName)
+				}
+			}
+		},
+		get: jc.GetCardByName,
+	})
+}
+
+// Autocomplete implements Autocompleter
+func (jc *JSONCache) Autocomplete(query string, limit int) ([]Completion, error) {
+	normalized := normalizeName(query)
+	if normalized == "" || limit <= 0 {
+		return []Completion{}, nil
+	}
+
+	index := jc.completions.Load()
+	if index == nil {
+		return []Completion{}, nil
+	}
+	return index.complete(normalized, limit), nil
+}
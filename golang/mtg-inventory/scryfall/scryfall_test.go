@@ -1,9 +1,15 @@
 package scryfall
 
 import (
+	"encoding/json"
 	"errors"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
 )
 
 func TestJSONCache(t *testing.T) {
@@ -21,35 +27,129 @@ func TestJSONCache(t *testing.T) {
 		t.Fatalf("Error loading JSON cache: %s", err.Error())
 	}
 
-	_, err = cache.GetCard("Primeval Titan", "mm2", "en", "")
+	testBulkDataLookups(t, cache)
+}
+
+// testBulkDataLookups tests lookups of cards in ./testdata/all-cards.json
+func testBulkDataLookups(t *testing.T, cache inventory.Scryfall) {
+	t.Helper()
+
+	_, err := cache.GetCard("Primeval Titan", "mm2", "en", "")
 	if err != nil {
-		t.Fatalf("Error retrieving 'Primeval Titan' from JSON cache with name, set, and language: %s", err.Error())
+		t.Fatalf("Error retrieving 'Primeval Titan' with name, set, and language: %s", err.Error())
 	}
 
 	_, err = cache.GetCard("Primeval Titan", "mm2", "en", "156")
 	if err != nil {
-		t.Fatalf("Error retrieving 'Primeval Titan' from JSON cache with name, set, language, and collector number: %s", err.Error())
+		t.Fatalf("Error retrieving 'Primeval Titan' with name, set, language, and collector number: %s", err.Error())
 	}
 
 	_, err = cache.GetCardByName("Primeval Titan")
 	if err != nil {
-		t.Fatalf("Error retrieving 'Primeval Titan' from JSON cache with name: %s", err.Error())
+		t.Fatalf("Error retrieving 'Primeval Titan' with name: %s", err.Error())
 	}
 
 	_, err = cache.GetCardByName("Very Cryptic Command")
 	if err == nil {
-		t.Fatalf("No error retrieving 'Very Cryptic Command' from JSON cache with name")
+		t.Fatalf("No error retrieving 'Very Cryptic Command' with name")
 	} else if !errors.Is(err, ErrMultipleCacheHits) {
-		t.Fatalf("Unexpected error retrieving 'Very Cryptic Command' from JSON cache with name: %s", err.Error())
+		t.Fatalf("Unexpected error retrieving 'Very Cryptic Command' with name: %s", err.Error())
 	}
 
 	_, err = cache.GetCardByOracleID("ae83ef2c-960f-4c5b-97cc-52465c687c18")
 	if err != nil {
-		t.Fatalf("Error retrieving 'Primeval Titan' from JSON cache with oracle ID: %s", err.Error())
+		t.Fatalf("Error retrieving 'Primeval Titan' with oracle ID: %s", err.Error())
 	}
 
 	_, err = cache.GetCardByID("eea2bf31-4320-4605-ab5b-6b32472b82fa")
 	if err != nil {
-		t.Fatalf("Error retrieving 'Primeval Titan' from JSON cache with Scryfall ID: %s", err.Error())
+		t.Fatalf("Error retrieving 'Primeval Titan' with Scryfall ID: %s", err.Error())
+	}
+}
+
+func TestCardFields(t *testing.T) {
+	var expected inventory.ScryfallCard
+	err := json.Unmarshal([]byte(delverISD), &expected)
+	if err != nil {
+		t.Fatalf("Error unmarshaling card: %s", err.Error())
+	}
+	if expected.SetName != "Innistrad" || expected.Rarity != "common" || expected.TypeLine == "" ||
+		!reflect.DeepEqual(expected.ColorIdentity, []string{"U"}) || expected.Legalities["modern"] != "legal" {
+		t.Fatalf("Unexpected gameplay or print fields: %+v", expected)
+	}
+	if expected.MTGOID != 42000 || expected.MTGOFoilID != 42001 || expected.Layout != "transform" || !reflect.DeepEqual(expected.Games, []string{"paper", "mtgo"}) {
+		t.Fatalf("Unexpected game fields: %+v", expected)
+	}
+	if expected.Prices.USDFoil != "4.25" || expected.Prices.USDEtched != "" || expected.Prices.Tix != "0.03" {
+		t.Fatalf("Unexpected prices: %+v", expected.Prices)
+	}
+	if expected.CardFaces[1].ManaCost != "" || expected.CardFaces[0].ImageURIs == nil {
+		t.Fatalf("Unexpected faces: %+v", expected.CardFaces)
+	}
+
+	if !expected.HasFinish(inventory.FinishFoil) || expected.HasFinish(inventory.FinishEtched) {
+		t.Errorf("Expected foil and not etched finishes, got %q", expected.Finishes)
+	}
+	if images := expected.Images(); images == nil || images.Normal != "https://cards.example/normal/front/delver.jpg" {
+		t.Errorf("Expected the front face's images, got %+v", images)
+	}
+	if price := expected.USDPrice(inventory.FinishFoil); price != "4.25" {
+		t.Errorf("Expected foil price 4.25, got %q", price)
+	}
+	var bolt inventory.ScryfallCard
+	err = json.Unmarshal([]byte(boltM10), &bolt)
+	if err != nil {
+		t.Fatalf("Error unmarshaling card: %s", err.Error())
+	}
+	if !bolt.HasFinish(inventory.FinishEtched) || bolt.Images() != nil {
+		t.Errorf("Expected a card without finishes or images to have every finish and no images")
+	}
+
+	bulkData := "[" + delverISD + "]"
+	cache, err := NewJSONCache(strings.NewReader(bulkData))
+	if err != nil {
+		t.Fatalf("Error loading JSON cache: %s", err.Error())
+	}
+	path := filepath.Join(t.TempDir(), "index.db")
+	err = BuildIndex(strings.NewReader(bulkData), path)
+	if err != nil {
+		t.Fatalf("Error building index: %s", err.Error())
+	}
+	index, err := OpenIndex(path)
+	if err != nil {
+		t.Fatalf("Error opening index: %s", err.Error())
+	}
+	defer index.Close()
+	client, server := newTestClient(t)
+	defer server.Close()
+
+	cachePath := filepath.Join(t.TempDir(), "cache.json")
+	fileCache, err := OpenFileCache(cachePath)
+	if err != nil {
+		t.Fatalf("Error opening file cache: %s", err.Error())
+	}
+	err = fileCache.Add(&expected)
+	fileCache.Close()
+	if err != nil {
+		t.Fatalf("Error adding card to file cache: %s", err.Error())
+	}
+	fileCache, err = OpenFileCache(cachePath)
+	if err != nil {
+		t.Fatalf("Error reopening file cache: %s", err.Error())
+	}
+	defer fileCache.Close()
+
+	for name, sf := range map[string]inventory.Scryfall{
+		"JSONCache": cache,
+		"Index":     index,
+		"Client":    client,
+		"FileCache": fileCache,
+	} {
+		card, err := sf.GetCardByID("delver-isd")
+		if err != nil {
+			t.Errorf("Error getting card from %s: %s", name, err.Error())
+		} else if !reflect.DeepEqual(card, &expected) {
+			t.Errorf("Expected %s to have card %+v, got %+v", name, expected, *card)
+		}
 	}
 }
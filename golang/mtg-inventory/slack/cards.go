@@ -0,0 +1,264 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/chat"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/scryfall"
+	"github.com/slack-go/slack"
+)
+
+const (
+	viewOwned  = "owned"
+	viewKept   = "kept"
+	viewOracle = "oracle"
+)
+
+// actionCardsPage is the action ID of the buttons that page through cards
+const actionCardsPage = "cards_page"
+
+// cardsPageSize is the number of card rows shown per message
+const cardsPageSize = 10
+
+const (
+	// actionCardName is the action ID of the selects that autocomplete card
+	// names, whose option values are oracle IDs
+	actionCardName = "card_name"
+
+	// blockWhoHasCard is the block ID of the card select in the who-has
+	// modal
+	blockWhoHasCard = "who_has_card"
+
+	// callbackWhoHas is the callback ID of the who-has modal
+	callbackWhoHas = "who_has"
+
+	// cardOptionsLimit is the number of options returned while a card name
+	// is typed
+	cardOptionsLimit = 20
+)
+
+// cardsPage describes one page of a card listing. It is stored in the value of
+// the pagination buttons, so the keys are kept short.
+type cardsPage struct {
+	View     string `json:"v"`
+	User     string `json:"u,omitempty"`
+	OracleID string `json:"o,omitempty"`
+	Name     string `json:"n,omitempty"`
+	Offset   uint   `json:"off,omitempty"`
+}
+
+// whoHas resolves a card name and lists everyone who owns or keeps the card
+func (s *Server) whoHas(ctx context.Context, name string) []slack.Block {
+	card, problem := chat.FindCard(s.Scryfall, name)
+	if card == nil {
+		return textBlocks(problem)
+	}
+
+	return s.renderCardsPage(ctx, &cardsPage{
+		View:     viewOracle,
+		OracleID: card.CardOracleID(),
+		Name:     card.Name,
+	})
+}
+
+// cardOptions returns the options of a card select for what has been typed so
+// far, with the card's oracle ID as each option's value
+func (s *Server) cardOptions(query string) *slack.OptionsResponse {
+	completions, err := scryfall.Autocomplete(s.Scryfall, query, cardOptionsLimit)
+	if err != nil {
+		log.Printf("Error autocompleting %q: %s", query, err.Error())
+	}
+	options := make([]*slack.OptionBlockObject, 0, len(completions))
+	for _, completion := range completions {
+		options = append(options, slack.NewOptionBlockObject(
+			completion.OracleID,
+			slack.NewTextBlockObject(slack.PlainTextType, completion.Name, false, false),
+			nil,
+		))
+	}
+	return &slack.OptionsResponse{Options: options}
+}
+
+// whoHasView returns a modal to search for a card to list who has it in a
+// channel
+func whoHasView(channelID string) *slack.ModalViewRequest {
+	minQueryLength := 2
+	input := slack.NewOptionsSelectBlockElement(
+		slack.OptTypeExternal,
+		slack.NewTextBlockObject(slack.PlainTextType, "Start typing a card name", false, false),
+		actionCardName,
+	)
+	input.MinQueryLength = &minQueryLength
+	return &slack.ModalViewRequest{
+		Type:       slack.VTModal,
+		CallbackID: callbackWhoHas,
+		Title:      slack.NewTextBlockObject(slack.PlainTextType, "Who has", false, false),
+		Submit:     slack.NewTextBlockObject(slack.PlainTextType, "Search", false, false),
+		Close:      slack.NewTextBlockObject(slack.PlainTextType, "Cancel", false, false),
+		Blocks: slack.Blocks{BlockSet: []slack.Block{
+			slack.NewInputBlock(
+				blockWhoHasCard,
+				slack.NewTextBlockObject(slack.PlainTextType, "Card", false, false),
+				nil,
+				input,
+			),
+		}},
+		PrivateMetadata: channelID,
+	}
+}
+
+// openWhoHasView opens the who-has modal for the user who ran a command,
+// returning blocks to respond with if it can't be opened
+func (s *Server) openWhoHasView(ctx context.Context, cmd *slack.SlashCommand) []slack.Block {
+	_, err := s.API.OpenViewContext(ctx, cmd.TriggerID, *whoHasView(cmd.ChannelID))
+	if err != nil {
+		log.Printf("Error opening who-has view: %s", err.Error())
+		return textBlocks("Something went wrong opening the card search.")
+	}
+	return nil
+}
+
+// whoHasSelection lists who has the card selected in the who-has modal
+func (s *Server) whoHasSelection(ctx context.Context, state *slack.ViewState) []slack.Block {
+	if state == nil {
+		return textBlocks("Choose a card.")
+	}
+	oracleID := state.Values[blockWhoHasCard][actionCardName].SelectedOption.Value
+	card, err := s.Scryfall.GetCardByOracleID(oracleID)
+	if err != nil {
+		log.Printf("Error looking up card with oracle ID %q: %s", oracleID, err.Error())
+		return textBlocks("Something went wrong looking up that card.")
+	}
+	return s.renderCardsPage(ctx, &cardsPage{
+		View:     viewOracle,
+		OracleID: oracleID,
+		Name:     card.Name,
+	})
+}
+
+// handleWhoHasSubmission responds to the submitted who-has modal with a
+// listing only the user who submitted it can see
+func (s *Server) handleWhoHasSubmission(ctx context.Context, callback *slack.InteractionCallback) *slack.ViewSubmissionResponse {
+	blocks := s.whoHasSelection(ctx, callback.View.State)
+	_, err := s.API.PostEphemeralContext(ctx, callback.View.PrivateMetadata, callback.User.ID, slack.MsgOptionBlocks(blocks...))
+	if err != nil {
+		log.Printf("Error posting who-has listing: %s", err.Error())
+	}
+	return nil
+}
+
+// renderCardsPage fetches a page of cards and renders it as a table with
+// buttons for the previous and next pages
+func (s *Server) renderCardsPage(ctx context.Context, page *cardsPage) []slack.Block {
+	var title, empty string
+	var header []string
+	var columns func(*inventory.CardRow) []string
+	var rows []*inventory.CardRow
+	var err error
+	switch page.View {
+	case viewOwned:
+		title = "Cards you own"
+		empty = "You don't own any cards."
+		header = []string{"Qty", "Card", "Copy", "Keeper"}
+		columns = func(row *inventory.CardRow) []string {
+			return []string{fmt.Sprint(row.Quantity), row.Card.Name, chat.DescribeCopy(row.Card), row.Keeper}
+		}
+		rows, err = s.Backend.GetCardsByOwner(ctx, page.User, cardsPageSize+1, page.Offset)
+	case viewKept:
+		title = "Cards you are keeping"
+		empty = "You aren't keeping any cards."
+		header = []string{"Qty", "Card", "Copy", "Owner"}
+		columns = func(row *inventory.CardRow) []string {
+			return []string{fmt.Sprint(row.Quantity), row.Card.Name, chat.DescribeCopy(row.Card), row.Owner}
+		}
+		rows, err = s.Backend.GetCardsByKeeper(ctx, page.User, cardsPageSize+1, page.Offset)
+	case viewOracle:
+		title = "Who has " + page.Name
+		empty = "No one has " + page.Name + "."
+		header = []string{"Qty", "Copy", "Owner", "Keeper"}
+		columns = func(row *inventory.CardRow) []string {
+			return []string{fmt.Sprint(row.Quantity), chat.DescribeCopy(row.Card), row.Owner, row.Keeper}
+		}
+		rows, err = s.Backend.GetCardsByOracleID(ctx, page.OracleID, cardsPageSize+1, page.Offset)
+	default:
+		log.Printf("Unknown cards view %q", page.View)
+		return textBlocks("Something went wrong looking up cards.")
+	}
+	if err != nil {
+		log.Printf("Error getting %s cards: %s", page.View, err.Error())
+		return textBlocks("Something went wrong looking up cards.")
+	}
+
+	if len(rows) == 0 && page.Offset == 0 {
+		return textBlocks(empty)
+	}
+
+	hasNext := len(rows) > cardsPageSize
+	if hasNext {
+		rows = rows[:cardsPageSize]
+	}
+
+	tableRows := make([][]string, 0, len(rows))
+	for _, row := range rows {
+		tableRows = append(tableRows, columns(row))
+	}
+
+	blocks := []slack.Block{
+		slack.NewSectionBlock(
+			slack.NewTextBlockObject(slack.MarkdownType,
+				fmt.Sprintf("*%s* (%d–%d)\n```\n%s```", title, page.Offset+1, page.Offset+uint(len(rows)), chat.Table(header, tableRows)),
+				false, false),
+			nil,
+			nil,
+		),
+	}
+
+	buttons := make([]slack.BlockElement, 0, 2)
+	if page.Offset > 0 {
+		previous := *page
+		previous.Offset -= min(previous.Offset, cardsPageSize)
+		buttons = append(buttons, pageButton("Previous", &previous))
+	}
+	if hasNext {
+		next := *page
+		next.Offset += cardsPageSize
+		buttons = append(buttons, pageButton("Next", &next))
+	}
+	if len(buttons) > 0 {
+		blocks = append(blocks, slack.NewActionBlock("", buttons...))
+	}
+
+	return blocks
+}
+
+// handleCardsPage replaces a card listing with the page a button points to
+func (s *Server) handleCardsPage(ctx context.Context, callback *slack.InteractionCallback, action *slack.BlockAction) error {
+	var page cardsPage
+	err := json.Unmarshal([]byte(action.Value), &page)
+	if err != nil {
+		return fmt.Errorf("error unmarshaling cards page %q: %w", action.Value, err)
+	}
+
+	_, _, err = s.API.PostMessageContext(ctx, callback.Channel.ID,
+		slack.MsgOptionBlocks(s.renderCardsPage(ctx, &page)...),
+		slack.MsgOptionReplaceOriginal(callback.ResponseURL),
+	)
+	if err != nil {
+		return fmt.Errorf("error replacing cards page: %w", err)
+	}
+	return nil
+}
+
+func pageButton(text string, page *cardsPage) *slack.ButtonBlockElement {
+	// Marshaling a struct of strings and integers cannot fail
+	value, _ := json.Marshal(page)
+	return slack.NewButtonBlockElement(
+		actionCardsPage,
+		string(value),
+		slack.NewTextBlockObject(slack.PlainTextType, text, false, false),
+	)
+}
@@ -0,0 +1,280 @@
+package slack
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"strings"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/importer"
+	"github.com/slack-go/slack"
+)
+
+const (
+	// callbackImport is the callback ID of the import modal
+	callbackImport = "import"
+
+	// blockImportFile and actionImportFile are the IDs of the file input
+	// in the import modal
+	blockImportFile  = "import_file"
+	actionImportFile = "import_file"
+
+	// blockImportFormat and actionImportFormat are the IDs of the format
+	// select in the import modal
+	blockImportFormat  = "import_format"
+	actionImportFormat = "import_format"
+
+	// importMaxBytes is the size of the largest file that is imported
+	importMaxBytes = 1 << 20
+
+	// importProblemsShown is the number of lines that couldn't be imported
+	// that are listed
+	importProblemsShown = 10
+)
+
+// importView returns a modal to upload a decklist or CSV file to add to your
+// cards, whose result is posted in a channel
+func importView(channelID string) *slack.ModalViewRequest {
+	options := []*slack.OptionBlockObject{
+		slack.NewOptionBlockObject(importer.FormatDecklist, slack.NewTextBlockObject(slack.PlainTextType, "Decklist", false, false), nil),
+		slack.NewOptionBlockObject(importer.FormatCSV, slack.NewTextBlockObject(slack.PlainTextType, "CSV from any tool", false, false), nil),
+	}
+	for _, format := range importer.Formats {
+		options = append(options, slack.NewOptionBlockObject(format.Name, slack.NewTextBlockObject(slack.PlainTextType, format.Title+" CSV", false, false), nil))
+	}
+	formatBlock := slack.NewInputBlock(
+		blockImportFormat,
+		slack.NewTextBlockObject(slack.PlainTextType, "Format", false, false),
+		slack.NewTextBlockObject(slack.PlainTextType, "Detected from the file if you don't choose one.", false, false),
+		slack.NewOptionsSelectBlockElement(slack.OptTypeStatic, nil, actionImportFormat, options...),
+	)
+	formatBlock.Optional = true
+
+	return &slack.ModalViewRequest{
+		Type:       slack.VTModal,
+		CallbackID: callbackImport,
+		Title:      slack.NewTextBlockObject(slack.PlainTextType, "Import cards", false, false),
+		Submit:     slack.NewTextBlockObject(slack.PlainTextType, "Import", false, false),
+		Close:      slack.NewTextBlockObject(slack.PlainTextType, "Cancel", false, false),
+		Blocks: slack.Blocks{BlockSet: []slack.Block{
+			slack.NewInputBlock(
+				blockImportFile,
+				slack.NewTextBlockObject(slack.PlainTextType, "File", false, false),
+				slack.NewTextBlockObject(slack.PlainTextType, "A decklist, or a CSV file from Moxfield, Deckbox, Archidekt or TCGplayer.", false, false),
+				slack.NewFileInputBlockElement(actionImportFile).WithFileTypes("txt", "csv").WithMaxFiles(1),
+			),
+			formatBlock,
+		}},
+		PrivateMetadata: channelID,
+	}
+}
+
+// openImportView opens the import modal for the user who ran a command,
+// returning blocks to respond with if it can't be opened
+func (s *Server) openImportView(ctx context.Context, cmd *slack.SlashCommand) []slack.Block {
+	_, err := s.API.OpenViewContext(ctx, cmd.TriggerID, *importView(cmd.ChannelID))
+	if err != nil {
+		log.Printf("Error opening import view: %s", err.Error())
+		return textBlocks("Something went wrong opening the import.")
+	}
+	return nil
+}
+
+// importFormat returns the format chosen in the import modal, or the one
+// guessed from the file's type if none was chosen
+func importFormat(file *slack.File, chosen string) string {
+	if chosen != "" {
+		return chosen
+	}
+	if file.Filetype == "csv" || strings.EqualFold(path.Ext(file.Name), ".csv") {
+		return importer.FormatCSV
+	}
+	return importer.FormatDecklist
+}
+
+// handleImportSubmission checks the file uploaded to the import modal and
+// closes the modal, importing the file in the background since that can take
+// longer than Slack waits for a response
+func (s *Server) handleImportSubmission(ctx context.Context, callback *slack.InteractionCallback) *slack.ViewSubmissionResponse {
+	state := callback.View.State
+	if state == nil {
+		state = &slack.ViewState{}
+	}
+	files := state.Values[blockImportFile][actionImportFile].Files
+	if len(files) == 0 {
+		return slack.NewErrorsViewSubmissionResponse(map[string]string{
+			blockImportFile: "Choose a file to import.",
+		})
+	}
+	file := &files[0]
+	if file.Size > importMaxBytes {
+		return slack.NewErrorsViewSubmissionResponse(map[string]string{
+			blockImportFile: "That file is too big to import.",
+		})
+	}
+
+	format := importFormat(file, state.Values[blockImportFormat][actionImportFormat].SelectedOption.Value)
+	go s.importFile(context.WithoutCancel(ctx), callback, file, format)
+	return nil
+}
+
+// importFile imports a file uploaded to the import modal and tells the user
+// how it went in the channel the modal was opened from
+func (s *Server) importFile(ctx context.Context, callback *slack.InteractionCallback, file *slack.File, format string) {
+	blocks := s.downloadAndImport(ctx, callback, file, format)
+	_, err := s.API.PostEphemeralContext(ctx, callback.View.PrivateMetadata, callback.User.ID, slack.MsgOptionBlocks(blocks...))
+	if err != nil {
+		log.Printf("Error posting import result: %s", err.Error())
+	}
+}
+
+// downloadAndImport downloads a file uploaded to the import modal, adds its
+// cards to the user's and describes how it went
+func (s *Server) downloadAndImport(ctx context.Context, callback *slack.InteractionCallback, file *slack.File, format string) []slack.Block {
+	username, err := s.username(ctx, callback.Team.ID, callback.User.ID, callback.User.Name)
+	if err != nil {
+		log.Printf("Error importing cards: %s", err.Error())
+		return textBlocks("Something went wrong looking you up.")
+	}
+
+	var content bytes.Buffer
+	err = s.API.GetFileContext(ctx, file.URLPrivateDownload, &content)
+	if err != nil {
+		log.Printf("Error downloading file %q to import: %s", file.ID, err.Error())
+		return textBlocks("Something went wrong downloading the file.")
+	}
+
+	return s.importCards(ctx, username, format, &content)
+}
+
+// importCards adds the cards in a file in the named format to those username
+// owns and keeps, and describes how it went
+func (s *Server) importCards(ctx context.Context, username, format string, r io.Reader) []slack.Block {
+	lines, err := importer.Read(r, format)
+	var lineErrs importer.LineErrors
+	if errors.As(err, &lineErrs) {
+		return textBlocks(importProblems("I couldn't read these lines:", lineErrs))
+	} else if errors.Is(err, importer.ErrEmptyDecklist) {
+		return textBlocks("There are no cards in that file.")
+	} else if errors.Is(err, importer.ErrUnknownFormat) {
+		return textBlocks("I don't recognize the columns of that CSV file. Try choosing the tool it's from.")
+	} else if err != nil {
+		return textBlocks(fmt.Sprintf("I couldn't read that file: %s", err.Error()))
+	}
+
+	rows, err := importer.NewImporter(s.Backend, s.Scryfall).Import(ctx, lines, username, username)
+	added := fmt.Sprintf("I added %d cards in %d rows.", totalQuantity(rows), len(rows))
+	if errors.As(err, &lineErrs) {
+		text := importProblems("I couldn't import these lines, so I didn't add any cards:", lineErrs)
+		if len(rows) > 0 {
+			text = importProblems(added+" Then I couldn't import this line, so I stopped:", lineErrs)
+		}
+		return textBlocks(text)
+	} else if err != nil {
+		log.Printf("Error importing cards for %q: %s", username, err.Error())
+		if len(rows) > 0 {
+			return textBlocks(added + " Then something went wrong adding the rest.")
+		}
+		return textBlocks("Something went wrong adding your cards.")
+	}
+	return textBlocks(added)
+}
+
+// importProblems lists the first lines that couldn't be imported under intro
+func importProblems(intro string, lineErrs importer.LineErrors) string {
+	var text strings.Builder
+	text.WriteString(intro)
+	for i, rowErr := range lineErrs {
+		if i == importProblemsShown {
+			fmt.Fprintf(&text, "\n…and %d more.", len(lineErrs)-i)
+			break
+		}
+		fmt.Fprintf(&text, "\n• %s", rowErr.Error())
+	}
+	return text.String()
+}
+
+func totalQuantity(rows []*inventory.CardRow) uint {
+	var total uint
+	for _, row := range rows {
+		total += row.Quantity
+	}
+	return total
+}
+
+// exportFile returns a file of the cards username owns or keeps, which args
+// chooses along with the format, or a reason there is none
+func (s *Server) exportFile(ctx context.Context, username, args string) (filename string, content []byte, reason string) {
+	view, format := "mine", importer.Moxfield.Name
+	for _, arg := range strings.Fields(args) {
+		switch arg {
+		case "mine", "held":
+			view = arg
+		default:
+			var err error
+			format, err = importer.ParseExportFormat(arg)
+			if err != nil {
+				return "", nil, fmt.Sprintf("Usage: `/mtg export [mine|held] [%s]`", strings.Join(importer.ExportFormatNames, "|"))
+			}
+		}
+	}
+
+	var rows []*inventory.CardRow
+	var err error
+	if view == "mine" {
+		rows, err = inventory.ListAll(ctx, s.Backend.GetCardsByOwner, username)
+	} else {
+		rows, err = inventory.ListAll(ctx, s.Backend.GetCardsByKeeper, username)
+	}
+	if err != nil {
+		log.Printf("Error getting cards to export for %q: %s", username, err.Error())
+		return "", nil, "Something went wrong looking up your cards."
+	}
+	if len(rows) == 0 && view == "mine" {
+		return "", nil, "You don't own any cards."
+	} else if len(rows) == 0 {
+		return "", nil, "You aren't keeping any cards."
+	}
+
+	var buf bytes.Buffer
+	err = importer.Write(&buf, format, s.Scryfall, rows)
+	if err != nil {
+		log.Printf("Error exporting cards for %q: %s", username, err.Error())
+		return "", nil, "Something went wrong exporting your cards."
+	}
+	return fmt.Sprintf("%s-%s-%s%s", username, view, format, importer.Extension(format)), buf.Bytes(), ""
+}
+
+// exportCards sends the user who ran a command a file of their cards in a
+// direct message
+func (s *Server) exportCards(ctx context.Context, cmd *slack.SlashCommand, username, args string) []slack.Block {
+	filename, content, reason := s.exportFile(ctx, username, args)
+	if reason != "" {
+		return textBlocks(reason)
+	}
+
+	channel, _, _, err := s.API.OpenConversationContext(ctx, &slack.OpenConversationParameters{
+		Users: []string{cmd.UserID},
+	})
+	if err != nil {
+		log.Printf("Error opening direct message with %q: %s", cmd.UserID, err.Error())
+		return textBlocks("Something went wrong sending you the file.")
+	}
+	_, err = s.API.UploadFileV2Context(ctx, slack.UploadFileV2Parameters{
+		Reader:   bytes.NewReader(content),
+		FileSize: len(content),
+		Filename: filename,
+		Title:    filename,
+		Channel:  channel.ID,
+	})
+	if err != nil {
+		log.Printf("Error uploading %q: %s", filename, err.Error())
+		return textBlocks("Something went wrong sending you the file.")
+	}
+	return textBlocks(fmt.Sprintf("I sent you `%s` in a direct message.", filename))
+}
@@ -0,0 +1,91 @@
+package slack
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/importer"
+	"github.com/slack-go/slack"
+)
+
+func TestImportFormat(t *testing.T) {
+	for _, test := range []struct {
+		file     slack.File
+		chosen   string
+		expected string
+	}{
+		{slack.File{Name: "collection.csv", Filetype: "csv"}, "", importer.FormatCSV},
+		{slack.File{Name: "Collection.CSV"}, "", importer.FormatCSV},
+		{slack.File{Name: "burn.txt", Filetype: "text"}, "", importer.FormatDecklist},
+		{slack.File{Name: "collection.csv", Filetype: "csv"}, importer.Deckbox.Name, importer.Deckbox.Name},
+	} {
+		format := importFormat(&test.file, test.chosen)
+		if format != test.expected {
+			t.Errorf("Expected format %q for %q chosen as %q, got %q", test.expected, test.file.Name, test.chosen, format)
+		}
+	}
+}
+
+func TestImportCards(t *testing.T) {
+	s := newTestServer(t)
+	ctx := context.Background()
+
+	blocks := s.importCards(ctx, "alice", importer.FormatDecklist, strings.NewReader("4 Lightning Bolt\n1 Ragavan, Nimble Pilferer\n"))
+	text, _ := blocksText(t, commandPayload(slack.ResponseTypeEphemeral, blocks))
+	if text != "I added 5 cards in 2 rows." {
+		t.Fatalf("Unexpected import: %s", text)
+	}
+	rows, err := s.Backend.GetCardsByOwner(ctx, "alice", 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to get cards: %s", err.Error())
+	} else if len(rows) != 2 || rows[0].Keeper != "alice" {
+		t.Fatalf("Expected 2 rows kept by alice, got %+v", rows)
+	}
+
+	blocks = s.importCards(ctx, "alice", importer.FormatDecklist, strings.NewReader("1 Lightning Bolt\n4 Lightnig Bolt\n"))
+	text, _ = blocksText(t, commandPayload(slack.ResponseTypeEphemeral, blocks))
+	if !strings.HasPrefix(text, "I couldn't import these lines") || !strings.Contains(text, `line 2 "4 Lightnig Bolt"`) || !strings.Contains(text, `did you mean "Lightning Bolt"`) {
+		t.Fatalf("Unexpected import of a misspelled card: %s", text)
+	}
+
+	blocks = s.importCards(ctx, "alice", importer.FormatCSV, strings.NewReader("Card,Amount\nLightning Bolt,4\n"))
+	text, _ = blocksText(t, commandPayload(slack.ResponseTypeEphemeral, blocks))
+	if !strings.HasPrefix(text, "I don't recognize the columns") {
+		t.Fatalf("Unexpected import of an unknown CSV format: %s", text)
+	}
+}
+
+func TestExportFile(t *testing.T) {
+	s := newTestServer(t)
+	ctx := context.Background()
+
+	_, _, reason := s.exportFile(ctx, "alice", "held")
+	if reason != "You aren't keeping any cards." {
+		t.Fatalf("Unexpected reason for an empty export: %q", reason)
+	}
+	_, _, reason = s.exportFile(ctx, "alice", "mine cardkingdom")
+	if !strings.HasPrefix(reason, "Usage:") {
+		t.Fatalf("Expected usage for an unknown format, got %q", reason)
+	}
+
+	s.importCards(ctx, "alice", importer.FormatDecklist, strings.NewReader("4 Lightning Bolt\n"))
+	filename, content, reason := s.exportFile(ctx, "alice", "deckbox held")
+	if reason != "" {
+		t.Fatalf("Failed to export: %s", reason)
+	}
+	if filename != "alice-held-deckbox.csv" {
+		t.Errorf("Unexpected filename %q", filename)
+	}
+	if !strings.HasPrefix(string(content), "Count,") || !strings.Contains(string(content), "4,,Lightning Bolt,,m10,146,Near Mint,English,") {
+		t.Errorf("Unexpected export: %s", content)
+	}
+
+	filename, content, reason = s.exportFile(ctx, "alice", "held arena")
+	if reason != "" {
+		t.Fatalf("Failed to export: %s", reason)
+	}
+	if filename != "alice-held-arena.txt" || !strings.HasPrefix(string(content), "Deck\n4 Lightning Bolt") {
+		t.Errorf("Unexpected Arena export %q: %s", filename, content)
+	}
+}
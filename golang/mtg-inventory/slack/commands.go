@@ -0,0 +1,94 @@
+package slack
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"github.com/slack-go/slack"
+)
+
+// MTGCommand is the slash command that all inventory subcommands hang off of
+const MTGCommand = "/mtg"
+
+const helpText = "Usage:\n" +
+	"• `/mtg cards mine` lists the cards you own\n" +
+	"• `/mtg cards held` lists the cards you are keeping\n" +
+	"• `/mtg who-has <card name>` lists who owns and keeps a card, or searches for one without a name\n" +
+	"• `/mtg request 4 Lightning Bolt, 1 Ragavan, Nimble Pilferer` asks the channel for cards\n" +
+	"• `/mtg import` adds the cards in a decklist or a CSV file from Moxfield, Deckbox, Archidekt or TCGplayer to yours\n" +
+	"• `/mtg export [mine|held] [moxfield|deckbox|archidekt|tcgplayer]` sends you your cards as a CSV file\n" +
+	"• `/mtg export [mine|held] [arena|mtgo]` sends you your cards as an MTG Arena decklist or Magic Online .dek file to playtest\n" +
+	"• `/mtg link <username> @user` links a user to an existing username (admins only)"
+
+// handleMTGCommand returns the payload to acknowledge a /mtg command with, or
+// nil if it opened a modal instead
+func (s *Server) handleMTGCommand(ctx context.Context, cmd slack.SlashCommand) map[string]interface{} {
+	username, err := s.username(ctx, cmd.TeamID, cmd.UserID, cmd.UserName)
+	if err != nil {
+		log.Printf("Error getting username: %s", err.Error())
+		return commandPayload(slack.ResponseTypeEphemeral, textBlocks("Something went wrong looking you up."))
+	}
+
+	responseType := slack.ResponseTypeEphemeral
+	var blocks []slack.Block
+	subcommand, args, _ := strings.Cut(strings.TrimSpace(cmd.Text), " ")
+	args = strings.TrimSpace(args)
+	switch subcommand {
+	case "cards":
+		switch args {
+		case "mine":
+			blocks = s.renderCardsPage(ctx, &cardsPage{View: viewOwned, User: username})
+		case "held":
+			blocks = s.renderCardsPage(ctx, &cardsPage{View: viewKept, User: username})
+		default:
+			blocks = textBlocks(helpText)
+		}
+	case "who-has":
+		if args == "" {
+			blocks = s.openWhoHasView(ctx, &cmd)
+			if blocks == nil {
+				return nil
+			}
+		} else {
+			blocks = s.whoHas(ctx, args)
+		}
+	case "request":
+		if args == "" {
+			blocks = textBlocks(helpText)
+		} else {
+			responseType, blocks = s.openRequest(ctx, username, args)
+		}
+	case "import":
+		blocks = s.openImportView(ctx, &cmd)
+		if blocks == nil {
+			return nil
+		}
+	case "export":
+		blocks = s.exportCards(ctx, &cmd, username, args)
+	case "link":
+		blocks = s.linkUser(ctx, &cmd, args)
+	default:
+		blocks = textBlocks(helpText)
+	}
+
+	return commandPayload(responseType, blocks)
+}
+
+func commandPayload(responseType string, blocks []slack.Block) map[string]interface{} {
+	return map[string]interface{}{
+		"response_type": responseType,
+		"blocks":        blocks,
+	}
+}
+
+// textBlocks returns a single section of Markdown text
+func textBlocks(text string) []slack.Block {
+	return []slack.Block{
+		slack.NewSectionBlock(
+			slack.NewTextBlockObject(slack.MarkdownType, text, false, false),
+			nil,
+			nil,
+		),
+	}
+}
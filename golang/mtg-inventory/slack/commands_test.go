@@ -0,0 +1,186 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/chat/chattest"
+	"github.com/slack-go/slack"
+)
+
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+
+	return &Server{
+		Backend:  chattest.NewBackend(t, IdentityProvider, "T1", "UALICE", "UBOB"),
+		Scryfall: chattest.NewScryfall(t),
+		Admins:   map[string]bool{"UADMIN": true},
+	}
+}
+
+// blocksText returns the text of every section and the values of every button
+func blocksText(t *testing.T, payload map[string]interface{}) (string, []string) {
+	t.Helper()
+
+	blocks, ok := payload["blocks"].([]slack.Block)
+	if !ok {
+		t.Fatalf("Payload has no blocks: %v", payload)
+	}
+	var text strings.Builder
+	values := make([]string, 0)
+	for _, block := range blocks {
+		switch block := block.(type) {
+		case *slack.SectionBlock:
+			text.WriteString(block.Text.Text)
+		case *slack.ActionBlock:
+			for _, element := range block.Elements.ElementSet {
+				if button, ok := element.(*slack.ButtonBlockElement); ok {
+					values = append(values, button.Value)
+				}
+			}
+		}
+	}
+	return text.String(), values
+}
+
+func TestMTGCommandCards(t *testing.T) {
+	s := newTestServer(t)
+	ctx := context.Background()
+
+	payload := s.handleMTGCommand(ctx, slack.SlashCommand{Command: MTGCommand, Text: "cards mine", TeamID: "T1", UserID: "UALICE", UserName: "alice"})
+	text, _ := blocksText(t, payload)
+	if !strings.Contains(text, "You don't own any cards.") {
+		t.Fatalf("Unexpected empty listing: %s", text)
+	}
+
+	rows := make([]*inventory.CardRow, 0)
+	for i := 0; i < cardsPageSize+2; i++ {
+		rows = append(rows, &inventory.CardRow{
+			Quantity: 1,
+			Card: &inventory.Card{
+				Name:       fmt.Sprintf("Card %02d", i),
+				OracleID:   fmt.Sprintf("oracle-%02d", i),
+				ScryfallID: fmt.Sprintf("scryfall-%02d", i),
+				Finish:     inventory.FinishNonfoil,
+				Condition:  inventory.ConditionNearMint,
+			},
+			Owner:  "alice",
+			Keeper: "bob",
+		})
+	}
+	err := s.Backend.AddCards(ctx, rows)
+	if err != nil {
+		t.Fatalf("Failed to add cards: %s", err.Error())
+	}
+
+	payload = s.handleMTGCommand(ctx, slack.SlashCommand{Command: MTGCommand, Text: "cards mine", TeamID: "T1", UserID: "UALICE", UserName: "alice"})
+	text, values := blocksText(t, payload)
+	if !strings.Contains(text, "Card 00") || strings.Contains(text, "Card 10") {
+		t.Fatalf("Unexpected first page: %s", text)
+	}
+	if len(values) != 1 {
+		t.Fatalf("Expected only a next button, got: %v", values)
+	}
+
+	var next cardsPage
+	err = json.Unmarshal([]byte(values[0]), &next)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal button value: %s", err.Error())
+	}
+	text, values = blocksText(t, map[string]interface{}{"blocks": s.renderCardsPage(ctx, &next)})
+	if !strings.Contains(text, "Card 10") || !strings.Contains(text, "Card 11") || strings.Contains(text, "Card 09") {
+		t.Fatalf("Unexpected second page: %s", text)
+	}
+	if len(values) != 1 || !strings.Contains(values[0], `"v":"owned"`) {
+		t.Fatalf("Expected only a previous button, got: %v", values)
+	}
+
+	payload = s.handleMTGCommand(ctx, slack.SlashCommand{Command: MTGCommand, Text: "cards held", TeamID: "T1", UserID: "UBOB", UserName: "bob"})
+	text, _ = blocksText(t, payload)
+	if !strings.Contains(text, "Cards you are keeping") || !strings.Contains(text, "alice") {
+		t.Fatalf("Unexpected held listing: %s", text)
+	}
+}
+
+func TestMTGCommandWhoHas(t *testing.T) {
+	s := newTestServer(t)
+	ctx := context.Background()
+
+	err := s.Backend.AddCards(ctx, []*inventory.CardRow{
+		{
+			Quantity: 4,
+			Card: &inventory.Card{
+				Name:       "Lightning Bolt",
+				OracleID:   "bolt-oracle",
+				ScryfallID: "bolt-m10",
+				Finish:     inventory.FinishNonfoil,
+				Condition:  inventory.ConditionNearMint,
+			},
+			Owner:  "alice",
+			Keeper: "bob",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to add cards: %s", err.Error())
+	}
+
+	payload := s.handleMTGCommand(ctx, slack.SlashCommand{Command: MTGCommand, Text: "who-has Lightning Bolt", TeamID: "T1", UserID: "UBOB", UserName: "bob"})
+	text, _ := blocksText(t, payload)
+	if !strings.Contains(text, "Who has Lightning Bolt") || !strings.Contains(text, "alice") {
+		t.Fatalf("Unexpected who-has listing: %s", text)
+	}
+
+	payload = s.handleMTGCommand(ctx, slack.SlashCommand{Command: MTGCommand, Text: "who-has Ragavan, Nimble Pilferer", TeamID: "T1", UserID: "UBOB", UserName: "bob"})
+	text, _ = blocksText(t, payload)
+	if !strings.Contains(text, "No one has Ragavan, Nimble Pilferer.") {
+		t.Fatalf("Unexpected who-has listing: %s", text)
+	}
+
+	payload = s.handleMTGCommand(ctx, slack.SlashCommand{Command: MTGCommand, Text: "who-has Black Lotus", TeamID: "T1", UserID: "UBOB", UserName: "bob"})
+	text, _ = blocksText(t, payload)
+	if !strings.Contains(text, "couldn't find") {
+		t.Fatalf("Unexpected who-has listing: %s", text)
+	}
+
+	payload = s.handleMTGCommand(ctx, slack.SlashCommand{Command: MTGCommand, Text: "bogus", TeamID: "T1", UserID: "UBOB", UserName: "bob"})
+	text, _ = blocksText(t, payload)
+	if !strings.Contains(text, "Usage") {
+		t.Fatalf("Expected help text: %s", text)
+	}
+}
+
+func TestCardOptions(t *testing.T) {
+	s := newTestServer(t)
+
+	options := s.cardOptions("ragav")
+	if len(options.Options) != 1 || options.Options[0].Value != "ragavan-oracle" || options.Options[0].Text.Text != "Ragavan, Nimble Pilferer" {
+		t.Fatalf("Unexpected options: %+v", options.Options)
+	}
+
+	options = s.cardOptions("black lotus")
+	if len(options.Options) != 0 {
+		t.Fatalf("Expected no options, got %+v", options.Options)
+	}
+}
+
+func TestWhoHasSelection(t *testing.T) {
+	s := newTestServer(t)
+	ctx := context.Background()
+
+	view := whoHasView("C1")
+	if view.CallbackID != callbackWhoHas || view.PrivateMetadata != "C1" {
+		t.Fatalf("Unexpected who-has view: %+v", view)
+	}
+
+	state := &slack.ViewState{Values: map[string]map[string]slack.BlockAction{
+		blockWhoHasCard: {actionCardName: {SelectedOption: slack.OptionBlockObject{Value: "ragavan-oracle"}}},
+	}}
+	text, _ := blocksText(t, commandPayload(slack.ResponseTypeEphemeral, s.whoHasSelection(ctx, state)))
+	if !strings.Contains(text, "No one has Ragavan, Nimble Pilferer.") {
+		t.Fatalf("Unexpected who-has listing: %s", text)
+	}
+}
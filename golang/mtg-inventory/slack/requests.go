@@ -0,0 +1,346 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/chat"
+	"github.com/slack-go/slack"
+)
+
+const (
+	// actionFillRequest is the action ID of the button on a posted request
+	actionFillRequest = "fill_request"
+
+	// callbackFillRequest is the callback ID of the modal to fill a request
+	callbackFillRequest = "fill_request"
+
+	// actionFillQuantity is the action ID of the quantity inputs in the modal
+	actionFillQuantity = "quantity"
+
+	// fillRequestMaxMetadata is the most characters Slack allows in the
+	// private metadata of a modal, which carries the rows offered in it
+	fillRequestMaxMetadata = 3000
+)
+
+// openRequest resolves a card list and opens a request for it, returning the
+// response type and blocks to reply with
+func (s *Server) openRequest(ctx context.Context, requestor, text string) (string, []slack.Block) {
+	entries, err := chat.ParseCardList(text)
+	if err != nil {
+		return slack.ResponseTypeEphemeral, textBlocks(fmt.Sprintf("I couldn't read that list: %s.", err.Error()))
+	}
+
+	rows, problems := chat.ResolveCardList(s.Scryfall, entries)
+	if len(problems) > 0 {
+		return slack.ResponseTypeEphemeral, textBlocks("I couldn't open that request:\n• " + strings.Join(problems, "\n• "))
+	}
+
+	request, err := s.Backend.OpenRequest(ctx, requestor, rows)
+	if errors.Is(err, inventory.ErrUserNoExist) {
+		return slack.ResponseTypeEphemeral, textBlocks("You don't have an inventory yet.")
+	} else if errors.Is(err, inventory.ErrTooManyRows) {
+		return slack.ResponseTypeEphemeral, textBlocks(fmt.Sprintf("You can request at most %d different cards at once.", inventory.RowUploadLimit))
+	} else if err != nil {
+		log.Printf("Error opening request for %q: %s", requestor, err.Error())
+		return slack.ResponseTypeEphemeral, textBlocks("Something went wrong opening that request.")
+	}
+
+	return slack.ResponseTypeInChannel, requestBlocks(request)
+}
+
+// requestBlocks renders an open request with a button to fill it
+func requestBlocks(request *inventory.Request) []slack.Block {
+	lines := make([]string, 0, len(request.Cards))
+	for _, card := range request.Cards {
+		lines = append(lines, fmt.Sprintf("• %d %s", card.Quantity, card.Name))
+	}
+
+	return []slack.Block{
+		slack.NewSectionBlock(
+			slack.NewTextBlockObject(slack.MarkdownType,
+				fmt.Sprintf("*%s* opened request #%d:\n%s", request.Requestor, request.ID, strings.Join(lines, "\n")),
+				false, false),
+			nil,
+			nil,
+		),
+		slack.NewActionBlock("",
+			slack.NewButtonBlockElement(
+				actionFillRequest,
+				strconv.FormatInt(request.ID, 10),
+				slack.NewTextBlockObject(slack.PlainTextType, "I can fill this", false, false),
+			).WithStyle(slack.StylePrimary),
+		),
+	}
+}
+
+// fillRow identifies a card row offered in the modal to fill a request
+type fillRow struct {
+	ScryfallID string              `json:"s"`
+	Finish     inventory.Finish    `json:"f"`
+	Condition  inventory.Condition `json:"c"`
+	Language   string              `json:"l,omitempty"`
+	Owner      string              `json:"o"`
+	Name       string              `json:"n"`
+	OracleID   string              `json:"i"`
+}
+
+// fillRequestMetadata is stored in the private metadata of the modal to fill
+// a request
+type fillRequestMetadata struct {
+	RequestID int64      `json:"r"`
+	ChannelID string     `json:"c"`
+	Rows      []*fillRow `json:"rows"`
+}
+
+// handleFillRequest opens the modal to fill a request for the user who
+// clicked its button
+func (s *Server) handleFillRequest(ctx context.Context, callback *slack.InteractionCallback, action *slack.BlockAction) error {
+	requestID, err := strconv.ParseInt(action.Value, 10, 64)
+	if err != nil {
+		return fmt.Errorf("error parsing request ID %q: %w", action.Value, err)
+	}
+
+	username, err := s.username(ctx, callback.Team.ID, callback.User.ID, callback.User.Name)
+	if err != nil {
+		return err
+	}
+
+	view, err := s.fillRequestView(ctx, requestID, username, callback.Channel.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.API.OpenViewContext(ctx, callback.TriggerID, *view)
+	if err != nil {
+		return fmt.Errorf("error opening view to fill request %d: %w", requestID, err)
+	}
+	return nil
+}
+
+// fillRequestView returns a modal with a quantity input for every card row
+// the user keeps that matches a card in the request
+func (s *Server) fillRequestView(ctx context.Context, requestID int64, username, channelID string) (*slack.ModalViewRequest, error) {
+	request, err := s.Backend.GetRequestByID(ctx, requestID, inventory.MaxListLimit, 0)
+	if errors.Is(err, inventory.ErrRequestNoExist) {
+		return infoView(fmt.Sprintf("Request #%d no longer exists.", requestID)), nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error getting request %d: %w", requestID, err)
+	}
+	if request.Closed != nil {
+		return infoView(fmt.Sprintf("Request #%d is closed.", requestID)), nil
+	}
+	if request.Requestor == username {
+		return infoView("You can't fill your own request."), nil
+	}
+
+	metadata := &fillRequestMetadata{
+		RequestID: requestID,
+		ChannelID: channelID,
+		Rows:      make([]*fillRow, 0),
+	}
+	privateMetadata, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling metadata: %w", err)
+	}
+	metadataLength := len(privateMetadata)
+	blocks := []slack.Block{
+		slack.NewSectionBlock(
+			slack.NewTextBlockObject(slack.MarkdownType,
+				fmt.Sprintf("How many of each card can you send *%s*?", request.Requestor),
+				false, false),
+			nil,
+			nil,
+		),
+	}
+	for _, requested := range request.Cards {
+		rows, err := chat.KeptCards(ctx, s.Backend, username, requested.OracleID)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			metadataRow := &fillRow{
+				ScryfallID: row.Card.ScryfallID,
+				Finish:     row.Card.Finish,
+				Condition:  row.Card.Condition,
+				Language:   row.Card.Language,
+				Owner:      row.Owner,
+				Name:       row.Card.Name,
+				OracleID:   row.Card.OracleID,
+			}
+			rowJSON, err := json.Marshal(metadataRow)
+			if err != nil {
+				return nil, fmt.Errorf("error marshaling metadata: %w", err)
+			}
+			// Rows after the first are separated by a comma
+			rowLength := len(rowJSON)
+			if len(metadata.Rows) > 0 {
+				rowLength++
+			}
+			if metadataLength+rowLength > fillRequestMaxMetadata {
+				continue
+			}
+			metadataLength += rowLength
+			metadata.Rows = append(metadata.Rows, metadataRow)
+
+			label := row.Card.Name
+			if description := chat.DescribeCopy(row.Card); description != "" {
+				label += " (" + description + ")"
+			}
+			label += ", owned by " + row.Owner
+			input := slack.NewNumberInputBlockElement(nil, actionFillQuantity, false)
+			input.MinValue = "0"
+			input.MaxValue = strconv.FormatUint(uint64(row.Quantity), 10)
+			block := slack.NewInputBlock(
+				fillBlockID(len(metadata.Rows)-1),
+				slack.NewTextBlockObject(slack.PlainTextType, label, false, false),
+				slack.NewTextBlockObject(slack.PlainTextType,
+					fmt.Sprintf("You keep %d and %d were requested.", row.Quantity, requested.Quantity),
+					false, false),
+				input,
+			)
+			block.Optional = true
+			blocks = append(blocks, block)
+		}
+	}
+	if len(metadata.Rows) == 0 {
+		return infoView("You aren't keeping any of the requested cards."), nil
+	}
+
+	privateMetadata, err = json.Marshal(metadata)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling metadata: %w", err)
+	}
+
+	return &slack.ModalViewRequest{
+		Type:            slack.VTModal,
+		CallbackID:      callbackFillRequest,
+		Title:           slack.NewTextBlockObject(slack.PlainTextType, fmt.Sprintf("Fill request #%d", requestID), false, false),
+		Submit:          slack.NewTextBlockObject(slack.PlainTextType, "Open transfer", false, false),
+		Close:           slack.NewTextBlockObject(slack.PlainTextType, "Cancel", false, false),
+		Blocks:          slack.Blocks{BlockSet: blocks},
+		PrivateMetadata: string(privateMetadata),
+	}, nil
+}
+
+// fillRequest opens a transfer from the submitted modal to fill a request. It
+// returns errors to show on the modal's inputs if the transfer can't be opened.
+func (s *Server) fillRequest(ctx context.Context, username string, metadata *fillRequestMetadata, state *slack.ViewState) (*inventory.Transfer, map[string]string, error) {
+	request, err := s.Backend.GetRequestByID(ctx, metadata.RequestID, 1, 0)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error getting request %d: %w", metadata.RequestID, err)
+	}
+	if request.Closed != nil {
+		return nil, map[string]string{fillBlockID(0): "This request has been closed."}, nil
+	}
+
+	if state == nil {
+		state = &slack.ViewState{}
+	}
+	rows := make([]*inventory.TransferredCards, 0, len(metadata.Rows))
+	blockIDs := make(map[*inventory.TransferredCards]string)
+	for i, row := range metadata.Rows {
+		value := state.Values[fillBlockID(i)][actionFillQuantity].Value
+		if value == "" {
+			continue
+		}
+		quantity, err := strconv.ParseUint(value, 10, 0)
+		if err != nil {
+			return nil, map[string]string{fillBlockID(i): "Enter a whole number."}, nil
+		}
+		if quantity == 0 {
+			continue
+		}
+		transferRow := &inventory.TransferredCards{
+			Quantity: uint(quantity),
+			Card: &inventory.Card{
+				Name:       row.Name,
+				OracleID:   row.OracleID,
+				ScryfallID: row.ScryfallID,
+				Finish:     row.Finish,
+				Condition:  row.Condition,
+				Language:   row.Language,
+			},
+			Owner: row.Owner,
+		}
+		rows = append(rows, transferRow)
+		blockIDs[transferRow] = fillBlockID(i)
+	}
+	if len(rows) == 0 {
+		return nil, map[string]string{fillBlockID(0): "Choose at least one card to send."}, nil
+	}
+
+	transfer, err := s.Backend.OpenTransfer(ctx, request.Requestor, username, &request.ID, rows)
+	var rowErr *inventory.RowError
+	if errors.As(err, &rowErr) && errors.Is(rowErr, inventory.ErrTooFewCards) {
+		blockID := fillBlockID(0)
+		if row, ok := rowErr.Row.(*inventory.TransferredCards); ok && blockIDs[row] != "" {
+			blockID = blockIDs[row]
+		}
+		return nil, map[string]string{blockID: "You aren't keeping that many."}, nil
+	} else if err != nil {
+		return nil, nil, fmt.Errorf("error opening transfer to fill request %d: %w", request.ID, err)
+	}
+
+	return transfer, nil, nil
+}
+
+// handleFillRequestSubmission responds to the submitted modal to fill a
+// request, announcing the transfer in the request's channel
+func (s *Server) handleFillRequestSubmission(ctx context.Context, callback *slack.InteractionCallback) *slack.ViewSubmissionResponse {
+	var metadata fillRequestMetadata
+	err := json.Unmarshal([]byte(callback.View.PrivateMetadata), &metadata)
+	if err != nil {
+		log.Printf("Error unmarshaling metadata to fill request: %s", err.Error())
+		return nil
+	}
+
+	username, err := s.username(ctx, callback.Team.ID, callback.User.ID, callback.User.Name)
+	if err != nil {
+		log.Printf("Error filling request %d: %s", metadata.RequestID, err.Error())
+		return slack.NewErrorsViewSubmissionResponse(map[string]string{
+			fillBlockID(0): "Something went wrong looking you up.",
+		})
+	}
+
+	transfer, inputErrors, err := s.fillRequest(ctx, username, &metadata, callback.View.State)
+	if err != nil {
+		log.Printf("Error filling request %d: %s", metadata.RequestID, err.Error())
+		return slack.NewErrorsViewSubmissionResponse(map[string]string{
+			fillBlockID(0): "Something went wrong opening the transfer.",
+		})
+	}
+	if inputErrors != nil {
+		return slack.NewErrorsViewSubmissionResponse(inputErrors)
+	}
+
+	_, _, err = s.API.PostMessageContext(ctx, metadata.ChannelID, slack.MsgOptionBlocks(textBlocks(
+		fmt.Sprintf("*%s* opened transfer #%d of %d cards to *%s* for request #%d.",
+			transfer.FromUser, transfer.ID, transfer.Quantity, transfer.ToUser, metadata.RequestID),
+	)...))
+	if err != nil {
+		log.Printf("Error announcing transfer %d: %s", transfer.ID, err.Error())
+	}
+
+	return nil
+}
+
+func fillBlockID(i int) string {
+	return "row-" + strconv.Itoa(i)
+}
+
+// infoView returns a modal that only shows text
+func infoView(text string) *slack.ModalViewRequest {
+	return &slack.ModalViewRequest{
+		Type:   slack.VTModal,
+		Title:  slack.NewTextBlockObject(slack.PlainTextType, "Fill request", false, false),
+		Close:  slack.NewTextBlockObject(slack.PlainTextType, "Close", false, false),
+		Blocks: slack.Blocks{BlockSet: textBlocks(text)},
+	}
+}
@@ -0,0 +1,194 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+	"github.com/slack-go/slack"
+)
+
+func TestMTGCommandRequest(t *testing.T) {
+	s := newTestServer(t)
+	ctx := context.Background()
+
+	payload := s.handleMTGCommand(ctx, slack.SlashCommand{Command: MTGCommand, Text: "request 4 Lightning Bolt, 1 Black Lotus", TeamID: "T1", UserID: "UALICE", UserName: "alice"})
+	text, _ := blocksText(t, payload)
+	if payload["response_type"] != slack.ResponseTypeEphemeral || !strings.Contains(text, `couldn't find a card named "Black Lotus"`) {
+		t.Fatalf("Unexpected response to unknown card: %s", text)
+	}
+
+	payload = s.handleMTGCommand(ctx, slack.SlashCommand{Command: MTGCommand, Text: "request 4 Lightning Bolt, 1 Ragavan, Nimble Pilferer", TeamID: "T1", UserID: "UALICE", UserName: "alice"})
+	text, values := blocksText(t, payload)
+	if payload["response_type"] != slack.ResponseTypeInChannel {
+		t.Fatalf("Expected request to be posted in channel: %s", text)
+	}
+	if !strings.Contains(text, "• 4 Lightning Bolt") || !strings.Contains(text, "• 1 Ragavan, Nimble Pilferer") {
+		t.Fatalf("Unexpected request: %s", text)
+	}
+	if len(values) != 1 {
+		t.Fatalf("Expected a button to fill the request, got: %v", values)
+	}
+	requestID, err := strconv.ParseInt(values[0], 10, 64)
+	if err != nil {
+		t.Fatalf("Failed to parse request ID %q: %s", values[0], err.Error())
+	}
+
+	request, err := s.Backend.GetRequestByID(ctx, requestID, inventory.MaxListLimit, 0)
+	if err != nil {
+		t.Fatalf("Failed to get request: %s", err.Error())
+	}
+	if request.Requestor != "alice" || request.Quantity != 5 {
+		t.Fatalf("Unexpected request: %+v", request)
+	}
+}
+
+func TestFillRequest(t *testing.T) {
+	s := newTestServer(t)
+	ctx := context.Background()
+
+	request, err := s.Backend.OpenRequest(ctx, "alice", []*inventory.RequestedCards{
+		{
+			Quantity: 4,
+			Name:     "Lightning Bolt",
+			OracleID: "bolt-oracle",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to open request: %s", err.Error())
+	}
+
+	view, err := s.fillRequestView(ctx, request.ID, "bob", "C123")
+	if err != nil {
+		t.Fatalf("Failed to build view: %s", err.Error())
+	}
+	if view.CallbackID != "" || !strings.Contains(view.Blocks.BlockSet[0].(*slack.SectionBlock).Text.Text, "aren't keeping") {
+		t.Fatalf("Expected an info view when keeping no cards: %+v", view)
+	}
+
+	view, err = s.fillRequestView(ctx, request.ID, "alice", "C123")
+	if err != nil {
+		t.Fatalf("Failed to build view: %s", err.Error())
+	}
+	if view.CallbackID != "" {
+		t.Fatalf("Expected an info view for the requestor: %+v", view)
+	}
+
+	err = s.Backend.AddCards(ctx, []*inventory.CardRow{
+		{
+			Quantity: 3,
+			Card: &inventory.Card{
+				Name:       "Lightning Bolt",
+				OracleID:   "bolt-oracle",
+				ScryfallID: "bolt-m10",
+				Finish:     inventory.FinishNonfoil,
+				Condition:  inventory.ConditionNearMint,
+			},
+			Owner:  "bob",
+			Keeper: "bob",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to add cards: %s", err.Error())
+	}
+
+	view, err = s.fillRequestView(ctx, request.ID, "bob", "C123")
+	if err != nil {
+		t.Fatalf("Failed to build view: %s", err.Error())
+	}
+	if view.CallbackID != callbackFillRequest || len(view.Blocks.BlockSet) != 2 {
+		t.Fatalf("Expected a view with one input: %+v", view)
+	}
+	var metadata fillRequestMetadata
+	err = json.Unmarshal([]byte(view.PrivateMetadata), &metadata)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal metadata: %s", err.Error())
+	}
+	if metadata.RequestID != request.ID || metadata.ChannelID != "C123" || len(metadata.Rows) != 1 {
+		t.Fatalf("Unexpected metadata: %+v", metadata)
+	}
+
+	state := func(quantity string) *slack.ViewState {
+		return &slack.ViewState{
+			Values: map[string]map[string]slack.BlockAction{
+				fillBlockID(0): {actionFillQuantity: {Value: quantity}},
+			},
+		}
+	}
+
+	_, inputErrors, err := s.fillRequest(ctx, "bob", &metadata, state(""))
+	if err != nil || inputErrors[fillBlockID(0)] == "" {
+		t.Fatalf("Expected an input error when choosing nothing, got: %v, %v", inputErrors, err)
+	}
+
+	_, inputErrors, err = s.fillRequest(ctx, "bob", &metadata, state("4"))
+	if err != nil || inputErrors[fillBlockID(0)] == "" {
+		t.Fatalf("Expected an input error when choosing too many, got: %v, %v", inputErrors, err)
+	}
+
+	transfer, inputErrors, err := s.fillRequest(ctx, "bob", &metadata, state("2"))
+	if err != nil || inputErrors != nil {
+		t.Fatalf("Failed to fill request: %v, %v", inputErrors, err)
+	}
+	if transfer.ToUser != "alice" || transfer.FromUser != "bob" || transfer.RequestID == nil || *transfer.RequestID != request.ID {
+		t.Fatalf("Unexpected transfer: %+v", transfer)
+	}
+	if len(transfer.Cards) != 1 || transfer.Cards[0].Quantity != 2 || transfer.Cards[0].Card.Name != "Lightning Bolt" {
+		t.Fatalf("Unexpected transferred cards: %+v", transfer.Cards)
+	}
+}
+
+func TestFillRequestMetadataLimit(t *testing.T) {
+	s := newTestServer(t)
+	ctx := context.Background()
+
+	request, err := s.Backend.OpenRequest(ctx, "alice", []*inventory.RequestedCards{
+		{
+			Quantity: 4,
+			Name:     "Lightning Bolt",
+			OracleID: "bolt-oracle",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to open request: %s", err.Error())
+	}
+
+	rows := make([]*inventory.CardRow, 0, 50)
+	for i := 0; i < 50; i++ {
+		rows = append(rows, &inventory.CardRow{
+			Quantity: 1,
+			Card: &inventory.Card{
+				Name:       "Lightning Bolt",
+				OracleID:   "bolt-oracle",
+				ScryfallID: "bolt-" + strings.Repeat("0", 32) + strconv.Itoa(i),
+				Finish:     inventory.FinishNonfoil,
+				Condition:  inventory.ConditionNearMint,
+			},
+			Owner:  "bob",
+			Keeper: "bob",
+		})
+	}
+	err = s.Backend.AddCards(ctx, rows)
+	if err != nil {
+		t.Fatalf("Failed to add cards: %s", err.Error())
+	}
+
+	view, err := s.fillRequestView(ctx, request.ID, "bob", "C123")
+	if err != nil {
+		t.Fatalf("Failed to build view: %s", err.Error())
+	}
+	if len(view.PrivateMetadata) > fillRequestMaxMetadata {
+		t.Fatalf("Expected at most %d characters of metadata, got %d", fillRequestMaxMetadata, len(view.PrivateMetadata))
+	}
+	var metadata fillRequestMetadata
+	err = json.Unmarshal([]byte(view.PrivateMetadata), &metadata)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal metadata: %s", err.Error())
+	}
+	if len(metadata.Rows) == 0 || len(metadata.Rows) == len(rows) || len(view.Blocks.BlockSet) != len(metadata.Rows)+1 {
+		t.Fatalf("Expected some but not all rows to be offered, got %d rows and %d blocks", len(metadata.Rows), len(view.Blocks.BlockSet))
+	}
+}
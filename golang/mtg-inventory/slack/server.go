@@ -4,11 +4,14 @@ Package slack integrates the Backend and Scryfall into a Slack app.
 package slack
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/scryfall"
 	"github.com/slack-go/slack"
 	"github.com/slack-go/slack/socketmode"
 )
@@ -20,6 +23,9 @@ type Server struct {
 	Scryfall inventory.Scryfall
 	API      *slack.Client
 	Client   *socketmode.Client
+
+	// Admins contains the Slack user IDs allowed to run admin commands
+	Admins map[string]bool
 }
 
 // NewServer returns a new Server
@@ -47,11 +53,14 @@ func NewServer(
 		Scryfall: scryfall,
 		API:      api,
 		Client:   client,
+		Admins:   make(map[string]bool),
 	}
 	return server
 }
 
-// Serve consumes events until it fails and returns an error
+// Serve consumes events until it fails and returns an error. Each request
+// is handled in its own goroutine, so that a slow one doesn't hold up the
+// others.
 func (s *Server) Serve() error {
 	go func() {
 		for event := range s.Client.Events {
@@ -62,31 +71,8 @@ func (s *Server) Serve() error {
 				fmt.Println("Connection failed. Retrying later...")
 			case socketmode.EventTypeConnected:
 				fmt.Println("Connected to Slack with Socket Mode.")
-			case socketmode.EventTypeSlashCommand:
-				cmd, ok := event.Data.(slack.SlashCommand)
-				if !ok {
-					log.Printf("SlashCommand not a SlashCommand")
-					continue
-				}
-
-				switch cmd.Command {
-				case "/ping":
-					payload := map[string]interface{}{
-						"blocks": []slack.Block{
-							slack.NewSectionBlock(
-								&slack.TextBlockObject{
-									Type: slack.PlainTextType,
-									Text: "pong",
-								},
-								nil,
-								nil,
-							),
-						},
-					}
-					s.Client.Ack(*event.Request, payload)
-				default:
-					log.Printf("Unhandled slash command: %s", cmd.Command)
-				}
+			case socketmode.EventTypeSlashCommand, socketmode.EventTypeInteractive:
+				go s.handleEvent(event)
 			default:
 				log.Printf("Unhandled event type received: %s\n", event.Type)
 			}
@@ -95,3 +81,108 @@ func (s *Server) Serve() error {
 
 	return s.Client.Run()
 }
+
+// handleEvent handles a slash command or interaction and acknowledges it.
+// Block actions are acknowledged before they're handled, since their
+// responses aren't sent in the acknowledgement.
+func (s *Server) handleEvent(event socketmode.Event) {
+	switch event.Type {
+	case socketmode.EventTypeSlashCommand:
+		cmd, ok := event.Data.(slack.SlashCommand)
+		if !ok {
+			log.Printf("SlashCommand not a SlashCommand")
+			return
+		}
+
+		switch cmd.Command {
+		case "/ping":
+			text := "pong"
+			if dated, ok := s.Scryfall.(scryfall.Dated); ok && !dated.UpdatedAt().IsZero() {
+				text += fmt.Sprintf(" (card data from %s)", dated.UpdatedAt().Format(time.RFC3339))
+			}
+			payload := map[string]interface{}{
+				"blocks": []slack.Block{
+					slack.NewSectionBlock(
+						&slack.TextBlockObject{
+							Type: slack.PlainTextType,
+							Text: text,
+						},
+						nil,
+						nil,
+					),
+				},
+			}
+			s.Client.Ack(*event.Request, payload)
+		case MTGCommand:
+			payload := s.handleMTGCommand(context.Background(), cmd)
+			if payload != nil {
+				s.Client.Ack(*event.Request, payload)
+			} else {
+				s.Client.Ack(*event.Request)
+			}
+		default:
+			s.Client.Ack(*event.Request)
+			log.Printf("Unhandled slash command: %s", cmd.Command)
+		}
+	case socketmode.EventTypeInteractive:
+		callback, ok := event.Data.(slack.InteractionCallback)
+		if !ok {
+			log.Printf("Interactive not an InteractionCallback")
+			return
+		}
+
+		switch callback.Type {
+		case slack.InteractionTypeBlockActions:
+			s.Client.Ack(*event.Request)
+			for _, action := range callback.ActionCallback.BlockActions {
+				err := s.handleBlockAction(context.Background(), &callback, action)
+				if err != nil {
+					log.Printf("Error handling block action %q: %s", action.ActionID, err.Error())
+				}
+			}
+		case slack.InteractionTypeBlockSuggestion:
+			if callback.ActionID == actionCardName {
+				s.Client.Ack(*event.Request, s.cardOptions(callback.Value))
+			} else {
+				s.Client.Ack(*event.Request)
+				log.Printf("Unhandled block suggestion: %s", callback.ActionID)
+			}
+		case slack.InteractionTypeViewSubmission:
+			response := s.handleViewSubmission(context.Background(), &callback)
+			if response != nil {
+				s.Client.Ack(*event.Request, response)
+			} else {
+				s.Client.Ack(*event.Request)
+			}
+		default:
+			s.Client.Ack(*event.Request)
+			log.Printf("Unhandled interaction type: %s", callback.Type)
+		}
+	}
+}
+
+func (s *Server) handleBlockAction(ctx context.Context, callback *slack.InteractionCallback, action *slack.BlockAction) error {
+	switch action.ActionID {
+	case actionCardsPage:
+		return s.handleCardsPage(ctx, callback, action)
+	case actionFillRequest:
+		return s.handleFillRequest(ctx, callback, action)
+	default:
+		log.Printf("Unhandled block action: %s", action.ActionID)
+		return nil
+	}
+}
+
+func (s *Server) handleViewSubmission(ctx context.Context, callback *slack.InteractionCallback) *slack.ViewSubmissionResponse {
+	switch callback.View.CallbackID {
+	case callbackFillRequest:
+		return s.handleFillRequestSubmission(ctx, callback)
+	case callbackWhoHas:
+		return s.handleWhoHasSubmission(ctx, callback)
+	case callbackImport:
+		return s.handleImportSubmission(ctx, callback)
+	default:
+		log.Printf("Unhandled view submission: %s", callback.View.CallbackID)
+		return nil
+	}
+}
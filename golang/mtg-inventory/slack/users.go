@@ -0,0 +1,59 @@
+package slack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	inventory "github.com/benrm/mtg-inventory/golang/mtg-inventory"
+	"github.com/benrm/mtg-inventory/golang/mtg-inventory/chat"
+	"github.com/slack-go/slack"
+)
+
+// IdentityProvider is the provider of the identities linked by the Slack app
+const IdentityProvider = "slack"
+
+// mentionRegexp matches an escaped user mention, e.g. <@U123> or <@U123|ben>
+var mentionRegexp = regexp.MustCompile(`^<@([UW][A-Z0-9]+)(\|[^>]*)?>$`)
+
+// username returns the username linked to a Slack user in a workspace,
+// creating and linking a User on their first interaction
+func (s *Server) username(ctx context.Context, workspace, userID, handle string) (string, error) {
+	return chat.Username(ctx, s.Backend, IdentityProvider, workspace, userID, handle)
+}
+
+// linkUser handles /mtg link, which lets an admin link a Slack user to an
+// existing username
+func (s *Server) linkUser(ctx context.Context, cmd *slack.SlashCommand, args string) []slack.Block {
+	if !s.Admins[cmd.UserID] {
+		return textBlocks("Only admins can link accounts.")
+	}
+
+	fields := strings.Fields(args)
+	if len(fields) != 2 {
+		return textBlocks("Usage: `/mtg link <username> @user`")
+	}
+	username := fields[0]
+	matches := mentionRegexp.FindStringSubmatch(fields[1])
+	if matches == nil {
+		return textBlocks(fmt.Sprintf("%q is not a user mention.", fields[1]))
+	}
+
+	err := s.Backend.LinkIdentity(ctx, &inventory.Identity{
+		Provider:   IdentityProvider,
+		Workspace:  cmd.TeamID,
+		ExternalID: matches[1],
+		Username:   username,
+	})
+	if errors.Is(err, inventory.ErrUserNoExist) {
+		return textBlocks(fmt.Sprintf("There is no user named %q.", username))
+	} else if err != nil {
+		log.Printf("Error linking %s to %q: %s", matches[1], username, err.Error())
+		return textBlocks("Something went wrong linking that account.")
+	}
+
+	return textBlocks(fmt.Sprintf("Linked <@%s> to %s.", matches[1], username))
+}
@@ -0,0 +1,85 @@
+package slack
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+func TestUsername(t *testing.T) {
+	s := newTestServer(t)
+	ctx := context.Background()
+
+	username, err := s.username(ctx, "T1", "UALICE", "alice-renamed")
+	if err != nil {
+		t.Fatalf("Failed to get username: %s", err.Error())
+	}
+	if username != "alice" {
+		t.Fatalf("Expected linked username %q, got %q", "alice", username)
+	}
+
+	// A new user whose handle is taken gets a suffix rather than the
+	// existing user's cards
+	username, err = s.username(ctx, "T1", "UOTHERALICE", "alice")
+	if err != nil {
+		t.Fatalf("Failed to get username: %s", err.Error())
+	}
+	if username != "alice-2" {
+		t.Fatalf("Expected new username %q, got %q", "alice-2", username)
+	}
+	username, err = s.username(ctx, "T1", "UOTHERALICE", "alice")
+	if err != nil {
+		t.Fatalf("Failed to get username: %s", err.Error())
+	}
+	if username != "alice-2" {
+		t.Fatalf("Expected linked username %q, got %q", "alice-2", username)
+	}
+
+	// The same user ID in another workspace is another person
+	username, err = s.username(ctx, "T2", "UALICE", "carol")
+	if err != nil {
+		t.Fatalf("Failed to get username: %s", err.Error())
+	}
+	if username != "carol" {
+		t.Fatalf("Expected new username %q, got %q", "carol", username)
+	}
+}
+
+func TestMTGCommandLink(t *testing.T) {
+	s := newTestServer(t)
+	ctx := context.Background()
+
+	payload := s.handleMTGCommand(ctx, slack.SlashCommand{Command: MTGCommand, Text: "link bob <@UNEW|newbob>", TeamID: "T1", UserID: "UALICE", UserName: "alice"})
+	text, _ := blocksText(t, payload)
+	if !strings.Contains(text, "Only admins") {
+		t.Fatalf("Expected non-admin to be refused: %s", text)
+	}
+
+	payload = s.handleMTGCommand(ctx, slack.SlashCommand{Command: MTGCommand, Text: "link nobody <@UNEW|newbob>", TeamID: "T1", UserID: "UADMIN", UserName: "admin"})
+	text, _ = blocksText(t, payload)
+	if !strings.Contains(text, `no user named "nobody"`) {
+		t.Fatalf("Expected unknown username to be refused: %s", text)
+	}
+
+	payload = s.handleMTGCommand(ctx, slack.SlashCommand{Command: MTGCommand, Text: "link bob newbob", TeamID: "T1", UserID: "UADMIN", UserName: "admin"})
+	text, _ = blocksText(t, payload)
+	if !strings.Contains(text, "not a user mention") {
+		t.Fatalf("Expected bad mention to be refused: %s", text)
+	}
+
+	payload = s.handleMTGCommand(ctx, slack.SlashCommand{Command: MTGCommand, Text: "link bob <@UNEW|newbob>", TeamID: "T1", UserID: "UADMIN", UserName: "admin"})
+	text, _ = blocksText(t, payload)
+	if !strings.Contains(text, "Linked <@UNEW> to bob.") {
+		t.Fatalf("Unexpected link response: %s", text)
+	}
+
+	username, err := s.username(ctx, "T1", "UNEW", "newbob")
+	if err != nil {
+		t.Fatalf("Failed to get username: %s", err.Error())
+	}
+	if username != "bob" {
+		t.Fatalf("Expected linked username %q, got %q", "bob", username)
+	}
+}
@@ -1,18 +1,103 @@
 package inventory
 
-import "time"
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
 
 // User represents a user in the users table
 type User struct {
 	Username string `json:"username"`
 }
 
-// Card represents a Card
+// Identity links an account on an external service, such as a Slack user in a
+// workspace, to a User
+type Identity struct {
+	Provider   string `json:"provider"`
+	Workspace  string `json:"workspace"`
+	ExternalID string `json:"external_id"`
+	Username   string `json:"username"`
+}
+
+// Finish is the finish of a printed card, as Scryfall names it
+type Finish string
+
+// Finishes a card can be printed in
+const (
+	FinishNonfoil Finish = "nonfoil"
+	FinishFoil    Finish = "foil"
+	FinishEtched  Finish = "etched"
+)
+
+// Finishes contains every Finish
+var Finishes = []Finish{FinishNonfoil, FinishFoil, FinishEtched}
+
+// IsFoil returns whether the finish is a kind of foil
+func (f Finish) IsFoil() bool {
+	return f == FinishFoil || f == FinishEtched
+}
+
+// ParseFinish parses the name of a finish, ignoring case
+func ParseFinish(s string) (Finish, error) {
+	finish := Finish(strings.ToLower(strings.TrimSpace(s)))
+	if !slices.Contains(Finishes, finish) {
+		return "", fmt.Errorf("finish %q: %w", s, ErrInvalidFinish)
+	}
+	return finish, nil
+}
+
+// Condition is how worn a card is, on the scale most stores use
+type Condition string
+
+// Conditions a card can be in, from best to worst
+const (
+	ConditionNearMint         Condition = "NM"
+	ConditionLightlyPlayed    Condition = "LP"
+	ConditionModeratelyPlayed Condition = "MP"
+	ConditionHeavilyPlayed    Condition = "HP"
+	ConditionDamaged          Condition = "DMG"
+)
+
+// Conditions contains every Condition, from best to worst
+var Conditions = []Condition{ConditionNearMint, ConditionLightlyPlayed, ConditionModeratelyPlayed, ConditionHeavilyPlayed, ConditionDamaged}
+
+// ParseCondition parses the abbreviation of a condition, ignoring case
+func ParseCondition(s string) (Condition, error) {
+	condition := Condition(strings.ToUpper(strings.TrimSpace(s)))
+	if !slices.Contains(Conditions, condition) {
+		return "", fmt.Errorf("condition %q: %w", s, ErrInvalidCondition)
+	}
+	return condition, nil
+}
+
+// Card represents a Card. Copies of a card with the same Scryfall ID are
+// told apart by their finish, condition and language, where an empty
+// language is that of the printing.
 type Card struct {
-	Name       string `json:"name"`
-	OracleID   string `json:"oracle_id"`
-	ScryfallID string `json:"scryfall_id"`
-	Foil       bool   `json:"foil"`
+	Name       string    `json:"name"`
+	OracleID   string    `json:"oracle_id"`
+	ScryfallID string    `json:"scryfall_id"`
+	Finish     Finish    `json:"finish"`
+	Condition  Condition `json:"condition"`
+	Language   string    `json:"language,omitempty"`
+}
+
+// Check returns ErrNoCard if there's no card, and ErrInvalidFinish or
+// ErrInvalidCondition if the card's finish or condition isn't one of the
+// known ones
+func (c *Card) Check() error {
+	if c == nil {
+		return ErrNoCard
+	}
+	if !slices.Contains(Finishes, c.Finish) {
+		return fmt.Errorf("finish %q: %w", c.Finish, ErrInvalidFinish)
+	}
+	if !slices.Contains(Conditions, c.Condition) {
+		return fmt.Errorf("condition %q: %w", c.Condition, ErrInvalidCondition)
+	}
+	return nil
 }
 
 // CardRow represents a row in the cards table
@@ -59,7 +144,58 @@ type TransferredCards struct {
 	Owner    string `json:"owner"`
 }
 
-// HTTPError is the type used to marshal errors into JSON
+// Zone is the part of a deck that a card is played from
+type Zone string
+
+// Zones of a deck
+const (
+	ZoneCommander Zone = "commander"
+	ZoneMainboard Zone = "mainboard"
+	ZoneSideboard Zone = "sideboard"
+)
+
+// Zones contains every Zone, in the order decks list them
+var Zones = []Zone{ZoneCommander, ZoneMainboard, ZoneSideboard}
+
+// ParseZone parses the name of a zone, ignoring case
+func ParseZone(s string) (Zone, error) {
+	zone := Zone(strings.ToLower(strings.TrimSpace(s)))
+	if !slices.Contains(Zones, zone) {
+		return "", fmt.Errorf("zone %q: %w", s, ErrInvalidZone)
+	}
+	return zone, nil
+}
+
+// Deck represents a row in the decks table. Its cards are copies that its
+// owner owns, whoever is keeping them.
+type Deck struct {
+	ID       int64        `json:"id"`
+	Name     string       `json:"name"`
+	Owner    string       `json:"owner"`
+	Quantity uint         `json:"quantity"`
+	Cards    []*DeckCards `json:"cards"`
+}
+
+// DeckCards represents a row in the deck_cards table
+type DeckCards struct {
+	Zone     Zone  `json:"zone"`
+	Quantity uint  `json:"quantity"`
+	Card     *Card `json:"card"`
+}
+
+// Check returns ErrInvalidZone if the zone isn't one of Zones, or the error
+// from checking the card
+func (dc *DeckCards) Check() error {
+	if !slices.Contains(Zones, dc.Zone) {
+		return fmt.Errorf("zone %q: %w", dc.Zone, ErrInvalidZone)
+	}
+	return dc.Card.Check()
+}
+
+// HTTPError is the type used to marshal errors into JSON. Code identifies
+// the error for programs, since the message may change.
 type HTTPError struct {
 	Error string `json:"error"`
+	Code  string `json:"code,omitempty"`
+	Row   any    `json:"row,omitempty"`
 }
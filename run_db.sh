@@ -1,7 +1,9 @@
 #!/bin/sh
 set -e
 
+# The schema is created by the migrations embedded in the backend, so start the
+# server with -migrate against a fresh database
+
 podman run -d --name db --env-file ./.env \
     --env MYSQL_DATABASE=mtg_inventory --publish 33006:3306 \
-    --volume $(pwd)/sql:/docker-entrypoint-initdb.d \
     docker.io/mysql:8
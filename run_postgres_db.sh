@@ -0,0 +1,9 @@
+#!/bin/sh
+set -e
+
+# The schema is created by the migrations embedded in the backend, so start the
+# server with -migrate against a fresh database
+
+podman run -d --name db --env-file ./.env \
+    --env POSTGRES_DB=mtg_inventory --publish 5432:5432 \
+    docker.io/postgres:16